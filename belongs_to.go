@@ -0,0 +1,81 @@
+package jsonapi
+
+import "fmt"
+
+// errNoInverse returns the error returned when the relationship of f has no
+// inverse relationship to filter the children with.
+func (f BelongsToFilter) errNoInverse() error {
+	return fmt.Errorf("jsonapi: relationship %q of type %q has no inverse relationship",
+		f.Name, f.Type)
+}
+
+// Match reports whether res belongs to the parent, which means that the
+// relationship of res named ToName points to the parent. The types found in the
+// linkage of res are used if it is a LinkageHolder.
+//
+// false is returned if there is no inverse relationship.
+func (f BelongsToFilter) Match(res Resource) bool {
+	rel, ok := res.Rels()[f.ToName]
+	if f.ToName == "" || !ok {
+		return false
+	}
+
+	for _, iden := range relIdentifiers(res, rel) {
+		if iden.ID == f.ID && iden.Type == f.Type {
+			return true
+		}
+	}
+
+	return false
+}
+
+// FilterCollection returns a collection with the resources of col that belong
+// to the parent (see Match), in the same order. This is what a nested route
+// like /users/u1/articles returns when the resources are in memory.
+//
+// col is returned as it is if f is the zero value, which is the case for a URL
+// without a parent. An error is returned if there is no inverse relationship.
+func (f BelongsToFilter) FilterCollection(col Collection) (Collection, error) {
+	if f == (BelongsToFilter{}) {
+		return col, nil
+	}
+
+	if f.ToName == "" {
+		return nil, f.errNoInverse()
+	}
+
+	children := Resources{}
+
+	for i := 0; i < col.Len(); i++ {
+		if res := col.At(i); f.Match(res) {
+			children = append(children, res)
+		}
+	}
+
+	return &children, nil
+}
+
+// Condition returns a condition that selects the resources of type typ that
+// belong to the parent, for a filter expression tree like the ones returned by
+// ParseFilter. The condition is on the inverse relationship, which must be a
+// to-one relationship of typ, so that it can be compiled by an SQLCompiler for
+// example.
+//
+// nil is returned if f is the zero value.
+func (f BelongsToFilter) Condition(typ Type) (*Condition, error) {
+	if f == (BelongsToFilter{}) {
+		return nil, nil
+	}
+
+	rel, ok := typ.Rels[f.ToName]
+	if f.ToName == "" || !ok {
+		return nil, f.errNoInverse()
+	}
+
+	if !rel.ToOne {
+		return nil, fmt.Errorf("jsonapi: inverse relationship %q of type %q is not to-one",
+			rel.FromName, typ.Name)
+	}
+
+	return &Condition{Field: rel.FromName, Op: FilterEq, Values: []string{f.ID}}, nil
+}
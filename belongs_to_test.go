@@ -0,0 +1,80 @@
+package jsonapi_test
+
+import (
+	"testing"
+
+	. "github.com/mark-hartmann/jsonapi"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBelongsToFilter(t *testing.T) {
+	assert := assert.New(t)
+
+	users := Type{Name: "users"}
+	users.MustAddRel(Rel{FromName: "articles", ToType: "articles", ToName: "author", FromOne: true})
+	users.MustAddRel(Rel{FromName: "favorites", ToType: "articles"})
+
+	articles := Type{Name: "articles"}
+	articles.MustAddRel(Rel{FromName: "author", ToType: "users", ToOne: true, ToName: "articles"})
+	articles.MustAddRel(Rel{FromName: "fans", ToType: "users", ToName: "liked"})
+
+	schema := &Schema{}
+	schema.MustAddType(users)
+	schema.MustAddType(articles)
+
+	col := Resources{}
+
+	for _, ids := range [][2]string{{"a1", "u1"}, {"a2", "u2"}, {"a3", "u1"}, {"a4", ""}} {
+		res := &SoftResource{Type: &articles}
+		res.SetID(ids[0])
+		res.Set("author", ids[1])
+		col.Add(res)
+	}
+
+	url, err := NewURLFromRaw(schema, "/users/u1/articles")
+	assert.NoError(err)
+
+	f := url.BelongsToFilter
+	assert.True(f.Match(col.At(0)))
+	assert.False(f.Match(col.At(1)))
+
+	children, err := f.FilterCollection(&col)
+	assert.NoError(err)
+
+	if assert.Equal(2, children.Len()) {
+		assert.Equal("a1", children.At(0).Get("id"))
+		assert.Equal("a3", children.At(1).Get("id"))
+	}
+
+	cond, err := f.Condition(articles)
+	assert.NoError(err)
+	assert.Equal(&Condition{Field: "author", Op: FilterEq, Values: []string{"u1"}}, cond)
+
+	// Without a parent
+	url, _ = NewURLFromRaw(schema, "/articles")
+
+	children, err = url.BelongsToFilter.FilterCollection(&col)
+	assert.NoError(err)
+	assert.Equal(&col, children)
+
+	cond, err = url.BelongsToFilter.Condition(articles)
+	assert.NoError(err)
+	assert.Nil(cond)
+
+	// Without an inverse relationship
+	url, _ = NewURLFromRaw(schema, "/users/u1/favorites")
+	assert.False(url.BelongsToFilter.Match(col.At(0)))
+
+	_, err = url.BelongsToFilter.FilterCollection(&col)
+	assert.EqualError(err,
+		`jsonapi: relationship "favorites" of type "users" has no inverse relationship`)
+
+	_, err = url.BelongsToFilter.Condition(articles)
+	assert.Error(err)
+
+	// To-many inverse relationship
+	f = BelongsToFilter{Type: "users", ID: "u1", Name: "liked", ToName: "fans"}
+	_, err = f.Condition(articles)
+	assert.EqualError(err, `jsonapi: inverse relationship "fans" of type "articles" is not to-one`)
+}
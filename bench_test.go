@@ -0,0 +1,24 @@
+package jsonapi_test
+
+import (
+	"testing"
+
+	. "github.com/mark-hartmann/jsonapi"
+	"github.com/mark-hartmann/jsonapi/jsonapitest"
+)
+
+func BenchmarkMarshalSmallResource(b *testing.B) {
+	jsonapitest.BenchmarkSmallResource(b, MarshalOptions{})
+}
+
+func BenchmarkMarshalLargeDocument(b *testing.B) {
+	jsonapitest.BenchmarkLargeDocument(b, MarshalOptions{})
+}
+
+func BenchmarkMarshalSmallResourceNoPooling(b *testing.B) {
+	jsonapitest.BenchmarkSmallResource(b, MarshalOptions{DisablePooling: true})
+}
+
+func BenchmarkMarshalLargeDocumentNoPooling(b *testing.B) {
+	jsonapitest.BenchmarkLargeDocument(b, MarshalOptions{DisablePooling: true})
+}
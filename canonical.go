@@ -0,0 +1,94 @@
+package jsonapi
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"sort"
+)
+
+// CanonicalizeJSON returns the canonical form of a marshaled document.
+//
+// In the canonical form, object members are sorted by key, included resources
+// are sorted by type and ID, and the resource identifiers of to-many
+// relationships are sorted by type and ID. The order of the primary data is
+// left untouched. Numbers are kept as they are, and no HTML escaping is done.
+//
+// Two documents holding the same information have the same canonical form,
+// which makes it suitable for golden files, diffs and content-addressed
+// caching.
+func CanonicalizeJSON(data []byte) ([]byte, error) {
+	var doc map[string]interface{}
+	if err := unmarshalJSONNumbers(data, &doc); err != nil {
+		return nil, fmt.Errorf("jsonapi: failed to canonicalize document: %w", err)
+	}
+
+	switch data := doc["data"].(type) {
+	case map[string]interface{}:
+		sortLinkage(data)
+	case []interface{}:
+		for _, res := range data {
+			if res, ok := res.(map[string]interface{}); ok {
+				sortLinkage(res)
+			}
+		}
+	}
+
+	if included, ok := doc["included"].([]interface{}); ok {
+		for _, res := range included {
+			if res, ok := res.(map[string]interface{}); ok {
+				sortLinkage(res)
+			}
+		}
+
+		sortIdentifiers(included)
+	}
+
+	buf := &bytes.Buffer{}
+	enc := json.NewEncoder(buf)
+	enc.SetEscapeHTML(false)
+
+	if err := enc.Encode(doc); err != nil {
+		return nil, fmt.Errorf("jsonapi: failed to canonicalize document: %w", err)
+	}
+
+	// Encode adds a newline.
+	return bytes.TrimSuffix(buf.Bytes(), []byte("\n")), nil
+}
+
+// sortLinkage sorts the resource identifiers of the to-many relationships of
+// res, an unmarshaled resource object.
+func sortLinkage(res map[string]interface{}) {
+	rels, _ := res["relationships"].(map[string]interface{})
+
+	for _, rel := range rels {
+		if rel, ok := rel.(map[string]interface{}); ok {
+			if data, ok := rel["data"].([]interface{}); ok {
+				sortIdentifiers(data)
+			}
+		}
+	}
+}
+
+// sortIdentifiers sorts unmarshaled resource objects or resource identifiers
+// by type and ID.
+func sortIdentifiers(objs []interface{}) {
+	key := func(obj interface{}) (string, string) {
+		m, _ := obj.(map[string]interface{})
+		typ, _ := m["type"].(string)
+		id, _ := m["id"].(string)
+
+		return typ, id
+	}
+
+	sort.SliceStable(objs, func(i, j int) bool {
+		typ1, id1 := key(objs[i])
+		typ2, id2 := key(objs[j])
+
+		if typ1 != typ2 {
+			return typ1 < typ2
+		}
+
+		return id1 < id2
+	})
+}
@@ -0,0 +1,61 @@
+package jsonapi_test
+
+import (
+	"testing"
+
+	. "github.com/mark-hartmann/jsonapi"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCanonicalizeJSON(t *testing.T) {
+	assert := assert.New(t)
+
+	doc1 := []byte(`{
+		"jsonapi": {"version": "1.0"},
+		"data": [
+			{"type": "b", "id": "2", "attributes": {"z": 1.50, "a": "<&>"}},
+			{
+				"type": "a",
+				"id": "1",
+				"relationships": {
+					"rel": {"data": [{"type": "b", "id": "2"}, {"type": "a", "id": "3"}]},
+					"one": {"data": {"type": "b", "id": "1"}}
+				}
+			}
+		],
+		"included": [
+			{"type": "b", "id": "1"},
+			{"type": "a", "id": "3", "meta": {"y": 2, "x": 1}}
+		]
+	}`)
+
+	doc2 := []byte(`{"included":[{"meta":{"x":1,"y":2},"id":"3","type":"a"},{"id":"1","type":"b"}],
+		"data":[{"attributes":{"a":"<&>","z":1.50},"id":"2","type":"b"},{"id":"1","type":"a",
+		"relationships":{"one":{"data":{"id":"1","type":"b"}},"rel":{"data":[{"id":"3",
+		"type":"a"},{"id":"2","type":"b"}]}}}],"jsonapi":{"version":"1.0"}}`)
+
+	expected := `{"data":[{"attributes":{"a":"<&>","z":1.50},"id":"2","type":"b"},` +
+		`{"id":"1","relationships":{"one":{"data":{"id":"1","type":"b"}},` +
+		`"rel":{"data":[{"id":"3","type":"a"},{"id":"2","type":"b"}]}},"type":"a"}],` +
+		`"included":[{"id":"3","meta":{"x":1,"y":2},"type":"a"},{"id":"1","type":"b"}],` +
+		`"jsonapi":{"version":"1.0"}}`
+
+	c1, err := CanonicalizeJSON(doc1)
+	assert.NoError(err)
+	assert.Equal(expected, string(c1))
+
+	c2, err := CanonicalizeJSON(doc2)
+	assert.NoError(err)
+	assert.Equal(expected, string(c2))
+
+	// A single resource
+	c, err := CanonicalizeJSON([]byte(`{"data": {"type": "a", "id": "1",
+		"relationships": {"rel": {"data": [{"type": "a", "id": "2"}, {"type": "a", "id": "10"}]}}}}`))
+	assert.NoError(err)
+	assert.Equal(`{"data":{"id":"1","relationships":{"rel":{"data":[{"id":"10","type":"a"},`+
+		`{"id":"2","type":"a"}]}},"type":"a"}}`, string(c))
+
+	_, err = CanonicalizeJSON([]byte(`{"data": `))
+	assert.Error(err)
+}
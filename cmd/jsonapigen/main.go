@@ -0,0 +1,80 @@
+// Command jsonapigen generates Go resources from a schema definition file
+// (see jsonapi.ReadSchema and jsonapi.GenerateGo). It is meant to be run by
+// go generate, which sets the name of the package:
+//
+//	//go:generate jsonapigen -schema schema.json -o models_gen.go
+//
+// The names of the structs can be set with the -names flag, like
+// -names people=Person,articles=Article.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"strings"
+
+	"github.com/mark-hartmann/jsonapi"
+)
+
+func main() {
+	var (
+		schemaPath = flag.String("schema", "", "path of the JSON schema definition file")
+		pkg        = flag.String("package", os.Getenv("GOPACKAGE"), "name of the generated package")
+		out        = flag.String("o", "", "path of the generated file (standard output if empty)")
+		names      = flag.String("names", "", "struct names by type, like people=Person")
+	)
+
+	flag.Parse()
+
+	if err := run(*schemaPath, *pkg, *out, *names); err != nil {
+		fmt.Fprintln(os.Stderr, "jsonapigen:", err)
+		os.Exit(1)
+	}
+}
+
+func run(schemaPath, pkg, out, names string) error {
+	if schemaPath == "" {
+		return fmt.Errorf("the -schema flag is required")
+	}
+
+	f, err := os.Open(schemaPath)
+	if err != nil {
+		return err
+	}
+
+	defer func() { _ = f.Close() }()
+
+	schema, err := jsonapi.ReadSchema(f)
+	if err != nil {
+		return err
+	}
+
+	opts := jsonapi.GenerateOptions{Package: pkg, TypeNames: map[string]string{}}
+
+	for _, pair := range strings.Split(names, ",") {
+		if pair == "" {
+			continue
+		}
+
+		parts := strings.SplitN(pair, "=", 2)
+		if len(parts) != 2 {
+			return fmt.Errorf("invalid struct name %q", pair)
+		}
+
+		opts.TypeNames[parts[0]] = parts[1]
+	}
+
+	src, err := jsonapi.GenerateGo(schema, opts)
+	if err != nil {
+		return err
+	}
+
+	if out == "" {
+		_, err = os.Stdout.Write(src)
+		return err
+	}
+
+	return ioutil.WriteFile(out, src, 0o600)
+}
@@ -0,0 +1,192 @@
+package jsonapi
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"strconv"
+)
+
+// NumberCoercion defines which JSON values are accepted for the numeric
+// attribute types.
+type NumberCoercion int
+
+// Number coercions
+const (
+	// CoerceStrict only accepts JSON numbers that have the form of the type
+	// of the attribute, so 1.0 is not a valid int.
+	CoerceStrict NumberCoercion = iota
+	// CoerceLenient also accepts integral numbers with a fraction or an
+	// exponent (like 1.0 or 1e3) for the integer types, and strings holding
+	// a number (like "12") for all the numeric types. The values must still
+	// fit in the type of the attribute.
+	CoerceLenient
+)
+
+// UnmarshalToType unmarshals data like the UnmarshalToType function does,
+// except that the numeric values are first coerced according to the options.
+func (o UnmarshalOptions) UnmarshalToType(data []byte, attr Attr) (interface{}, error) {
+	if o.Numbers == CoerceLenient {
+		var err error
+		if data, err = coerceNumbers(data, attr); err != nil {
+			return nil, err
+		}
+	}
+
+	return UnmarshalToType(data, attr)
+}
+
+// coerceNumbers rewrites the value or the elements of the array in data as
+// plain JSON numbers that the unmarshaler of the numeric type of attr accepts.
+// data is returned as is for the other types.
+func coerceNumbers(data []byte, attr Attr) ([]byte, error) {
+	isFloat := attr.Type == AttrTypeFloat32 || attr.Type == AttrTypeFloat64
+	if !isFloat && !isIntAttrType(attr.Type) {
+		return data, nil
+	}
+
+	if !attr.Array {
+		return coerceNumber(data, attr.Type)
+	}
+
+	var elems []json.RawMessage
+	if err := json.Unmarshal(data, &elems); err != nil || elems == nil {
+		// Let the unmarshaler report the error.
+		return data, nil
+	}
+
+	for i := range elems {
+		n, err := coerceNumber(elems[i], attr.Type)
+		if err != nil {
+			return nil, err
+		}
+
+		elems[i] = n
+	}
+
+	return json.Marshal(elems)
+}
+
+// coerceNumber rewrites the JSON value data as a plain number for the numeric
+// attribute type typ. null is returned as is.
+func coerceNumber(data []byte, typ int) ([]byte, error) {
+	raw := string(data)
+	if raw == "null" {
+		return data, nil
+	}
+
+	if len(raw) >= 2 && raw[0] == '"' {
+		if err := json.Unmarshal(data, &raw); err != nil {
+			return nil, err
+		}
+	}
+
+	f, _, err := big.ParseFloat(raw, 10, 256, big.ToNearestEven)
+	if err != nil || f.IsInf() {
+		return nil, fmt.Errorf("%q is not a number", raw)
+	}
+
+	// A number can be so small that it is rounded to zero.
+	if f.Sign() == 0 && !isZeroNumber(raw) {
+		return nil, fmt.Errorf("%s is out of range for %s", raw, registry.names[typ])
+	}
+
+	if !isIntAttrType(typ) {
+		f64, err := strconv.ParseFloat(raw, 64)
+		if err != nil {
+			return nil, err
+		}
+
+		if f64 == 0 && !isZeroNumber(raw) {
+			return nil, fmt.Errorf("%s is out of range for %s", raw, registry.names[typ])
+		}
+
+		return []byte(strconv.FormatFloat(f64, 'g', -1, 64)), nil
+	}
+
+	if !f.IsInt() {
+		return nil, fmt.Errorf("%s is not an integer", raw)
+	}
+
+	// No integer type has more than 64 bits, so there is no need to write
+	// the digits of a bigger number.
+	if f.MantExp(nil) > 65 {
+		return nil, fmt.Errorf("%s is %w for %s", raw, errIntRange, registry.names[typ])
+	}
+
+	n := "0"
+	if f.Sign() != 0 {
+		n = f.Text('f', 0)
+	}
+
+	if err := checkIntRange(n, typ); err != nil {
+		return nil, err
+	}
+
+	return []byte(n), nil
+}
+
+// isZeroNumber reports whether the digits of the number s, before its
+// exponent, are all zeros.
+func isZeroNumber(s string) bool {
+	for _, c := range s {
+		switch {
+		case c == 'e' || c == 'E':
+			return true
+		case c >= '1' && c <= '9':
+			return false
+		}
+	}
+
+	return true
+}
+
+// isIntAttrType reports whether typ is one of the integer attribute types.
+func isIntAttrType(typ int) bool {
+	switch typ {
+	case AttrTypeInt, AttrTypeInt8, AttrTypeInt16, AttrTypeInt32, AttrTypeInt64,
+		AttrTypeUint, AttrTypeUint8, AttrTypeUint16, AttrTypeUint32, AttrTypeUint64:
+		return true
+	}
+
+	return false
+}
+
+// errIntRange is returned when an integer does not fit in its attribute type.
+var errIntRange = errors.New("out of range")
+
+// checkIntRange returns an error if the integer s does not fit in the integer
+// attribute type typ.
+func checkIntRange(s string, typ int) error {
+	var err error
+
+	switch typ {
+	case AttrTypeInt:
+		_, err = strconv.ParseInt(s, 10, strconv.IntSize)
+	case AttrTypeInt8:
+		_, err = strconv.ParseInt(s, 10, 8)
+	case AttrTypeInt16:
+		_, err = strconv.ParseInt(s, 10, 16)
+	case AttrTypeInt32:
+		_, err = strconv.ParseInt(s, 10, 32)
+	case AttrTypeInt64:
+		_, err = strconv.ParseInt(s, 10, 64)
+	case AttrTypeUint:
+		_, err = strconv.ParseUint(s, 10, strconv.IntSize)
+	case AttrTypeUint8:
+		_, err = strconv.ParseUint(s, 10, 8)
+	case AttrTypeUint16:
+		_, err = strconv.ParseUint(s, 10, 16)
+	case AttrTypeUint32:
+		_, err = strconv.ParseUint(s, 10, 32)
+	case AttrTypeUint64:
+		_, err = strconv.ParseUint(s, 10, 64)
+	}
+
+	if err != nil {
+		return fmt.Errorf("%s is %w for %s", s, errIntRange, registry.names[typ])
+	}
+
+	return nil
+}
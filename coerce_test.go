@@ -0,0 +1,165 @@
+package jsonapi_test
+
+import (
+	"errors"
+	"testing"
+
+	. "github.com/mark-hartmann/jsonapi"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestUnmarshalOptionsNumbers(t *testing.T) {
+	lenient := UnmarshalOptions{Numbers: CoerceLenient}
+
+	tests := []struct {
+		name     string
+		attr     Attr
+		data     string
+		expected interface{}
+		strict   bool // whether the strict mode accepts data
+		fails    bool
+	}{
+		{
+			name:     "int",
+			attr:     Attr{Type: AttrTypeInt},
+			data:     `12`,
+			expected: 12,
+			strict:   true,
+		}, {
+			name:     "integral float for int",
+			attr:     Attr{Type: AttrTypeInt},
+			data:     `1.0`,
+			expected: 1,
+		}, {
+			name:     "exponent for int64",
+			attr:     Attr{Type: AttrTypeInt64},
+			data:     `-1.5e3`,
+			expected: int64(-1500),
+		}, {
+			name:     "numeric string for uint16",
+			attr:     Attr{Type: AttrTypeUint16},
+			data:     `"65535"`,
+			expected: uint16(65535),
+		}, {
+			name:     "numeric string for nullable int8",
+			attr:     Attr{Type: AttrTypeInt8, Nullable: true},
+			data:     `"-0"`,
+			expected: func() *int8 { n := int8(0); return &n }(),
+		}, {
+			name:     "numeric string for float64",
+			attr:     Attr{Type: AttrTypeFloat64},
+			data:     `"2.5"`,
+			expected: 2.5,
+		}, {
+			name:     "array of int32",
+			attr:     Attr{Type: AttrTypeInt32, Array: true},
+			data:     `[1, 2.0, "3", "4e0"]`,
+			expected: []int32{1, 2, 3, 4},
+		}, {
+			name:     "null",
+			attr:     Attr{Type: AttrTypeInt, Nullable: true},
+			data:     `null`,
+			expected: (*int)(nil),
+			strict:   true,
+		}, {
+			name:  "fraction for int",
+			attr:  Attr{Type: AttrTypeInt},
+			data:  `1.5`,
+			fails: true,
+		}, {
+			name:  "overflow for int8",
+			attr:  Attr{Type: AttrTypeInt8},
+			data:  `128.0`,
+			fails: true,
+		}, {
+			name:  "negative uint",
+			attr:  Attr{Type: AttrTypeUint},
+			data:  `"-1"`,
+			fails: true,
+		}, {
+			name:  "huge exponent for int64",
+			attr:  Attr{Type: AttrTypeInt64},
+			data:  `1e1000000`,
+			fails: true,
+		}, {
+			name:  "underflow for int",
+			attr:  Attr{Type: AttrTypeInt},
+			data:  `"1e-1000000000"`,
+			fails: true,
+		}, {
+			name:  "underflow for float64",
+			attr:  Attr{Type: AttrTypeFloat64},
+			data:  `1e-400`,
+			fails: true,
+		}, {
+			name:     "zero with an exponent",
+			attr:     Attr{Type: AttrTypeFloat64},
+			data:     `"0.0e-1000000000"`,
+			expected: 0.0,
+		}, {
+			name:  "overflow in array",
+			attr:  Attr{Type: AttrTypeUint8, Array: true},
+			data:  `[1, "256"]`,
+			fails: true,
+		}, {
+			name:  "not a number",
+			attr:  Attr{Type: AttrTypeFloat32},
+			data:  `"Inf"`,
+			fails: true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			assert := assert.New(t)
+
+			v, err := lenient.UnmarshalToType([]byte(test.data), test.attr)
+			if test.fails {
+				assert.Error(err)
+				return
+			}
+
+			assert.NoError(err)
+			assert.Equal(test.expected, v)
+
+			_, err = UnmarshalOptions{}.UnmarshalToType([]byte(test.data), test.attr)
+			if test.strict {
+				assert.NoError(err)
+			} else {
+				assert.Error(err)
+			}
+		})
+	}
+
+	// Other types are not affected.
+	_, err := lenient.UnmarshalToType([]byte(`12`), Attr{Type: AttrTypeString})
+	assert.Error(t, err)
+}
+
+func TestUnmarshalOptionsNumbersResource(t *testing.T) {
+	assert := assert.New(t)
+
+	schema := newMockSchema()
+	opts := UnmarshalOptions{Numbers: CoerceLenient}
+
+	res, err := opts.UnmarshalResource([]byte(`{
+		"id": "id1",
+		"type": "mocktypes1",
+		"attributes": {"int": "10", "uint8": 3.0}
+	}`), schema)
+	assert.NoError(err)
+	assert.Equal(10, res.Get("int"))
+	assert.Equal(uint8(3), res.Get("uint8"))
+
+	_, err = opts.UnmarshalResource([]byte(`{
+		"id": "id1",
+		"type": "mocktypes1",
+		"attributes": {"uint8": 300}
+	}`), schema)
+
+	var fieldErr *InvalidFieldValueError
+
+	assert.True(errors.As(err, &fieldErr))
+	assert.Equal("uint8", fieldErr.Field)
+}
@@ -0,0 +1,77 @@
+package jsonapi
+
+import "fmt"
+
+// DefaultCollationParam is the query parameter used by Collations to select a
+// collation when Collations.Param is empty, like ?x-collation=fr.
+const DefaultCollationParam = "x-collation"
+
+// A Collator compares strings according to the rules of a language, so that
+// non-ASCII strings are sorted the way users expect. CompareString returns -1,
+// 0 or 1 if a is respectively less than, equal to or greater than b.
+//
+// *collate.Collator from golang.org/x/text/collate implements it.
+type Collator interface {
+	CompareString(a, b string) int
+}
+
+// CollatorFunc is a function that implements Collator.
+type CollatorFunc func(a, b string) int
+
+// CompareString calls f(a, b).
+func (f CollatorFunc) CompareString(a, b string) int {
+	return f(a, b)
+}
+
+// Collations holds the collators a client can select with a query parameter.
+type Collations struct {
+	// Param is the name of the query parameter holding the name of the
+	// collation. DefaultCollationParam is used if it is empty.
+	Param string
+
+	// Collators holds the collators by name, like language tags.
+	Collators map[string]Collator
+
+	// Default is the collator used when the parameter is missing. A nil
+	// collator compares the strings byte-wise.
+	Default Collator
+}
+
+// Select returns the collator selected by the query parameter found in the
+// off-spec parameters of params (see Params.Params), or Default if there is no
+// such parameter. An IllegalParameterError is returned if the collation is
+// unknown or if there are several values.
+//
+// The collator can be given to SoftCollection.SetCollator.
+func (c Collations) Select(params *Params) (Collator, error) {
+	param := c.Param
+	if param == "" {
+		param = DefaultCollationParam
+	}
+
+	var vals []string
+	if params != nil {
+		vals = params.Params[param]
+	}
+
+	switch len(vals) {
+	case 0:
+		return c.Default, nil
+	case 1:
+	default:
+		return nil, &IllegalParameterError{
+			Param: param,
+			err:   fmt.Errorf("only one collation can be selected"),
+		}
+	}
+
+	collator, ok := c.Collators[vals[0]]
+	if !ok {
+		return nil, &IllegalParameterError{
+			Param: param,
+			err:   fmt.Errorf("unknown collation %q", vals[0]),
+		}
+	}
+
+	return collator, nil
+}
@@ -0,0 +1,94 @@
+package jsonapi_test
+
+import (
+	"net/url"
+	"strings"
+	"testing"
+
+	. "github.com/mark-hartmann/jsonapi"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// foldAccents is a naive collator that ignores the case and a few accents.
+var foldAccents = CollatorFunc(func(a, b string) int {
+	fold := strings.NewReplacer("é", "e", "è", "e", "É", "e", "à", "a").Replace
+	a, b = strings.ToLower(fold(a)), strings.ToLower(fold(b))
+
+	return strings.Compare(a, b)
+})
+
+func TestCollations(t *testing.T) {
+	assert := assert.New(t)
+
+	schema := newMockSchema()
+
+	params := func(query string) *Params {
+		u, err := NewURLFromRaw(schema, "/mocktypes1?"+query)
+		assert.NoError(err)
+
+		return u.Params
+	}
+
+	collations := Collations{Collators: map[string]Collator{"fr": foldAccents}}
+
+	c, err := collations.Select(params(""))
+	assert.NoError(err)
+	assert.Nil(c)
+
+	c, err = collations.Select(params("x-collation=fr"))
+	assert.NoError(err)
+	assert.Equal(-1, c.CompareString("École", "etude"))
+
+	_, err = collations.Select(params("x-collation=de"))
+	assert.EqualError(err, `jsonapi: illegal query parameter "x-collation": unknown collation "de"`)
+
+	_, err = collations.Select(params("x-collation=fr&x-collation=fr"))
+	assert.Error(err)
+
+	// Custom parameter and default collator
+	collations.Param = "lang-collation"
+	collations.Default = foldAccents
+
+	c, err = collations.Select(params("x-collation=de"))
+	assert.NoError(err)
+	assert.NotNil(c)
+
+	_, err = collations.Select(params(url.Values{"lang-collation": {"de"}}.Encode()))
+	assert.Error(err)
+}
+
+func TestSoftCollectionSetCollator(t *testing.T) {
+	assert := assert.New(t)
+
+	typ := &Type{Name: "words"}
+	typ.MustAddAttr(Attr{Name: "word", Type: AttrTypeString})
+
+	sc := &SoftCollection{}
+	sc.SetType(typ)
+
+	for i, w := range []string{"zèbre", "école", "Étude", "abricot", "漢語"} {
+		sr := &SoftResource{Type: typ}
+		sr.SetID(string(rune('a' + i)))
+		sr.Set("word", w)
+		sc.Add(sr)
+	}
+
+	words := func() []string {
+		words := []string{}
+		sc.Range(func(_ int, r Resource) bool {
+			words = append(words, r.Get("word").(string))
+			return true
+		})
+
+		return words
+	}
+
+	sc.SetSortRules([]SortRule{{Name: "word"}})
+	sc.SortStable()
+	assert.Equal([]string{"abricot", "zèbre", "Étude", "école", "漢語"}, words())
+
+	sc.SetCollator(foldAccents)
+	sc.SortStable()
+	assert.Equal([]string{"abricot", "école", "Étude", "zèbre", "漢語"}, words())
+}
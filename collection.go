@@ -23,48 +23,204 @@ type Collection interface {
 
 // MarshalCollection marshals a Collection into a JSON-encoded payload.
 func MarshalCollection(c Collection, prepath string, fields map[string][]string, relData map[string][]string) []byte {
-	var raws []*json.RawMessage
+	return MarshalOptions{}.MarshalCollection(c, prepath, fields, relData)
+}
+
+// MarshalCollection marshals a Collection like the MarshalCollection function
+// does, according to the options.
+func (o MarshalOptions) MarshalCollection(c Collection, prepath string,
+	fields map[string][]string, relData map[string][]string) []byte {
+	// NOTE An error should not happen, except if a resource cannot be
+	// marshaled, in which case nil is returned like MarshalResource does.
+	data, _ := o.marshalCollection(c, prepath, fields, relData)
+
+	return data
+}
 
+// marshalCollection marshals c like MarshalCollection does. An error is
+// returned if the context of the options is done or if a resource cannot be
+// marshaled.
+func (o MarshalOptions) marshalCollection(c Collection, prepath string,
+	fields map[string][]string, relData map[string][]string) ([]byte, error) {
 	if c.Len() == 0 {
-		return []byte("[]")
+		return []byte("[]"), nil
 	}
 
+	w := o.writer()
+	defer o.release(w)
+
+	buf := w.buf
+	buf.WriteByte('[')
+
 	for i := 0; i < c.Len(); i++ {
+		if i > 0 {
+			buf.WriteByte(',')
+		}
+
+		if err := ctxErr(o.ctx); err != nil {
+			return nil, err
+		}
+
 		r := c.At(i)
-		raw := json.RawMessage(
-			MarshalResource(r, prepath, fields[r.GetType().Name], relData),
-		)
-		raws = append(raws, &raw)
+		w.resource(r, prepath, fields[r.GetType().Name], relData)
+
+		if w.err != nil {
+			return nil, w.err
+		}
+	}
+
+	buf.WriteByte(']')
+
+	return o.bytes(w), nil
+}
+
+// A CollectionIterator gives access to resources one at a time. Unlike a
+// Collection, it does not need to know the number of resources in advance and
+// it can report failures, which makes it suitable for streaming resources from
+// a database cursor into a payload.
+//
+// A CollectionIterator can be used as the primary data of a Document.
+type CollectionIterator interface {
+	// GetType returns the type of the resources.
+	GetType() Type
+
+	// Next returns the next resource. A nil resource and a nil error are
+	// returned once all the resources have been returned.
+	Next() (Resource, error)
+}
+
+// Iterate returns a CollectionIterator that returns the resources of c.
+func Iterate(c Collection) CollectionIterator {
+	return &collectionIterator{col: c}
+}
+
+type collectionIterator struct {
+	col Collection
+	i   int
+}
+
+func (it *collectionIterator) GetType() Type {
+	return it.col.GetType()
+}
+
+func (it *collectionIterator) Next() (Resource, error) {
+	if it.i >= it.col.Len() {
+		return nil, nil
+	}
+
+	it.i++
+
+	return it.col.At(it.i - 1), nil
+}
+
+// MarshalCollectionIterator marshals the resources returned by it into a
+// JSON-encoded payload like MarshalCollection does.
+//
+// The iteration stops at the first error returned by it, which is returned.
+func MarshalCollectionIterator(it CollectionIterator, prepath string,
+	fields map[string][]string, relData map[string][]string) ([]byte, error) {
+	return MarshalOptions{}.marshalIterator(it, prepath, func(typ Type) []string {
+		return fields[typ.Name]
+	}, relData)
+}
+
+// marshalIterator marshals the resources returned by it. fields returns the
+// fields to marshal for a type.
+func (o MarshalOptions) marshalIterator(it CollectionIterator, prepath string,
+	fields func(Type) []string, relData map[string][]string) ([]byte, error) {
+	w := o.writer()
+	defer o.release(w)
+
+	buf := w.buf
+	buf.WriteByte('[')
+
+	for i := 0; ; i++ {
+		if err := ctxErr(o.ctx); err != nil {
+			return nil, err
+		}
+
+		r, err := it.Next()
+		if err != nil {
+			return nil, err
+		}
+
+		if r == nil {
+			break
+		}
+
+		if i > 0 {
+			buf.WriteByte(',')
+		}
+
+		w.resource(r, prepath, fields(r.GetType()), relData)
+
+		if w.err != nil {
+			return nil, w.err
+		}
 	}
 
-	// NOTE An error should not happen.
-	pl, _ := json.Marshal(raws)
+	buf.WriteByte(']')
 
-	return pl
+	return o.bytes(w), nil
 }
 
 // UnmarshalCollection unmarshals a JSON-encoded payload into a Collection.
 func UnmarshalCollection(data []byte, schema *Schema) (Collection, error) {
+	return UnmarshalOptions{}.UnmarshalCollection(data, schema)
+}
+
+// UnmarshalCollection unmarshals a JSON-encoded payload into a Collection like
+// the UnmarshalCollection function does, according to the options.
+//
+// If CollectFieldErrors is set, the field errors of all the resources are
+// returned together.
+func (o UnmarshalOptions) UnmarshalCollection(data []byte, schema *Schema) (Collection, error) {
+	col, _, err := o.unmarshalCollection(data, schema)
+
+	return col, err
+}
+
+// unmarshalCollection unmarshals data like UnmarshalCollection does and also
+// returns the unknown fields that were skipped if the options require them to
+// be reported (see UnmarshalOptions.skippedErrors).
+func (o UnmarshalOptions) unmarshalCollection(data []byte,
+	schema *Schema) (Collection, FieldErrors, error) {
 	var cske []json.RawMessage
 
 	err := json.Unmarshal(data, &cske)
 	if err != nil {
-		return nil, payloadErr(err)
+		return nil, nil, payloadErr(err)
 	}
 
 	col := &Resources{}
 
+	var errs, skippedErrs FieldErrors
+
 	for i := range cske {
-		res, err := UnmarshalResource(cske[i], schema)
-		if err != nil {
-			return nil, fmt.Errorf("jsonapi: failed to unmarshal resource at %d: %w",
+		if err := ctxErr(o.ctx); err != nil {
+			return nil, nil, err
+		}
+
+		res, skipped, err := o.unmarshalResource(cske[i], schema)
+
+		if fe, ok := err.(FieldErrors); ok {
+			errs = append(errs, fe.withPrefix(fmt.Sprintf("/%d", i))...)
+			continue
+		} else if err != nil {
+			return nil, nil, fmt.Errorf("jsonapi: failed to unmarshal resource at %d: %w",
 				i, &srcError{src: fmt.Sprintf("/%d", i), ptr: true, error: err})
 		}
 
 		col.Add(res)
+
+		skippedErrs = append(skippedErrs, o.skippedErrors(fmt.Sprintf("/%d", i), skipped)...)
+	}
+
+	if len(errs) > 0 {
+		return nil, nil, errs
 	}
 
-	return col, nil
+	return col, skippedErrs, nil
 }
 
 // Resources is a slice of objects that implements the Collection interface. The resources
@@ -95,3 +251,164 @@ func (r *Resources) At(i int) Resource {
 func (r *Resources) Add(res Resource) {
 	*r = append(*r, res)
 }
+
+// Find returns the resource of type typ with an ID equal to id, or nil if there
+// is no such resource.
+func (r *Resources) Find(typ, id string) Resource {
+	if i := r.indexOf(typ, id); i >= 0 {
+		return (*r)[i]
+	}
+
+	return nil
+}
+
+// Remove removes all the resources with an ID equal to id.
+//
+// Nothing happens if no resource has such an ID.
+func (r *Resources) Remove(id string) {
+	res := (*r)[:0]
+
+	for _, rs := range *r {
+		if rs.Get("id").(string) != id {
+			res = append(res, rs)
+		}
+	}
+
+	// Clear the tail so the removed resources can be garbage collected.
+	for i := len(res); i < len(*r); i++ {
+		(*r)[i] = nil
+	}
+
+	*r = res
+}
+
+// UpsertMany adds the given resources to the collection. A resource replaces
+// the resource of the same type and ID if the collection already contains one.
+func (r *Resources) UpsertMany(res []Resource) {
+	for _, rs := range res {
+		if i := r.indexOf(rs.GetType().Name, rs.Get("id").(string)); i >= 0 {
+			(*r)[i] = rs
+		} else {
+			r.Add(rs)
+		}
+	}
+}
+
+func (r *Resources) indexOf(typ, id string) int {
+	for i, rs := range *r {
+		if rs.Get("id").(string) == id && rs.GetType().Name == typ {
+			return i
+		}
+	}
+
+	return -1
+}
+
+// IndexedResources is a Collection like Resources that also keeps an index of
+// its resources by type and ID. Finding a resource and checking whether it
+// is part of the collection does not require going through all the resources.
+//
+// Unlike Resources, it cannot contain two resources with the same type and ID.
+//
+// The zero value is an empty collection ready to use.
+type IndexedResources struct {
+	res   Resources
+	index map[string]map[string]int
+}
+
+// GetType returns a zero Type object because the collection does not represent
+// a particular type.
+func (r *IndexedResources) GetType() Type {
+	return Type{}
+}
+
+// Len returns the number of elements in the collection.
+func (r *IndexedResources) Len() int {
+	return len(r.res)
+}
+
+// At returns the resource at position i. If the index is out of bounds, nil is
+// returned.
+func (r *IndexedResources) At(i int) Resource {
+	return r.res.At(i)
+}
+
+// Add adds a Resource object to the collection. It replaces the resource of
+// the same type and ID if there is already one.
+func (r *IndexedResources) Add(res Resource) {
+	typ, id := res.GetType().Name, res.Get("id").(string)
+
+	if i, ok := r.index[typ][id]; ok {
+		r.res[i] = res
+		return
+	}
+
+	if r.index == nil {
+		r.index = map[string]map[string]int{}
+	}
+
+	if r.index[typ] == nil {
+		r.index[typ] = map[string]int{}
+	}
+
+	r.index[typ][id] = len(r.res)
+	r.res = append(r.res, res)
+}
+
+// Has reports whether the collection contains a resource of type typ with an
+// ID equal to id.
+func (r *IndexedResources) Has(typ, id string) bool {
+	_, ok := r.index[typ][id]
+	return ok
+}
+
+// Find returns the resource of type typ with an ID equal to id, or nil if there
+// is no such resource.
+func (r *IndexedResources) Find(typ, id string) Resource {
+	if i, ok := r.index[typ][id]; ok {
+		return r.res[i]
+	}
+
+	return nil
+}
+
+// Remove removes all the resources with an ID equal to id.
+//
+// Nothing happens if no resource has such an ID.
+func (r *IndexedResources) Remove(id string) {
+	n := len(r.res)
+
+	r.res.Remove(id)
+
+	if len(r.res) == n {
+		return
+	}
+
+	// The positions of the following resources have changed.
+	r.index = make(map[string]map[string]int, len(r.index))
+	for i, res := range r.res {
+		typ := res.GetType().Name
+		if r.index[typ] == nil {
+			r.index[typ] = map[string]int{}
+		}
+
+		r.index[typ][res.Get("id").(string)] = i
+	}
+}
+
+// UpsertMany adds the given resources to the collection. A resource replaces
+// the resource of the same type and ID if the collection already contains one.
+func (r *IndexedResources) UpsertMany(res []Resource) {
+	for _, rs := range res {
+		r.Add(rs)
+	}
+}
+
+// Resources returns the resources of the collection in the order they were
+// added.
+func (r *IndexedResources) Resources() Resources {
+	res := make(Resources, len(r.res))
+	copy(res, r.res)
+
+	return res
+}
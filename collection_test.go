@@ -1,7 +1,12 @@
 package jsonapi_test
 
 import (
+	"bytes"
+	"errors"
+	"fmt"
+	"strconv"
 	"testing"
+	"time"
 
 	. "github.com/mark-hartmann/jsonapi"
 
@@ -9,6 +14,7 @@ import (
 )
 
 var _ Collection = (*Resources)(nil)
+var _ Collection = (*IndexedResources)(nil)
 
 func TestResources(t *testing.T) {
 	assert := assert.New(t)
@@ -36,3 +42,223 @@ func TestUnmarshalCollection(t *testing.T) {
 	assert.Error(t, err)
 	assert.ErrorIs(t, err, ErrInvalidPayload)
 }
+
+func BenchmarkMarshalCollection(b *testing.B) {
+	typ := MustBuildType(mocktype{})
+	col := &Resources{}
+
+	for i := 0; i < 1000; i++ {
+		col.Add(Wrap(&mocktype{
+			ID:   "id" + strconv.Itoa(i),
+			Str:  "str",
+			Int:  i,
+			To1:  "id" + strconv.Itoa(i+1),
+			ToX:  []string{"id" + strconv.Itoa(i+2), "id" + strconv.Itoa(i+3)},
+			Time: time.Time{},
+		}))
+	}
+
+	fields := map[string][]string{"mocktype": typ.Fields()}
+	relData := map[string][]string{"mocktype": {"to-1", "to-x"}}
+
+	b.ReportAllocs()
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		_ = MarshalCollection(col, "https://example.org", fields, relData)
+	}
+}
+
+func TestResourcesBatch(t *testing.T) {
+	assert := assert.New(t)
+
+	newRes := func(typ, id string, str string) Resource {
+		sr := &SoftResource{Type: &Type{Name: typ}}
+		sr.AddAttr(Attr{Name: "str", Type: AttrTypeString})
+		sr.SetID(id)
+		sr.Set("str", str)
+
+		return sr
+	}
+
+	for _, col := range []interface {
+		Collection
+		Find(typ, id string) Resource
+		Remove(id string)
+		UpsertMany([]Resource)
+	}{&Resources{}, &IndexedResources{}} {
+		col.UpsertMany([]Resource{
+			newRes("type1", "id1", "a"),
+			newRes("type1", "id2", "b"),
+			newRes("type2", "id1", "c"),
+			newRes("type2", "id3", "d"),
+		})
+		assert.Equal(4, col.Len())
+
+		// Find
+		assert.Equal("b", col.Find("type1", "id2").Get("str"))
+		assert.Equal("c", col.Find("type2", "id1").Get("str"))
+		assert.Nil(col.Find("type2", "id2"))
+		assert.Nil(col.Find("type3", "id1"))
+
+		// Upsert
+		col.UpsertMany([]Resource{
+			newRes("type1", "id2", "e"),
+			newRes("type2", "id4", "f"),
+		})
+		assert.Equal(5, col.Len())
+		assert.Equal("e", col.Find("type1", "id2").Get("str"))
+		assert.Equal("e", col.At(1).Get("str"))
+		assert.Equal("f", col.At(4).Get("str"))
+
+		// Remove
+		col.Remove("id1")
+		col.Remove("unknown")
+		assert.Equal(3, col.Len())
+		assert.Nil(col.Find("type1", "id1"))
+		assert.Nil(col.Find("type2", "id1"))
+		assert.Equal("e", col.Find("type1", "id2").Get("str"))
+		assert.Equal("d", col.Find("type2", "id3").Get("str"))
+		assert.Equal("f", col.Find("type2", "id4").Get("str"))
+
+		ids := []string{}
+		for i := 0; i < col.Len(); i++ {
+			ids = append(ids, col.At(i).Get("id").(string))
+		}
+
+		assert.Equal([]string{"id2", "id3", "id4"}, ids)
+	}
+}
+
+func TestIndexedResources(t *testing.T) {
+	assert := assert.New(t)
+
+	col := &IndexedResources{}
+	assert.Equal(Type{}, col.GetType())
+	assert.Equal(0, col.Len())
+	assert.Nil(col.At(0))
+	assert.False(col.Has("type1", "id1"))
+
+	res := &SoftResource{Type: &Type{Name: "type1"}}
+	res.SetID("id1")
+
+	// Adding the same resource twice does not duplicate it.
+	col.Add(res)
+	col.Add(res)
+	assert.Equal(1, col.Len())
+	assert.True(col.Has("type1", "id1"))
+	assert.False(col.Has("type1", "id2"))
+	assert.Equal(Resources{res}, col.Resources())
+
+	// Resources returns a copy.
+	col.Resources()[0] = nil
+	assert.Equal(res, col.At(0))
+}
+
+func BenchmarkIndexedResourcesFind(b *testing.B) {
+	for _, col := range []interface {
+		Collection
+		Find(typ, id string) Resource
+	}{&Resources{}, &IndexedResources{}} {
+		for i := 0; i < 10000; i++ {
+			sr := &SoftResource{Type: &Type{Name: "type"}}
+			sr.SetID("id" + strconv.Itoa(i))
+			col.Add(sr)
+		}
+
+		b.Run(fmt.Sprintf("%T", col), func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				_ = col.Find("type", "id"+strconv.Itoa(i%10000))
+			}
+		})
+	}
+}
+
+// failingIterator returns n resources and then fails.
+type failingIterator struct {
+	n int
+}
+
+func (it *failingIterator) GetType() Type {
+	return Type{}
+}
+
+func (it *failingIterator) Next() (Resource, error) {
+	if it.n == 0 {
+		return nil, errors.New("connection lost")
+	}
+
+	it.n--
+
+	return Wrap(&mockType3{ID: "id" + strconv.Itoa(it.n)}), nil
+}
+
+func TestCollectionIterator(t *testing.T) {
+	assert := assert.New(t)
+
+	col := &Resources{}
+	col.Add(Wrap(&mockType3{ID: "id1", Attr1: "a"}))
+	col.Add(Wrap(&mockType3{ID: "id2", Attr1: "b"}))
+
+	fields := map[string][]string{"mocktypes3": {"attr1"}}
+
+	pl, err := MarshalCollectionIterator(Iterate(col), "", fields, nil)
+	assert.NoError(err)
+	assert.Equal(string(MarshalCollection(col, "", fields, nil)), string(pl))
+
+	pl, err = MarshalCollectionIterator(Iterate(&Resources{}), "", fields, nil)
+	assert.NoError(err)
+	assert.Equal("[]", string(pl))
+
+	_, err = MarshalCollectionIterator(&failingIterator{n: 2}, "", fields, nil)
+	assert.EqualError(err, "connection lost")
+
+	// Document
+	buf := &bytes.Buffer{}
+	err = MarshalDocument(buf, &Document{Data: Iterate(col)}, nil)
+	assert.NoError(err)
+
+	expected := &bytes.Buffer{}
+	err = MarshalDocument(expected, &Document{Data: col}, nil)
+	assert.NoError(err)
+	assert.Equal(expected.String(), buf.String())
+
+	err = MarshalDocument(buf, &Document{Data: &failingIterator{n: 1}}, nil)
+	assert.EqualError(err, "connection lost")
+}
+
+// failingAttrMarshaler is a TypeMarshaler that always fails.
+type failingAttrMarshaler struct{}
+
+func (failingAttrMarshaler) MarshalAttr(interface{}, Attr) ([]byte, error) {
+	return nil, errors.New("cannot marshal")
+}
+
+func TestMarshalCollectionError(t *testing.T) {
+	assert := assert.New(t)
+
+	typ := Type{Name: "articles"}
+	typ.MustAddAttr(Attr{
+		Name:      "title",
+		Type:      AttrTypeString,
+		Marshaler: failingAttrMarshaler{},
+	})
+
+	res := &SoftResource{Type: &typ}
+	res.SetID("a1")
+
+	col := &Resources{res}
+	fields := map[string][]string{"articles": {"title"}}
+
+	assert.Nil(MarshalCollection(col, "", fields, nil))
+
+	buf := &bytes.Buffer{}
+	err := MarshalDocument(buf, &Document{Data: col}, nil)
+	assert.Error(err)
+	assert.Contains(err.Error(), "cannot marshal")
+
+	// A single resource fails the same way.
+	err = MarshalDocument(buf, &Document{Data: res}, nil)
+	assert.Error(err)
+	assert.Contains(err.Error(), "cannot marshal")
+}
@@ -0,0 +1,167 @@
+package jsonapi
+
+import (
+	"bytes"
+	"reflect"
+	"time"
+)
+
+// compareValues compares two values of the same attribute and returns -1, 0 or
+// 1 if v1 is respectively less than, equal to or greater than v2.
+//
+// Numbers are compared by value, strings with the collator or byte-wise if it
+// is nil, times chronologically and false comes before true. A nil value, like
+// the value of a nullable attribute that is null, comes before any other
+// value. The values of the other types, like arrays, are considered equal.
+func compareValues(v1, v2 interface{}, collator Collator) int {
+	v1, v2 = derefValue(v1), derefValue(v2)
+
+	switch {
+	case v1 == nil && v2 == nil:
+		return 0
+	case v1 == nil:
+		return -1
+	case v2 == nil:
+		return 1
+	}
+
+	switch a := v1.(type) {
+	case string:
+		b, ok := v2.(string)
+
+		switch {
+		case ok && collator != nil:
+			return collator.CompareString(a, b)
+		case ok:
+			return compareStrings(a, b)
+		}
+	case bool:
+		if b, ok := v2.(bool); ok {
+			return compareBools(a, b)
+		}
+	case time.Time:
+		if b, ok := v2.(time.Time); ok {
+			return compareTimes(a, b)
+		}
+	case Decimal:
+		if b, ok := v2.(Decimal); ok {
+			return a.Cmp(b)
+		}
+	case UUID:
+		if b, ok := v2.(UUID); ok {
+			return bytes.Compare(a[:], b[:])
+		}
+	}
+
+	return compareNumbers(v1, v2)
+}
+
+// derefValue returns the value v points to if it is a pointer, or nil if the
+// pointer is nil. Other values are returned as they are.
+func derefValue(v interface{}) interface{} {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Ptr {
+		return v
+	}
+
+	if rv.IsNil() {
+		return nil
+	}
+
+	return rv.Elem().Interface()
+}
+
+// compareNumbers compares two integers or floats of the same kind. 0 is
+// returned if they are not numbers.
+func compareNumbers(v1, v2 interface{}) int {
+	rv1, rv2 := reflect.ValueOf(v1), reflect.ValueOf(v2)
+
+	switch {
+	case isIntKind(rv1.Kind()) && isIntKind(rv2.Kind()):
+		return compareInts(rv1.Int(), rv2.Int())
+	case isUintKind(rv1.Kind()) && isUintKind(rv2.Kind()):
+		return compareUints(rv1.Uint(), rv2.Uint())
+	case isFloatKind(rv1.Kind()) && isFloatKind(rv2.Kind()):
+		return compareFloats(rv1.Float(), rv2.Float())
+	}
+
+	return 0
+}
+
+func isIntKind(k reflect.Kind) bool {
+	return k >= reflect.Int && k <= reflect.Int64
+}
+
+func isUintKind(k reflect.Kind) bool {
+	return k >= reflect.Uint && k <= reflect.Uint64
+}
+
+func isFloatKind(k reflect.Kind) bool {
+	return k == reflect.Float32 || k == reflect.Float64
+}
+
+func compareStrings(a, b string) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	}
+
+	return 0
+}
+
+func compareBools(a, b bool) int {
+	switch {
+	case a == b:
+		return 0
+	case b:
+		return -1
+	}
+
+	return 1
+}
+
+func compareTimes(a, b time.Time) int {
+	switch {
+	case a.Before(b):
+		return -1
+	case a.After(b):
+		return 1
+	}
+
+	return 0
+}
+
+func compareInts(a, b int64) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	}
+
+	return 0
+}
+
+func compareUints(a, b uint64) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	}
+
+	return 0
+}
+
+func compareFloats(a, b float64) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	}
+
+	return 0
+}
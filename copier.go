@@ -1,8 +1,128 @@
 package jsonapi
 
+import "reflect"
+
 // Copier is a interface for objects that can return a new and empty instance or
 // a deep copy of themselves.
 type Copier interface {
 	New() Resource
 	Copy() Resource
 }
+
+// A Cloner is a value that returns a deep copy of itself.
+//
+// The copies of resources (see Copier) do not share any memory with the
+// original ones. The slices, maps, pointers and exported struct fields of the
+// values are copied recursively, which is enough for the built-in attribute
+// types. A value of a custom attribute type (see RegisterAttrType) that holds
+// references in unexported fields should implement Cloner, otherwise they are
+// shared by the copies. Clone must return a value of the same type.
+type Cloner interface {
+	Clone() interface{}
+}
+
+// copyValue returns a deep copy of v.
+func copyValue(v interface{}) interface{} {
+	if v == nil {
+		return nil
+	}
+
+	return copyReflectValue(reflect.ValueOf(v)).Interface()
+}
+
+// copyReflectValue returns a deep copy of v. The values held by unexported
+// struct fields are not copied, unless the struct implements Cloner.
+func copyReflectValue(v reflect.Value) reflect.Value {
+	if c, ok := v.Interface().(Cloner); ok {
+		cv := reflect.ValueOf(c.Clone())
+		if cv.IsValid() && cv.Type() == v.Type() {
+			return cv
+		}
+	}
+
+	switch v.Kind() {
+	case reflect.Ptr:
+		if v.IsNil() {
+			return v
+		}
+
+		nv := reflect.New(v.Type().Elem())
+		nv.Elem().Set(copyReflectValue(v.Elem()))
+
+		return nv
+	case reflect.Interface:
+		if v.IsNil() {
+			return v
+		}
+
+		nv := reflect.New(v.Type()).Elem()
+		nv.Set(copyReflectValue(v.Elem()))
+
+		return nv
+	case reflect.Slice:
+		if v.IsNil() {
+			return v
+		}
+
+		nv := reflect.MakeSlice(v.Type(), v.Len(), v.Len())
+		reflect.Copy(nv, v)
+
+		if holdsReferences(v.Type().Elem()) {
+			for i := 0; i < v.Len(); i++ {
+				nv.Index(i).Set(copyReflectValue(v.Index(i)))
+			}
+		}
+
+		return nv
+	case reflect.Map:
+		if v.IsNil() {
+			return v
+		}
+
+		nv := reflect.MakeMapWithSize(v.Type(), v.Len())
+
+		iter := v.MapRange()
+		for iter.Next() {
+			nv.SetMapIndex(iter.Key(), copyReflectValue(iter.Value()))
+		}
+
+		return nv
+	case reflect.Array:
+		nv := reflect.New(v.Type()).Elem()
+		nv.Set(v)
+
+		if holdsReferences(v.Type().Elem()) {
+			for i := 0; i < v.Len(); i++ {
+				nv.Index(i).Set(copyReflectValue(v.Index(i)))
+			}
+		}
+
+		return nv
+	case reflect.Struct:
+		nv := reflect.New(v.Type()).Elem()
+		nv.Set(v)
+
+		for i := 0; i < nv.NumField(); i++ {
+			if f := nv.Field(i); f.CanSet() && holdsReferences(f.Type()) {
+				f.Set(copyReflectValue(v.Field(i)))
+			}
+		}
+
+		return nv
+	}
+
+	return v
+}
+
+// holdsReferences returns true if the values of type t can share memory with
+// other values, directly or through their elements or fields.
+func holdsReferences(t reflect.Type) bool {
+	switch t.Kind() {
+	case reflect.Ptr, reflect.Interface, reflect.Slice, reflect.Map, reflect.Struct:
+		return true
+	case reflect.Array:
+		return holdsReferences(t.Elem())
+	}
+
+	return false
+}
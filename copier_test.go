@@ -0,0 +1,138 @@
+package jsonapi_test
+
+import (
+	"encoding/json"
+	"testing"
+
+	. "github.com/mark-hartmann/jsonapi"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// clonerValue holds a slice in an unexported field, which is only copied
+// because it implements Cloner.
+type clonerValue struct {
+	vals []int
+}
+
+func (c clonerValue) Clone() interface{} {
+	return clonerValue{vals: append([]int{}, c.vals...)}
+}
+
+func TestSoftResourceCopyIsolation(t *testing.T) {
+	assert := assert.New(t)
+
+	typ := &Type{Name: "articles"}
+	typ.MustAddAttr(Attr{Name: "tags", Type: AttrTypeString, Array: true})
+	typ.MustAddAttr(Attr{Name: "scores", Type: AttrTypeInt, Array: true, Nullable: true})
+	typ.MustAddAttr(Attr{Name: "subtitle", Type: AttrTypeString, Nullable: true})
+	typ.MustAddAttr(Attr{Name: "cover", Type: AttrTypeBytes})
+	typ.MustAddAttr(Attr{Name: "extra", Type: AttrTypeJSON})
+	typ.MustAddRel(Rel{FromName: "author", ToType: "people", ToOne: true})
+	typ.MustAddRel(Rel{FromName: "comments", ToType: "comments"})
+
+	subtitle := "A"
+	scores := []int{1, 2}
+
+	sr := &SoftResource{Type: typ}
+	sr.SetID("a1")
+	sr.Set("tags", []string{"a", "b"})
+	sr.Set("scores", &scores)
+	sr.Set("subtitle", &subtitle)
+	sr.Set("cover", []byte{1, 2})
+	sr.Set("extra", json.RawMessage(`{"a":1}`))
+	sr.Set("author", Identifier{ID: "p1", Type: "people", Meta: Meta{"role": "editor"}})
+	sr.Set("comments", []string{"c1", "c2"})
+	sr.SetMeta(Meta{"tags": []string{"x"}, "cloner": clonerValue{vals: []int{1}}})
+	sr.SetLinks(map[string]Link{"self": {HRef: "/a1", Meta: map[string]interface{}{"k": "v"}}})
+	sr.SetRelMeta("comments", Meta{"total": 2})
+	sr.SetRelCount("comments", 2)
+
+	cp := sr.Copy().(*SoftResource)
+	assert.True(Equal(sr, cp))
+	assert.Equal(sr.Meta(), cp.Meta())
+	assert.Equal(sr.Links(), cp.Links())
+	assert.Equal(sr.Linkage("author"), cp.Linkage("author"))
+	assert.Equal(sr.RelMeta("comments"), cp.RelMeta("comments"))
+
+	n, ok := cp.RelCount("comments")
+	assert.True(ok)
+	assert.Equal(2, n)
+
+	// Modify the values held by the copy.
+	cp.Get("tags").([]string)[0] = "z"
+	(*cp.Get("scores").(*[]int))[0] = 9
+	*cp.Get("subtitle").(*string) = "Z"
+	cp.Get("cover").([]byte)[0] = 9
+	cp.Get("extra").(json.RawMessage)[2] = 'b'
+	cp.Get("comments").([]string)[0] = "c9"
+	cp.Linkage("author")[0].Meta["role"] = "author"
+	cp.Meta()["tags"].([]string)[0] = "y"
+	cp.Meta()["cloner"].(clonerValue).vals[0] = 9
+	cp.Links()["self"].Meta["k"] = "w"
+	cp.RelMeta("comments")["total"] = 3
+	cp.SetRelCount("comments", 3)
+	cp.Type.Attrs["tags"] = Attr{Name: "tags", Type: AttrTypeInt}
+
+	// The original is not affected.
+	assert.Equal([]string{"a", "b"}, sr.Get("tags"))
+	assert.Equal(&[]int{1, 2}, sr.Get("scores"))
+	assert.Equal("A", subtitle)
+	assert.Equal([]byte{1, 2}, sr.Get("cover"))
+	assert.Equal(json.RawMessage(`{"a":1}`), sr.Get("extra"))
+	assert.Equal([]string{"c1", "c2"}, sr.Get("comments"))
+	assert.Equal("editor", sr.Linkage("author")[0].Meta["role"])
+	assert.Equal([]string{"x"}, sr.Meta()["tags"])
+	assert.Equal(clonerValue{vals: []int{1}}, sr.Meta()["cloner"])
+	assert.Equal("v", sr.Links()["self"].Meta["k"])
+	assert.Equal(2, sr.RelMeta("comments")["total"])
+	assert.Equal(AttrTypeString, sr.Type.Attrs["tags"].Type)
+
+	n, _ = sr.RelCount("comments")
+	assert.Equal(2, n)
+}
+
+func TestWrapperCopyIsolation(t *testing.T) {
+	assert := assert.New(t)
+
+	res := &mockType4{ID: "m1", StrArr: []string{"a", "b"}, IntArr: []int{1}}
+	w := Wrap(res)
+	w.SetMeta(Meta{"tags": []string{"x"}})
+
+	cp := w.Copy().(*Wrapper)
+	assert.Equal("m1", cp.Get("id"))
+	assert.True(Equal(w, cp))
+	assert.Equal(w.Meta(), cp.Meta())
+
+	cp.Get("strarr").([]string)[0] = "z"
+	cp.Get("intarr").([]int)[0] = 9
+	cp.Meta()["tags"].([]string)[0] = "y"
+
+	assert.Equal([]string{"a", "b"}, res.StrArr)
+	assert.Equal([]int{1}, res.IntArr)
+	assert.Equal([]string{"x"}, w.Meta()["tags"])
+}
+
+func TestMapResourceCopyIsolation(t *testing.T) {
+	assert := assert.New(t)
+
+	typ := &Type{Name: "articles"}
+	typ.MustAddAttr(Attr{Name: "tags", Type: AttrTypeString, Array: true})
+	typ.MustAddRel(Rel{FromName: "comments", ToType: "comments"})
+
+	mr, err := WrapMap(typ, map[string]interface{}{
+		"id":       "a1",
+		"tags":     []string{"a"},
+		"comments": []string{"c1"},
+	})
+	assert.NoError(err)
+
+	cp := mr.Copy()
+	assert.True(Equal(mr, cp))
+
+	cp.Get("tags").([]string)[0] = "z"
+	cp.Get("comments").([]string)[0] = "c9"
+
+	assert.Equal([]string{"a"}, mr.Get("tags"))
+	assert.Equal([]string{"c1"}, mr.Get("comments"))
+}
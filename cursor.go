@@ -0,0 +1,126 @@
+package jsonapi
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+)
+
+// cursorSkeleton is the content of a cursor.
+type cursorSkeleton struct {
+	Rules  []string          `json:"r"`
+	Values []json.RawMessage `json:"v"`
+}
+
+// EncodeCursor returns an opaque cursor that holds the values of the sort keys
+// of res according to rules. It is meant to be sent to a client as the
+// page[cursor] parameter of a link to the next page, so that the next page can
+// be fetched with a keyset condition (the resources that come after res).
+//
+// The cursor is URL-safe and also holds the rules, so that DecodeCursorFor can
+// make sure it is used with the same sorting. For the cursor to identify a
+// single resource, the rules should end with a unique field like id.
+//
+// The rules with a Path are based on the values of other resources which res
+// does not know about, so their values are encoded as null.
+func EncodeCursor(res Resource, rules []SortRule) string {
+	ske := cursorSkeleton{
+		Rules:  make([]string, 0, len(rules)),
+		Values: make([]json.RawMessage, 0, len(rules)),
+	}
+
+	for _, rule := range rules {
+		ske.Rules = append(ske.Rules, rule.String())
+
+		val := json.RawMessage("null")
+
+		if len(rule.Path) == 0 {
+			if v, err := json.Marshal(res.Get(rule.Name)); err == nil {
+				val = v
+			}
+		}
+
+		ske.Values = append(ske.Values, val)
+	}
+
+	// The skeleton only holds strings and valid JSON values.
+	pl, _ := json.Marshal(ske)
+
+	return base64.RawURLEncoding.EncodeToString(pl)
+}
+
+// DecodeCursor returns the values held by a cursor made by EncodeCursor.
+//
+// The values are decoded like the json package does, except that numbers are
+// json.Number values so that no precision is lost. A time.Time value, for
+// example, is a string in the RFC 3339 format.
+//
+// An *IllegalParameterError for the page[cursor] parameter is returned if the
+// cursor is malformed.
+func DecodeCursor(cursor string) ([]interface{}, error) {
+	ske, err := decodeCursor(cursor)
+	if err != nil {
+		return nil, err
+	}
+
+	return cursorValues(ske)
+}
+
+// DecodeCursorFor decodes the cursor like DecodeCursor and also checks that it
+// was made for the given sort rules, which are usually the ones of the current
+// request. An *IllegalParameterError is returned if they do not match.
+func DecodeCursorFor(cursor string, rules []SortRule) ([]interface{}, error) {
+	ske, err := decodeCursor(cursor)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(ske.Rules) != len(rules) {
+		return nil, cursorErr()
+	}
+
+	for i, rule := range rules {
+		if ske.Rules[i] != rule.String() {
+			return nil, cursorErr()
+		}
+	}
+
+	return cursorValues(ske)
+}
+
+// decodeCursor decodes the content of cursor.
+func decodeCursor(cursor string) (*cursorSkeleton, error) {
+	pl, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return nil, cursorErr()
+	}
+
+	ske := &cursorSkeleton{}
+
+	if err := json.Unmarshal(pl, ske); err != nil || len(ske.Rules) != len(ske.Values) {
+		return nil, cursorErr()
+	}
+
+	return ske, nil
+}
+
+// cursorValues decodes the values of ske.
+func cursorValues(ske *cursorSkeleton) ([]interface{}, error) {
+	vals := make([]interface{}, len(ske.Values))
+
+	for i, raw := range ske.Values {
+		dec := json.NewDecoder(bytes.NewReader(raw))
+		dec.UseNumber()
+
+		if err := dec.Decode(&vals[i]); err != nil {
+			return nil, cursorErr()
+		}
+	}
+
+	return vals, nil
+}
+
+// cursorErr returns the error for an invalid cursor.
+func cursorErr() error {
+	return &IllegalParameterError{Param: "page[cursor]"}
+}
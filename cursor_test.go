@@ -0,0 +1,68 @@
+package jsonapi_test
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"net/url"
+	"testing"
+	"time"
+
+	. "github.com/mark-hartmann/jsonapi"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCursor(t *testing.T) {
+	assert := assert.New(t)
+
+	schema := newMockSchema()
+	now := time.Date(2021, 1, 2, 3, 4, 5, 6, time.UTC)
+	res := Wrap(&mockType1{ID: "mt1", Str: "a b/c", Int: 42, Time: now})
+
+	u, err := NewURLFromRaw(schema, "/mocktypes1?sort=-time,to-one.strptr,int,str,id")
+	assert.NoError(err)
+
+	rules := u.Params.SortRules
+
+	cursor := EncodeCursor(res, rules)
+	assert.Equal(url.QueryEscape(cursor), cursor, "cursor must be URL-safe")
+
+	vals, err := DecodeCursor(cursor)
+	assert.NoError(err)
+	assert.Equal([]interface{}{
+		"2021-01-02T03:04:05.000000006Z", nil, json.Number("42"), "a b/c", "mt1",
+	}, vals)
+
+	vals2, err := DecodeCursorFor(cursor, rules)
+	assert.NoError(err)
+	assert.Equal(vals, vals2)
+
+	// The rules must match.
+	_, err = DecodeCursorFor(cursor, rules[1:])
+	assert.EqualError(err, `jsonapi: illegal query parameter "page[cursor]"`)
+
+	otherRules := append([]SortRule(nil), rules...)
+	otherRules[0].Desc = false
+	_, err = DecodeCursorFor(cursor, otherRules)
+	assert.Error(err)
+
+	// Malformed cursors
+	for _, c := range []string{
+		"not base64!",
+		base64.RawURLEncoding.EncodeToString([]byte("{")),
+		base64.RawURLEncoding.EncodeToString([]byte(`{"r":["id"],"v":[]}`)),
+	} {
+		_, err = DecodeCursor(c)
+
+		var ipe *IllegalParameterError
+
+		assert.True(errors.As(err, &ipe), c)
+		assert.Equal("page[cursor]", ipe.Param)
+	}
+
+	// No rules
+	vals, err = DecodeCursor(EncodeCursor(res, nil))
+	assert.NoError(err)
+	assert.Empty(vals)
+}
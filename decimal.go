@@ -0,0 +1,219 @@
+package jsonapi
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"strconv"
+	"strings"
+)
+
+// maxDecimalScale limits the exponent of the decimals that can be parsed. It
+// prevents small payloads like "1e999999999" from producing huge strings.
+const maxDecimalScale = 10000
+
+// Decimal is an arbitrary-precision decimal number. It is the go-type of the
+// attributes of type AttrTypeDecimal.
+//
+// A Decimal represents the number unscaled * 10^-scale. The scale is kept as
+// parsed, so "1.50" remains "1.50" once marshaled again. The zero value is 0.
+//
+// Decimal values are immutable and can be copied freely.
+type Decimal struct {
+	unscaled *big.Int
+	scale    int32
+}
+
+// NewDecimal returns the decimal unscaled * 10^-scale.
+func NewDecimal(unscaled int64, scale int32) Decimal {
+	return Decimal{
+		unscaled: big.NewInt(unscaled),
+		scale:    scale,
+	}
+}
+
+// ParseDecimal parses s as a decimal number. The format is the one of JSON
+// numbers, except that a leading plus sign is accepted.
+func ParseDecimal(s string) (Decimal, error) {
+	var (
+		d   Decimal
+		num = s
+		exp int64
+	)
+
+	if i := strings.IndexAny(num, "eE"); i >= 0 {
+		e, err := strconv.ParseInt(num[i+1:], 10, 32)
+		if err != nil {
+			return d, fmt.Errorf("jsonapi: invalid decimal %q", s)
+		}
+
+		num, exp = num[:i], e
+	}
+
+	neg := false
+
+	if len(num) > 0 && (num[0] == '-' || num[0] == '+') {
+		neg = num[0] == '-'
+		num = num[1:]
+	}
+
+	intPart, fracPart := num, ""
+	if i := strings.IndexByte(num, '.'); i >= 0 {
+		intPart, fracPart = num[:i], num[i+1:]
+
+		if fracPart == "" {
+			return d, fmt.Errorf("jsonapi: invalid decimal %q", s)
+		}
+	}
+
+	if intPart == "" || !isDigits(intPart) || !isDigits(fracPart) {
+		return d, fmt.Errorf("jsonapi: invalid decimal %q", s)
+	}
+
+	scale := int64(len(fracPart)) - exp
+	if scale > maxDecimalScale || scale < -maxDecimalScale {
+		return d, fmt.Errorf("jsonapi: exponent of decimal %q is out of range", s)
+	}
+
+	d.unscaled, _ = new(big.Int).SetString(intPart+fracPart, 10)
+	d.scale = int32(scale)
+
+	if neg {
+		d.unscaled.Neg(d.unscaled)
+	}
+
+	return d, nil
+}
+
+// MustParseDecimal calls ParseDecimal and panics if an error is returned.
+func MustParseDecimal(s string) Decimal {
+	d, err := ParseDecimal(s)
+	if err != nil {
+		panic(err)
+	}
+
+	return d
+}
+
+// Sign returns -1, 0 or +1 depending on the sign of d.
+func (d Decimal) Sign() int {
+	if d.unscaled == nil {
+		return 0
+	}
+
+	return d.unscaled.Sign()
+}
+
+// Cmp compares d and d2 and returns -1, 0 or +1 like big.Rat.Cmp does. The
+// scale does not matter, so 1.5 and 1.50 are equal.
+func (d Decimal) Cmp(d2 Decimal) int {
+	return d.Rat().Cmp(d2.Rat())
+}
+
+// Rat returns d as a new big.Rat.
+func (d Decimal) Rat() *big.Rat {
+	r := new(big.Rat)
+	if d.unscaled == nil {
+		return r
+	}
+
+	pow := new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(abs32(d.scale))), nil)
+	if d.scale >= 0 {
+		return r.SetFrac(d.unscaled, pow)
+	}
+
+	return r.SetInt(new(big.Int).Mul(d.unscaled, pow))
+}
+
+// Float64 returns the nearest float64 value of d.
+func (d Decimal) Float64() float64 {
+	f, _ := d.Rat().Float64()
+	return f
+}
+
+// String returns d in decimal notation without exponent, like "-12.50".
+func (d Decimal) String() string {
+	if d.Sign() == 0 {
+		if d.scale <= 0 {
+			return "0"
+		}
+
+		return "0." + strings.Repeat("0", int(d.scale))
+	}
+
+	digits := new(big.Int).Abs(d.unscaled).String()
+
+	var sb strings.Builder
+
+	if d.unscaled.Sign() < 0 {
+		sb.WriteByte('-')
+	}
+
+	switch scale := int(d.scale); {
+	case scale <= 0:
+		sb.WriteString(digits)
+		sb.WriteString(strings.Repeat("0", -scale))
+	case len(digits) <= scale:
+		sb.WriteString("0.")
+		sb.WriteString(strings.Repeat("0", scale-len(digits)))
+		sb.WriteString(digits)
+	default:
+		sb.WriteString(digits[:len(digits)-scale])
+		sb.WriteByte('.')
+		sb.WriteString(digits[len(digits)-scale:])
+	}
+
+	return sb.String()
+}
+
+// MarshalJSON marshals d as a JSON string to make sure that no precision is
+// lost by the clients. See MarshalOptions.DecimalAsNumber to marshal it as a
+// number instead.
+func (d Decimal) MarshalJSON() ([]byte, error) {
+	return json.Marshal(d.String())
+}
+
+// UnmarshalJSON unmarshals a JSON string or number into d.
+func (d *Decimal) UnmarshalJSON(data []byte) error {
+	s := string(data)
+
+	if s == "null" {
+		return nil
+	}
+
+	if len(data) > 0 && data[0] == '"' {
+		if err := json.Unmarshal(data, &s); err != nil {
+			return err
+		}
+	} else if strings.HasPrefix(s, "+") {
+		return errors.New("jsonapi: invalid decimal number")
+	}
+
+	dec, err := ParseDecimal(s)
+	if err != nil {
+		return err
+	}
+
+	*d = dec
+
+	return nil
+}
+
+func isDigits(s string) bool {
+	for i := 0; i < len(s); i++ {
+		if s[i] < '0' || s[i] > '9' {
+			return false
+		}
+	}
+
+	return true
+}
+
+func abs32(n int32) int32 {
+	if n < 0 {
+		return -n
+	}
+
+	return n
+}
@@ -0,0 +1,148 @@
+package jsonapi_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"math/big"
+	"testing"
+
+	. "github.com/mark-hartmann/jsonapi"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseDecimal(t *testing.T) {
+	assert := assert.New(t)
+
+	tests := []struct {
+		in, out string
+	}{
+		{in: "0", out: "0"},
+		{in: "-0", out: "0"},
+		{in: "0.00", out: "0.00"},
+		{in: "12", out: "12"},
+		{in: "+12", out: "12"},
+		{in: "-12.50", out: "-12.50"},
+		{in: "0.001", out: "0.001"},
+		{in: "1.5e3", out: "1500"},
+		{in: "15E-4", out: "0.0015"},
+		{in: "-1e-2", out: "-0.01"},
+		{
+			in:  "123456789012345678901234567890.123456789",
+			out: "123456789012345678901234567890.123456789",
+		},
+	}
+
+	for _, test := range tests {
+		d, err := ParseDecimal(test.in)
+		assert.NoError(err, test.in)
+		assert.Equal(test.out, d.String(), test.in)
+	}
+
+	for _, in := range []string{"", "-", "a", "1.", ".5", "1..2", "1e", "1e+", "--1", "1e99999"} {
+		_, err := ParseDecimal(in)
+		assert.Error(err, in)
+	}
+
+	assert.Panics(func() { MustParseDecimal("abc") })
+	assert.Equal("-1.23", NewDecimal(-123, 2).String())
+	assert.Equal("1200", NewDecimal(12, -2).String())
+}
+
+func TestDecimalMethods(t *testing.T) {
+	assert := assert.New(t)
+
+	assert.Equal("0", Decimal{}.String())
+	assert.Equal(0, Decimal{}.Sign())
+	assert.Equal(0, Decimal{}.Rat().Sign())
+	assert.Equal(-1, MustParseDecimal("-0.1").Sign())
+
+	assert.Equal(0, MustParseDecimal("1.5").Cmp(MustParseDecimal("1.50")))
+	assert.Equal(-1, MustParseDecimal("1.49").Cmp(MustParseDecimal("1.5")))
+	assert.Equal(1, MustParseDecimal("1e2").Cmp(MustParseDecimal("99.9")))
+
+	assert.Equal(big.NewRat(-5, 4), MustParseDecimal("-1.25").Rat())
+	assert.Equal(1.25, MustParseDecimal("1.25").Float64())
+}
+
+func TestDecimalJSON(t *testing.T) {
+	assert := assert.New(t)
+
+	pl, err := json.Marshal([]Decimal{MustParseDecimal("0.10"), {}})
+	assert.NoError(err)
+	assert.Equal(`["0.10","0"]`, string(pl))
+
+	var ds []Decimal
+	err = json.Unmarshal([]byte(`["1.20", 3.40, -5e1]`), &ds)
+	assert.NoError(err)
+	assert.Len(ds, 3)
+	assert.Equal("1.20", ds[0].String())
+	assert.Equal("3.40", ds[1].String())
+	assert.Equal("-50", ds[2].String())
+
+	var d Decimal
+	assert.Error(json.Unmarshal([]byte(`"abc"`), &d))
+	assert.Error(json.Unmarshal([]byte(`true`), &d))
+}
+
+func TestDecimalAttribute(t *testing.T) {
+	assert := assert.New(t)
+
+	typ := Type{Name: "products"}
+	typ.MustAddAttr(Attr{Name: "price", Type: AttrTypeDecimal})
+	typ.MustAddAttr(Attr{Name: "discount", Type: AttrTypeDecimal, Nullable: true})
+	typ.MustAddAttr(Attr{Name: "taxes", Type: AttrTypeDecimal, Array: true})
+
+	schema := &Schema{}
+	schema.MustAddType(typ)
+
+	// Unmarshaling
+	res, err := UnmarshalResource([]byte(`{
+		"id": "p1",
+		"type": "products",
+		"attributes": {
+			"price": "19.90",
+			"discount": null,
+			"taxes": [1.5, "0.25"]
+		}
+	}`), schema)
+	assert.NoError(err)
+
+	assert.Equal("19.90", res.Get("price").(Decimal).String())
+	assert.Equal((*Decimal)(nil), res.Get("discount"))
+	assert.Len(res.Get("taxes").([]Decimal), 2)
+
+	_, err = UnmarshalResource([]byte(`{
+		"id": "p1",
+		"type": "products",
+		"attributes": {"price": "free"}
+	}`), schema)
+	assert.Error(err)
+
+	// Marshaling
+	discount := MustParseDecimal("0.5")
+	res.Set("discount", &discount)
+
+	doc := &Document{Data: res}
+
+	buf := &bytes.Buffer{}
+	assert.NoError(MarshalDocument(buf, doc, nil))
+	assert.Contains(buf.String(),
+		`"attributes":{"discount":"0.5","price":"19.90","taxes":["1.5","0.25"]}`)
+
+	buf.Reset()
+	assert.NoError(MarshalOptions{DecimalAsNumber: true}.MarshalDocument(buf, doc, nil))
+	assert.Contains(buf.String(),
+		`"attributes":{"discount":0.5,"price":19.90,"taxes":[1.5,0.25]}`)
+
+	// Struct fields of type Decimal are detected.
+	type product struct {
+		ID    string   `json:"id" api:"products"`
+		Price Decimal  `json:"price" api:"attr"`
+		Taxes *Decimal `json:"taxes" api:"attr"`
+	}
+
+	wtyp := MustBuildType(product{})
+	assert.Equal(Attr{Name: "price", Type: AttrTypeDecimal}, wtyp.Attrs["price"])
+	assert.Equal(Attr{Name: "taxes", Type: AttrTypeDecimal, Nullable: true}, wtyp.Attrs["taxes"])
+}
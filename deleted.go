@@ -0,0 +1,132 @@
+package jsonapi
+
+import "time"
+
+// Meta keys used to mark resources as deleted or archived (see MarkDeleted and
+// MarkArchived).
+//
+// A deleted or archived resource is a tombstone: it keeps its type and ID so
+// that clients can tell it apart from a resource that never existed.
+const (
+	MetaDeleted    = "deleted"
+	MetaDeletedAt  = "deleted-at"
+	MetaArchived   = "archived"
+	MetaArchivedAt = "archived-at"
+)
+
+// A Deletable is a resource that knows whether it is deleted or archived.
+//
+// Resources that don't implement this interface are considered deleted or
+// archived according to their meta values (see IsDeleted and IsArchived).
+type Deletable interface {
+	Deleted() bool
+	Archived() bool
+}
+
+// MarkDeleted sets the MetaDeleted meta value of res to true and its
+// MetaDeletedAt meta value to at, unless at is the zero time.
+func MarkDeleted(res MetaHolder, at time.Time) {
+	mark(res, MetaDeleted, MetaDeletedAt, at)
+}
+
+// MarkArchived sets the MetaArchived meta value of res to true and its
+// MetaArchivedAt meta value to at, unless at is the zero time.
+func MarkArchived(res MetaHolder, at time.Time) {
+	mark(res, MetaArchived, MetaArchivedAt, at)
+}
+
+// mark sets the meta value of res under key to true and the one under atKey to
+// at if it is not the zero time.
+func mark(res MetaHolder, key, atKey string, at time.Time) {
+	meta := res.Meta()
+	if meta == nil {
+		meta = Meta{}
+	}
+
+	meta[key] = true
+
+	if !at.IsZero() {
+		meta[atKey] = at.UTC().Format(time.RFC3339Nano)
+	}
+
+	res.SetMeta(meta)
+}
+
+// IsDeleted reports whether res is deleted. The Deleted method is used if res
+// is a Deletable. Otherwise, res is deleted if it is a MetaHolder whose
+// MetaDeleted meta value is true.
+func IsDeleted(res Resource) bool {
+	if d, ok := res.(Deletable); ok {
+		return d.Deleted()
+	}
+
+	if mh, ok := res.(MetaHolder); ok {
+		return mh.Meta().GetBool(MetaDeleted)
+	}
+
+	return false
+}
+
+// IsArchived reports whether res is archived, like IsDeleted does for deleted
+// resources.
+func IsArchived(res Resource) bool {
+	if d, ok := res.(Deletable); ok {
+		return d.Archived()
+	}
+
+	if mh, ok := res.(MetaHolder); ok {
+		return mh.Meta().GetBool(MetaArchived)
+	}
+
+	return false
+}
+
+// hidden returns a function that reports whether a resource must be omitted
+// from a document marshaled for url, or nil if no resource is omitted.
+//
+// Deleted and archived resources are omitted if OmitDeleted is true, unless url
+// has a filter[deleted]=true or filter[archived]=true parameter respectively.
+func (o MarshalOptions) hidden(url *URL) func(Resource) bool {
+	if !o.OmitDeleted {
+		return nil
+	}
+
+	var withDeleted, withArchived bool
+
+	if url != nil && url.Params != nil {
+		withDeleted = containsString(url.Params.Filter["filter["+MetaDeleted+"]"], "true")
+		withArchived = containsString(url.Params.Filter["filter["+MetaArchived+"]"], "true")
+	}
+
+	return func(res Resource) bool {
+		return (!withDeleted && IsDeleted(res)) || (!withArchived && IsArchived(res))
+	}
+}
+
+// visibleCollection returns the resources of col that are not hidden.
+func visibleCollection(col Collection, hidden func(Resource) bool) Collection {
+	visible := Resources{}
+
+	for i := 0; i < col.Len(); i++ {
+		if res := col.At(i); !hidden(res) {
+			visible = append(visible, res)
+		}
+	}
+
+	return &visible
+}
+
+// visibleIterator is a CollectionIterator that skips the hidden resources.
+type visibleIterator struct {
+	CollectionIterator
+	hidden func(Resource) bool
+}
+
+func (it *visibleIterator) Next() (Resource, error) {
+	for {
+		res, err := it.CollectionIterator.Next()
+		if res == nil || err != nil || !it.hidden(res) {
+			return res, err
+		}
+	}
+}
@@ -0,0 +1,139 @@
+package jsonapi_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+	"time"
+
+	. "github.com/mark-hartmann/jsonapi"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMarkDeleted(t *testing.T) {
+	assert := assert.New(t)
+
+	typ := Type{Name: "articles"}
+	res := &SoftResource{Type: &typ}
+	res.SetID("a1")
+
+	assert.False(IsDeleted(res))
+	assert.False(IsArchived(res))
+
+	at := time.Date(2020, 1, 2, 3, 4, 5, 0, time.FixedZone("", 3600))
+	MarkDeleted(res, at)
+	assert.True(IsDeleted(res))
+	assert.False(IsArchived(res))
+	assert.Equal(Meta{"deleted": true, "deleted-at": "2020-01-02T02:04:05Z"}, res.Meta())
+
+	MarkArchived(res, time.Time{})
+	assert.True(IsArchived(res))
+	assert.Equal(Meta{
+		"archived":   true,
+		"deleted":    true,
+		"deleted-at": "2020-01-02T02:04:05Z",
+	}, res.Meta())
+
+	// Deletable
+	assert.True(IsDeleted(tombstone{Resource: res, deleted: true}))
+	assert.False(IsDeleted(tombstone{Resource: res}))
+	assert.False(IsArchived(tombstone{Resource: res}))
+}
+
+func TestMarshalOptionsOmitDeleted(t *testing.T) {
+	assert := assert.New(t)
+
+	schema := &Schema{}
+	articles := Type{Name: "articles"}
+	articles.MustAddRel(Rel{FromName: "author", ToType: "people", ToOne: true})
+	schema.MustAddType(articles)
+	schema.MustAddType(Type{Name: "people"})
+
+	people := schema.GetType("people")
+	newRes := func(typ *Type, id string) *SoftResource {
+		res := &SoftResource{Type: typ}
+		res.SetID(id)
+
+		return res
+	}
+
+	a1, a2, a3 := newRes(&articles, "a1"), newRes(&articles, "a2"), newRes(&articles, "a3")
+	a1.Set("author", "p1")
+	a2.Set("author", "p2")
+	MarkDeleted(a2, time.Time{})
+	MarkArchived(a3, time.Time{})
+
+	p1, p2 := newRes(&people, "p1"), newRes(&people, "p2")
+	MarkDeleted(p2, time.Time{})
+
+	ids := func(doc *Document, raw string, opts MarshalOptions) ([]string, []string) {
+		url, err := NewURLFromRaw(schema, raw)
+		assert.NoError(err)
+
+		buf := &bytes.Buffer{}
+		assert.NoError(opts.MarshalDocument(buf, doc, url))
+
+		var pl struct {
+			Data     json.RawMessage
+			Included []struct{ ID string }
+		}
+
+		assert.NoError(json.Unmarshal(buf.Bytes(), &pl))
+
+		var data []struct{ ID string }
+		if err := json.Unmarshal(pl.Data, &data); err != nil {
+			var one struct{ ID string }
+			assert.NoError(json.Unmarshal(pl.Data, &one))
+			data = append(data, one)
+		}
+
+		var dataIDs, inclIDs []string
+		for _, res := range data {
+			dataIDs = append(dataIDs, res.ID)
+		}
+
+		for _, res := range pl.Included {
+			inclIDs = append(inclIDs, res.ID)
+		}
+
+		return dataIDs, inclIDs
+	}
+
+	col := Resources{a1, a2, a3}
+	doc := &Document{Data: &col, Included: []Resource{p1, p2}}
+	opts := MarshalOptions{OmitDeleted: true}
+
+	data, incl := ids(doc, "/articles?include=author", MarshalOptions{})
+	assert.Equal([]string{"a1", "a2", "a3"}, data)
+	assert.Equal([]string{"p1", "p2"}, incl)
+
+	data, incl = ids(doc, "/articles?include=author", opts)
+	assert.Equal([]string{"a1"}, data)
+	assert.Equal([]string{"p1"}, incl)
+
+	data, incl = ids(doc, "/articles?include=author&filter[deleted]=true", opts)
+	assert.Equal([]string{"a1", "a2"}, data)
+	assert.Equal([]string{"p1", "p2"}, incl)
+
+	data, _ = ids(doc, "/articles?filter[archived]=true", opts)
+	assert.Equal([]string{"a1", "a3"}, data)
+
+	// Iterator
+	doc.Data = Iterate(&col)
+	data, _ = ids(doc, "/articles", opts)
+	assert.Equal([]string{"a1"}, data)
+
+	// A single resource is a tombstone.
+	doc = &Document{Data: a2}
+	data, _ = ids(doc, "/articles/a2", opts)
+	assert.Equal([]string{"a2"}, data)
+}
+
+type tombstone struct {
+	Resource
+	deleted bool
+}
+
+func (t tombstone) Deleted() bool  { return t.deleted }
+func (t tombstone) Archived() bool { return false }
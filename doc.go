@@ -25,6 +25,43 @@ There needs to be an ID field of type string. The `api` tag represents the name
 		Articles []string `json:"articles" api:"rel,articles"`
 	}
 
+After attr or rel, the api tag accepts positional arguments and options. For an
+attribute, the first argument is the name of a registered attribute type and the
+second one can be no-array. For a relationship, the arguments are the target type
+and the name of the inverse relationship. The following options are available:
+
+	name=<name>      overrides the member name taken from the json tag
+	nullable[=bool]  overrides the nullability deduced from the Go type
+	format=<format>  sets the format of an attribute (see Attr.Format)
+	readonly         marks an attribute as read-only (see Attr.ReadOnly)
+	omitempty        omits an attribute when it is empty (see Attr.OmitEmpty)
+	deprecated       marks a field as deprecated (see Attr.Deprecated)
+
+If the nullable option does not match the Go type of an attribute, like for a
+string field marked as nullable, a Wrapper converts the values between T and *T,
+and a null value sets the zero value of a field that is not a pointer.
+
+The api tag of the ID field also accepts the deprecated option, for the type
+itself. The api-doc and api-example tags set the description and the example of
+a field, or of the type if they are on the ID field. They are only meant for
+documentation and are ignored by the marshaling.
+
+	type Article struct {
+		ID    string `json:"id" api:"articles" api-doc:"A blog post."`
+		Title string `json:"title" api:"attr" api-doc:"The title." api-example:"Hello"`
+	}
+
+Fields of embedded structs are promoted unless the embedded struct is tagged
+with api:"-".
+
+	type Post struct {
+		Base `api:"-"` // Fields of Base are ignored
+		Timestamps     // Fields of Timestamps are promoted
+
+		Title string `json:"title" api:"attr,name=display-title"`
+		Cover []byte `json:"cover" api:"attr,bytes"` // Base64 instead of an array of numbers
+	}
+
 A lot more is offered in this library. The best way to learn how to use it is to look at the source code and its comments.
 */
 package jsonapi
@@ -1,22 +1,27 @@
 package jsonapi
 
 import (
+	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
 	"io"
-	"sort"
 )
 
 // A Document represents a JSON:API document.
 type Document struct {
-	// Data
+	// Data is a Resource, a Collection, a CollectionIterator, an Identifier
+	// or Identifiers. The resources of a CollectionIterator are consumed when
+	// the document is marshaled and are not taken into account by Include.
 	Data interface{}
 
 	// Included
 	Included []Resource
 
 	// References
+	//
+	// Resources holds the IDs of the resources that are part of the
+	// document by type. It is maintained by Include.
 	Resources map[string]map[string]struct{}
 	Links     map[string]Link
 
@@ -26,87 +31,219 @@ type Document struct {
 	// Top-level members
 	Meta Meta
 
+	// Pagination is used to add pagination links when the primary data is a
+	// page of a collection. See Pagination.
+	Pagination *Pagination
+
+	// OmitSelfLink removes the top-level self link that is otherwise built
+	// from the URL. A self link set in Links is always used instead of the
+	// one from the URL.
+	OmitSelfLink bool
+
+	// OmitData removes the data member when Data is nil. By default, a nil
+	// Data is marshaled as null, which is what a request for an empty to-one
+	// relationship expects. A document without data must have errors or meta.
+	//
+	// UnmarshalDocument sets OmitData when the payload has no data member, so
+	// a null primary data and an absent one can be told apart.
+	OmitData bool
+
 	// Errors
 	Errors []Error
 
+	// SkippedFields reports the unknown fields that UnmarshalDocument skipped
+	// because UnmarshalOptions.UnknownFields is UnknownFieldsReport, whatever
+	// the resources they belong to. Each error wraps an *UnknownFieldError
+	// and has a source pointer relative to the document, like
+	// /included/0/attributes, so ErrorMapper.MapAll can turn them into error
+	// objects. It is not used when the document is marshaled.
+	SkippedFields FieldErrors
+
 	// Internal
 	PrePath string
+
+	// dataIndexed is true once the primary data is in Resources and indexed
+	// is the number of included resources in Resources and included.
+	dataIndexed bool
+	indexed     int
+	included    map[string]map[string]Resource
 }
 
 // Include adds res to the set of resources to be included under the included
 // top-level field.
 //
-// It also makes sure that resources are not added twice.
+// It also makes sure that resources are not added twice and that resources
+// from the primary data are not included. The resources already in the
+// document are tracked in the Resources field, so this method runs in constant
+// time. The primary data must therefore be set before any resource is
+// included.
 func (d *Document) Include(res Resource) {
-	key := res.Get("id").(string) + " " + res.GetType().Name
+	d.indexResources()
 
-	if len(d.Included) == 0 {
-		d.Included = []Resource{}
+	typ, id := res.GetType().Name, res.Get("id").(string)
+	if _, ok := d.Resources[typ][id]; ok {
+		return
 	}
 
-	if dres, ok := d.Data.(Resource); ok {
-		// Check resource
-		rkey := dres.Get("id").(string) + " " + dres.GetType().Name
+	d.addResource(typ, id)
+	d.addIncluded(typ, id, res)
+	d.Included = append(d.Included, res)
+	d.indexed = len(d.Included)
+}
 
-		if rkey == key {
-			return
-		}
-	} else if col, ok := d.Data.(Collection); ok {
-		// Check Collection
-		ctyp := col.GetType()
-		if ctyp.Name == res.GetType().Name {
-			for i := 0; i < col.Len(); i++ {
-				rkey := col.At(i).Get("id").(string) + " " + col.At(i).GetType().Name
-
-				if rkey == key {
-					return
-				}
+// FindIncluded returns the included resource of type typ whose ID is id, or nil
+// if there is none.
+//
+// Like Include, it runs in constant time. The resources appended to Included
+// directly are also found, but Included must not be modified otherwise.
+func (d *Document) FindIncluded(typ, id string) Resource {
+	d.indexResources()
+
+	return d.included[typ][id]
+}
+
+// indexResources adds the resources of the primary data and the included
+// resources that are not yet indexed to the Resources field.
+func (d *Document) indexResources() {
+	if d.Resources == nil {
+		d.Resources = map[string]map[string]struct{}{}
+	}
+
+	if d.Included == nil {
+		d.Included = []Resource{}
+	}
+
+	if !d.dataIndexed && d.Data != nil {
+		switch data := d.Data.(type) {
+		case Resource:
+			d.addResource(data.GetType().Name, data.Get("id").(string))
+		case Collection:
+			for i := 0; i < data.Len(); i++ {
+				r := data.At(i)
+				d.addResource(r.GetType().Name, r.Get("id").(string))
 			}
 		}
+
+		d.dataIndexed = true
 	}
 
-	// Check already included resources
-	for _, res := range d.Included {
-		if key == res.Get("id").(string)+" "+res.GetType().Name {
-			return
-		}
+	// Resources might have been appended to Included directly.
+	for ; d.indexed < len(d.Included); d.indexed++ {
+		r := d.Included[d.indexed]
+		typ, id := r.GetType().Name, r.Get("id").(string)
+		d.addResource(typ, id)
+		d.addIncluded(typ, id, r)
 	}
+}
 
-	d.Included = append(d.Included, res)
+func (d *Document) addResource(typ, id string) {
+	if d.Resources[typ] == nil {
+		d.Resources[typ] = map[string]struct{}{}
+	}
+
+	d.Resources[typ][id] = struct{}{}
+}
+
+func (d *Document) addIncluded(typ, id string, res Resource) {
+	if d.included == nil {
+		d.included = map[string]map[string]Resource{}
+	}
+
+	if d.included[typ] == nil {
+		d.included[typ] = map[string]Resource{}
+	}
+
+	if _, ok := d.included[typ][id]; !ok {
+		d.included[typ][id] = res
+	}
 }
 
 // MarshalDocument marshals a document according to the JSON:API specification.
 //
-// Both doc and url must not be nil.
+// doc must not be nil. url can be nil if the document is not the response to a
+// request, in which case all the fields of the resources are marshaled and the
+// document has no self link.
 func MarshalDocument(dst io.Writer, doc *Document, url *URL) error {
+	return MarshalOptions{}.MarshalDocument(dst, doc, url)
+}
+
+// MarshalDocumentContext marshals a document like MarshalDocument does. The
+// marshaling stops as soon as ctx is done, in which case the error of ctx is
+// returned and nothing is written to dst.
+func MarshalDocumentContext(ctx context.Context, dst io.Writer, doc *Document, url *URL) error {
+	return MarshalOptions{}.MarshalDocumentContext(ctx, dst, doc, url)
+}
+
+// MarshalDocumentContext marshals a document like the MarshalDocumentContext
+// function does, according to the options.
+func (o MarshalOptions) MarshalDocumentContext(ctx context.Context, dst io.Writer, doc *Document,
+	url *URL) error {
+	o.ctx = ctx
+
+	return o.MarshalDocument(dst, doc, url)
+}
+
+// MarshalDocument marshals a document like the MarshalDocument function does and
+// encodes the result according to the options.
+//
+// doc must not be nil. url can be nil (see MarshalDocument).
+func (o MarshalOptions) MarshalDocument(dst io.Writer, doc *Document, url *URL) error {
 	var err error
 
+	if err = ctxErr(o.ctx); err != nil {
+		return err
+	}
+
+	if o.Stats != nil {
+		*o.Stats = MarshalStats{}
+	}
+
+	fields := o.docFields(doc, url)
+	hidden := o.hidden(url)
+
 	// Data
 	var data json.RawMessage
 
 	switch d := doc.Data.(type) {
 	case Resource:
-		if url.Params.Fields != nil {
-			data = MarshalResource(d, doc.PrePath, url.Params.Fields[d.GetType().Name], doc.RelData)
-		} else {
-			data = MarshalResource(d, doc.PrePath, nil, doc.RelData)
-		}
+		data, err = o.marshalResource(d, doc.PrePath, fields[d.GetType().Name], doc.RelData)
 	case Collection:
-		data = MarshalCollection(
+		if hidden != nil {
+			d = visibleCollection(d, hidden)
+		}
+
+		data, err = o.marshalCollection(
 			d,
 			doc.PrePath,
-			url.Params.Fields,
+			fields,
 			doc.RelData,
 		)
+	case CollectionIterator:
+		if hidden != nil {
+			d = &visibleIterator{CollectionIterator: d, hidden: hidden}
+		}
+
+		data, err = o.marshalIterator(d, doc.PrePath, func(typ Type) []string {
+			// The types of the resources are not known in advance.
+			if f, ok := fields[typ.Name]; ok {
+				return f
+			}
+
+			return o.defaultFields(typ)
+		}, doc.RelData)
 	case Identifier:
 		data, err = json.Marshal(d)
 	case Identifiers:
 		data, err = json.Marshal(d)
 	default:
-		if doc.Data != nil {
+		switch {
+		case doc.Data != nil:
 			err = errors.New("data contains an unknown type")
-		} else if len(doc.Errors) == 0 {
+		case len(doc.Errors) > 0:
+		case !doc.OmitData:
 			data = []byte("null")
+		case len(doc.Meta) == 0:
+			err = errMissingPrimaryMember
 		}
 	}
 
@@ -124,22 +261,33 @@ func MarshalDocument(dst io.Writer, doc *Document, url *URL) error {
 	// Included
 	var inclusions []*json.RawMessage
 
-	if len(doc.Included) > 0 {
-		sort.Slice(doc.Included, func(i, j int) bool {
-			return doc.Included[i].Get("id").(string) < doc.Included[j].Get("id").(string)
-		})
-
-		if len(data) > 0 {
-			for key := range doc.Included {
-				typ := doc.Included[key].GetType().Name
-				raw := MarshalResource(
-					doc.Included[key],
-					doc.PrePath,
-					url.Params.Fields[typ],
-					doc.RelData,
-				)
-				rawm := json.RawMessage(raw)
-				inclusions = append(inclusions, &rawm)
+	if len(doc.Included) > 0 && len(data) > 0 {
+		for _, inc := range o.sortIncluded(doc) {
+			if hidden != nil && hidden(inc) {
+				continue
+			}
+
+			if err := ctxErr(o.ctx); err != nil {
+				return err
+			}
+
+			typ := inc.GetType().Name
+
+			raw, err := o.marshalResource(
+				inc,
+				doc.PrePath,
+				fields[typ],
+				doc.RelData,
+			)
+			if err != nil {
+				return err
+			}
+
+			rawm := json.RawMessage(raw)
+			inclusions = append(inclusions, &rawm)
+
+			if o.Stats != nil {
+				o.Stats.Included++
 			}
 		}
 	}
@@ -149,6 +297,11 @@ func MarshalDocument(dst io.Writer, doc *Document, url *URL) error {
 
 	if len(errs) > 0 {
 		plMap["errors"] = errs
+
+		// The primary data is not written.
+		if o.Stats != nil {
+			*o.Stats = MarshalStats{}
+		}
 	} else if len(data) > 0 {
 		plMap["data"] = data
 
@@ -161,25 +314,97 @@ func MarshalDocument(dst io.Writer, doc *Document, url *URL) error {
 		plMap["meta"] = doc.Meta
 	}
 
-	links := doc.Links
+	if links := o.docLinks(doc, url); links != nil {
+		plMap["links"] = links
+	}
+
+	plMap["jsonapi"] = map[string]string{"version": "1.0"}
+
+	return o.encode(dst, plMap)
+}
+
+// docLinks returns the top-level links of doc. The links from doc.Links are
+// completed with the self link and the pagination links.
+//
+// doc.Links is not modified.
+func (o MarshalOptions) docLinks(doc *Document, url *URL) map[string]Link {
+	var links map[string]Link
+
+	if doc.Links != nil {
+		links = make(map[string]Link, len(doc.Links)+1)
+		for k, v := range doc.Links {
+			links[k] = v
+		}
+	}
 
 	if url != nil {
 		if links == nil {
 			links = map[string]Link{}
 		}
 
-		links["self"] = Link{
-			HRef: doc.PrePath + url.String(),
+		if _, ok := links["self"]; !ok {
+			links["self"] = Link{
+				HRef: o.urlLink(doc.PrePath, url),
+			}
 		}
 	}
 
-	if links != nil {
-		plMap["links"] = links
+	if doc.OmitSelfLink {
+		delete(links, "self")
 	}
 
-	plMap["jsonapi"] = map[string]string{"version": "1.0"}
+	if doc.Pagination != nil {
+		href := func(u *URL) string {
+			return o.urlLink(doc.PrePath, u)
+		}
 
-	return json.NewEncoder(dst).Encode(plMap)
+		for k, v := range doc.Pagination.links(url, href) {
+			if _, ok := links[k]; !ok {
+				links[k] = v
+			}
+		}
+	}
+
+	if len(links) == 0 && doc.Links == nil {
+		return nil
+	}
+
+	return links
+}
+
+// docFields returns the fields to marshal for each type. They are the ones from
+// the sparse fieldsets of the URL, or the default ones (see defaultFields) for
+// the types of the resources found in doc that have no sparse fieldset.
+func (o MarshalOptions) docFields(doc *Document, url *URL) map[string][]string {
+	fields := map[string][]string{}
+
+	if url != nil && url.Params != nil {
+		for typ, f := range url.Params.Fields {
+			fields[typ] = f
+		}
+	}
+
+	add := func(res Resource) {
+		typ := res.GetType()
+		if _, ok := fields[typ.Name]; !ok {
+			fields[typ.Name] = o.defaultFields(typ)
+		}
+	}
+
+	switch d := doc.Data.(type) {
+	case Resource:
+		add(d)
+	case Collection:
+		for i := 0; i < d.Len(); i++ {
+			add(d.At(i))
+		}
+	}
+
+	for _, res := range doc.Included {
+		add(res)
+	}
+
+	return fields
 }
 
 var (
@@ -187,12 +412,49 @@ var (
 	errCoexistingMembers    = errors.New(`jsonapi: "data" and "errors" must not coexist`)
 	errMemberDataType       = errors.New("jsonapi: invalid member data type")
 	errInvalidIncluded      = errors.New("jsonapi: invalid inclusions without primary data")
+	errIdentifierType       = errors.New("jsonapi: resource identifier has no type")
+	errDuplicateResource    = errors.New("jsonapi: duplicate resource in compound document")
 )
 
 // UnmarshalDocument reads a payload to build and return a Document object.
 //
+// The resources of the document are indexed in Resources and the included ones
+// can be found with FindIncluded. An error is returned if a resource appears
+// more than once in the document.
+//
 // schema must not be nil.
 func UnmarshalDocument(r io.Reader, schema *Schema) (*Document, error) {
+	return UnmarshalOptions{}.UnmarshalDocument(r, schema)
+}
+
+// UnmarshalDocumentContext reads a payload to build and return a Document
+// object like UnmarshalDocument does. ctx is checked before each resource of
+// the primary data and of the included resources, and the error of ctx is
+// returned as soon as it is done.
+func UnmarshalDocumentContext(ctx context.Context, r io.Reader, schema *Schema) (*Document, error) {
+	return UnmarshalOptions{}.UnmarshalDocumentContext(ctx, r, schema)
+}
+
+// UnmarshalDocumentContext reads a payload to build and return a Document
+// object like the UnmarshalDocumentContext function does, according to the
+// options.
+func (o UnmarshalOptions) UnmarshalDocumentContext(ctx context.Context, r io.Reader,
+	schema *Schema) (*Document, error) {
+	o.ctx = ctx
+
+	return o.UnmarshalDocument(r, schema)
+}
+
+// UnmarshalDocument reads a payload to build and return a Document object like
+// the UnmarshalDocument function does, according to the options.
+//
+// If CollectFieldErrors is set, the field errors of the primary data and the
+// included resources are returned together as a FieldErrors. The source
+// pointers of the errors are relative to the document. If
+// CollectIncludedErrors is set, all the included resources are checked and the
+// errors of the invalid ones are returned as *IncludedError values in a
+// FieldErrors.
+func (o UnmarshalOptions) UnmarshalDocument(r io.Reader, schema *Schema) (*Document, error) {
 	doc := &Document{
 		Included:  []Resource{},
 		Resources: map[string]map[string]struct{}{},
@@ -204,10 +466,23 @@ func UnmarshalDocument(r io.Reader, schema *Schema) (*Document, error) {
 	dec := json.NewDecoder(r)
 
 	// Unmarshal
-	if err := dec.Decode(ske); err != nil {
+	var raw json.RawMessage
+	if err := dec.Decode(&raw); err != nil {
+		return nil, payloadErr(err)
+	}
+
+	if err := ctxErr(o.ctx); err != nil {
+		return nil, err
+	}
+
+	if err := json.Unmarshal(raw, ske); err != nil {
 		return nil, payloadErr(err)
 	}
 
+	if err := o.Policy.checkMembers(raw); err != nil {
+		return nil, err
+	}
+
 	// SPEC 5.1
 	// A document MUST contain at least one of the following three members.
 	if ske.Data == nil && ske.Errors == nil && ske.Meta == nil {
@@ -217,21 +492,39 @@ func UnmarshalDocument(r io.Reader, schema *Schema) (*Document, error) {
 	// SPEC 5.1
 	// data and errors must not coexist.
 	if ske.Data != nil && ske.Errors != nil {
-		return nil, payloadErr(errCoexistingMembers)
+		if len(ske.Errors) > 0 {
+			return nil, payloadErr(errCoexistingMembers)
+		}
+
+		err := o.Policy.check(o.Policy.EmptyErrorsWithData, ViolationReject,
+			payloadErr(errCoexistingMembers))
+		if err != nil {
+			return nil, err
+		}
+
+		ske.Errors = nil
 	}
 
 	if ske.Data == nil && ske.Included != nil {
-		return nil, payloadErr(errInvalidIncluded)
+		err := o.Policy.check(o.Policy.IncludedWithoutData, ViolationReject,
+			payloadErr(errInvalidIncluded))
+		if err != nil {
+			return nil, err
+		}
 	}
 
+	var errs FieldErrors
+
 	// Data
 	switch {
 	case len(ske.Data) > 0:
 		switch {
 		case ske.Data[0] == '{':
 			// Resource
-			res, err := UnmarshalResource(ske.Data, schema)
-			if err != nil {
+			res, skipped, err := o.unmarshalResource(ske.Data, schema)
+			if fe, ok := err.(FieldErrors); ok {
+				errs = append(errs, fe.withPrefix("/data")...)
+			} else if err != nil {
 				return nil, &srcError{
 					ptr:   true,
 					src:   "/data",
@@ -240,9 +533,16 @@ func UnmarshalDocument(r io.Reader, schema *Schema) (*Document, error) {
 			}
 
 			doc.Data = res
+			doc.SkippedFields = o.skippedErrors("/data", skipped)
 		case ske.Data[0] == '[':
-			col, err := UnmarshalCollection(ske.Data, schema)
-			if err != nil {
+			col, skipped, err := o.unmarshalCollection(ske.Data, schema)
+			if cerr := ctxErr(o.ctx); cerr != nil {
+				return nil, cerr
+			}
+
+			if fe, ok := err.(FieldErrors); ok {
+				errs = append(errs, fe.withPrefix("/data")...)
+			} else if err != nil {
 				return nil, &srcError{
 					ptr:   true,
 					src:   "/data",
@@ -251,6 +551,10 @@ func UnmarshalDocument(r io.Reader, schema *Schema) (*Document, error) {
 			}
 
 			doc.Data = col
+
+			if len(skipped) > 0 {
+				doc.SkippedFields = skipped.withPrefix("/data")
+			}
 		case string(ske.Data) == "null":
 			doc.Data = nil
 		default:
@@ -260,19 +564,81 @@ func UnmarshalDocument(r io.Reader, schema *Schema) (*Document, error) {
 		doc.Errors = ske.Errors
 	}
 
+	doc.OmitData = ske.Data == nil
+
 	// Included
+	doc.indexResources()
+
 	for i, raw := range ske.Included {
-		res, err := UnmarshalResource(raw, schema)
-		if err != nil {
+		if err := ctxErr(o.ctx); err != nil {
+			return nil, err
+		}
+
+		res, skipped, err := o.unmarshalResource(raw, schema)
+		if err != nil && o.CollectIncludedErrors {
+			fe, ok := err.(FieldErrors)
+			if !ok {
+				fe = FieldErrors{err}
+			}
+
+			for _, err := range fe {
+				errs = append(errs, &IncludedError{Index: i, err: err})
+			}
+
+			continue
+		}
+
+		if fe, ok := err.(FieldErrors); ok {
+			errs = append(errs, fe.withPrefix(fmt.Sprintf("/included/%d", i))...)
+			continue
+		} else if err != nil {
 			return nil, fmt.Errorf("jsonapi: failed to unmarshal included resource at %d: %w",
 				i, &srcError{src: fmt.Sprintf("/included/%d", i), ptr: true, error: err})
 		}
 
-		doc.Included = append(doc.Included, res)
+		// SPEC (Compound Documents)
+		// A compound document must not include more than one resource object
+		// for each type and id pair.
+		if _, ok := doc.Resources[res.GetType().Name][res.Get("id").(string)]; ok {
+			if o.CollectIncludedErrors {
+				errs = append(errs, &IncludedError{Index: i, err: payloadErr(errDuplicateResource)})
+				continue
+			}
+
+			return nil, &srcError{
+				ptr:   true,
+				src:   fmt.Sprintf("/included/%d", i),
+				error: payloadErr(errDuplicateResource),
+			}
+		}
+
+		doc.Include(res)
+
+		doc.SkippedFields = append(doc.SkippedFields,
+			o.skippedErrors(fmt.Sprintf("/included/%d", i), skipped)...)
+	}
+
+	if len(errs) > 0 {
+		return nil, errs
 	}
 
 	// Meta
+	if o.CheckMetaNames {
+		if name := invalidMemberName(ske.Meta); name != "" {
+			return nil, &srcError{ptr: true, src: "/meta", error: memberNameErr(name)}
+		}
+	}
+
 	doc.Meta = ske.Meta
 
 	return doc, nil
 }
+
+// ctxErr returns the error of ctx, which is nil if ctx is nil or is not done.
+func ctxErr(ctx context.Context) error {
+	if ctx == nil {
+		return nil
+	}
+
+	return ctx.Err()
+}
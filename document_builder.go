@@ -0,0 +1,262 @@
+package jsonapi
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// A ResourceGetter returns the resource of type typ whose ID is id.
+//
+// If the resource does not exist, Get returns nil and no error.
+type ResourceGetter interface {
+	Get(typ, id string) (Resource, error)
+}
+
+// A BatchGetter returns the resources of type typ whose IDs are ids, in any
+// order.
+//
+// The resources that do not exist are not returned, and no error is returned
+// for them. The returned resources must not be nil.
+type BatchGetter interface {
+	GetMany(typ string, ids []string) ([]Resource, error)
+}
+
+// A DocumentBuilder builds compound documents.
+//
+// The resources to include are found by following the relationship paths of
+// Include from the primary data. Each related resource is retrieved once with
+// Getter and included once. The linkage of every relationship that is part of
+// a path is added to RelData, so all the included resources can be reached
+// from the primary data as required by the specification.
+//
+// If Getter also implements BatchGetter, the related resources are retrieved
+// with GetMany, once per step of a path, instead of one by one with Get. This
+// avoids one lookup per resource (the N+1 problem) when the related resources
+// come from a database.
+//
+// The paths may follow self-referential relationships any number of times, like
+// manager.manager.manager for people. The relationships of a resource are only
+// followed once for the same remaining path, so cycles in the data do not
+// multiply the work.
+type DocumentBuilder struct {
+	Getter ResourceGetter
+
+	// Include holds the relationship paths to include, like Params.Include.
+	Include [][]Rel
+}
+
+// Build returns a document whose primary data is data, a Resource or a
+// Collection, with the related resources included.
+//
+// Related resources that do not exist are ignored. An error is returned if
+// Getter fails.
+func (b DocumentBuilder) Build(data interface{}) (*Document, error) {
+	doc := &Document{
+		Data:    data,
+		RelData: map[string][]string{},
+	}
+
+	var primary []Resource
+
+	switch d := data.(type) {
+	case Resource:
+		primary = []Resource{d}
+	case Collection:
+		primary = make([]Resource, 0, d.Len())
+		for i := 0; i < d.Len(); i++ {
+			primary = append(primary, d.At(i))
+		}
+	case nil:
+	default:
+		return nil, errors.New("jsonapi: primary data must be a resource or a collection")
+	}
+
+	if len(b.Include) > 0 && b.Getter == nil {
+		return nil, errors.New("jsonapi: a resource getter is required to include resources")
+	}
+
+	// fetched holds the resources already retrieved, so each one is retrieved
+	// at most once even if several paths lead to it.
+	fetched := map[string]map[string]Resource{}
+
+	// The primary resources are already known.
+	for _, r := range primary {
+		cacheResource(fetched, r.GetType().Name, r.Get("id").(string), r)
+	}
+
+	// expanded holds the resources whose relationships were already followed,
+	// by remaining path. Following a path again from the same resource cannot
+	// lead to new resources, which keeps the work bounded when the
+	// relationships form cycles, like people whose manager is a person.
+	expanded := map[string]struct{}{}
+
+	for _, path := range b.Include {
+		current := primary
+
+		for i, rel := range path {
+			var next []Resource
+
+			rest := joinRelNames(path[i:])
+
+			// The resources whose relationship is followed at this step.
+			var from []Resource
+
+			for _, r := range current {
+				key := r.GetType().Name + " " + r.Get("id").(string) + " " + rest
+				if _, ok := expanded[key]; ok {
+					continue
+				}
+
+				expanded[key] = struct{}{}
+
+				from = append(from, r)
+			}
+
+			if err := b.fetchMany(fetched, rel, from); err != nil {
+				return nil, err
+			}
+
+			for _, r := range from {
+				addRelData(doc.RelData, r.GetType().Name, rel.FromName)
+
+				for _, id := range relIDs(r.Get(rel.FromName)) {
+					res, err := b.fetch(fetched, rel.ToType, id)
+					if err != nil {
+						return nil, err
+					}
+
+					if res != nil {
+						doc.Include(res)
+						next = append(next, res)
+					}
+				}
+			}
+
+			current = next
+		}
+	}
+
+	return doc, nil
+}
+
+// fetch returns the resource of type typ whose ID is id, retrieving it with
+// the getter if it is not in fetched yet.
+func (b DocumentBuilder) fetch(
+	fetched map[string]map[string]Resource, typ, id string,
+) (Resource, error) {
+	if res, ok := fetched[typ][id]; ok {
+		return res, nil
+	}
+
+	res, err := b.Getter.Get(typ, id)
+	if err != nil {
+		return nil, fmt.Errorf("jsonapi: failed to get resource %q of type %q: %w", id, typ, err)
+	}
+
+	cacheResource(fetched, typ, id, res)
+
+	return res, nil
+}
+
+// fetchMany retrieves the resources related to ress through rel that are not
+// in fetched yet with a single call to GetMany, if the getter implements
+// BatchGetter. The resources that do not exist are remembered as nil.
+func (b DocumentBuilder) fetchMany(
+	fetched map[string]map[string]Resource, rel Rel, ress []Resource,
+) error {
+	bg, ok := b.Getter.(BatchGetter)
+	if !ok {
+		return nil
+	}
+
+	var ids []string
+
+	seen := map[string]struct{}{}
+
+	for _, r := range ress {
+		for _, id := range relIDs(r.Get(rel.FromName)) {
+			if _, ok := fetched[rel.ToType][id]; ok {
+				continue
+			}
+
+			if _, ok := seen[id]; !ok {
+				seen[id] = struct{}{}
+				ids = append(ids, id)
+			}
+		}
+	}
+
+	if len(ids) == 0 {
+		return nil
+	}
+
+	res, err := bg.GetMany(rel.ToType, ids)
+	if err != nil {
+		return fmt.Errorf("jsonapi: failed to get resources of type %q: %w", rel.ToType, err)
+	}
+
+	for _, r := range res {
+		if r == nil {
+			return fmt.Errorf("jsonapi: nil resource returned for type %q", rel.ToType)
+		}
+
+		cacheResource(fetched, rel.ToType, r.Get("id").(string), r)
+	}
+
+	for _, id := range ids {
+		if _, ok := fetched[rel.ToType][id]; !ok {
+			cacheResource(fetched, rel.ToType, id, nil)
+		}
+	}
+
+	return nil
+}
+
+// cacheResource stores res in fetched. res may be nil to remember that the
+// resource does not exist.
+func cacheResource(fetched map[string]map[string]Resource, typ, id string, res Resource) {
+	if fetched[typ] == nil {
+		fetched[typ] = map[string]Resource{}
+	}
+
+	fetched[typ][id] = res
+}
+
+// addRelData adds rel to the relationships of typ whose linkage is included.
+func addRelData(relData map[string][]string, typ, rel string) {
+	if !containsString(relData[typ], rel) {
+		relData[typ] = append(relData[typ], rel)
+	}
+}
+
+// joinRelNames returns the names of rels separated by dots, like an include
+// path.
+func joinRelNames(rels []Rel) string {
+	names := make([]string, len(rels))
+	for i, rel := range rels {
+		names[i] = rel.FromName
+	}
+
+	return strings.Join(names, ".")
+}
+
+// relIDs returns the IDs held by v, the value of a relationship.
+func relIDs(v interface{}) []string {
+	switch v := v.(type) {
+	case string:
+		if v != "" {
+			return []string{v}
+		}
+	case []string:
+		return v
+	case RelData:
+		if v.Res.ID != "" {
+			return []string{v.Res.ID}
+		}
+	case RelDataMany:
+		return v.Res.IDs()
+	}
+
+	return nil
+}
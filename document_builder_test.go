@@ -0,0 +1,223 @@
+package jsonapi_test
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	. "github.com/mark-hartmann/jsonapi"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// mockGetter returns the resources of a collection and counts the calls.
+type mockGetter struct {
+	res   *Resources
+	calls int
+	err   error
+}
+
+func (g *mockGetter) Get(typ, id string) (Resource, error) {
+	g.calls++
+
+	if g.err != nil {
+		return nil, g.err
+	}
+
+	return g.res.Find(typ, id), nil
+}
+
+func TestDocumentBuilder(t *testing.T) {
+	assert := assert.New(t)
+
+	schema := newMockSchema()
+
+	newRes := func(typ, id string, rels map[string]interface{}) Resource {
+		rt := schema.GetType(typ)
+		res := rt.New()
+		res.Set("id", id)
+
+		for name, v := range rels {
+			res.Set(name, v)
+		}
+
+		return res
+	}
+
+	primary := &Resources{
+		newRes("mocktypes1", "a1", map[string]interface{}{
+			"to-one":  "b1",
+			"to-many": []string{"b1", "b2", "b404"},
+		}),
+		newRes("mocktypes1", "a2", map[string]interface{}{
+			"to-one": "b2",
+		}),
+	}
+
+	getter := &mockGetter{res: &Resources{
+		newRes("mocktypes2", "b1", map[string]interface{}{
+			"to-one-from-one": "a2",
+		}),
+		newRes("mocktypes2", "b2", map[string]interface{}{
+			"to-one-from-one": "a3",
+		}),
+		newRes("mocktypes1", "a3", nil),
+	}}
+
+	u, err := NewURLFromRaw(schema, "/mocktypes1?include=to-one.to-one-from-one,to-many")
+	assert.NoError(err)
+
+	builder := DocumentBuilder{Getter: getter, Include: u.Params.Include}
+
+	doc, err := builder.Build(primary)
+	assert.NoError(err)
+	assert.Equal(primary, doc.Data)
+
+	ids := []string{}
+	for _, res := range doc.Included {
+		ids = append(ids, res.GetType().Name+"/"+res.Get("id").(string))
+	}
+
+	// a2 is part of the primary data and b404 does not exist.
+	assert.ElementsMatch([]string{"mocktypes2/b1", "mocktypes2/b2", "mocktypes1/a3"}, ids)
+	assert.Equal(4, getter.calls)
+
+	assert.ElementsMatch([]string{"to-one", "to-many"}, doc.RelData["mocktypes1"])
+	assert.Equal([]string{"to-one-from-one"}, doc.RelData["mocktypes2"])
+
+	// Single resource without inclusions
+	doc, err = DocumentBuilder{}.Build(primary.At(0))
+	assert.NoError(err)
+	assert.Equal(primary.At(0), doc.Data)
+	assert.Empty(doc.Included)
+
+	// Errors
+	getter.err = errors.New("db down")
+	_, err = builder.Build(primary)
+	assert.EqualError(err, `jsonapi: failed to get resource "b1" of type "mocktypes2": db down`)
+
+	_, err = DocumentBuilder{Include: u.Params.Include}.Build(primary)
+	assert.Error(err)
+
+	_, err = builder.Build("a1")
+	assert.Error(err)
+}
+
+func TestDocumentBuilderCycles(t *testing.T) {
+	assert := assert.New(t)
+
+	schema := newPeopleSchema()
+	typ := schema.GetType("people")
+
+	// p1 and p2 manage each other and are friends with each other.
+	people := &Resources{}
+
+	for _, ids := range [][2]string{{"p1", "p2"}, {"p2", "p1"}, {"p3", "p1"}} {
+		res := typ.New()
+		res.Set("id", ids[0])
+		res.Set("manager", ids[1])
+		res.Set("friends", []string{ids[1], ids[1]})
+		people.Add(res)
+	}
+
+	getter := &mockGetter{res: people}
+
+	u, err := NewURLFromRaw(schema,
+		"/people?include=manager.manager.manager.manager,friends.friends.friends")
+	assert.NoError(err)
+	assert.Len(u.Params.Include, 2)
+	assert.Len(u.Params.Include[1], 4)
+
+	doc, err := DocumentBuilder{Getter: getter, Include: u.Params.Include}.Build(people.At(2))
+	assert.NoError(err)
+
+	ids := []string{}
+	for _, res := range doc.Included {
+		ids = append(ids, res.Get("id").(string))
+	}
+
+	assert.Equal([]string{"p1", "p2"}, ids)
+	assert.Equal(2, getter.calls)
+	assert.ElementsMatch([]string{"manager", "friends"}, doc.RelData["people"])
+}
+
+// mockBatchGetter is a mockGetter that also implements BatchGetter and records
+// the batches.
+type mockBatchGetter struct {
+	mockGetter
+	batches []string
+	withNil bool
+}
+
+func (g *mockBatchGetter) GetMany(typ string, ids []string) ([]Resource, error) {
+	g.batches = append(g.batches, typ+" "+strings.Join(ids, ","))
+
+	if g.err != nil {
+		return nil, g.err
+	}
+
+	var ress []Resource
+
+	if g.withNil {
+		ress = append(ress, nil)
+	}
+
+	for _, id := range ids {
+		if res := g.res.Find(typ, id); res != nil {
+			ress = append(ress, res)
+		}
+	}
+
+	return ress, nil
+}
+
+func TestDocumentBuilderBatch(t *testing.T) {
+	assert := assert.New(t)
+
+	schema := newPeopleSchema()
+	typ := schema.GetType("people")
+
+	people := &Resources{}
+
+	for _, ids := range [][2]string{{"p1", "p3"}, {"p2", "p3"}, {"p3", "p4"}, {"p4", "p404"}} {
+		res := typ.New()
+		res.Set("id", ids[0])
+		res.Set("manager", ids[1])
+		res.Set("friends", []string{ids[1], "p404"})
+		people.Add(res)
+	}
+
+	getter := &mockBatchGetter{mockGetter: mockGetter{res: people}}
+
+	u, err := NewURLFromRaw(schema, "/people?include=manager.manager.manager,friends")
+	assert.NoError(err)
+
+	primary := &Resources{people.At(0), people.At(1)}
+
+	doc, err := DocumentBuilder{Getter: getter, Include: u.Params.Include}.Build(primary)
+	assert.NoError(err)
+
+	ids := []string{}
+	for _, res := range doc.Included {
+		ids = append(ids, res.Get("id").(string))
+	}
+
+	assert.Equal([]string{"p3", "p4"}, ids)
+
+	// One batch per step, without the resources already known, and no Get.
+	assert.Equal([]string{
+		"people p3,p404",
+		"people p4",
+	}, getter.batches)
+	assert.Equal(0, getter.calls)
+
+	// Errors
+	getter.err = errors.New("db down")
+	_, err = DocumentBuilder{Getter: getter, Include: u.Params.Include}.Build(primary)
+	assert.EqualError(err, `jsonapi: failed to get resources of type "people": db down`)
+
+	getter.err = nil
+	getter.withNil = true
+	_, err = DocumentBuilder{Getter: getter, Include: u.Params.Include}.Build(primary)
+	assert.EqualError(err, `jsonapi: nil resource returned for type "people"`)
+}
@@ -2,10 +2,12 @@ package jsonapi_test
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"io/ioutil"
 	"math"
 	"path/filepath"
+	"strconv"
 	"strings"
 	"testing"
 	"time"
@@ -91,6 +93,41 @@ func TestInclude(t *testing.T) {
 	assert.Equal(expect, ids)
 }
 
+func TestIncludeResources(t *testing.T) {
+	assert := assert.New(t)
+
+	typ1 := &Type{Name: "t1"}
+	typ2 := &Type{Name: "t2"}
+
+	// The primary data is a collection of resources of different types.
+	doc := &Document{
+		Data: &Resources{
+			newResource(typ1, "id1"),
+			newResource(typ2, "id1"),
+		},
+	}
+
+	doc.Include(newResource(typ1, "id1"))
+	doc.Include(newResource(typ2, "id1"))
+	doc.Include(newResource(typ2, "id2"))
+
+	// Resources appended directly are taken into account.
+	doc.Included = append(doc.Included, newResource(typ1, "id3"))
+	doc.Include(newResource(typ1, "id3"))
+	doc.Include(newResource(typ1, "id4"))
+
+	ids := []string{}
+	for _, res := range doc.Included {
+		ids = append(ids, res.GetType().Name+"-"+res.Get("id").(string))
+	}
+
+	assert.Equal([]string{"t2-id2", "t1-id3", "t1-id4"}, ids)
+	assert.Equal(map[string]map[string]struct{}{
+		"t1": {"id1": {}, "id3": {}, "id4": {}},
+		"t2": {"id1": {}, "id2": {}},
+	}, doc.Resources)
+}
+
 func TestMarshalDocumentLinks(t *testing.T) {
 	tests := map[string]struct {
 		doc *Document
@@ -509,7 +546,9 @@ func TestMarshalDocument(t *testing.T) {
 				},
 			},
 			fields: map[string][]string{
-				"mocktype": {"str", "uint64", "bool", "int", "time", "to-1", "to-x-from-1"},
+				"mocktype":   {"str", "uint64", "bool", "int", "time", "to-1", "to-x-from-1"},
+				"mocktypes1": nil, // No fields for the included resources
+				"mocktype6":  nil,
 			},
 		}, {
 			name: "meta",
@@ -645,6 +684,105 @@ func TestMarshalDocument(t *testing.T) {
 	}
 }
 
+func TestMarshalDocumentWithoutURL(t *testing.T) {
+	res1 := Wrap(&mockType3{
+		ID:    "id1",
+		Attr1: "str",
+		Attr2: 1,
+		Rel1:  "id3",
+	})
+	res2 := Wrap(&mockType3{
+		ID:   "id2",
+		Rel2: []string{"id3"},
+	})
+	inc := &SoftResource{Type: &Type{Name: "things"}}
+	inc.AddAttr(Attr{Name: "str", Type: AttrTypeString})
+	inc.SetID("id3")
+	inc.Set("str", "abc")
+
+	resource := func(id, attr1 string, attr2 int) string {
+		return `{
+			"attributes": {"attr1": "` + attr1 + `", "attr2": ` + strconv.Itoa(attr2) + `},
+			"id": "` + id + `",
+			"links": {"self": "/mocktypes3/` + id + `"},
+			"relationships": {
+				"rel1": {
+					"links": {
+						"related": "/mocktypes3/` + id + `/rel1",
+						"self": "/mocktypes3/` + id + `/relationships/rel1"
+					}
+				},
+				"rel2": {
+					"links": {
+						"related": "/mocktypes3/` + id + `/rel2",
+						"self": "/mocktypes3/` + id + `/relationships/rel2"
+					}
+				}
+			},
+			"type": "mocktypes3"
+		}`
+	}
+
+	included := `{
+		"attributes": {"str": "abc"},
+		"id": "id3",
+		"links": {"self": "/things/id3"},
+		"type": "things"
+	}`
+
+	tests := []struct {
+		name     string
+		doc      *Document
+		expected string
+	}{
+		{
+			name: "resource",
+			doc: &Document{
+				Data:     res1,
+				Included: []Resource{inc},
+			},
+			expected: `{
+				"data": ` + resource("id1", "str", 1) + `,
+				"included": [` + included + `],
+				"jsonapi": {"version": "1.0"}
+			}`,
+		}, {
+			name: "collection",
+			doc: &Document{
+				Data: &Resources{res1, res2},
+			},
+			expected: `{
+				"data": [
+					` + resource("id1", "str", 1) + `,
+					` + resource("id2", "", 0) + `
+				],
+				"jsonapi": {"version": "1.0"}
+			}`,
+		}, {
+			name: "null",
+			doc: &Document{
+				Links: map[string]Link{"related": {HRef: "/related"}},
+			},
+			expected: `{
+				"data": null,
+				"jsonapi": {"version": "1.0"},
+				"links": {"related": "/related"}
+			}`,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			assert := assert.New(t)
+
+			payload := &bytes.Buffer{}
+			err := MarshalDocument(payload, test.doc, nil)
+			assert.NoError(err)
+			assert.JSONEq(test.expected, payload.String())
+		})
+	}
+}
+
 func TestMarshalInvalidDocuments(t *testing.T) {
 	// TODO Describe how this test suite works
 	// Setup
@@ -1133,7 +1271,7 @@ func TestUnmarshalDocument_Invalid(t *testing.T) {
 		assert.EqualError(t, err, ""+
 			"jsonapi: failed to unmarshal resource: "+
 			`jsonapi: invalid value "\"abc\"" for field "int8": `+
-			`strconv.Atoi: parsing "\"abc\"": invalid syntax`)
+			`strconv.ParseInt: parsing "\"abc\"": invalid syntax`)
 
 		var InvalidFieldValueErr *InvalidFieldValueError
 		assert.ErrorAs(t, err, &InvalidFieldValueErr)
@@ -1262,6 +1400,65 @@ func TestUnmarshalDocument_Invalid(t *testing.T) {
 		assert.True(t, isPtr)
 		assert.Equal(t, "/included/0", src)
 	})
+
+	t.Run("duplicate included resource", func(t *testing.T) {
+		for _, payload := range []string{
+			`{"data":{"id":"1","type":"objtest"},"included":[` +
+				`{"id":"2","type":"objtest"},{"id":"2","type":"objtest"}]}`,
+			`{"data":[{"id":"1","type":"objtest"}],"included":[` +
+				`{"id":"2","type":"objtest"},{"id":"1","type":"objtest"}]}`,
+		} {
+			_, err := UnmarshalDocument(strings.NewReader(payload), schema)
+			assert.EqualError(t, err, "jsonapi: duplicate resource in compound document")
+			assert.ErrorIs(t, err, ErrInvalidPayload)
+
+			var srcErr srcError
+			assert.ErrorAs(t, err, &srcErr)
+
+			src, isPtr := srcErr.Source()
+			assert.True(t, isPtr)
+			assert.Equal(t, "/included/1", src)
+		}
+	})
+}
+
+func TestDocumentFindIncluded(t *testing.T) {
+	assert := assert.New(t)
+
+	typ1 := &Type{Name: "t1"}
+	typ2 := &Type{Name: "t2"}
+
+	doc := &Document{Data: newResource(typ1, "id1")}
+	assert.Nil(doc.FindIncluded("t1", "id1"))
+
+	doc.Include(newResource(typ1, "id2"))
+	doc.Included = append(doc.Included, newResource(typ2, "id1"))
+
+	assert.Nil(doc.FindIncluded("t1", "id1")) // Primary data
+	assert.Equal(doc.Included[0], doc.FindIncluded("t1", "id2"))
+	assert.Equal(doc.Included[1], doc.FindIncluded("t2", "id1"))
+	assert.Nil(doc.FindIncluded("t2", "id2"))
+	assert.Nil(doc.FindIncluded("t3", "id1"))
+
+	// Unmarshaled documents
+	schema := newMockSchema()
+	payload := `{"data":{"id":"1","type":"mocktypes1"},"included":[` +
+		`{"id":"2","type":"mocktypes1"},{"id":"1","type":"mocktypes2"}]}`
+
+	doc, err := UnmarshalDocument(strings.NewReader(payload), schema)
+	assert.NoError(err)
+	assert.Len(doc.Included, 2)
+
+	res := doc.FindIncluded("mocktypes2", "1")
+	assert.NotNil(res)
+	assert.Equal("mocktypes2", res.GetType().Name)
+	assert.Equal(doc.Included[1], res)
+	assert.Nil(doc.FindIncluded("mocktypes1", "1"))
+
+	assert.Equal(map[string]map[string]struct{}{
+		"mocktypes1": {"1": {}, "2": {}},
+		"mocktypes2": {"1": {}},
+	}, doc.Resources)
 }
 
 func newResource(typ *Type, id string) Resource {
@@ -1271,3 +1468,298 @@ func newResource(typ *Type, id string) Resource {
 
 	return res
 }
+
+func BenchmarkDocumentInclude(b *testing.B) {
+	typ1 := &Type{Name: "t1"}
+	typ2 := &Type{Name: "t2"}
+
+	col := &Resources{}
+	for i := 0; i < 1000; i++ {
+		col.Add(newResource(typ1, "id"+strconv.Itoa(i)))
+	}
+
+	// Half of the resources are duplicates.
+	res := make([]Resource, 0, 20000)
+	for i := 0; i < 10000; i++ {
+		res = append(res,
+			newResource(typ2, "id"+strconv.Itoa(i)),
+			newResource(typ2, "id"+strconv.Itoa(i)),
+		)
+	}
+
+	b.ReportAllocs()
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		doc := &Document{Data: col}
+
+		for _, r := range res {
+			doc.Include(r)
+		}
+	}
+}
+
+func TestMarshalDocumentSelfLink(t *testing.T) {
+	assert := assert.New(t)
+
+	url, err := NewURLFromRaw(newMockSchema(), "/mocktypes1")
+	assert.NoError(err)
+
+	doc := &Document{
+		Data:  &Resources{},
+		Links: map[string]Link{"related": {HRef: "/related"}},
+	}
+
+	marshal := func() string {
+		payload := &bytes.Buffer{}
+		assert.NoError(MarshalDocument(payload, doc, url))
+
+		return payload.String()
+	}
+
+	// The self link is built from the URL.
+	assert.JSONEq(`{
+		"data": [],
+		"jsonapi": {"version": "1.0"},
+		"links": {"related": "/related", "self": "/mocktypes1"}
+	}`, marshal())
+
+	// doc.Links is not modified.
+	assert.Equal(map[string]Link{"related": {HRef: "/related"}}, doc.Links)
+
+	// Override
+	doc.Links["self"] = Link{HRef: "https://example.org/mocktypes1"}
+	assert.JSONEq(`{
+		"data": [],
+		"jsonapi": {"version": "1.0"},
+		"links": {"related": "/related", "self": "https://example.org/mocktypes1"}
+	}`, marshal())
+
+	// Suppression
+	doc.OmitSelfLink = true
+	assert.JSONEq(`{
+		"data": [],
+		"jsonapi": {"version": "1.0"},
+		"links": {"related": "/related"}
+	}`, marshal())
+
+	doc.Links = nil
+	assert.JSONEq(`{
+		"data": [],
+		"jsonapi": {"version": "1.0"}
+	}`, marshal())
+}
+
+func TestMarshalDocumentEmptyFieldset(t *testing.T) {
+	assert := assert.New(t)
+
+	schema := newMockSchema()
+
+	typ := &Type{Name: "mocktypes1"}
+	_ = typ.AddAttr(Attr{Name: "str", Type: AttrTypeString})
+	_ = typ.AddRel(Rel{FromName: "to-one", ToType: "mocktypes2", ToOne: true})
+
+	res := &SoftResource{}
+	res.SetType(typ)
+	res.SetID("mt1")
+	res.Set("str", "abc")
+
+	marshal := func(raw string) (string, *URL) {
+		url, err := NewURLFromRaw(schema, raw)
+		assert.NoError(err)
+
+		payload := &bytes.Buffer{}
+		assert.NoError(MarshalDocument(payload, &Document{Data: res}, url))
+
+		return payload.String(), url
+	}
+
+	// No sparse fieldset
+	payload, url := marshal("/mocktypes1/mt1")
+	assert.NotContains(url.Params.Fields, "mocktypes1")
+	assert.JSONEq(`{
+		"data": {
+			"attributes": {"str": "abc"},
+			"id": "mt1",
+			"links": {"self": "/mocktypes1/mt1"},
+			"relationships": {
+				"to-one": {
+					"links": {
+						"related": "/mocktypes1/mt1/to-one",
+						"self": "/mocktypes1/mt1/relationships/to-one"
+					}
+				}
+			},
+			"type": "mocktypes1"
+		},
+		"jsonapi": {"version": "1.0"},
+		"links": {"self": "/mocktypes1/mt1"}
+	}`, payload)
+
+	// Empty sparse fieldset
+	payload, url = marshal("/mocktypes1/mt1?fields[mocktypes1]=")
+	assert.Equal(map[string][]string{"mocktypes1": {}}, url.Params.Fields)
+	assert.Equal("/mocktypes1/mt1?fields[mocktypes1]=", url.UnescapedString())
+	assert.JSONEq(`{
+		"data": {
+			"id": "mt1",
+			"links": {"self": "/mocktypes1/mt1"},
+			"type": "mocktypes1"
+		},
+		"jsonapi": {"version": "1.0"},
+		"links": {"self": "/mocktypes1/mt1?fields%5Bmocktypes1%5D="}
+	}`, payload)
+
+	// The empty fieldset survives a round trip through the URL.
+	url2, err := NewURLFromRaw(schema, url.String())
+	assert.NoError(err)
+	assert.Equal(url.Params.Fields, url2.Params.Fields)
+}
+
+func TestMarshalDocumentDefaultFields(t *testing.T) {
+	assert := assert.New(t)
+
+	typ := Type{Name: "articles"}
+	typ.MustAddAttr(Attr{Name: "title", Type: AttrTypeString})
+	typ.MustAddAttr(Attr{Name: "body", Type: AttrTypeString})
+
+	schema := &Schema{}
+	schema.MustAddType(typ)
+	assert.NoError(schema.SetDefaultFields("articles", "title"))
+
+	res := &SoftResource{Type: &typ}
+	res.SetID("a1")
+	res.Set("title", "Title")
+	res.Set("body", "Body")
+
+	marshal := func(url *URL) string {
+		opts := MarshalOptions{Schema: schema, RelLinks: RelLinksNone}
+		payload := &bytes.Buffer{}
+		assert.NoError(opts.MarshalDocument(payload, &Document{Data: &Resources{res}}, url))
+
+		return payload.String()
+	}
+
+	// Without a URL
+	out := marshal(nil)
+	assert.Contains(out, `"attributes":{"title":"Title"}`)
+
+	// With a URL that has a sparse fieldset for the type
+	url, err := NewURLFromRaw(schema, "/articles?fields[articles]=body")
+	assert.NoError(err)
+	assert.Contains(marshal(url), `"attributes":{"body":"Body"}`)
+
+	// An empty default fieldset is kept when its last field is removed.
+	schema.RemoveAttr("articles", "title")
+
+	out = marshal(nil)
+	assert.NotContains(out, `"attributes"`)
+	assert.NotContains(out, `"body"`)
+}
+
+func TestDocumentOmitData(t *testing.T) {
+	assert := assert.New(t)
+
+	schema := newMockSchema()
+
+	// Null data
+	doc, err := UnmarshalDocument(strings.NewReader(`{"data": null}`), schema)
+	assert.NoError(err)
+	assert.Nil(doc.Data)
+	assert.False(doc.OmitData)
+
+	buf := &bytes.Buffer{}
+	assert.NoError(MarshalDocument(buf, doc, nil))
+	assert.JSONEq(`{"data":null,"jsonapi":{"version":"1.0"},"links":{}}`, buf.String())
+
+	// No data
+	doc, err = UnmarshalDocument(strings.NewReader(`{"meta": {"count": 2}}`), schema)
+	assert.NoError(err)
+	assert.Nil(doc.Data)
+	assert.True(doc.OmitData)
+
+	buf.Reset()
+	assert.NoError(MarshalDocument(buf, doc, nil))
+	assert.JSONEq(`{"jsonapi":{"version":"1.0"},"links":{},"meta":{"count":2}}`, buf.String())
+
+	// Data takes precedence.
+	doc.Data = Wrap(&mockType1{ID: "id1"})
+
+	buf.Reset()
+	assert.NoError(MarshalDocument(buf, doc, nil))
+	assert.Contains(buf.String(), `"data":{`)
+
+	// Nothing to marshal
+	buf.Reset()
+	assert.Error(MarshalDocument(buf, &Document{OmitData: true}, nil))
+}
+
+// cancelingIterator cancels its context once it has returned n resources.
+type cancelingIterator struct {
+	CollectionIterator
+	cancel func()
+	n      int
+}
+
+func (it *cancelingIterator) Next() (Resource, error) {
+	if it.n == 0 {
+		it.cancel()
+	}
+
+	it.n--
+
+	return it.CollectionIterator.Next()
+}
+
+func TestDocumentContext(t *testing.T) {
+	assert := assert.New(t)
+
+	typ := Type{Name: "articles"}
+	typ.MustAddAttr(Attr{Name: "title", Type: AttrTypeString})
+
+	schema := &Schema{}
+	schema.MustAddType(typ)
+
+	col := &Resources{}
+
+	for _, id := range []string{"a1", "a2", "a3"} {
+		res := &SoftResource{Type: &typ}
+		res.SetID(id)
+		col.Add(res)
+	}
+
+	inc := &SoftResource{Type: &typ}
+	inc.SetID("a4")
+
+	doc := &Document{Data: col, Included: []Resource{inc}}
+	buf := &bytes.Buffer{}
+
+	// Live context
+	assert.NoError(MarshalDocumentContext(context.Background(), buf, doc, nil))
+
+	payload := buf.Bytes()
+
+	doc2, err := UnmarshalDocumentContext(context.Background(), bytes.NewReader(payload), schema)
+	assert.NoError(err)
+	assert.Equal(3, doc2.Data.(Collection).Len())
+
+	// Canceled context
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	buf = &bytes.Buffer{}
+	err = MarshalDocumentContext(ctx, buf, doc, nil)
+	assert.Equal(context.Canceled, err)
+	assert.Empty(buf.Bytes())
+
+	_, err = UnmarshalDocumentContext(ctx, bytes.NewReader(payload), schema)
+	assert.Equal(context.Canceled, err)
+
+	// Canceled during the marshaling
+	ctx, cancel = context.WithCancel(context.Background())
+	doc.Data = &cancelingIterator{CollectionIterator: Iterate(col), cancel: cancel, n: 1}
+
+	err = MarshalOptions{}.MarshalDocumentContext(ctx, buf, doc, nil)
+	assert.Equal(context.Canceled, err)
+	assert.Empty(buf.Bytes())
+}
@@ -0,0 +1,235 @@
+package jsonapi
+
+import (
+	"reflect"
+	"sort"
+)
+
+// NilPolicy defines which attribute values EqualOptions considers equal to a
+// nil value.
+type NilPolicy int
+
+// Nil policies
+const (
+	// NilEqual makes all nil values equal, regardless of their types. For
+	// example, a nil *string is equal to a nil *int or to a nil interface.
+	NilEqual NilPolicy = iota
+	// NilEqualsEmpty is like NilEqual, but empty slices and maps are also
+	// equal to nil values.
+	NilEqualsEmpty
+	// NilStrict only considers two values equal if they have the same type
+	// and value according to reflect.DeepEqual.
+	NilStrict
+)
+
+// EqualOptions configures how EqualOpts compares resources.
+//
+// The zero value gives the same result as Equal.
+type EqualOptions struct {
+	// CompareIDs makes resources with different IDs unequal.
+	CompareIDs bool
+
+	// Nil defines which values are equal to nil values.
+	Nil NilPolicy
+
+	// Fields restricts the comparison to the attributes and relationships of
+	// the given names. All fields are compared if Fields is nil.
+	Fields []string
+}
+
+// A Difference is a difference between two resources found by Diff.
+//
+// Field is the name of the attribute or relationship, or "type" or "id". Value1
+// and Value2 are the values found in the first and the second resource. For a
+// type, they are the names of the types. A field that exists in only one of the
+// resources has Missing1 or Missing2 set and a nil value in the other one.
+type Difference struct {
+	Field    string
+	Value1   interface{}
+	Value2   interface{}
+	Missing1 bool
+	Missing2 bool
+}
+
+// Equal reports whether r1 and r2 are equal.
+//
+// Two resources are equal if their types are equal, all the attributes are
+// equal (same type and same value), and all the relationships are equal. Nil
+// values are equal regardless of their types and empty to-many relationships
+// are equal.
+//
+// IDs are ignored.
+func Equal(r1, r2 Resource) bool {
+	return EqualOpts(r1, r2, EqualOptions{})
+}
+
+// EqualStrict is like Equal, but it also considers IDs.
+func EqualStrict(r1, r2 Resource) bool {
+	return EqualOpts(r1, r2, EqualOptions{CompareIDs: true})
+}
+
+// EqualOpts reports whether r1 and r2 are equal according to opts.
+func EqualOpts(r1, r2 Resource, opts EqualOptions) bool {
+	return len(opts.diff(r1, r2, true)) == 0
+}
+
+// Diff returns the differences between r1 and r2 like DiffOpts with the IDs
+// being compared.
+func Diff(r1, r2 Resource) []Difference {
+	return DiffOpts(r1, r2, EqualOptions{CompareIDs: true})
+}
+
+// DiffOpts returns the differences between r1 and r2 according to opts, sorted
+// by field name with the type and the ID first. nil is returned if the
+// resources are equal.
+func DiffOpts(r1, r2 Resource, opts EqualOptions) []Difference {
+	return opts.diff(r1, r2, false)
+}
+
+// diff returns the differences between r1 and r2. It stops at the first one
+// if first is true.
+func (o EqualOptions) diff(r1, r2 Resource, first bool) []Difference {
+	var diffs []Difference
+
+	add := func(d Difference) bool {
+		diffs = append(diffs, d)
+		return first
+	}
+
+	// Type
+	if t1, t2 := r1.GetType().Name, r2.GetType().Name; t1 != t2 {
+		if add(Difference{Field: "type", Value1: t1, Value2: t2}) {
+			return diffs
+		}
+	}
+
+	// ID
+	if o.CompareIDs {
+		if id1, id2 := r1.Get("id").(string), r2.Get("id").(string); id1 != id2 {
+			if add(Difference{Field: "id", Value1: id1, Value2: id2}) {
+				return diffs
+			}
+		}
+	}
+
+	attrs1, attrs2 := r1.Attrs(), r2.Attrs()
+	rels1, rels2 := r1.Rels(), r2.Rels()
+
+	for _, name := range o.fieldNames(attrs1, attrs2, rels1, rels2) {
+		_, isAttr1 := attrs1[name]
+		_, isAttr2 := attrs2[name]
+		_, isRel1 := rels1[name]
+		_, isRel2 := rels2[name]
+
+		d := Difference{Field: name}
+
+		if isAttr1 || isRel1 {
+			d.Value1 = r1.Get(name)
+		} else {
+			d.Missing1 = true
+		}
+
+		if isAttr2 || isRel2 {
+			d.Value2 = r2.Get(name)
+		} else {
+			d.Missing2 = true
+		}
+
+		var equal bool
+
+		switch {
+		case d.Missing1 || d.Missing2:
+		case isAttr1 && isAttr2:
+			equal = o.equalValues(d.Value1, d.Value2)
+		case isRel1 && isRel2:
+			equal = rels1[name].ToOne == rels2[name].ToOne && equalRelValues(d.Value1, d.Value2)
+		}
+
+		if !equal && add(d) {
+			return diffs
+		}
+	}
+
+	return diffs
+}
+
+// fieldNames returns the names of the fields to compare in alphabetical order.
+func (o EqualOptions) fieldNames(
+	attrs1, attrs2 map[string]Attr, rels1, rels2 map[string]Rel,
+) []string {
+	set := map[string]struct{}{}
+
+	for name := range attrs1 {
+		set[name] = struct{}{}
+	}
+
+	for name := range attrs2 {
+		set[name] = struct{}{}
+	}
+
+	for name := range rels1 {
+		set[name] = struct{}{}
+	}
+
+	for name := range rels2 {
+		set[name] = struct{}{}
+	}
+
+	names := make([]string, 0, len(set))
+
+	for name := range set {
+		if o.Fields == nil || containsString(o.Fields, name) {
+			names = append(names, name)
+		}
+	}
+
+	sort.Strings(names)
+
+	return names
+}
+
+// equalValues reports whether the attribute values v1 and v2 are equal
+// according to the nil policy.
+func (o EqualOptions) equalValues(v1, v2 interface{}) bool {
+	if reflect.DeepEqual(v1, v2) {
+		return true
+	}
+
+	switch o.Nil {
+	case NilEqual:
+		return isNil(v1) && isNil(v2)
+	case NilEqualsEmpty:
+		return isNilOrEmpty(v1) && isNilOrEmpty(v2)
+	}
+
+	return false
+}
+
+// equalRelValues reports whether the relationship values v1 and v2 are equal.
+// Empty to-many relationships are equal, even if one of them is nil.
+func equalRelValues(v1, v2 interface{}) bool {
+	ids1, ok1 := v1.([]string)
+	ids2, ok2 := v2.([]string)
+
+	if ok1 && ok2 && len(ids1) == 0 && len(ids2) == 0 {
+		return true
+	}
+
+	return reflect.DeepEqual(v1, v2)
+}
+
+// isNilOrEmpty reports whether v is nil or an empty slice or map.
+func isNilOrEmpty(v interface{}) bool {
+	if isNil(v) {
+		return true
+	}
+
+	rv := reflect.ValueOf(v)
+
+	switch rv.Kind() {
+	case reflect.Slice, reflect.Map:
+		return rv.Len() == 0
+	}
+
+	return false
+}
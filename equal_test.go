@@ -0,0 +1,115 @@
+package jsonapi_test
+
+import (
+	"testing"
+
+	. "github.com/mark-hartmann/jsonapi"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEqualOpts(t *testing.T) {
+	assert := assert.New(t)
+
+	newRes := func(id string, nullable bool, intType int) *SoftResource {
+		typ := &Type{Name: "type"}
+		_ = typ.AddAttr(Attr{Name: "str", Type: AttrTypeString})
+		_ = typ.AddAttr(Attr{Name: "strs", Type: AttrTypeString, Array: true, Nullable: nullable})
+		_ = typ.AddAttr(Attr{Name: "int", Type: intType, Nullable: true})
+		_ = typ.AddRel(Rel{FromName: "to-one", ToType: "type", ToOne: true})
+		_ = typ.AddRel(Rel{FromName: "to-many", ToType: "type"})
+
+		sr := &SoftResource{}
+		sr.SetType(typ)
+		sr.SetID(id)
+
+		return sr
+	}
+
+	r1, r2 := newRes("1", true, AttrTypeInt), newRes("2", true, AttrTypeInt)
+
+	// IDs
+	assert.True(EqualOpts(r1, r2, EqualOptions{}))
+	assert.False(EqualOpts(r1, r2, EqualOptions{CompareIDs: true}))
+
+	// Nil values of different types
+	r2 = newRes("2", true, AttrTypeInt8)
+
+	assert.True(EqualOpts(r1, r2, EqualOptions{Nil: NilEqual}))
+	assert.True(EqualOpts(r1, r2, EqualOptions{Nil: NilEqualsEmpty}))
+	assert.False(EqualOpts(r1, r2, EqualOptions{Nil: NilStrict}))
+
+	// Nil and empty values
+	r2 = newRes("2", false, AttrTypeInt)
+
+	assert.False(EqualOpts(r1, r2, EqualOptions{Nil: NilEqual}))
+	assert.True(EqualOpts(r1, r2, EqualOptions{Nil: NilEqualsEmpty}))
+	assert.False(EqualOpts(r1, r2, EqualOptions{Nil: NilStrict}))
+
+	// Fields
+	r1.Set("str", "a")
+	r2.Set("str", "b")
+	r1.Set("to-one", "1")
+
+	assert.False(EqualOpts(r1, r2, EqualOptions{}))
+	assert.False(EqualOpts(r1, r2, EqualOptions{Fields: []string{"str"}}))
+	assert.False(EqualOpts(r1, r2, EqualOptions{Fields: []string{"to-one"}}))
+	assert.True(EqualOpts(r1, r2, EqualOptions{Fields: []string{"int", "to-many"}}))
+	assert.True(EqualOpts(r1, r2, EqualOptions{Fields: []string{}}))
+
+	// Empty to-many relationships
+	r1.Set("to-many", []string(nil))
+	r2.Set("to-many", []string{})
+
+	assert.True(EqualOpts(r1, r2, EqualOptions{Fields: []string{"to-many"}}))
+}
+
+func TestDiff(t *testing.T) {
+	assert := assert.New(t)
+
+	typ1 := &Type{Name: "type1"}
+	_ = typ1.AddAttr(Attr{Name: "a", Type: AttrTypeString})
+	_ = typ1.AddAttr(Attr{Name: "b", Type: AttrTypeInt})
+	_ = typ1.AddRel(Rel{FromName: "rel", ToType: "type1", ToOne: true})
+
+	typ2 := &Type{Name: "type2"}
+	_ = typ2.AddAttr(Attr{Name: "b", Type: AttrTypeInt})
+	_ = typ2.AddAttr(Attr{Name: "c", Type: AttrTypeBool})
+	_ = typ2.AddRel(Rel{FromName: "rel", ToType: "type1", ToOne: true})
+
+	r1 := &SoftResource{}
+	r1.SetType(typ1)
+	r1.SetID("1")
+	r1.Set("a", "abc")
+	r1.Set("b", 1)
+	r1.Set("rel", "2")
+
+	r2 := &SoftResource{}
+	r2.SetType(typ2)
+	r2.SetID("1")
+	r2.Set("b", 2)
+	r2.Set("c", true)
+	r2.Set("rel", "2")
+
+	assert.Equal([]Difference{
+		{Field: "type", Value1: "type1", Value2: "type2"},
+		{Field: "a", Value1: "abc", Missing2: true},
+		{Field: "b", Value1: 1, Value2: 2},
+		{Field: "c", Missing1: true, Value2: true},
+	}, Diff(r1, r2))
+
+	r2.SetID("2")
+
+	assert.Equal([]Difference{
+		{Field: "type", Value1: "type1", Value2: "type2"},
+		{Field: "b", Value1: 1, Value2: 2},
+	}, DiffOpts(r1, r2, EqualOptions{Fields: []string{"b", "rel"}}))
+
+	r3 := r1.Copy().(*SoftResource)
+	assert.Nil(Diff(r1, r3))
+
+	r3.SetID("2")
+	assert.Equal([]Difference{
+		{Field: "id", Value1: "1", Value2: "2"},
+	}, Diff(r1, r3))
+}
@@ -117,18 +117,66 @@ func (e *InvalidFieldValueError) IsAttr() bool {
 	return !e.asRel
 }
 
+// NewInvalidFieldValueError returns the error for val, a value that cannot be assigned to the
+// field named field of typ. It is meant for the resources that implement SetterE, like the ones
+// generated by GenerateGo.
+func NewInvalidFieldValueError(typ Type, field string, val interface{}) *InvalidFieldValueError {
+	e := &InvalidFieldValueError{
+		Type:  typ.Name,
+		Field: field,
+		Value: fmt.Sprint(val),
+		err:   fmt.Errorf("got value of type %T, not string", val),
+	}
+
+	if attr, ok := typ.Attrs[field]; ok {
+		e.FieldType, _ = GetAttrTypeName(attr.Type, attr.Array, attr.Nullable)
+		e.err = fmt.Errorf("got value of type %T, not %T", val, attrZeroValue(attr))
+	} else if rel, ok := typ.Rels[field]; ok {
+		e.FieldType = rel.ToType
+		e.asRel = true
+
+		if !rel.ToOne {
+			e.err = fmt.Errorf("got value of type %T, not []string", val)
+		}
+	}
+
+	return e
+}
+
+// ReadOnlyFieldError is returned if a value is given for an attribute that cannot be
+// modified by the clients (see Attr.ReadOnly).
+type ReadOnlyFieldError struct {
+	Type  string
+	Field string
+}
+
+func (e *ReadOnlyFieldError) Error() string {
+	return fmt.Sprintf("jsonapi: field %q of type %q is read-only", e.Field, e.Type)
+}
+
 // IllegalParameterError is returned when a query parameter is used in an illegal
 // context. That is, if a collection parameter is used for a single resource or
 // if a parameter is not supported.
 type IllegalParameterError struct {
 	Param      string
 	isResource bool
+	err        error
 }
 
 func (e *IllegalParameterError) Error() string {
+	if e.err != nil {
+		return fmt.Sprintf("jsonapi: illegal query parameter %q: %s", e.Param, e.err)
+	}
+
 	return fmt.Sprintf("jsonapi: illegal query parameter %q", e.Param)
 }
 
+// Unwrap returns the error that explains why the parameter is illegal, like the
+// one returned by URLOptions.AuthorizePath, if any.
+func (e *IllegalParameterError) Unwrap() error {
+	return e.err
+}
+
 func (e *IllegalParameterError) Source() (string, bool) {
 	return e.Param, false
 }
@@ -162,16 +210,95 @@ func (e *ConflictingValueError) Values() (string, string) {
 	return e.value, e.conflictValue
 }
 
+// UnknownParameterError is returned when a query parameter is neither defined
+// by the specification nor allowed by the server. See URLOptions.StrictParams.
+type UnknownParameterError struct {
+	Param string
+}
+
+func (e *UnknownParameterError) Error() string {
+	return fmt.Sprintf("jsonapi: unknown query parameter %q", e.Param)
+}
+
+func (e *UnknownParameterError) Source() (string, bool) {
+	return e.Param, false
+}
+
 // An Error represents an error object from the JSON:API specification.
 type Error struct {
-	ID     string                 `json:"id"`
-	Code   string                 `json:"code"`
-	Status string                 `json:"status"`
-	Title  string                 `json:"title"`
-	Detail string                 `json:"detail"`
-	Links  map[string]Link        `json:"links"`
+	ID     string          `json:"id"`
+	Code   string          `json:"code"`
+	Status string          `json:"status"`
+	Title  string          `json:"title"`
+	Detail string          `json:"detail"`
+	Links  map[string]Link `json:"links"`
+
+	// Src is the source of the error.
+	Src ErrorSource `json:"-"`
+
+	// Source holds the members of the source object.
+	//
+	// Deprecated: use Src. The errors returned by this package set both, and
+	// the members of Src take precedence when the error is marshaled.
 	Source map[string]interface{} `json:"source"`
-	Meta   Meta                   `json:"meta"`
+
+	Meta Meta `json:"meta"`
+}
+
+// An ErrorSource is the source object of an error: a JSON pointer to the
+// member of the request document, the name of the query parameter, or the name
+// of the request header that caused the error. Only the members that are set
+// are marshaled.
+type ErrorSource struct {
+	Pointer   string `json:"pointer,omitempty"`
+	Parameter string `json:"parameter,omitempty"`
+	Header    string `json:"header,omitempty"`
+}
+
+// IsZero reports whether no member of s is set.
+func (s ErrorSource) IsZero() bool {
+	return s == ErrorSource{}
+}
+
+// setSource sets the source of e. The deprecated Source map is kept in sync.
+func (e *Error) setSource(src ErrorSource) {
+	e.Src = src
+
+	if e.Source == nil {
+		e.Source = map[string]interface{}{}
+	}
+
+	for k, v := range src.members() {
+		e.Source[k] = v
+	}
+}
+
+// WithSource returns a copy of e whose source is src. The deprecated Source map
+// of the copy only holds the members of src.
+func (e Error) WithSource(src ErrorSource) Error {
+	e.Source = nil
+	e.setSource(src)
+
+	return e
+}
+
+// members returns the members of s that are set.
+func (s ErrorSource) members() map[string]interface{} {
+	m := map[string]interface{}{}
+
+	if s.Pointer != "" {
+		m["pointer"] = s.Pointer
+	}
+
+	if s.Parameter != "" {
+		m["parameter"] = s.Parameter
+	}
+
+	if s.Header != "" {
+		m["header"] = s.Header
+	}
+
+	return m
 }
 
 // NewError returns an empty Error object.
@@ -240,8 +367,17 @@ func (e Error) MarshalJSON() ([]byte, error) {
 		m["links"] = e.Links
 	}
 
-	if len(e.Source) > 0 {
-		m["source"] = e.Source
+	if len(e.Source) > 0 || !e.Src.IsZero() {
+		src := make(map[string]interface{}, len(e.Source))
+		for k, v := range e.Source {
+			src[k] = v
+		}
+
+		for k, v := range e.Src.members() {
+			src[k] = v
+		}
+
+		m["source"] = src
 	}
 
 	if len(e.Meta) > 0 {
@@ -251,6 +387,24 @@ func (e Error) MarshalJSON() ([]byte, error) {
 	return json.Marshal(m)
 }
 
+// UnmarshalJSON populates e from a JSON:API error object. The string members
+// of the source object are also set in Src.
+func (e *Error) UnmarshalJSON(data []byte) error {
+	type alias Error
+
+	a := alias{}
+	if err := json.Unmarshal(data, &a); err != nil {
+		return err
+	}
+
+	*e = Error(a)
+	e.Src.Pointer, _ = e.Source["pointer"].(string)
+	e.Src.Parameter, _ = e.Source["parameter"].(string)
+	e.Src.Header, _ = e.Source["header"].(string)
+
+	return nil
+}
+
 // NewErrBadRequest (400) returns the corresponding error.
 func NewErrBadRequest(title, detail string) Error {
 	e := NewError()
@@ -262,6 +416,38 @@ func NewErrBadRequest(title, detail string) Error {
 	return e
 }
 
+// NewErrUnknownParameter (400) returns the corresponding error for the query
+// parameter named param, which is set as the source of the error.
+func NewErrUnknownParameter(param string) Error {
+	e := NewErrBadRequest("Unknown parameter",
+		fmt.Sprintf("The query parameter %q is not supported.", param))
+	e.setSource(ErrorSource{Parameter: param})
+
+	return e
+}
+
+// NewErrInvalidPageNumberParameter (400) returns the corresponding error for
+// the invalid value of the page[number] parameter, which is set as the source
+// of the error.
+func NewErrInvalidPageNumberParameter(badPageNumber string) Error {
+	e := NewErrBadRequest("Invalid page number parameter",
+		fmt.Sprintf("The page number parameter %q is not valid.", badPageNumber))
+	e.setSource(ErrorSource{Parameter: "page[number]"})
+
+	return e
+}
+
+// NewErrInvalidPageSizeParameter (400) returns the corresponding error for the
+// invalid value of the page[size] parameter, which is set as the source of the
+// error.
+func NewErrInvalidPageSizeParameter(badPageSize string) Error {
+	e := NewErrBadRequest("Invalid page size parameter",
+		fmt.Sprintf("The page size parameter %q is not valid.", badPageSize))
+	e.setSource(ErrorSource{Parameter: "page[size]"})
+
+	return e
+}
+
 // NewErrUnauthorized (401) returns the corresponding error.
 func NewErrUnauthorized() Error {
 	e := NewError()
@@ -295,6 +481,49 @@ func NewErrNotFound() Error {
 	return e
 }
 
+// NewErrMethodNotAllowed (405) returns the corresponding error.
+func NewErrMethodNotAllowed() Error {
+	e := NewError()
+
+	e.Status = strconv.Itoa(http.StatusMethodNotAllowed)
+	e.Title = "Method not allowed"
+
+	return e
+}
+
+// NewErrNotAcceptable (406) returns the corresponding error.
+func NewErrNotAcceptable() Error {
+	e := NewError()
+
+	e.Status = strconv.Itoa(http.StatusNotAcceptable)
+	e.Title = "Not acceptable"
+	e.Detail = "No acceptable representation of the resource can be returned."
+
+	return e
+}
+
+// NewErrConflict (409) returns the corresponding error.
+func NewErrConflict() Error {
+	e := NewError()
+
+	e.Status = strconv.Itoa(http.StatusConflict)
+	e.Title = "Conflict"
+	e.Detail = "The request conflicts with the current state of the server."
+
+	return e
+}
+
+// NewErrGone (410) returns the corresponding error.
+func NewErrGone() Error {
+	e := NewError()
+
+	e.Status = strconv.Itoa(http.StatusGone)
+	e.Title = "Gone"
+	e.Detail = "The resource is no longer available."
+
+	return e
+}
+
 // NewErrPayloadTooLarge (413) returns the corresponding error.
 func NewErrPayloadTooLarge() Error {
 	e := NewError()
@@ -326,6 +555,21 @@ func NewErrUnsupportedMediaType() Error {
 	return e
 }
 
+// NewErrUnprocessableEntity (422) returns the corresponding error.
+//
+// It is meant for documents that are well-formed but semantically invalid,
+// like a value that breaks a validation rule. The source of the error can be
+// set with WithSource.
+func NewErrUnprocessableEntity() Error {
+	e := NewError()
+
+	e.Status = strconv.Itoa(http.StatusUnprocessableEntity)
+	e.Title = "Unprocessable entity"
+	e.Detail = "The document could not be processed."
+
+	return e
+}
+
 // NewErrTooManyRequests (429) returns the corresponding error.
 func NewErrTooManyRequests() Error {
 	e := NewError()
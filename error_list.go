@@ -0,0 +1,115 @@
+package jsonapi
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// An ErrorList collects the errors that occurred while handling a request.
+//
+// Identical errors are only added once. Status returns the HTTP status code
+// that should be used for the response and Document returns a document that
+// can be marshaled as the response payload.
+//
+// The zero value is an empty list ready to use.
+type ErrorList struct {
+	errs []Error
+	keys map[string]struct{}
+}
+
+// Add adds the given errors to the list. An error is ignored if an identical
+// error is already in the list.
+func (l *ErrorList) Add(errs ...Error) {
+	if l.keys == nil {
+		l.keys = map[string]struct{}{}
+	}
+
+	for _, e := range errs {
+		// Two errors are identical if their JSON representations are. An
+		// error that cannot be marshaled cannot be compared and is always
+		// added.
+		if pl, err := e.MarshalJSON(); err == nil {
+			if _, ok := l.keys[string(pl)]; ok {
+				continue
+			}
+
+			l.keys[string(pl)] = struct{}{}
+		}
+
+		l.errs = append(l.errs, e)
+	}
+}
+
+// Len returns the number of errors in the list.
+func (l *ErrorList) Len() int {
+	return len(l.errs)
+}
+
+// Errors returns the errors in the order they were added.
+func (l *ErrorList) Errors() []Error {
+	errs := make([]Error, len(l.errs))
+	copy(errs, l.errs)
+
+	return errs
+}
+
+// Status returns the most generally applicable HTTP status code for the errors
+// of the list.
+//
+// If all the errors have the same status, it is returned. Otherwise, 400 is
+// returned if all of them are client errors (4xx) and 500 in any other case.
+// Errors without a valid status are ignored, and 500 is returned if no error
+// has one. 0 is returned if the list is empty.
+//
+// SPEC 7.5 (Error Objects)
+func (l *ErrorList) Status() int {
+	if len(l.errs) == 0 {
+		return 0
+	}
+
+	statuses := map[int]struct{}{}
+
+	for _, e := range l.errs {
+		s, err := strconv.Atoi(e.Status)
+		if err == nil && s >= 400 && s <= 599 {
+			statuses[s] = struct{}{}
+		}
+	}
+
+	if len(statuses) == 0 {
+		return http.StatusInternalServerError
+	}
+
+	status := http.StatusBadRequest
+
+	for s := range statuses {
+		if len(statuses) == 1 {
+			return s
+		}
+
+		if s >= 500 {
+			status = http.StatusInternalServerError
+		}
+	}
+
+	return status
+}
+
+// Document returns a new document holding the errors of the list.
+func (l *ErrorList) Document() *Document {
+	return &Document{
+		Errors: l.Errors(),
+	}
+}
+
+// Error returns the string representations of all the errors separated by
+// semicolons.
+func (l *ErrorList) Error() string {
+	msgs := make([]string, 0, len(l.errs))
+	for _, e := range l.errs {
+		msgs = append(msgs, e.Error())
+	}
+
+	return strings.Join(msgs, "; ")
+}
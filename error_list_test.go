@@ -0,0 +1,129 @@
+package jsonapi_test
+
+import (
+	"bytes"
+	"testing"
+
+	. "github.com/mark-hartmann/jsonapi"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestErrorList(t *testing.T) {
+	assert := assert.New(t)
+
+	var list ErrorList
+
+	assert.Equal(0, list.Len())
+	assert.Equal(0, list.Status())
+	assert.Empty(list.Errors())
+
+	e1 := NewErrBadRequest("Bad request", "Invalid attribute.")
+	e1.Source["pointer"] = "/data/attributes/title"
+
+	e2 := NewErrBadRequest("Bad request", "Invalid attribute.")
+	e2.Source["pointer"] = "/data/attributes/title"
+
+	e3 := NewErrBadRequest("Bad request", "Invalid attribute.")
+	e3.Source["pointer"] = "/data/attributes/content"
+
+	// e4 is identical to e1 once marshaled.
+	e4 := Error{
+		Status: "400",
+		Title:  "Bad request",
+		Detail: "Invalid attribute.",
+		Source: map[string]interface{}{"pointer": "/data/attributes/title"},
+	}
+
+	list.Add(e1, e2)
+	list.Add(e3, e4)
+
+	assert.Equal(2, list.Len())
+	assert.Equal([]Error{e1, e3}, list.Errors())
+	assert.Equal(
+		"400 Bad Request: Invalid attribute.; 400 Bad Request: Invalid attribute.",
+		list.Error(),
+	)
+
+	// Errors returns a copy.
+	list.Errors()[0] = NewErrNotFound()
+	assert.Equal(e1, list.Errors()[0])
+
+	// Document
+	doc := list.Document()
+	assert.Equal([]Error{e1, e3}, doc.Errors)
+
+	payload := &bytes.Buffer{}
+	err := MarshalDocument(payload, doc, nil)
+	assert.NoError(err)
+	assert.JSONEq(`{
+		"errors": [
+			{
+				"detail": "Invalid attribute.",
+				"source": {"pointer": "/data/attributes/title"},
+				"status": "400",
+				"title": "Bad request"
+			},
+			{
+				"detail": "Invalid attribute.",
+				"source": {"pointer": "/data/attributes/content"},
+				"status": "400",
+				"title": "Bad request"
+			}
+		],
+		"jsonapi": {"version": "1.0"}
+	}`, payload.String())
+}
+
+func TestErrorListStatus(t *testing.T) {
+	tests := []struct {
+		name     string
+		statuses []string
+		expected int
+	}{
+		{
+			name:     "single error",
+			statuses: []string{"404"},
+			expected: 404,
+		}, {
+			name:     "same status",
+			statuses: []string{"403", "403"},
+			expected: 403,
+		}, {
+			name:     "client errors",
+			statuses: []string{"403", "404", "413"},
+			expected: 400,
+		}, {
+			name:     "server errors",
+			statuses: []string{"501", "503"},
+			expected: 500,
+		}, {
+			name:     "client and server errors",
+			statuses: []string{"404", "503"},
+			expected: 500,
+		}, {
+			name:     "invalid statuses are ignored",
+			statuses: []string{"", "abc", "200", "404"},
+			expected: 404,
+		}, {
+			name:     "no valid status",
+			statuses: []string{"", "abc"},
+			expected: 500,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var list ErrorList
+
+			for i, status := range test.statuses {
+				e := NewError()
+				e.ID = string(rune('a' + i))
+				e.Status = status
+				list.Add(e)
+			}
+
+			assert.Equal(t, test.expected, list.Status())
+		})
+	}
+}
@@ -0,0 +1,197 @@
+package jsonapi
+
+import (
+	"errors"
+	"net/http"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// An ErrorRule converts err into an Error object. The boolean is false if the
+// rule does not apply to err.
+type ErrorRule func(err error) (Error, bool)
+
+// An ErrorMapper converts Go errors into Error objects that can be sent to a
+// client.
+//
+// The registered rules are tried in the order they were registered, followed by
+// the built-in rules for the errors returned by this package. If no rule
+// applies, a 500 Internal Server Error is returned so that no internal detail
+// is leaked.
+//
+// The zero value is ready to use and only applies the built-in rules.
+type ErrorMapper struct {
+	rules []ErrorRule
+}
+
+// Register adds a rule to the mapper.
+func (m *ErrorMapper) Register(rule ErrorRule) {
+	m.rules = append(m.rules, rule)
+}
+
+// RegisterIs adds a rule that returns a copy of e for the errors matching
+// target according to errors.Is.
+func (m *ErrorMapper) RegisterIs(target error, e Error) {
+	m.Register(func(err error) (Error, bool) {
+		if errors.Is(err, target) {
+			return copyError(e), true
+		}
+
+		return Error{}, false
+	})
+}
+
+// RegisterAs adds a rule that returns a copy of e for the errors matching the
+// type target points to according to errors.As.
+//
+// Like errors.As, it panics if target is not a non-nil pointer to either a type
+// that implements error, or to any interface type.
+func (m *ErrorMapper) RegisterAs(target interface{}, e Error) {
+	// errors.As validates the target.
+	_ = errors.As(errors.New(""), target)
+
+	typ := reflect.TypeOf(target).Elem()
+
+	m.Register(func(err error) (Error, bool) {
+		if errors.As(err, reflect.New(typ).Interface()) {
+			return copyError(e), true
+		}
+
+		return Error{}, false
+	})
+}
+
+// Map converts err into an Error object.
+//
+// If err is a FieldErrors, only its first error is converted. Use MapAll to
+// convert all of them.
+func (m *ErrorMapper) Map(err error) Error {
+	if fe, ok := err.(FieldErrors); ok && len(fe) > 0 {
+		err = fe[0]
+	}
+
+	for _, rule := range m.rules {
+		if e, ok := rule(err); ok {
+			return e
+		}
+	}
+
+	if e, ok := mapBuiltinError(err); ok {
+		return e
+	}
+
+	return NewErrInternalServerError()
+}
+
+// MapAll converts all the given errors and collects them into an ErrorList.
+//
+// Each error of a FieldErrors is converted into its own Error object.
+func (m *ErrorMapper) MapAll(errs ...error) *ErrorList {
+	list := &ErrorList{}
+
+	for _, err := range errs {
+		if fe, ok := err.(FieldErrors); ok {
+			for _, err := range fe {
+				list.Add(m.Map(err))
+			}
+
+			continue
+		}
+
+		list.Add(m.Map(err))
+	}
+
+	return list
+}
+
+// mapBuiltinError converts the errors returned by this package.
+func mapBuiltinError(err error) (Error, bool) {
+	var (
+		e             Error
+		unknownType   *UnknownTypeError
+		unknownField  *UnknownFieldError
+		invalidField  *InvalidFieldError
+		invalidValue  *InvalidFieldValueError
+		illegalParam  *IllegalParameterError
+		conflictValue *ConflictingValueError
+		readOnly      *ReadOnlyFieldError
+		unknownParam  *UnknownParameterError
+	)
+
+	switch {
+	case errors.As(err, &e):
+		return e, true
+	case errors.As(err, &unknownType):
+		e = NewErrBadRequest("Unknown type", errDetail(unknownType))
+	case errors.As(err, &unknownField):
+		e = NewErrBadRequest("Unknown field", errDetail(unknownField))
+	case errors.As(err, &invalidField):
+		e = NewErrBadRequest("Invalid field", errDetail(invalidField))
+	case errors.As(err, &invalidValue):
+		e = NewErrBadRequest("Invalid field value", errDetail(invalidValue))
+	case errors.As(err, &illegalParam):
+		e = NewErrBadRequest("Illegal parameter", errDetail(illegalParam))
+	case errors.As(err, &conflictValue):
+		e = NewErrBadRequest("Conflicting values", errDetail(conflictValue))
+	case errors.As(err, &unknownParam):
+		e = NewErrUnknownParameter(unknownParam.Param)
+	case errors.As(err, &readOnly):
+		e = NewErrForbidden()
+		e.Detail = errDetail(readOnly)
+	case errors.Is(err, ErrInvalidPayload):
+		e = NewErrBadRequest("Invalid payload", errDetail(err))
+	default:
+		return Error{}, false
+	}
+
+	// An error in the URL path means the requested resource does not exist.
+	var pe pathErr
+	if errors.As(err, &pe) && pe.InPath() {
+		e.Status = strconv.Itoa(http.StatusNotFound)
+		e.Title = "Not found"
+	}
+
+	if src, isPtr, ok := errSrc(err); ok {
+		if isPtr {
+			e.setSource(ErrorSource{Pointer: src})
+		} else {
+			e.setSource(ErrorSource{Parameter: src})
+		}
+	}
+
+	return e, true
+}
+
+// errDetail returns the message of err without the package prefix and with
+// the first letter in upper case.
+func errDetail(err error) string {
+	msg := strings.TrimPrefix(err.Error(), "jsonapi: ")
+	if msg == "" {
+		return msg
+	}
+
+	return strings.ToUpper(msg[:1]) + msg[1:] + "."
+}
+
+// copyError returns a copy of e that does not share its maps.
+func copyError(e Error) Error {
+	links := make(map[string]Link, len(e.Links))
+	for k, v := range e.Links {
+		links[k] = v
+	}
+
+	src := make(map[string]interface{}, len(e.Source))
+	for k, v := range e.Source {
+		src[k] = v
+	}
+
+	meta := make(Meta, len(e.Meta))
+	for k, v := range e.Meta {
+		meta[k] = v
+	}
+
+	e.Links, e.Source, e.Meta = links, src, meta
+
+	return e
+}
@@ -0,0 +1,200 @@
+package jsonapi_test
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"testing"
+
+	. "github.com/mark-hartmann/jsonapi"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type mapperTestError struct {
+	code string
+}
+
+func (e *mapperTestError) Error() string {
+	return "mapper test error " + e.code
+}
+
+func TestErrorMapperBuiltin(t *testing.T) {
+	schema := newMockSchema()
+	mapper := &ErrorMapper{}
+
+	parseURL := func(rawurl string) error {
+		_, err := NewURLFromRaw(schema, rawurl)
+		return err
+	}
+
+	unmarshal := func(payload string) error {
+		_, err := UnmarshalDocument(strings.NewReader(payload), schema)
+		return err
+	}
+
+	tests := []struct {
+		name   string
+		err    error
+		status string
+		title  string
+		detail string
+		source map[string]interface{}
+	}{
+		{
+			name:   "unknown type in path",
+			err:    parseURL("/unknown"),
+			status: "404",
+			title:  "Not found",
+			detail: `Resource type "unknown" does not exist.`,
+			source: map[string]interface{}{},
+		}, {
+			name:   "unknown relationship in path",
+			err:    parseURL("/mocktypes1/abc/unknown"),
+			status: "404",
+			title:  "Not found",
+			detail: `Field "unknown" does not exist in resource type "mocktypes1".`,
+			source: map[string]interface{}{},
+		}, {
+			name:   "unknown type in parameter",
+			err:    parseURL("/mocktypes1?fields[unknown]=abc"),
+			status: "400",
+			title:  "Unknown type",
+			detail: `Resource type "unknown" does not exist.`,
+			source: map[string]interface{}{"parameter": "fields"},
+		}, {
+			name:   "unknown field in parameter",
+			err:    parseURL("/mocktypes1?include=unknown"),
+			status: "400",
+			title:  "Unknown field",
+			detail: `Field "unknown" does not exist in resource type "mocktypes1".`,
+			source: map[string]interface{}{"parameter": "include"},
+		}, {
+			name:   "illegal parameter",
+			err:    parseURL("/mocktypes1/abc?sort=str"),
+			status: "400",
+			title:  "Illegal parameter",
+			detail: `Illegal query parameter "sort".`,
+			source: map[string]interface{}{"parameter": "sort"},
+		}, {
+			name: "unknown attribute in payload",
+			err: unmarshal(`{"data":{"id":"1","type":"mocktypes1",` +
+				`"attributes":{"unknown":1}}}`),
+			status: "400",
+			title:  "Unknown field",
+			detail: `Field "unknown" does not exist in resource type "mocktypes1".`,
+			source: map[string]interface{}{"pointer": "/data/attributes"},
+		}, {
+			name: "invalid attribute value in payload",
+			err: unmarshal(`{"data":{"id":"1","type":"mocktypes1",` +
+				`"attributes":{"str":1}}}`),
+			status: "400",
+			title:  "Invalid field value",
+			source: map[string]interface{}{"pointer": "/data/attributes/str"},
+		}, {
+			name:   "invalid payload",
+			err:    unmarshal(`{"data":"string"}`),
+			status: "400",
+			title:  "Invalid payload",
+			source: map[string]interface{}{"pointer": "/data"},
+		}, {
+			name:   "error object",
+			err:    fmt.Errorf("wrapped: %w", NewErrForbidden()),
+			status: "403",
+			title:  "Forbidden",
+			detail: "Permission is required to perform this request.",
+			source: map[string]interface{}{},
+		}, {
+			name:   "unknown error",
+			err:    errors.New("secret internal error"),
+			status: "500",
+			title:  "Internal server error",
+			source: map[string]interface{}{},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			assert := assert.New(t)
+
+			assert.Error(test.err)
+
+			e := mapper.Map(test.err)
+			assert.Equal(test.status, e.Status)
+			assert.Equal(test.title, e.Title)
+			assert.Equal(test.source, e.Source)
+
+			if test.detail != "" {
+				assert.Equal(test.detail, e.Detail)
+			}
+		})
+	}
+}
+
+func TestErrorMapperRules(t *testing.T) {
+	assert := assert.New(t)
+
+	mapper := &ErrorMapper{}
+
+	notExist := NewErrNotFound()
+	notExist.Code = "file-not-found"
+
+	mapper.RegisterIs(os.ErrNotExist, notExist)
+
+	testErr := NewError()
+	testErr.Status = "418"
+	testErr.Title = "I'm a teapot"
+
+	mapper.RegisterAs(new(*mapperTestError), testErr)
+
+	mapper.Register(func(err error) (Error, bool) {
+		var te *mapperTestError
+		if errors.As(err, &te) && te.code == "special" {
+			return NewErrServiceUnavailable(), true
+		}
+
+		return Error{}, false
+	})
+
+	// errors.Is
+	e := mapper.Map(fmt.Errorf("open: %w", os.ErrNotExist))
+	assert.Equal("404", e.Status)
+	assert.Equal("file-not-found", e.Code)
+
+	// The returned errors do not share the template's maps.
+	e.Meta["key"] = "value"
+	assert.Empty(mapper.Map(os.ErrNotExist).Meta)
+
+	// errors.As
+	e = mapper.Map(fmt.Errorf("wrapped: %w", &mapperTestError{code: "special"}))
+	assert.Equal("418", e.Status)
+	assert.Equal("I'm a teapot", e.Title)
+
+	// Registered rules take precedence over the built-in ones.
+	mapper = &ErrorMapper{}
+	mapper.Register(func(err error) (Error, bool) {
+		return NewErrBadRequest("Custom", err.Error()), true
+	})
+
+	e = mapper.Map(&IllegalParameterError{Param: "sort"})
+	assert.Equal("Custom", e.Title)
+
+	// Invalid targets
+	assert.Panics(func() {
+		mapper.RegisterAs(nil, testErr)
+	})
+	assert.Panics(func() {
+		mapper.RegisterAs(mapperTestError{}, testErr)
+	})
+
+	// MapAll
+	mapper = &ErrorMapper{}
+	list := mapper.MapAll(
+		errors.New("error 1"),
+		errors.New("error 2"),
+		&IllegalParameterError{Param: "sort"},
+	)
+	assert.Equal(2, list.Len())
+	assert.Equal(500, list.Status())
+}
@@ -119,6 +119,34 @@ func TestErrorConstructors(t *testing.T) {
 				return e
 			}(),
 			expected: "404 Not Found: The URI does not exist.",
+		}, {
+			name: "NewErrMethodNotAllowed",
+			err: func() Error {
+				e := NewErrMethodNotAllowed()
+				return e
+			}(),
+			expected: "405 Method Not Allowed: Method not allowed",
+		}, {
+			name: "NewErrNotAcceptable",
+			err: func() Error {
+				e := NewErrNotAcceptable()
+				return e
+			}(),
+			expected: "406 Not Acceptable: No acceptable representation of the resource can be returned.",
+		}, {
+			name: "NewErrConflict",
+			err: func() Error {
+				e := NewErrConflict()
+				return e
+			}(),
+			expected: "409 Conflict: The request conflicts with the current state of the server.",
+		}, {
+			name: "NewErrGone",
+			err: func() Error {
+				e := NewErrGone()
+				return e
+			}(),
+			expected: "410 Gone: The resource is no longer available.",
 		}, {
 			name: "NewErrPayloadTooLarge",
 			err: func() Error {
@@ -140,6 +168,13 @@ func TestErrorConstructors(t *testing.T) {
 				return e
 			}(),
 			expected: "415 Unsupported Media Type: Unsupported media type",
+		}, {
+			name: "NewErrUnprocessableEntity",
+			err: func() Error {
+				e := NewErrUnprocessableEntity()
+				return e
+			}(),
+			expected: "422 Unprocessable Entity: The document could not be processed.",
 		}, {
 			name: "NewErrTooManyRequests",
 			err: func() Error {
@@ -240,3 +275,57 @@ func TestErrorMarshalJSON(t *testing.T) {
 		}
 	`)
 }
+
+func TestErrorSource(t *testing.T) {
+	assert := assert.New(t)
+
+	// Only the members that are set are marshaled.
+	e := NewErrBadRequest("Bad request", "")
+	e.Src = ErrorSource{Header: "If-Match"}
+
+	payload, err := json.Marshal(e)
+	assert.NoError(err)
+	assert.JSONEq(`{
+		"status": "400",
+		"title": "Bad request",
+		"source": {"header": "If-Match"}
+	}`, string(payload))
+
+	// Src takes precedence over the deprecated map.
+	e.Source["header"] = "Accept"
+	e.Source["parameter"] = "sort"
+
+	payload, err = json.Marshal(e)
+	assert.NoError(err)
+	assert.JSONEq(`{
+		"status": "400",
+		"title": "Bad request",
+		"source": {"header": "If-Match", "parameter": "sort"}
+	}`, string(payload))
+
+	// Unmarshaling
+	var e2 Error
+	assert.NoError(json.Unmarshal(
+		[]byte(`{"status":"400","source":{"pointer":"/data","header":"Accept"}}`), &e2))
+	assert.Equal(ErrorSource{Pointer: "/data", Header: "Accept"}, e2.Src)
+	assert.Equal(map[string]interface{}{"pointer": "/data", "header": "Accept"}, e2.Source)
+	assert.True(ErrorSource{}.IsZero())
+	assert.False(e2.Src.IsZero())
+
+	// The errors of the package set both.
+	_, err = ParseMediaType("application/vnd.api+json; unknown=x")
+
+	e3, ok := err.(Error)
+	if assert.True(ok) {
+		assert.Equal("Content-Type", e3.Src.Header)
+		assert.Equal("Content-Type", e3.Source["header"])
+	}
+
+	// WithSource
+	e4 := NewErrUnprocessableEntity()
+	e5 := e4.WithSource(ErrorSource{Pointer: "/data/attributes/title"})
+	assert.Equal(ErrorSource{Pointer: "/data/attributes/title"}, e5.Src)
+	assert.Equal(map[string]interface{}{"pointer": "/data/attributes/title"}, e5.Source)
+	assert.True(e4.Src.IsZero())
+	assert.Empty(e4.Source)
+}
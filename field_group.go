@@ -0,0 +1,113 @@
+package jsonapi
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// SetFieldGroup defines a named sparse fieldset for the type named typ, like a
+// "summary" group made of the title and the creation date of an article. A
+// request can then use the group in a fields parameter with an @ prefix, like
+// fields[articles]=@summary, and NewParams replaces it by its fields. Groups
+// and fields can be mixed in the same parameter.
+//
+// Calling SetFieldGroup without any field removes the group. An error is
+// returned if the type or one of the fields does not exist, or if the name of
+// the group does not meet the member name requirements.
+func (s *Schema) SetFieldGroup(typ, name string, fields ...string) error {
+	t := s.GetType(typ)
+	if t.Name == "" {
+		return &UnknownTypeError{Type: typ}
+	}
+
+	if !memberRegexp.MatchString(name) {
+		return fmt.Errorf("jsonapi: field group name %q does not meet member name requirements",
+			name)
+	}
+
+	if len(fields) == 0 {
+		delete(s.fieldGroups[typ], name)
+		return nil
+	}
+
+	fields = removeDuplicates(fields)
+
+	if field := findFirstDifference(fields, t.Fields()); field != "" && field != "id" {
+		return &UnknownFieldError{Type: typ, Field: field}
+	}
+
+	if s.fieldGroups == nil {
+		s.fieldGroups = map[string]map[string][]string{}
+	}
+
+	if s.fieldGroups[typ] == nil {
+		s.fieldGroups[typ] = map[string][]string{}
+	}
+
+	s.fieldGroups[typ][name] = fields
+
+	return nil
+}
+
+// FieldGroup returns the fields of the group named name of the type named typ
+// and whether the group exists. See SetFieldGroup.
+func (s *Schema) FieldGroup(typ, name string) ([]string, bool) {
+	fields, ok := s.fieldGroups[typ][name]
+	if !ok {
+		return nil, false
+	}
+
+	return append([]string{}, fields...), true
+}
+
+// FieldGroups returns the sorted names of the field groups of the type named
+// typ.
+func (s *Schema) FieldGroups(typ string) []string {
+	names := make([]string, 0, len(s.fieldGroups[typ]))
+	for name := range s.fieldGroups[typ] {
+		names = append(names, name)
+	}
+
+	sort.Strings(names)
+
+	return names
+}
+
+// expandFieldGroups replaces the groups of fields, which start with @, by
+// their fields. An *UnknownFieldError is returned if a group does not exist.
+func (s *Schema) expandFieldGroups(typ string, fields []string) ([]string, error) {
+	expanded := make([]string, 0, len(fields))
+
+	for _, field := range fields {
+		if !strings.HasPrefix(field, "@") {
+			expanded = append(expanded, field)
+			continue
+		}
+
+		group, ok := s.fieldGroups[typ][field[1:]]
+		if !ok {
+			return nil, &UnknownFieldError{Type: typ, Field: field}
+		}
+
+		expanded = append(expanded, group...)
+	}
+
+	return expanded, nil
+}
+
+// removeGroupField removes field from the field groups of typ. The groups are
+// kept even if they become empty.
+func (s *Schema) removeGroupField(typ, field string) {
+	for name, fields := range s.fieldGroups[typ] {
+		kept := make([]string, 0, len(fields))
+
+		for _, f := range fields {
+			if f != field {
+				kept = append(kept, f)
+			}
+		}
+
+		s.fieldGroups[typ][name] = kept
+	}
+}
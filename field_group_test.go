@@ -0,0 +1,95 @@
+package jsonapi_test
+
+import (
+	"net/url"
+	"testing"
+
+	. "github.com/mark-hartmann/jsonapi"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSchemaFieldGroups(t *testing.T) {
+	assert := assert.New(t)
+
+	schema := &Schema{}
+	schema.MustAddType(Type{Name: "type1"})
+	schema.MustAddAttr("type1", Attr{Name: "attr1", Type: AttrTypeString})
+	schema.MustAddAttr("type1", Attr{Name: "attr2", Type: AttrTypeString})
+	schema.MustAddRel("type1", Rel{FromName: "rel1", ToType: "type1"})
+
+	_, ok := schema.FieldGroup("type1", "summary")
+	assert.False(ok)
+	assert.Empty(schema.FieldGroups("type1"))
+
+	assert.NoError(schema.SetFieldGroup("type1", "summary", "rel1", "attr1", "attr1"))
+	assert.NoError(schema.SetFieldGroup("type1", "full", "attr1", "attr2", "rel1"))
+
+	fields, ok := schema.FieldGroup("type1", "summary")
+	assert.True(ok)
+	assert.Equal([]string{"attr1", "rel1"}, fields)
+	assert.Equal([]string{"full", "summary"}, schema.FieldGroups("type1"))
+
+	// Invalid type, name or field
+	assert.EqualError(
+		schema.SetFieldGroup("type2", "summary", "attr1"),
+		`jsonapi: resource type "type2" does not exist`,
+	)
+	assert.EqualError(
+		schema.SetFieldGroup("type1", "@summary", "attr1"),
+		`jsonapi: field group name "@summary" does not meet member name requirements`,
+	)
+	assert.EqualError(
+		schema.SetFieldGroup("type1", "summary", "attr3"),
+		`jsonapi: field "attr3" does not exist in resource type "type1"`,
+	)
+
+	// Removed fields are removed from the groups.
+	schema.RemoveAttr("type1", "attr1")
+
+	fields, _ = schema.FieldGroup("type1", "summary")
+	assert.Equal([]string{"rel1"}, fields)
+
+	// Subset
+	sub, err := schema.Subset("type1")
+	assert.NoError(err)
+
+	fields, _ = sub.FieldGroup("type1", "full")
+	assert.Equal([]string{"attr2", "rel1"}, fields)
+
+	// Removal
+	assert.NoError(schema.SetFieldGroup("type1", "summary"))
+	assert.Equal([]string{"full"}, schema.FieldGroups("type1"))
+
+	schema.RemoveType("type1")
+	assert.Empty(schema.FieldGroups("type1"))
+}
+
+func TestNewParamsFieldGroups(t *testing.T) {
+	assert := assert.New(t)
+
+	schema := newMockSchema()
+	assert.NoError(schema.SetFieldGroup("mocktypes1", "summary", "str", "to-one"))
+	assert.NoError(schema.SetFieldGroup("mocktypes2", "summary", "strptr"))
+
+	params := func(query string) (*Params, error) {
+		su, err := NewSimpleURL(&url.URL{RawQuery: query})
+		assert.NoError(err)
+
+		return NewParams(schema, su, "mocktypes1")
+	}
+
+	p, err := params("fields[mocktypes1]=@summary,int,str&fields[mocktypes2]=@summary")
+	assert.NoError(err)
+	assert.Equal(map[string][]string{
+		"mocktypes1": {"int", "str", "to-one"},
+		"mocktypes2": {"strptr"},
+	}, p.Fields)
+
+	mt1 := schema.GetType("mocktypes1")
+	assert.Equal([]Rel{mt1.Rels["to-one"]}, p.Rels["mocktypes1"])
+
+	// Unknown group
+	_, err = params("fields[mocktypes1]=@full")
+	assert.EqualError(err, `jsonapi: field "@full" does not exist in resource type "mocktypes1"`)
+}
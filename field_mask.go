@@ -0,0 +1,53 @@
+package jsonapi
+
+// A FieldMask restricts the fields of the marshaled resources on the server
+// side, regardless of the sparse fieldsets requested by the client. It is
+// meant for redaction, like removing personal data from the responses of an
+// endpoint.
+//
+// The mask is applied after the sparse fieldsets, so a field is marshaled only
+// if both the fieldset and the mask keep it. The ID of a resource is always
+// marshaled.
+type FieldMask struct {
+	// Allow holds the only fields that can be marshaled, by type name. All
+	// the fields of a type without an entry are allowed.
+	Allow map[string][]string
+
+	// Deny holds the fields that are never marshaled, by type name. It takes
+	// precedence over Allow.
+	Deny map[string][]string
+}
+
+// Keep reports whether the field named field of the type named typ is kept by
+// the mask.
+func (m FieldMask) Keep(typ, field string) bool {
+	if containsString(m.Deny[typ], field) {
+		return false
+	}
+
+	allowed, ok := m.Allow[typ]
+
+	return !ok || containsString(allowed, field)
+}
+
+// Apply returns the fields among fields of the type named typ that are kept by
+// the mask, in the same order. fields is returned as it is if the mask does not
+// remove any of them.
+func (m FieldMask) Apply(typ string, fields []string) []string {
+	_, allow := m.Allow[typ]
+	_, deny := m.Deny[typ]
+
+	if !allow && !deny {
+		return fields
+	}
+
+	kept := make([]string, 0, len(fields))
+
+	for _, f := range fields {
+		if m.Keep(typ, f) {
+			kept = append(kept, f)
+		}
+	}
+
+	return kept
+}
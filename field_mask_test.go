@@ -0,0 +1,91 @@
+package jsonapi_test
+
+import (
+	"bytes"
+	"testing"
+
+	. "github.com/mark-hartmann/jsonapi"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFieldMask(t *testing.T) {
+	assert := assert.New(t)
+
+	mask := FieldMask{
+		Allow: map[string][]string{"people": {"name", "email", "manager"}},
+		Deny:  map[string][]string{"people": {"email"}, "articles": {"views"}},
+	}
+
+	assert.True(mask.Keep("people", "name"))
+	assert.False(mask.Keep("people", "email"))
+	assert.False(mask.Keep("people", "phone"))
+	assert.True(mask.Keep("articles", "title"))
+	assert.False(mask.Keep("articles", "views"))
+	assert.True(mask.Keep("comments", "body"))
+
+	fields := []string{"email", "manager", "name", "phone"}
+	assert.Equal([]string{"manager", "name"}, mask.Apply("people", fields))
+	assert.Equal(fields, mask.Apply("comments", fields))
+	assert.Equal(fields, FieldMask{}.Apply("people", fields))
+}
+
+func TestMarshalOptionsFieldMask(t *testing.T) {
+	assert := assert.New(t)
+
+	typ := Type{Name: "people"}
+	typ.MustAddAttr(Attr{Name: "name", Type: AttrTypeString})
+	typ.MustAddAttr(Attr{Name: "email", Type: AttrTypeString})
+	typ.MustAddAttr(Attr{Name: "phone", Type: AttrTypeString})
+
+	schema := &Schema{}
+	schema.MustAddType(typ)
+
+	res := &SoftResource{Type: &typ}
+	res.SetID("p1")
+	res.Set("name", "Ann")
+	res.Set("email", "ann@example.com")
+	res.Set("phone", "555")
+
+	opts := MarshalOptions{
+		FieldMask: FieldMask{Deny: map[string][]string{"people": {"email"}}},
+	}
+
+	marshal := func(rawURL string) string {
+		url, err := NewURLFromRaw(schema, rawURL)
+		assert.NoError(err)
+
+		buf := &bytes.Buffer{}
+		assert.NoError(opts.MarshalDocument(buf, &Document{Data: &Resources{res}}, url))
+
+		return buf.String()
+	}
+
+	// All the fields are requested.
+	assert.JSONEq(`{
+		"data": [{
+			"attributes": {"name": "Ann", "phone": "555"},
+			"id": "p1",
+			"links": {"self": "/people/p1"},
+			"type": "people"
+		}],
+		"jsonapi": {"version": "1.0"},
+		"links": {"self": "/people"}
+	}`, marshal("/people"))
+
+	// The mask and the sparse fieldset are intersected.
+	assert.JSONEq(`{
+		"data": [{
+			"attributes": {"name": "Ann"},
+			"id": "p1",
+			"links": {"self": "/people/p1"},
+			"type": "people"
+		}],
+		"jsonapi": {"version": "1.0"},
+		"links": {"self": "/people?fields%5Bpeople%5D=email%2Cname"}
+	}`, marshal("/people?fields[people]=name,email"))
+
+	// Without a URL
+	payload := opts.MarshalResource(res, "", typ.Fields(), nil)
+	assert.NotContains(string(payload), "email")
+}
@@ -0,0 +1,166 @@
+package jsonapi
+
+import (
+	"strings"
+)
+
+// Filter operators
+const (
+	FilterEq    = "eq"
+	FilterNe    = "ne"
+	FilterLt    = "lt"
+	FilterLe    = "le"
+	FilterGt    = "gt"
+	FilterGe    = "ge"
+	FilterIn    = "in"
+	FilterNin   = "nin"
+	FilterLike  = "like"
+	FilterIsNil = "null"
+)
+
+// A Filter is a node of a filter expression tree. The concrete types are
+// *Condition, And and Or.
+//
+// Accept calls the method of v that corresponds to the node.
+type Filter interface {
+	Accept(v FilterVisitor) error
+}
+
+// A FilterVisitor walks a filter expression tree. The methods of And and Or
+// are responsible for visiting the children if needed.
+type FilterVisitor interface {
+	VisitCondition(c *Condition) error
+	VisitAnd(a And) error
+	VisitOr(o Or) error
+}
+
+// A Condition compares a field with one or more values.
+//
+// Op is one of the filter operators. FilterIn and FilterNin take any number of
+// values while the other ones take exactly one. The value of FilterIsNil is
+// "true" or "false".
+type Condition struct {
+	Field  string
+	Op     string
+	Values []string
+}
+
+// Accept calls v.VisitCondition.
+func (c *Condition) Accept(v FilterVisitor) error {
+	return v.VisitCondition(c)
+}
+
+// And is a filter that matches if all of its filters match. An empty And
+// matches every resource.
+type And []Filter
+
+// Accept calls v.VisitAnd.
+func (a And) Accept(v FilterVisitor) error {
+	return v.VisitAnd(a)
+}
+
+// Or is a filter that matches if at least one of its filters matches. An empty
+// Or matches no resource.
+type Or []Filter
+
+// Accept calls v.VisitOr.
+func (o Or) Accept(v FilterVisitor) error {
+	return v.VisitOr(o)
+}
+
+// ParseFilter builds a filter expression tree from the filter parameters of
+// params for the resource type typ.
+//
+// JSON:API does not define a filtering strategy. ParseFilter supports the
+// following one, where the field is the ID, an attribute or a to-one
+// relationship of typ:
+//
+//	filter[field]=value          equality
+//	filter[field]=v1,v2          the value is one of the values (FilterIn)
+//	filter[field][op]=value      the operator op, like filter[age][ge]=18
+//
+// The conditions are combined with And, in the alphabetical order of the
+// parameters. nil is returned if there is no filter. An error is returned for
+// a parameter of any other form, an unknown field, an unknown operator or the
+// wrong number of values.
+func ParseFilter(params *Params, typ Type) (Filter, error) {
+	if params == nil || len(params.Filter) == 0 {
+		return nil, nil
+	}
+
+	names := sortedKeys(params.Filter)
+	filter := make(And, 0, len(names))
+
+	for _, name := range names {
+		cond, err := parseCondition(name, params.Filter[name], typ)
+		if err != nil {
+			return nil, &srcError{src: name, error: err}
+		}
+
+		filter = append(filter, cond)
+	}
+
+	return filter, nil
+}
+
+// parseCondition parses the filter parameter name with the given values.
+func parseCondition(name string, vals []string, typ Type) (*Condition, error) {
+	if !strings.HasPrefix(name, "filter[") || !strings.HasSuffix(name, "]") {
+		return nil, &IllegalParameterError{Param: name}
+	}
+
+	field, op, hasOp := cutString(name[7:len(name)-1], "][")
+	if field == "" || strings.ContainsAny(field, "[]") || strings.ContainsAny(op, "[]") {
+		return nil, &IllegalParameterError{Param: name}
+	}
+
+	if !filterableField(field, typ) {
+		return nil, &UnknownFieldError{Type: typ.Name, Field: field}
+	}
+
+	cond := &Condition{Field: field, Op: op}
+
+	for _, v := range vals {
+		cond.Values = append(cond.Values, parseCommaList(v)...)
+	}
+
+	if !hasOp {
+		cond.Op = FilterEq
+		if len(cond.Values) > 1 {
+			cond.Op = FilterIn
+		}
+	}
+
+	var valid bool
+
+	switch cond.Op {
+	case FilterIn, FilterNin:
+		valid = len(cond.Values) > 0
+	case FilterEq, FilterNe, FilterLt, FilterLe, FilterGt, FilterGe, FilterLike:
+		valid = len(cond.Values) == 1
+	case FilterIsNil:
+		valid = len(cond.Values) == 1 && (cond.Values[0] == "true" || cond.Values[0] == "false")
+	}
+
+	if !valid {
+		return nil, &IllegalParameterError{Param: name}
+	}
+
+	return cond, nil
+}
+
+// filterableField reports whether the field named name of typ can be used in a
+// filter.
+func filterableField(name string, typ Type) bool {
+	if name == "id" {
+		return true
+	}
+
+	if _, ok := typ.Attrs[name]; ok {
+		return true
+	}
+
+	rel, ok := typ.Rels[name]
+
+	return ok && rel.ToOne
+}
@@ -0,0 +1,235 @@
+package jsonapi
+
+import (
+	"fmt"
+	"strings"
+)
+
+// SQLCompiler turns a filter expression tree into the parameterized condition
+// of a SQL WHERE clause.
+//
+// It does not depend on a SQL dialect. The hooks can be used to adapt the
+// output to a database, like Placeholder for PostgreSQL:
+//
+//	c := &SQLCompiler{
+//		Columns:     map[string]string{"name": "users.name"},
+//		Placeholder: func(n int) string { return "$" + strconv.Itoa(n) },
+//	}
+//
+// The values of the conditions are passed as string arguments. Most drivers
+// convert them into the type of the column.
+type SQLCompiler struct {
+	// Columns maps the names of the fields to the column expressions used in
+	// the output. A condition on a field without a column makes Compile fail,
+	// so only the fields listed here can be filtered on.
+	Columns map[string]string
+
+	// Placeholder returns the placeholder of the nth argument, starting at 1.
+	// The placeholder is ? if Placeholder is nil.
+	Placeholder func(n int) string
+
+	// Collation returns the collation to use for the comparisons on the given
+	// field, or an empty string for the default one.
+	Collation func(field string) string
+}
+
+// Compile returns the condition that corresponds to f and its arguments.
+//
+// An empty And matches every resource and an empty Or matches none. An empty
+// string is returned if f matches every resource, like nil or an empty And,
+// and 1 = 0 if f matches none, like an empty Or or an And holding one.
+func (c *SQLCompiler) Compile(f Filter) (string, []interface{}, error) {
+	if match, ok := constFilter(f); ok {
+		if match {
+			return "", nil, nil
+		}
+
+		return "1 = 0", nil, nil
+	}
+
+	v := &sqlVisitor{c: c}
+	if err := f.Accept(v); err != nil {
+		return "", nil, err
+	}
+
+	return v.buf.String(), v.args, nil
+}
+
+// sqlVisitor is the FilterVisitor used by SQLCompiler.
+type sqlVisitor struct {
+	c    *SQLCompiler
+	buf  strings.Builder
+	args []interface{}
+}
+
+// sqlOps maps the filter operators to SQL operators.
+var sqlOps = map[string]string{
+	FilterEq:   "=",
+	FilterNe:   "<>",
+	FilterLt:   "<",
+	FilterLe:   "<=",
+	FilterGt:   ">",
+	FilterGe:   ">=",
+	FilterLike: "LIKE",
+	FilterIn:   "IN",
+	FilterNin:  "NOT IN",
+}
+
+// VisitCondition writes the condition.
+func (v *sqlVisitor) VisitCondition(cond *Condition) error {
+	col, ok := v.c.Columns[cond.Field]
+	if !ok {
+		return fmt.Errorf("jsonapi: no column for filter field %q", cond.Field)
+	}
+
+	if cond.Op == FilterIsNil {
+		v.buf.WriteString(col)
+
+		if len(cond.Values) == 1 && cond.Values[0] == "false" {
+			v.buf.WriteString(" IS NOT NULL")
+		} else {
+			v.buf.WriteString(" IS NULL")
+		}
+
+		return nil
+	}
+
+	op, ok := sqlOps[cond.Op]
+	if !ok {
+		return fmt.Errorf("jsonapi: unknown filter operator %q", cond.Op)
+	}
+
+	if v.c.Collation != nil {
+		if coll := v.c.Collation(cond.Field); coll != "" {
+			col += " COLLATE " + coll
+		}
+	}
+
+	v.buf.WriteString(col + " " + op + " ")
+
+	if cond.Op != FilterIn && cond.Op != FilterNin {
+		if len(cond.Values) != 1 {
+			return fmt.Errorf("jsonapi: filter operator %q takes one value", cond.Op)
+		}
+
+		v.arg(cond.Values[0])
+
+		return nil
+	}
+
+	if len(cond.Values) == 0 {
+		return fmt.Errorf("jsonapi: filter operator %q takes at least one value", cond.Op)
+	}
+
+	v.buf.WriteByte('(')
+
+	for i, val := range cond.Values {
+		if i > 0 {
+			v.buf.WriteString(", ")
+		}
+
+		v.arg(val)
+	}
+
+	v.buf.WriteByte(')')
+
+	return nil
+}
+
+// VisitAnd writes the filters joined by AND.
+func (v *sqlVisitor) VisitAnd(a And) error {
+	return v.group(a, " AND ")
+}
+
+// VisitOr writes the filters joined by OR.
+func (v *sqlVisitor) VisitOr(o Or) error {
+	return v.group(o, " OR ")
+}
+
+// group writes the filters joined by sep. The group is wrapped in parentheses
+// unless it has a single filter. The filters that always or never match are
+// skipped, since the group itself would be constant otherwise (see
+// constFilter).
+func (v *sqlVisitor) group(all []Filter, sep string) error {
+	filters := make([]Filter, 0, len(all))
+
+	for _, f := range all {
+		if _, ok := constFilter(f); !ok {
+			filters = append(filters, f)
+		}
+	}
+
+	if len(filters) == 1 {
+		return filters[0].Accept(v)
+	}
+
+	v.buf.WriteByte('(')
+
+	for i, f := range filters {
+		if i > 0 {
+			v.buf.WriteString(sep)
+		}
+
+		if err := f.Accept(v); err != nil {
+			return err
+		}
+	}
+
+	v.buf.WriteByte(')')
+
+	return nil
+}
+
+// constFilter reports whether f matches every resource or none, regardless of
+// its conditions. ok is false if the result depends on the conditions.
+//
+// nil matches every resource. An And never matches if one of its filters
+// never matches, and an Or always matches if one of its filters always
+// matches. Otherwise, a group is constant if all of its filters are, which
+// makes an empty And always match and an empty Or never match.
+func constFilter(f Filter) (match, ok bool) {
+	var (
+		filters  []Filter
+		isAnd    bool
+		constant = true
+	)
+
+	switch f := f.(type) {
+	case nil:
+		return true, true
+	case And:
+		filters, isAnd = f, true
+	case Or:
+		filters = f
+	default:
+		return false, false
+	}
+
+	for _, f := range filters {
+		m, ok := constFilter(f)
+		if !ok {
+			constant = false
+		} else if m != isAnd {
+			// false in an And, true in an Or
+			return m, true
+		}
+	}
+
+	if !constant {
+		return false, false
+	}
+
+	return isAnd, true
+}
+
+// arg writes the placeholder of val and adds it to the arguments.
+func (v *sqlVisitor) arg(val string) {
+	v.args = append(v.args, val)
+
+	if v.c.Placeholder == nil {
+		v.buf.WriteByte('?')
+		return
+	}
+
+	v.buf.WriteString(v.c.Placeholder(len(v.args)))
+}
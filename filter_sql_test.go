@@ -0,0 +1,139 @@
+package jsonapi_test
+
+import (
+	"strconv"
+	"testing"
+
+	. "github.com/mark-hartmann/jsonapi"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSQLCompiler(t *testing.T) {
+	assert := assert.New(t)
+
+	c := &SQLCompiler{
+		Columns: map[string]string{
+			"id":   "t.id",
+			"name": "t.name",
+			"age":  "t.age",
+		},
+	}
+
+	filter := And{
+		&Condition{Field: "name", Op: FilterLike, Values: []string{"a%"}},
+		Or{
+			&Condition{Field: "age", Op: FilterLt, Values: []string{"18"}},
+			&Condition{Field: "age", Op: FilterIsNil, Values: []string{"true"}},
+		},
+		And{},
+		&Condition{Field: "id", Op: FilterNin, Values: []string{"1", "2"}},
+		&Condition{Field: "name", Op: FilterIsNil, Values: []string{"false"}},
+	}
+
+	where, args, err := c.Compile(filter)
+	assert.NoError(err)
+	assert.Equal("(t.name LIKE ? AND (t.age < ? OR t.age IS NULL) AND "+
+		"t.id NOT IN (?, ?) AND t.name IS NOT NULL)", where)
+	assert.Equal([]interface{}{"a%", "18", "1", "2"}, args)
+
+	// Hooks
+	c.Placeholder = func(n int) string { return "$" + strconv.Itoa(n) }
+	c.Collation = func(field string) string {
+		if field == "name" {
+			return `"C"`
+		}
+
+		return ""
+	}
+
+	where, args, err = c.Compile(And{
+		&Condition{Field: "name", Op: FilterEq, Values: []string{"abc"}},
+		&Condition{Field: "id", Op: FilterIn, Values: []string{"1", "2"}},
+	})
+	assert.NoError(err)
+	assert.Equal(`(t.name COLLATE "C" = $1 AND t.id IN ($2, $3))`, where)
+	assert.Equal([]interface{}{"abc", "1", "2"}, args)
+
+	// Single condition and empty filters
+	where, _, err = c.Compile(&Condition{Field: "age", Op: FilterGe, Values: []string{"3"}})
+	assert.NoError(err)
+	assert.Equal("t.age >= $1", where)
+
+	where, args, err = c.Compile(nil)
+	assert.NoError(err)
+	assert.Empty(where)
+	assert.Empty(args)
+
+	where, _, err = c.Compile(Or{And{}})
+	assert.NoError(err)
+	assert.Empty(where)
+
+	// An empty Or never matches.
+	age := &Condition{Field: "age", Op: FilterGe, Values: []string{"3"}}
+
+	where, args, err = c.Compile(Or{})
+	assert.NoError(err)
+	assert.Equal("1 = 0", where)
+	assert.Empty(args)
+
+	where, _, err = c.Compile(And{age, Or{And{}, Or{}}, Or{Or{}}})
+	assert.NoError(err)
+	assert.Equal("1 = 0", where)
+
+	where, _, err = c.Compile(Or{age, Or{}, And{Or{}, age}})
+	assert.NoError(err)
+	assert.Equal("t.age >= $1", where)
+
+	where, _, err = c.Compile(And{age, And{}, Or{age, age}})
+	assert.NoError(err)
+	assert.Equal("(t.age >= $1 AND (t.age >= $2 OR t.age >= $3))", where)
+
+	// Errors
+	_, _, err = c.Compile(&Condition{Field: "unknown", Op: FilterEq, Values: []string{"1"}})
+	assert.EqualError(err, `jsonapi: no column for filter field "unknown"`)
+
+	_, _, err = c.Compile(&Condition{Field: "age", Op: "between", Values: []string{"1"}})
+	assert.EqualError(err, `jsonapi: unknown filter operator "between"`)
+
+	_, _, err = c.Compile(&Condition{Field: "age", Op: FilterEq})
+	assert.EqualError(err, `jsonapi: filter operator "eq" takes one value`)
+
+	_, _, err = c.Compile(&Condition{Field: "age", Op: FilterIn})
+	assert.EqualError(err, `jsonapi: filter operator "in" takes at least one value`)
+}
+
+// filterCounter is a FilterVisitor that counts the conditions of a tree.
+type filterCounter struct {
+	n int
+}
+
+func (c *filterCounter) VisitCondition(*Condition) error {
+	c.n++
+	return nil
+}
+
+func (c *filterCounter) VisitAnd(a And) error {
+	for _, f := range a {
+		_ = f.Accept(c)
+	}
+
+	return nil
+}
+
+func (c *filterCounter) VisitOr(o Or) error {
+	for _, f := range o {
+		_ = f.Accept(c)
+	}
+
+	return nil
+}
+
+func TestFilterVisitor(t *testing.T) {
+	c := &filterCounter{}
+	_ = And{
+		&Condition{Field: "a"},
+		Or{&Condition{Field: "b"}, &Condition{Field: "c"}},
+	}.Accept(c)
+	assert.Equal(t, 3, c.n)
+}
@@ -0,0 +1,90 @@
+package jsonapi_test
+
+import (
+	"testing"
+
+	. "github.com/mark-hartmann/jsonapi"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseFilter(t *testing.T) {
+	assert := assert.New(t)
+
+	schema := newMockSchema()
+	typ := schema.GetType("mocktypes1")
+	mapper := &ErrorMapper{}
+
+	tests := []struct {
+		query    string
+		expected Filter
+		err      string
+	}{
+		{
+			query:    "",
+			expected: nil,
+		}, {
+			query: "filter[str]=abc&filter[int][ge]=3&filter[to-one]=mt2",
+			expected: And{
+				&Condition{Field: "int", Op: FilterGe, Values: []string{"3"}},
+				&Condition{Field: "str", Op: FilterEq, Values: []string{"abc"}},
+				&Condition{Field: "to-one", Op: FilterEq, Values: []string{"mt2"}},
+			},
+		}, {
+			query: "filter[id]=mt1,mt2&filter[str][nin]=a&filter[str][nin]=b",
+			expected: And{
+				&Condition{Field: "id", Op: FilterIn, Values: []string{"mt1", "mt2"}},
+				&Condition{Field: "str", Op: FilterNin, Values: []string{"a", "b"}},
+			},
+		}, {
+			query: "filter[str][null]=false",
+			expected: And{
+				&Condition{Field: "str", Op: FilterIsNil, Values: []string{"false"}},
+			},
+		}, {
+			query: "filter=raw",
+			err:   `jsonapi: illegal query parameter "filter"`,
+		}, {
+			query: "filter[str][a][b]=1",
+			err:   `jsonapi: illegal query parameter "filter[str][a][b]"`,
+		}, {
+			query: "filter[str][between]=1",
+			err:   `jsonapi: illegal query parameter "filter[str][between]"`,
+		}, {
+			query: "filter[int][lt]=1,2",
+			err:   `jsonapi: illegal query parameter "filter[int][lt]"`,
+		}, {
+			query: "filter[str][null]=maybe",
+			err:   `jsonapi: illegal query parameter "filter[str][null]"`,
+		}, {
+			query: "filter[unknown]=1",
+			err:   `jsonapi: field "unknown" does not exist in resource type "mocktypes1"`,
+		}, {
+			query: "filter[to-many]=1",
+			err:   `jsonapi: field "to-many" does not exist in resource type "mocktypes1"`,
+		},
+	}
+
+	for _, test := range tests {
+		url, err := NewURLFromRaw(schema, "/mocktypes1?"+test.query)
+		assert.NoError(err)
+
+		filter, err := ParseFilter(url.Params, typ)
+		if test.err != "" {
+			assert.EqualError(err, test.err, test.query)
+
+			e := mapper.Map(err)
+			assert.Equal("400", e.Status, test.query)
+			assert.NotEmpty(e.Source["parameter"], test.query)
+
+			continue
+		}
+
+		assert.NoError(err, test.query)
+		assert.Equal(test.expected, filter, test.query)
+	}
+
+	filter, err := ParseFilter(nil, typ)
+	assert.NoError(err)
+	assert.Nil(filter)
+}
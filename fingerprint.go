@@ -0,0 +1,130 @@
+package jsonapi
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+)
+
+// Fingerprint returns a hash of the type, the ID, the attributes and the
+// relationship linkage of r. Links and meta are ignored.
+//
+// Only the given fields are taken into account. If fields is nil, all the
+// fields of the type are used.
+//
+// The fingerprint is stable: it only changes if one of the values changes, so
+// it can be used to detect modifications, for example in optimistic concurrency
+// control.
+//
+// An error is returned if r cannot be marshaled, like when an attribute
+// Marshaler fails.
+func Fingerprint(r Resource, fields []string) (string, error) {
+	typ := r.GetType()
+
+	if fields == nil {
+		fields = typ.Fields()
+	}
+
+	relData := map[string][]string{typ.Name: fields}
+
+	payload, err := MarshalOptions{}.marshalResource(r, "", fields, relData)
+	if err != nil {
+		return "", fmt.Errorf("jsonapi: failed to compute fingerprint: %w", err)
+	}
+
+	var res map[string]interface{}
+
+	// The resource is marshaled by this package, so it is always valid.
+	_ = unmarshalJSONNumbers(payload, &res)
+
+	stripResource(res)
+
+	return hashJSON(res), nil
+}
+
+// ETag returns a strong entity tag for the document as it would be marshaled
+// with MarshalDocument and the given URL, so sparse fieldsets are respected.
+//
+// Like Fingerprint, links and meta (at any level) are ignored, so the tag does
+// not change unless the data or the included resources change. The included
+// resources are hashed in a canonical order.
+//
+// The returned value is quoted and can be used as is in an ETag header and
+// compared with the values of an If-None-Match header.
+func (d *Document) ETag(url *URL) (string, error) {
+	buf := &bytes.Buffer{}
+	if err := MarshalDocument(buf, d, url); err != nil {
+		return "", fmt.Errorf("jsonapi: failed to compute etag: %w", err)
+	}
+
+	var doc map[string]interface{}
+	if err := unmarshalJSONNumbers(buf.Bytes(), &doc); err != nil {
+		return "", fmt.Errorf("jsonapi: failed to compute etag: %w", err)
+	}
+
+	delete(doc, "links")
+	delete(doc, "meta")
+	delete(doc, "jsonapi")
+
+	switch data := doc["data"].(type) {
+	case map[string]interface{}:
+		stripResource(data)
+	case []interface{}:
+		for _, res := range data {
+			res, _ := res.(map[string]interface{})
+			stripResource(res)
+		}
+	}
+
+	if included, ok := doc["included"].([]interface{}); ok {
+		for _, res := range included {
+			res, _ := res.(map[string]interface{})
+			stripResource(res)
+		}
+
+		sortIdentifiers(included)
+	}
+
+	return `"` + hashJSON(doc) + `"`, nil
+}
+
+// unmarshalJSONNumbers unmarshals data into v like json.Unmarshal does, but
+// numbers are kept as json.Number values.
+func unmarshalJSONNumbers(data []byte, v interface{}) error {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+
+	return dec.Decode(v)
+}
+
+// stripResource removes the links and the meta objects of res, an unmarshaled
+// resource object, and of its relationships.
+func stripResource(res map[string]interface{}) {
+	delete(res, "links")
+	delete(res, "meta")
+
+	rels, _ := res["relationships"].(map[string]interface{})
+
+	for _, rel := range rels {
+		if rel, ok := rel.(map[string]interface{}); ok {
+			delete(rel, "links")
+			delete(rel, "meta")
+		}
+	}
+}
+
+// hashJSON returns the hexadecimal SHA-256 hash of the JSON encoding of v.
+// Object members are sorted by key, so the hash is stable.
+func hashJSON(v interface{}) string {
+	h := sha256.New()
+
+	enc := json.NewEncoder(h)
+	enc.SetEscapeHTML(false)
+
+	// v is made of values unmarshaled by the json package.
+	_ = enc.Encode(v)
+
+	return hex.EncodeToString(h.Sum(nil))
+}
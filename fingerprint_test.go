@@ -0,0 +1,139 @@
+package jsonapi_test
+
+import (
+	"testing"
+
+	. "github.com/mark-hartmann/jsonapi"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFingerprint(t *testing.T) {
+	assert := assert.New(t)
+
+	typ := Type{Name: "articles"}
+	typ.MustAddAttr(Attr{Name: "title", Type: AttrTypeString})
+	typ.MustAddAttr(Attr{Name: "views", Type: AttrTypeInt})
+	typ.MustAddRel(Rel{FromName: "tags", ToType: "tags"})
+
+	res := &SoftResource{Type: &typ}
+	res.SetID("a1")
+	res.Set("title", "Hello")
+	res.Set("views", 10)
+	res.Set("tags", []string{"t1", "t2"})
+
+	fingerprint := func(fields []string) string {
+		fp, err := Fingerprint(res, fields)
+		assert.NoError(err)
+
+		return fp
+	}
+
+	fp := fingerprint(nil)
+	assert.Len(fp, 64)
+	assert.Equal(fp, fingerprint(nil))
+	assert.Equal(fp, fingerprint([]string{"tags", "views", "title"}))
+
+	// Meta is ignored.
+	res.SetMeta(Meta{"updated": "now"})
+	assert.Equal(fp, fingerprint(nil))
+
+	// Sparse fieldsets
+	titleFP := fingerprint([]string{"title"})
+	assert.NotEqual(fp, titleFP)
+
+	res.Set("views", 11)
+	assert.NotEqual(fp, fingerprint(nil))
+	assert.Equal(titleFP, fingerprint([]string{"title"}))
+
+	res.Set("views", 10)
+	assert.Equal(fp, fingerprint(nil))
+
+	res.Set("tags", []string{"t2", "t1"})
+	assert.Equal(fp, fingerprint(nil))
+
+	res.Set("tags", []string{"t1", "t3"})
+	assert.NotEqual(fp, fingerprint(nil))
+
+	res.SetID("a2")
+	assert.NotEqual(titleFP, fingerprint([]string{"title"}))
+}
+
+func TestFingerprintError(t *testing.T) {
+	assert := assert.New(t)
+
+	typ := Type{Name: "articles"}
+	typ.MustAddAttr(Attr{
+		Name:      "title",
+		Type:      AttrTypeString,
+		Marshaler: failingAttrMarshaler{},
+	})
+
+	res := &SoftResource{Type: &typ}
+	res.SetID("a1")
+
+	fp, err := Fingerprint(res, nil)
+	assert.EqualError(err, "jsonapi: failed to compute fingerprint: cannot marshal")
+	assert.Empty(fp)
+}
+
+func TestDocumentETag(t *testing.T) {
+	assert := assert.New(t)
+
+	typ := Type{Name: "articles"}
+	typ.MustAddAttr(Attr{Name: "title", Type: AttrTypeString})
+	typ.MustAddAttr(Attr{Name: "views", Type: AttrTypeInt})
+
+	newRes := func(id, title string) *SoftResource {
+		res := &SoftResource{Type: &typ}
+		res.SetID(id)
+		res.Set("title", title)
+
+		return res
+	}
+
+	col := &Resources{newRes("a1", "A"), newRes("a2", "B")}
+	doc := &Document{
+		Data:     col,
+		Included: []Resource{newRes("a3", "C"), newRes("a4", "D")},
+		Meta:     Meta{"generated": "now"},
+	}
+
+	etag, err := doc.ETag(nil)
+	assert.NoError(err)
+	assert.Regexp(`^"[0-9a-f]{64}"$`, etag)
+
+	// Meta and links are ignored, included resources are sorted.
+	doc.Meta = Meta{"generated": "later"}
+	doc.PrePath = "https://example.com"
+	doc.Included = []Resource{newRes("a4", "D"), newRes("a3", "C")}
+
+	etag2, err := doc.ETag(nil)
+	assert.NoError(err)
+	assert.Equal(etag, etag2)
+
+	(*col)[0].Set("views", 1)
+
+	etag2, err = doc.ETag(nil)
+	assert.NoError(err)
+	assert.NotEqual(etag, etag2)
+
+	// Sparse fieldsets
+	url, err := NewURLFromRaw(
+		(&Schema{Types: []Type{typ}}),
+		"/articles?fields[articles]=title",
+	)
+	assert.NoError(err)
+
+	etag3, err := doc.ETag(url)
+	assert.NoError(err)
+
+	(*col)[0].Set("views", 2)
+
+	etag4, err := doc.ETag(url)
+	assert.NoError(err)
+	assert.Equal(etag3, etag4)
+
+	_, err = (&Document{Data: 1}).ETag(nil)
+	assert.Error(err)
+}
@@ -0,0 +1,169 @@
+package jsonapi
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"math/rand"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// fixtureWords is used to build the generated strings.
+var fixtureWords = []string{
+	"alpha", "bravo", "charlie", "delta", "echo", "foxtrot", "golf", "hotel",
+	"india", "juliett", "kilo", "lima", "mike", "november", "oscar", "papa",
+}
+
+// GenerateResources returns n resources of type typ filled with random values.
+// It is meant for tests and demos that need valid resources without writing
+// fixtures by hand.
+//
+// The resources are *SoftResource values whose IDs are the name of the type
+// followed by a dash and a number from 1 to n, like "articles-1". The values
+// are plausible for the type of each attribute (words, small numbers, times in
+// 2020, etc). Arrays have up to three elements and nullable attributes are
+// sometimes null. Attributes of types without a known representation get
+// their zero value.
+//
+// A relationship points to IDs of the same form for its target type, picked
+// between 1 and n. Resources generated with the same n for all the types of a
+// schema are therefore linked to each other, which is enough to build compound
+// documents.
+//
+// The same seed always produces the same resources.
+func GenerateResources(typ Type, n int, seed int64) []Resource {
+	rnd := rand.New(rand.NewSource(seed))
+
+	ress := make([]Resource, 0, n)
+
+	for i := 1; i <= n; i++ {
+		rtyp := typ.Copy()
+		sr := &SoftResource{}
+		sr.SetType(&rtyp)
+		sr.SetID(fixtureID(typ.Name, i))
+
+		for _, name := range sortedAttrNames(typ.Attrs) {
+			attr := typ.Attrs[name]
+			sr.Set(attr.Name, fixtureAttrValue(rnd, attr))
+		}
+
+		for _, name := range sortedRelNames(typ.Rels) {
+			rel := typ.Rels[name]
+
+			if rel.ToOne {
+				sr.Set(rel.FromName, fixtureID(rel.ToType, rnd.Intn(n)+1))
+				continue
+			}
+
+			ids := []string{}
+			seen := map[int]bool{}
+
+			for j := rnd.Intn(4); j > 0; j-- {
+				if k := rnd.Intn(n) + 1; !seen[k] {
+					seen[k] = true
+
+					ids = append(ids, fixtureID(rel.ToType, k))
+				}
+			}
+
+			sort.Strings(ids)
+			sr.Set(rel.FromName, ids)
+		}
+
+		ress = append(ress, sr)
+	}
+
+	return ress
+}
+
+// fixtureID returns the ID of the ith generated resource of type typ.
+func fixtureID(typ string, i int) string {
+	return typ + "-" + strconv.Itoa(i)
+}
+
+// fixtureAttrValue returns a random value for attr. The value is generated as
+// JSON and unmarshaled with UnmarshalToType, so it has the right Go type.
+func fixtureAttrValue(rnd *rand.Rand, attr Attr) interface{} {
+	if attr.Nullable && rnd.Intn(5) == 0 {
+		return attrZeroValue(attr)
+	}
+
+	var data []byte
+
+	switch {
+	case attr.Array && attr.Type != AttrTypeBytes:
+		elems := make([]string, rnd.Intn(4))
+		for i := range elems {
+			elems[i] = string(fixtureJSON(rnd, attr))
+		}
+
+		data = []byte("[" + strings.Join(elems, ",") + "]")
+	default:
+		data = fixtureJSON(rnd, attr)
+	}
+
+	if data == nil {
+		return attrZeroValue(attr)
+	}
+
+	v, err := UnmarshalToType(data, attr)
+	if err != nil {
+		return attrZeroValue(attr)
+	}
+
+	return v
+}
+
+// fixtureJSON returns a random JSON value for a single (non-array) value of
+// attr, or nil if the type of attr is not known.
+func fixtureJSON(rnd *rand.Rand, attr Attr) []byte {
+	var v interface{}
+
+	switch attr.Type {
+	case AttrTypeString:
+		v = fixtureWords[rnd.Intn(len(fixtureWords))] + " " +
+			fixtureWords[rnd.Intn(len(fixtureWords))]
+	case AttrTypeInt, AttrTypeInt8, AttrTypeInt16, AttrTypeInt32, AttrTypeInt64:
+		v = rnd.Intn(201) - 100
+	case AttrTypeUint, AttrTypeUint8, AttrTypeUint16, AttrTypeUint32, AttrTypeUint64:
+		v = rnd.Intn(101)
+	case AttrTypeFloat32, AttrTypeFloat64:
+		v = float64(rnd.Intn(20001)-10000) / 100
+	case AttrTypeBool:
+		v = rnd.Intn(2) == 1
+	case AttrTypeTime:
+		// Any second of 2020.
+		t := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC).
+			Add(time.Duration(rnd.Intn(365*24*3600)) * time.Second)
+
+		return formatTime(t, attr.Format)
+	case AttrTypeDuration:
+		return formatDuration(time.Duration(rnd.Intn(24*3600))*time.Second, attr.Format)
+	case AttrTypeDecimal:
+		v = fmt.Sprintf("%d.%02d", rnd.Intn(1000), rnd.Intn(100))
+	case AttrTypeUUID:
+		var u UUID
+
+		_, _ = rnd.Read(u[:])
+		u[6] = u[6]&0x0f | 0x40 // Version 4
+		u[8] = u[8]&0x3f | 0x80 // Variant 10
+
+		v = u.String()
+	case AttrTypeJSON:
+		v = map[string]interface{}{"n": rnd.Intn(100)}
+	case AttrTypeBytes:
+		b := make([]byte, rnd.Intn(8)+1)
+		_, _ = rnd.Read(b)
+
+		v = base64.StdEncoding.EncodeToString(b)
+	default:
+		return nil
+	}
+
+	data, _ := json.Marshal(v)
+
+	return data
+}
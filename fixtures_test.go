@@ -0,0 +1,81 @@
+package jsonapi_test
+
+import (
+	"bytes"
+	"testing"
+
+	. "github.com/mark-hartmann/jsonapi"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGenerateResources(t *testing.T) {
+	assert := assert.New(t)
+
+	schema := newMockSchema()
+	typ1 := schema.GetType("mocktypes1")
+	typ2 := schema.GetType("mocktypes2")
+
+	ress1 := GenerateResources(typ1, 5, 42)
+	ress2 := GenerateResources(typ2, 5, 42)
+	assert.Len(ress1, 5)
+	assert.Len(ress2, 5)
+	assert.Equal("mocktypes1-1", ress1[0].Get("id"))
+	assert.Equal("mocktypes2-5", ress2[4].Get("id"))
+
+	// The same seed gives the same resources.
+	again := GenerateResources(typ1, 5, 42)
+	for i := range ress1 {
+		assert.True(EqualStrict(ress1[i], again[i]))
+	}
+
+	other := GenerateResources(typ1, 5, 43)
+	assert.False(Equal(ress1[0], other[0]) && Equal(ress1[1], other[1]))
+
+	// Every relationship points to a generated resource.
+	ids := map[string]bool{}
+	for _, res := range ress2 {
+		ids[res.Get("id").(string)] = true
+	}
+
+	for _, res := range ress1 {
+		for _, rel := range typ1.Rels {
+			if rel.ToOne {
+				assert.True(ids[res.Get(rel.FromName).(string)])
+				continue
+			}
+
+			for _, id := range res.Get(rel.FromName).([]string) {
+				assert.True(ids[id])
+			}
+		}
+	}
+
+	// The resources make a valid compound document.
+	col := &SoftCollection{}
+	col.SetType(&typ1)
+
+	for _, res := range ress1 {
+		col.Add(res)
+	}
+
+	doc := &Document{
+		Data:     col,
+		Included: ress2,
+		RelData:  map[string][]string{"mocktypes1": typ1.Fields()},
+	}
+	buf := &bytes.Buffer{}
+	assert.NoError(MarshalDocument(buf, doc, nil))
+
+	doc2, err := UnmarshalDocument(buf, schema)
+	assert.NoError(err)
+	assert.Equal(5, doc2.Data.(Collection).Len())
+	assert.Len(doc2.Included, 5)
+
+	for i := 0; i < 5; i++ {
+		assert.True(Equal(ress1[i], doc2.Data.(Collection).At(i)))
+	}
+
+	// No resources
+	assert.Empty(GenerateResources(typ1, 0, 1))
+}
@@ -0,0 +1,165 @@
+package jsonapi
+
+import (
+	"bytes"
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+// FuzzUnmarshalDocument is an entry point for fuzzing the unmarshaling of
+// documents with untrusted payloads. It is meant to be called from a fuzz
+// target:
+//
+//	f.Fuzz(func(t *testing.T, data []byte) {
+//		if err := jsonapi.FuzzUnmarshalDocument(schema, data); err != nil {
+//			t.Fatal(err)
+//		}
+//	})
+//
+// data is unmarshaled with schema. A payload that is rejected is not a failure.
+// Otherwise, the document is marshaled and the result must be unmarshaled and
+// marshaled again to the same payload. An error is returned if that is not the
+// case, and a panic is a bug of this package.
+func FuzzUnmarshalDocument(schema *Schema, data []byte) error {
+	doc, err := UnmarshalDocument(bytes.NewReader(data), schema)
+	if err != nil {
+		return nil
+	}
+
+	out1 := &bytes.Buffer{}
+	if err := MarshalDocument(out1, doc, nil); err != nil {
+		// Some documents can be unmarshaled but not marshaled, like the ones
+		// without any top-level member once the unknown ones are dropped.
+		return nil
+	}
+
+	doc2, err := UnmarshalDocument(bytes.NewReader(out1.Bytes()), schema)
+	if err != nil {
+		return fmt.Errorf("jsonapi: marshaled document %s cannot be unmarshaled: %w", out1, err)
+	}
+
+	out2 := &bytes.Buffer{}
+	if err := MarshalDocument(out2, doc2, nil); err != nil {
+		return fmt.Errorf("jsonapi: unmarshaled document %s cannot be marshaled: %w", out1, err)
+	}
+
+	if !bytes.Equal(out1.Bytes(), out2.Bytes()) {
+		return fmt.Errorf("jsonapi: marshaling is not stable: %s != %s", out1, out2)
+	}
+
+	return nil
+}
+
+// FuzzParseURL is an entry point for fuzzing the parsing of untrusted URLs, like
+// FuzzUnmarshalDocument for documents.
+//
+// raw is parsed with schema. A URL that is rejected is not a failure.
+// Otherwise, the string representation of the URL must be parsed again to the
+// same URL. An error is returned if that is not the case.
+func FuzzParseURL(schema *Schema, raw string) error {
+	u, err := NewURLFromRaw(schema, raw)
+	if err != nil {
+		return nil
+	}
+
+	str := u.String()
+
+	u2, err := NewURLFromRaw(schema, str)
+	if err != nil {
+		return fmt.Errorf("jsonapi: String() of %q returned %q which cannot be parsed: %w",
+			raw, str, err)
+	}
+
+	if str2 := u2.String(); str2 != str {
+		return fmt.Errorf("jsonapi: String() of %q is not stable: %q != %q", raw, str, str2)
+	}
+
+	if u.UnescapedString() != u2.UnescapedString() {
+		return fmt.Errorf("jsonapi: UnescapedString() of %q is not stable: %q != %q",
+			raw, u.UnescapedString(), u2.UnescapedString())
+	}
+
+	return nil
+}
+
+// FuzzDocumentCorpus returns valid payloads built from the types of schema to
+// be used as the seed corpus of a fuzz target based on FuzzUnmarshalDocument.
+//
+// For each type, there is a document whose primary data is a resource and one
+// whose primary data is a collection with the related resources included. The
+// resources are made by GenerateResources, so the same schema and seed always
+// produce the same corpus.
+func FuzzDocumentCorpus(schema *Schema, seed int64) [][]byte {
+	const n = 2
+
+	ress := make(map[string][]Resource, len(schema.Types))
+	for _, typ := range schema.Types {
+		ress[typ.Name] = GenerateResources(typ, n, seed)
+	}
+
+	var corpus [][]byte
+
+	add := func(doc *Document) {
+		buf := &bytes.Buffer{}
+		if err := MarshalDocument(buf, doc, nil); err == nil {
+			corpus = append(corpus, buf.Bytes())
+		}
+	}
+
+	for _, typ := range schema.Types {
+		add(&Document{Data: ress[typ.Name][0]})
+
+		col := Resources(ress[typ.Name])
+		doc := &Document{
+			Data:    &col,
+			RelData: map[string][]string{typ.Name: typ.Fields()},
+		}
+
+		for _, name := range sortedRelNames(typ.Rels) {
+			for _, res := range ress[typ.Rels[name].ToType] {
+				doc.Include(res)
+			}
+		}
+
+		add(doc)
+	}
+
+	return corpus
+}
+
+// FuzzURLCorpus returns valid URLs built from the types of schema to be used as
+// the seed corpus of a fuzz target based on FuzzParseURL.
+func FuzzURLCorpus(schema *Schema) []string {
+	var corpus []string
+
+	for _, typ := range schema.Types {
+		name := url.PathEscape(typ.Name)
+		corpus = append(corpus, "/"+name, "/"+name+"/1")
+
+		rels := sortedRelNames(typ.Rels)
+		for _, rel := range rels {
+			corpus = append(corpus,
+				"/"+name+"/1/"+url.PathEscape(rel),
+				"/"+name+"/1/relationships/"+url.PathEscape(rel),
+			)
+		}
+
+		query := url.Values{}
+		query.Set("fields["+typ.Name+"]", strings.Join(typ.Fields(), ","))
+		query.Set("page[size]", "10")
+		query.Set("page[number]", "2")
+
+		if attrs := sortedAttrNames(typ.Attrs); len(attrs) > 0 {
+			query.Set("sort", "-"+attrs[0]+",id")
+		}
+
+		if len(rels) > 0 {
+			query.Set("include", strings.Join(rels, ","))
+		}
+
+		corpus = append(corpus, "/"+name+"?"+query.Encode())
+	}
+
+	return corpus
+}
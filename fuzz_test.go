@@ -0,0 +1,45 @@
+//go:build go1.18
+// +build go1.18
+
+package jsonapi_test
+
+import (
+	"testing"
+
+	. "github.com/mark-hartmann/jsonapi"
+)
+
+func FuzzDocument(f *testing.F) {
+	schema := newMockSchema()
+
+	for _, seed := range FuzzDocumentCorpus(schema, 1) {
+		f.Add(seed)
+	}
+
+	f.Add([]byte(`{"data":null,"meta":{"a":[1,"b",{"c":null}]}}`))
+	f.Add([]byte(`{"errors":[{"status":"404","title":"Not Found"}]}`))
+
+	f.Fuzz(func(t *testing.T, data []byte) {
+		if err := FuzzUnmarshalDocument(schema, data); err != nil {
+			t.Fatal(err)
+		}
+	})
+}
+
+func FuzzURL(f *testing.F) {
+	schema := newMockSchema()
+
+	for _, seed := range FuzzURLCorpus(schema) {
+		f.Add(seed)
+	}
+
+	// The fragments of the path used to be written without being escaped.
+	f.Add("mocktypes1/%00")
+	f.Add("/mocktypes1/a%3Fb")
+
+	f.Fuzz(func(t *testing.T, raw string) {
+		if err := FuzzParseURL(schema, raw); err != nil {
+			t.Fatal(err)
+		}
+	})
+}
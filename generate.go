@@ -0,0 +1,581 @@
+package jsonapi
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"go/format"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// GenerateOptions configures the code generated by GenerateGo.
+type GenerateOptions struct {
+	// Package is the name of the package of the generated file. It is
+	// required.
+	Package string
+
+	// TypeNames maps the names of the types of the schema to the names of the
+	// generated structs, like "people" to "Person". The name of a struct is
+	// the type name in CamelCase otherwise, like "Articles".
+	TypeNames map[string]string
+}
+
+// GenerateGo returns the source of a Go file that declares a struct for each
+// type of schema, along with the methods that make it a Resource and a Copier
+// without any use of reflection, and a NewSchema function that returns a
+// schema holding the types with their NewFunc set.
+//
+// The fields of the structs have the Go types of the attributes (see
+// GetZeroValue) and the api tags that BuildType expects, so the structs can
+// also be wrapped. The types of the attributes must be registered (see
+// RegisterAttrType).
+//
+// The functions of the types and attributes, like Unmarshaler or Transformer,
+// are not part of the generated code and must be set again on the types
+// returned by NewSchema if needed.
+func GenerateGo(schema *Schema, opts GenerateOptions) ([]byte, error) {
+	if opts.Package == "" {
+		return nil, fmt.Errorf("jsonapi: package name is empty")
+	}
+
+	g := &generator{opts: opts, imports: map[string]bool{}}
+
+	types := make([]generatedType, 0, len(schema.Types))
+
+	for _, typ := range schema.Types {
+		gt, err := g.typ(typ)
+		if err != nil {
+			return nil, err
+		}
+
+		types = append(types, gt)
+	}
+
+	g.header(types)
+
+	for _, gt := range types {
+		g.resource(gt)
+	}
+
+	src, err := format.Source(g.buf.Bytes())
+	if err != nil {
+		return nil, fmt.Errorf("jsonapi: failed to format generated code: %w", err)
+	}
+
+	return src, nil
+}
+
+// generator holds the state of GenerateGo.
+type generator struct {
+	opts    GenerateOptions
+	buf     bytes.Buffer
+	imports map[string]bool
+}
+
+// generatedType is a type of the schema with the names and Go types of the
+// generated struct and its fields.
+type generatedType struct {
+	typ    Type
+	name   string
+	attrs  []generatedField
+	rels   []generatedField
+	fields map[string]string
+}
+
+// generatedField is an attribute or a relationship of a generated struct.
+type generatedField struct {
+	name   string
+	member string
+	goType reflect.Type
+	typ    string
+	attr   Attr
+	rel    Rel
+}
+
+// printf writes to the buffer of the generated file.
+func (g *generator) printf(format string, args ...interface{}) {
+	_, _ = fmt.Fprintf(&g.buf, format, args...)
+}
+
+// typ returns the generated type of typ. An error is returned if the name of a
+// field conflicts with another field or with a method of the struct.
+func (g *generator) typ(typ Type) (generatedType, error) {
+	gt := generatedType{
+		typ:    typ,
+		name:   g.opts.TypeNames[typ.Name],
+		fields: map[string]string{"ID": "id"},
+	}
+
+	if gt.name == "" {
+		gt.name = goName(typ.Name)
+	}
+
+	addField := func(member string) (string, error) {
+		name := goName(member)
+
+		switch name {
+		case "Attrs", "Rels", "GetType", "Get", "GetE", "Set", "SetE", "New", "Copy":
+			return "", fmt.Errorf("jsonapi: field %q of type %q conflicts with method %s",
+				member, typ.Name, name)
+		}
+
+		if other, ok := gt.fields[name]; ok {
+			return "", fmt.Errorf("jsonapi: fields %q and %q of type %q have the same Go name %s",
+				other, member, typ.Name, name)
+		}
+
+		gt.fields[name] = member
+
+		return name, nil
+	}
+
+	for _, key := range sortedAttrNames(typ.Attrs) {
+		attr := typ.Attrs[key]
+
+		zv := attrZeroValue(attr)
+		if zv == nil || !attrTypeRegistered(attr.Type) {
+			return gt, fmt.Errorf("jsonapi: attribute %q of type %q has an unregistered type",
+				attr.Name, typ.Name)
+		}
+
+		name, err := addField(attr.Name)
+		if err != nil {
+			return gt, err
+		}
+
+		goType := reflect.TypeOf(zv)
+
+		gt.attrs = append(gt.attrs, generatedField{
+			name:   name,
+			member: attr.Name,
+			goType: goType,
+			typ:    g.typeName(goType),
+			attr:   attr,
+		})
+	}
+
+	for _, key := range sortedRelNames(typ.Rels) {
+		rel := typ.Rels[key]
+
+		name, err := addField(rel.FromName)
+		if err != nil {
+			return gt, err
+		}
+
+		goType := reflect.TypeOf([]string{})
+		if rel.ToOne {
+			goType = reflect.TypeOf("")
+		}
+
+		gt.rels = append(gt.rels, generatedField{
+			name:   name,
+			member: rel.FromName,
+			goType: goType,
+			typ:    goType.String(),
+			rel:    rel,
+		})
+	}
+
+	return gt, nil
+}
+
+// typeName returns the name of t in the generated code and records the
+// packages of the named types it refers to.
+func (g *generator) typeName(t reflect.Type) string {
+	if t == reflect.TypeOf(json.RawMessage{}) {
+		// json.RawMessage can be an alias of a type of another package.
+		g.imports["encoding/json"] = true
+		return "json.RawMessage"
+	}
+
+	if t.Name() == "" {
+		switch t.Kind() {
+		case reflect.Ptr:
+			return "*" + g.typeName(t.Elem())
+		case reflect.Slice:
+			return "[]" + g.typeName(t.Elem())
+		case reflect.Array:
+			return fmt.Sprintf("[%d]%s", t.Len(), g.typeName(t.Elem()))
+		case reflect.Map:
+			return "map[" + g.typeName(t.Key()) + "]" + g.typeName(t.Elem())
+		}
+	}
+
+	if t.PkgPath() != "" {
+		g.imports[t.PkgPath()] = true
+	}
+
+	return t.String()
+}
+
+// header writes the package clause, the imports and the NewSchema function.
+func (g *generator) header(types []generatedType) {
+	g.printf("// Code generated by jsonapi.GenerateGo. DO NOT EDIT.\n\n")
+	g.printf("package %s\n\n", g.opts.Package)
+
+	g.imports["github.com/mark-hartmann/jsonapi"] = true
+
+	// The packages of the standard library come first.
+	var std, other []string
+
+	for path := range g.imports {
+		if strings.Contains(strings.SplitN(path, "/", 2)[0], ".") {
+			other = append(other, path)
+		} else {
+			std = append(std, path)
+		}
+	}
+
+	sort.Strings(std)
+	sort.Strings(other)
+
+	g.printf("import (\n")
+
+	for _, path := range std {
+		g.printf("%q\n", path)
+	}
+
+	if len(std) > 0 {
+		g.printf("\n")
+	}
+
+	for _, path := range other {
+		g.printf("%q\n", path)
+	}
+
+	g.printf(")\n\n")
+
+	g.printf("// NewSchema returns a new schema holding the generated types.\n")
+	g.printf("func NewSchema() (*jsonapi.Schema, error) {\n")
+	g.printf("schema := &jsonapi.Schema{}\n\n")
+
+	for _, gt := range types {
+		g.printf("if err := schema.AddType(new%sType()); err != nil {\n", gt.name)
+		g.printf("return nil, err\n}\n\n")
+	}
+
+	g.printf("return schema, nil\n}\n\n")
+}
+
+// resource writes the struct of gt, its type and its methods.
+func (g *generator) resource(gt generatedType) {
+	typ, recv := gt.typ, strings.ToLower(gt.name[:1])
+	varName := recv + gt.name[1:] + "Type"
+
+	// Struct
+	g.printf("// %s is a resource of type %q.\n", gt.name, typ.Name)
+	g.printf("type %s struct {\n", gt.name)
+	g.printf("ID string `json:\"id\" api:%q`\n", typ.Name)
+
+	for _, f := range gt.attrs {
+		g.printf("%s %s `json:%q api:%q%s`\n", f.name, f.typ, f.member, attrTag(f), docTags(
+			f.attr.Description, f.attr.Example))
+	}
+
+	for _, f := range gt.rels {
+		tag := "rel," + f.rel.ToType
+		if f.rel.ToName != "" {
+			tag += "," + f.rel.ToName
+		}
+
+		if f.rel.Deprecated {
+			tag += ",deprecated"
+		}
+
+		g.printf("%s %s `json:%q api:%q%s`\n", f.name, f.typ, f.member, tag, docTags(
+			f.rel.Description, f.rel.Example))
+	}
+
+	g.printf("}\n\n")
+
+	// Type
+	g.printf("var %s = new%sType()\n\n", varName, gt.name)
+	g.printf("func new%sType() jsonapi.Type {\n", gt.name)
+	g.printf("typ := jsonapi.Type{\n")
+	g.printf("Name: %q,\n", typ.Name)
+	g.printf("Attrs: map[string]jsonapi.Attr{\n")
+
+	for _, f := range gt.attrs {
+		g.printf("%q: %s,\n", f.member, attrLiteral(f.attr))
+	}
+
+	g.printf("},\n")
+	g.printf("Rels: map[string]jsonapi.Rel{\n")
+
+	for _, f := range gt.rels {
+		g.printf("%q: %s,\n", f.member, relLiteral(f.rel))
+	}
+
+	g.printf("},\n")
+
+	if typ.Description != "" {
+		g.printf("Description: %q,\n", typ.Description)
+	}
+
+	if typ.Example != "" {
+		g.printf("Example: %q,\n", typ.Example)
+	}
+
+	if typ.Deprecated {
+		g.printf("Deprecated: true,\n")
+	}
+
+	g.printf("}\n\n")
+	g.printf("typ.NewFunc = func() jsonapi.Resource {\nreturn &%s{}\n}\n\n", gt.name)
+	g.printf("return typ\n}\n\n")
+
+	// Structure
+	g.printf("// Attrs returns the attributes of the resource.\n")
+	g.printf("func (%s *%s) Attrs() map[string]jsonapi.Attr {\nreturn %s.Attrs\n}\n\n",
+		recv, gt.name, varName)
+	g.printf("// Rels returns the relationships of the resource.\n")
+	g.printf("func (%s *%s) Rels() map[string]jsonapi.Rel {\nreturn %s.Rels\n}\n\n",
+		recv, gt.name, varName)
+	g.printf("// GetType returns the type of the resource.\n")
+	g.printf("func (%s *%s) GetType() jsonapi.Type {\nreturn %s\n}\n\n", recv, gt.name, varName)
+
+	// Get
+	g.printf("// Get returns the value of the field named key. It panics if the field\n")
+	g.printf("// does not exist.\n")
+	g.printf("func (%s *%s) Get(key string) interface{} {\n", recv, gt.name)
+	g.printf("v, err := %s.GetE(key)\nif err != nil {\npanic(err)\n}\n\nreturn v\n}\n\n", recv)
+
+	g.printf("// GetE returns the value of the field named key.\n")
+	g.printf("func (%s *%s) GetE(key string) (interface{}, error) {\n", recv, gt.name)
+	g.printf("switch key {\n")
+	g.printf("case \"id\":\nreturn %s.ID, nil\n", recv)
+
+	for _, f := range append(gt.attrs, gt.rels...) {
+		g.printf("case %q:\nreturn %s.%s, nil\n", f.member, recv, f.name)
+	}
+
+	g.printf("}\n\n")
+	g.printf("return nil, &jsonapi.UnknownFieldError{Type: %q, Field: key}\n}\n\n", typ.Name)
+
+	// Set
+	g.printf("// Set sets the value of the field named key. It panics if the field does\n")
+	g.printf("// not exist or if the value is not of the type of the field.\n")
+	g.printf("func (%s *%s) Set(key string, val interface{}) {\n", recv, gt.name)
+	g.printf("if err := %s.SetE(key, val); err != nil {\npanic(err)\n}\n}\n\n", recv)
+
+	g.printf("// SetE sets the value of the field named key. A nil value sets the zero\n")
+	g.printf("// value of the field.\n")
+	g.printf("func (%s *%s) SetE(key string, val interface{}) error {\n", recv, gt.name)
+	g.printf("switch key {\n")
+	g.printf("case \"id\":\n")
+	g.setCase(recv, "ID", "string", varName)
+
+	for _, f := range append(gt.attrs, gt.rels...) {
+		g.printf("case %q:\n", f.member)
+		g.setCase(recv, f.name, f.typ, varName)
+	}
+
+	g.printf("}\n\n")
+	g.printf("return &jsonapi.UnknownFieldError{Type: %q, Field: key}\n}\n\n", typ.Name)
+
+	// Copier
+	g.printf("// New returns a new resource of the same type.\n")
+	g.printf("func (%s *%s) New() jsonapi.Resource {\nreturn &%s{}\n}\n\n", recv, gt.name, gt.name)
+
+	g.printf("// Copy returns a copy of the resource.\n")
+	g.printf("func (%s *%s) Copy() jsonapi.Resource {\n", recv, gt.name)
+	g.printf("c := *%s\n\n", recv)
+
+	for _, f := range append(gt.attrs, gt.rels...) {
+		g.copyField(recv, f)
+	}
+
+	g.printf("return &c\n}\n\n")
+}
+
+// setCase writes the body of the case of SetE for the field named name.
+func (g *generator) setCase(recv, name, goType, varName string) {
+	g.printf("v, ok := val.(%s)\n", goType)
+	g.printf("if !ok && val != nil {\n")
+	g.printf("return jsonapi.NewInvalidFieldValueError(%s, key, val)\n}\n\n", varName)
+	g.printf("%s.%s = v\n\nreturn nil\n", recv, name)
+}
+
+// copyField writes the statements that copy the value of f that would be
+// shared otherwise, like the elements of a slice.
+func (g *generator) copyField(recv string, f generatedField) {
+	t, field := f.goType, recv+"."+f.name
+
+	switch {
+	case t.Kind() == reflect.Slice:
+		g.printf("if %s != nil {\n", field)
+		g.printf("c.%s = append(%s{}, %s...)\n}\n\n", f.name, f.typ, field)
+	case t.Kind() == reflect.Ptr && t.Elem().Kind() == reflect.Slice:
+		g.printf("if %s != nil {\n", field)
+		g.printf("v := append(%s{}, (*%s)...)\nc.%s = &v\n}\n\n",
+			strings.TrimPrefix(f.typ, "*"), field, f.name)
+	case t.Kind() == reflect.Ptr:
+		g.printf("if %s != nil {\n", field)
+		g.printf("v := *%s\nc.%s = &v\n}\n\n", field, f.name)
+	}
+}
+
+// attrTag returns the api tag of the field of an attribute.
+func attrTag(f generatedField) string {
+	tag := "attr"
+
+	// The attribute type is given when it cannot be deduced from the Go type.
+	typ, array, _ := GetAttrType(f.goType.String())
+	if typ != f.attr.Type || array != f.attr.Array {
+		tag += "," + registry.names[f.attr.Type]
+
+		if array && !f.attr.Array {
+			tag += ",no-array"
+		}
+	}
+
+	if f.goType.Kind() == reflect.Ptr && !f.attr.Nullable {
+		tag += ",nullable=false"
+	}
+
+	if f.attr.Format != "" {
+		tag += ",format=" + f.attr.Format
+	}
+
+	if f.attr.ReadOnly {
+		tag += ",readonly"
+	}
+
+	if f.attr.OmitEmpty {
+		tag += ",omitempty"
+	}
+
+	if f.attr.Deprecated {
+		tag += ",deprecated"
+	}
+
+	return tag
+}
+
+// docTags returns the api-doc and api-example tags of a field, with a leading
+// space, or an empty string.
+func docTags(doc, example string) string {
+	var tags string
+
+	if doc != "" {
+		tags += " api-doc:" + strconv.Quote(doc)
+	}
+
+	if example != "" {
+		tags += " api-example:" + strconv.Quote(example)
+	}
+
+	return tags
+}
+
+// attrLiteral returns the Go literal of attr, without its functions.
+func attrLiteral(attr Attr) string {
+	lit := fmt.Sprintf("{Name: %q, Type: %s", attr.Name, attrTypeLiteral(attr.Type))
+
+	for _, opt := range []struct {
+		name string
+		set  bool
+	}{
+		{"Nullable", attr.Nullable},
+		{"Array", attr.Array},
+		{"ReadOnly", attr.ReadOnly},
+		{"OmitEmpty", attr.OmitEmpty},
+		{"Deprecated", attr.Deprecated},
+	} {
+		if opt.set {
+			lit += ", " + opt.name + ": true"
+		}
+	}
+
+	if attr.Format != "" {
+		lit += fmt.Sprintf(", Format: %q", attr.Format)
+	}
+
+	if attr.Description != "" {
+		lit += fmt.Sprintf(", Description: %q", attr.Description)
+	}
+
+	if attr.Example != "" {
+		lit += fmt.Sprintf(", Example: %q", attr.Example)
+	}
+
+	return lit + "}"
+}
+
+// relLiteral returns the Go literal of rel.
+func relLiteral(rel Rel) string {
+	lit := fmt.Sprintf("{FromType: %q, FromName: %q, ToType: %q", rel.FromType, rel.FromName,
+		rel.ToType)
+
+	for _, opt := range []struct {
+		name, val string
+	}{
+		{"ToName", strconv.Quote(rel.ToName)},
+		{"ToOne", strconv.FormatBool(rel.ToOne)},
+		{"FromOne", strconv.FormatBool(rel.FromOne)},
+		{"Description", strconv.Quote(rel.Description)},
+		{"Example", strconv.Quote(rel.Example)},
+		{"Deprecated", strconv.FormatBool(rel.Deprecated)},
+	} {
+		if opt.val != `""` && opt.val != "false" {
+			lit += ", " + opt.name + ": " + opt.val
+		}
+	}
+
+	return lit + "}"
+}
+
+// attrTypeLiteral returns the name of the constant of typ, or its value if it
+// is not one of the attribute types of this package.
+func attrTypeLiteral(typ int) string {
+	names := map[int]string{
+		AttrTypeString:   "AttrTypeString",
+		AttrTypeInt:      "AttrTypeInt",
+		AttrTypeInt8:     "AttrTypeInt8",
+		AttrTypeInt16:    "AttrTypeInt16",
+		AttrTypeInt32:    "AttrTypeInt32",
+		AttrTypeInt64:    "AttrTypeInt64",
+		AttrTypeUint:     "AttrTypeUint",
+		AttrTypeUint8:    "AttrTypeUint8",
+		AttrTypeUint16:   "AttrTypeUint16",
+		AttrTypeUint32:   "AttrTypeUint32",
+		AttrTypeUint64:   "AttrTypeUint64",
+		AttrTypeFloat32:  "AttrTypeFloat32",
+		AttrTypeFloat64:  "AttrTypeFloat64",
+		AttrTypeBool:     "AttrTypeBool",
+		AttrTypeTime:     "AttrTypeTime",
+		AttrTypeBytes:    "AttrTypeBytes",
+		AttrTypeDecimal:  "AttrTypeDecimal",
+		AttrTypeUUID:     "AttrTypeUUID",
+		AttrTypeJSON:     "AttrTypeJSON",
+		AttrTypeDuration: "AttrTypeDuration",
+	}
+
+	if name, ok := names[typ]; ok {
+		return "jsonapi." + name
+	}
+
+	return strconv.Itoa(typ)
+}
+
+// goName returns name in CamelCase, with the common initialisms in upper
+// case, like "author-id" to "AuthorID".
+func goName(name string) string {
+	parts := strings.FieldsFunc(name, func(r rune) bool {
+		return r == '-' || r == '_' || r == ' '
+	})
+
+	for i, part := range parts {
+		switch strings.ToLower(part) {
+		case "id", "url", "uri", "uuid", "api", "http", "json", "html", "ip":
+			parts[i] = strings.ToUpper(part)
+		default:
+			parts[i] = strings.ToUpper(part[:1]) + part[1:]
+		}
+	}
+
+	return strings.Join(parts, "")
+}
@@ -0,0 +1,112 @@
+package jsonapi_test
+
+import (
+	"go/parser"
+	"go/token"
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+
+	. "github.com/mark-hartmann/jsonapi"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGenerateGo(t *testing.T) {
+	assert := assert.New(t)
+
+	articles := Type{Name: "articles", Description: "An article."}
+	articles.MustAddAttr(Attr{Name: "title", Type: AttrTypeString, Example: "Hello"})
+	articles.MustAddAttr(Attr{Name: "tags", Type: AttrTypeString, Array: true})
+	articles.MustAddAttr(Attr{Name: "word-count", Type: AttrTypeInt, ReadOnly: true})
+	articles.MustAddAttr(Attr{Name: "published-at", Type: AttrTypeTime, Nullable: true})
+	articles.MustAddAttr(Attr{Name: "scores", Type: AttrTypeFloat64, Array: true, Nullable: true})
+	articles.MustAddAttr(Attr{Name: "price", Type: AttrTypeDecimal, OmitEmpty: true})
+	articles.MustAddAttr(Attr{Name: "extra", Type: AttrTypeJSON, Deprecated: true})
+	articles.MustAddAttr(Attr{Name: "cover", Type: AttrTypeBytes, Nullable: true})
+	articles.MustAddRel(Rel{
+		FromType: "articles",
+		FromName: "author",
+		ToType:   "people",
+		ToOne:    true,
+		ToName:   "articles",
+	})
+	articles.MustAddRel(Rel{FromType: "articles", FromName: "related", ToType: "articles"})
+
+	people := Type{Name: "people"}
+	people.MustAddAttr(Attr{Name: "name", Type: AttrTypeString})
+	people.MustAddRel(Rel{
+		FromType: "people",
+		FromName: "articles",
+		ToType:   "articles",
+		ToName:   "author",
+	})
+
+	schema := NewSchemaBuilder().Type(articles).Type(people).MustBuild()
+
+	src, err := GenerateGo(schema, GenerateOptions{
+		Package:   "models",
+		TypeNames: map[string]string{"articles": "Article", "people": "Person"},
+	})
+	assert.NoError(err)
+
+	_, err = parser.ParseFile(token.NewFileSet(), "models.go", src, parser.AllErrors)
+	assert.NoError(err)
+
+	// Golden file
+	path := filepath.Join("testdata", "goldenfiles", "generate", "models.go.golden")
+
+	if *update {
+		assert.NoError(ioutil.WriteFile(path, src, 0o600))
+	}
+
+	expected, _ := ioutil.ReadFile(path)
+	assert.Equal(string(expected), string(src))
+
+	// Errors
+	_, err = GenerateGo(schema, GenerateOptions{})
+	assert.EqualError(err, "jsonapi: package name is empty")
+
+	things := Type{Name: "things"}
+	things.MustAddAttr(Attr{Name: "copy", Type: AttrTypeString})
+
+	_, err = GenerateGo(&Schema{Types: []Type{things}}, GenerateOptions{Package: "models"})
+	assert.EqualError(err, `jsonapi: field "copy" of type "things" conflicts with method Copy`)
+
+	things = Type{Name: "things"}
+	things.MustAddAttr(Attr{Name: "a-b", Type: AttrTypeString})
+	things.MustAddAttr(Attr{Name: "a_b", Type: AttrTypeString})
+
+	_, err = GenerateGo(&Schema{Types: []Type{things}}, GenerateOptions{Package: "models"})
+	assert.EqualError(err,
+		`jsonapi: fields "a-b" and "a_b" of type "things" have the same Go name AB`)
+}
+
+func TestNewInvalidFieldValueError(t *testing.T) {
+	assert := assert.New(t)
+
+	typ := Type{Name: "articles"}
+	typ.MustAddAttr(Attr{Name: "title", Type: AttrTypeString, Nullable: true})
+	typ.MustAddRel(Rel{FromType: "articles", FromName: "author", ToType: "people", ToOne: true})
+	typ.MustAddRel(Rel{FromType: "articles", FromName: "tags", ToType: "tags"})
+
+	err := NewInvalidFieldValueError(typ, "title", 3)
+	assert.True(err.IsAttr())
+	assert.Equal("string (nullable)", err.FieldType)
+	assert.EqualError(err, `jsonapi: invalid value "3" for field "title": `+
+		`got value of type int, not *string`)
+
+	err = NewInvalidFieldValueError(typ, "author", 3)
+	assert.False(err.IsAttr())
+	assert.Equal("people", err.FieldType)
+	assert.EqualError(err, `jsonapi: invalid value "3" for field "author": `+
+		`got value of type int, not string`)
+
+	err = NewInvalidFieldValueError(typ, "tags", "a")
+	assert.EqualError(err, `jsonapi: invalid value "a" for field "tags": `+
+		`got value of type string, not []string`)
+
+	err = NewInvalidFieldValueError(typ, "id", 3)
+	assert.EqualError(err, `jsonapi: invalid value "3" for field "id": `+
+		`got value of type int, not string`)
+}
@@ -0,0 +1,147 @@
+package jsonapi
+
+import "sort"
+
+// A Graph links the resources of a document through their relationships. It is
+// returned by Document.Resolve.
+type Graph struct {
+	resources map[string]map[string]Resource
+	missing   Identifiers
+}
+
+// Resolve returns the graph of the resources of the document, which are the
+// resources of the primary data and the included resources.
+//
+// It is meant for clients that unmarshal a compound document and need to go
+// from a resource to the resources its relationships point to without
+// searching Included every time.
+func (d *Document) Resolve() *Graph {
+	g := &Graph{resources: map[string]map[string]Resource{}}
+
+	var ress []Resource
+
+	switch data := d.Data.(type) {
+	case Resource:
+		ress = append(ress, data)
+	case Collection:
+		for i := 0; i < data.Len(); i++ {
+			ress = append(ress, data.At(i))
+		}
+	}
+
+	ress = append(ress, d.Included...)
+
+	for _, res := range ress {
+		typ, id := res.GetType().Name, res.Get("id").(string)
+		if _, ok := g.resources[typ][id]; !ok {
+			cacheResource(g.resources, typ, id, res)
+		}
+	}
+
+	// Missing resources
+	seen := map[string]map[string]Resource{}
+
+	for _, res := range ress {
+		for _, rel := range res.Rels() {
+			for _, iden := range relIdentifiers(res, rel) {
+				if _, ok := seen[iden.Type][iden.ID]; ok {
+					continue
+				}
+
+				cacheResource(seen, iden.Type, iden.ID, nil)
+
+				if g.Resource(iden.Type, iden.ID) == nil {
+					g.missing = append(g.missing, iden)
+				}
+			}
+		}
+	}
+
+	sort.Slice(g.missing, func(i, j int) bool {
+		if g.missing[i].Type != g.missing[j].Type {
+			return g.missing[i].Type < g.missing[j].Type
+		}
+
+		return g.missing[i].ID < g.missing[j].ID
+	})
+
+	return g
+}
+
+// Resource returns the resource of type typ whose ID is id, or nil if it is not
+// part of the document.
+func (g *Graph) Resource(typ, id string) Resource {
+	return g.resources[typ][id]
+}
+
+// Related returns the resources the relationship named rel of res points to, in
+// the order of its linkage. The resources that are not part of the document are
+// skipped.
+//
+// nil is returned if res has no relationship named rel.
+func (g *Graph) Related(res Resource, rel string) []Resource {
+	r, ok := res.Rels()[rel]
+	if !ok {
+		return nil
+	}
+
+	related := []Resource{}
+
+	for _, iden := range relIdentifiers(res, r) {
+		if rres := g.Resource(iden.Type, iden.ID); rres != nil {
+			related = append(related, rres)
+		}
+	}
+
+	return related
+}
+
+// RelatedOne returns the resource the to-one relationship named rel of res
+// points to, or nil if the relationship is empty or the resource is not part of
+// the document.
+func (g *Graph) RelatedOne(res Resource, rel string) Resource {
+	if r, ok := res.Rels()[rel]; !ok || !r.ToOne {
+		return nil
+	}
+
+	if related := g.Related(res, rel); len(related) > 0 {
+		return related[0]
+	}
+
+	return nil
+}
+
+// Missing returns the identifiers found in the relationships of the resources
+// of the graph whose resources are not part of the document, sorted by type and
+// ID. Those are the resources a client has to fetch separately.
+func (g *Graph) Missing() Identifiers {
+	return g.missing
+}
+
+// relIdentifiers returns the identifiers of the relationship rel of res. Their
+// types come from the linkage of res if it is a LinkageHolder, so polymorphic
+// relationships are supported, and from the relationship otherwise.
+func relIdentifiers(res Resource, rel Rel) Identifiers {
+	ids := relIDs(res.Get(rel.FromName))
+	idens := make(Identifiers, 0, len(ids))
+
+	var linkage Identifiers
+	if lh, ok := res.(LinkageHolder); ok {
+		linkage = lh.Linkage(rel.FromName)
+	}
+
+	for _, id := range ids {
+		iden := Identifier{ID: id, Type: rel.ToType}
+
+		for i := range linkage {
+			if linkage[i].ID == id && linkage[i].Type != "" {
+				iden.Type = linkage[i].Type
+				break
+			}
+		}
+
+		idens = append(idens, iden)
+	}
+
+	return idens
+}
@@ -0,0 +1,110 @@
+package jsonapi_test
+
+import (
+	"strings"
+	"testing"
+
+	. "github.com/mark-hartmann/jsonapi"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDocumentResolve(t *testing.T) {
+	assert := assert.New(t)
+
+	articles := Type{Name: "articles"}
+	articles.MustAddRel(Rel{FromName: "author", ToType: "people", ToOne: true})
+	articles.MustAddRel(Rel{FromName: "comments", ToType: "comments"})
+
+	people := Type{Name: "people"}
+	people.MustAddRel(Rel{FromName: "articles", ToType: "articles"})
+
+	comments := Type{Name: "comments"}
+	comments.MustAddRel(Rel{FromName: "author", ToType: "people", ToOne: true})
+
+	schema := &Schema{}
+	schema.MustAddType(articles)
+	schema.MustAddType(people)
+	schema.MustAddType(comments)
+
+	payload := `{
+		"data": [{
+			"id": "a1",
+			"type": "articles",
+			"relationships": {
+				"author": {"data": {"id": "p1", "type": "people"}},
+				"comments": {"data": [
+					{"id": "c2", "type": "comments"},
+					{"id": "c1", "type": "comments"},
+					{"id": "c3", "type": "comments"}
+				]}
+			}
+		}, {
+			"id": "a2",
+			"type": "articles",
+			"relationships": {
+				"author": {"data": null}
+			}
+		}],
+		"included": [{
+			"id": "p1",
+			"type": "people",
+			"relationships": {
+				"articles": {"data": [{"id": "a1", "type": "articles"}]}
+			}
+		}, {
+			"id": "c1",
+			"type": "comments",
+			"relationships": {
+				"author": {"data": {"id": "p2", "type": "people"}}
+			}
+		}, {
+			"id": "c2",
+			"type": "comments",
+			"relationships": {
+				"author": {"data": {"id": "p1", "type": "people"}}
+			}
+		}]
+	}`
+
+	doc, err := UnmarshalDocument(strings.NewReader(payload), schema)
+	assert.NoError(err)
+
+	g := doc.Resolve()
+
+	a1 := g.Resource("articles", "a1")
+	assert.NotNil(a1)
+	assert.Nil(g.Resource("articles", "a3"))
+
+	p1 := g.RelatedOne(a1, "author")
+	assert.NotNil(p1)
+	assert.Equal("p1", p1.Get("id"))
+
+	// The relationships can be followed in all directions.
+	assert.Equal([]Resource{a1}, g.Related(p1, "articles"))
+
+	// The order of the linkage is kept and missing resources are skipped.
+	related := g.Related(a1, "comments")
+	assert.Len(related, 2)
+	assert.Equal("c2", related[0].Get("id"))
+	assert.Equal("c1", related[1].Get("id"))
+	assert.Equal(p1, g.RelatedOne(related[0], "author"))
+	assert.Nil(g.RelatedOne(related[1], "author"))
+
+	// Empty and unknown relationships
+	a2 := g.Resource("articles", "a2")
+	assert.Nil(g.RelatedOne(a2, "author"))
+	assert.Equal([]Resource{}, g.Related(a2, "comments"))
+	assert.Nil(g.Related(a2, "unknown"))
+	assert.Nil(g.RelatedOne(a1, "comments"))
+
+	assert.Equal(Identifiers{
+		{ID: "c3", Type: "comments"},
+		{ID: "p2", Type: "people"},
+	}, g.Missing())
+
+	// Empty document
+	g = (&Document{}).Resolve()
+	assert.Nil(g.Resource("articles", "a1"))
+	assert.Empty(g.Missing())
+}
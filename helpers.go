@@ -4,7 +4,6 @@ import (
 	"errors"
 	"fmt"
 	"reflect"
-	"strings"
 )
 
 // Check checks that the given value can be used with this library and returns
@@ -33,44 +32,41 @@ func Check(v interface{}) error {
 		return errors.New("jsonapi: struct doesn't have an ID field")
 	}
 
-	resType := idField.Tag.Get("api")
+	idTyp, err := parseTypeTag(idField)
+	if err != nil {
+		return err
+	}
+
+	resType := idTyp.Name
 	if resType == "" {
 		return errors.New("jsonapi: ID field's api tag is empty")
 	}
 
+	fields, err := apiFields(value.Type())
+	if err != nil {
+		return err
+	}
+
 	// Check attributes
 	for i := 0; i < value.NumField(); i++ {
-		sf := value.Type().Field(i)
-		typ := sf.Type
-
-		switch typ.Kind() {
-		case reflect.Ptr:
-			typ = typ.Elem()
-			if typ.Kind() == reflect.Slice || typ.Kind() == reflect.Array {
-				typ = typ.Elem()
-			}
-		case reflect.Array, reflect.Slice:
-			typ = typ.Elem()
+		if err := checkFieldType(value.Type().Field(i), resType); err != nil {
+			return err
 		}
+	}
 
-		switch typ.Kind() {
-		// Basically all types which cannot be unmarshalled by the json package.
-		case reflect.Chan, reflect.Complex64, reflect.Complex128, reflect.Func, reflect.Interface:
-			return fmt.Errorf("jsonapi: attribute %q of type %q is of unsupported type",
-				sf.Name,
-				resType,
-			)
+	// Fields promoted from embedded structs
+	for _, sf := range fields {
+		if len(sf.Index) > 1 {
+			if err := checkFieldType(sf.StructField, resType); err != nil {
+				return err
+			}
 		}
 	}
 
 	// Check relationships
-	for i := 0; i < value.NumField(); i++ {
-		sf := value.Type().Field(i)
-
-		if strings.HasPrefix(sf.Tag.Get("api"), "rel,") {
-			s := strings.Split(sf.Tag.Get("api"), ",")
-
-			if len(s) < 2 || len(s) > 3 {
+	for _, sf := range fields {
+		if sf.tag.kind == "rel" {
+			if len(sf.tag.args) < 1 || len(sf.tag.args) > 2 {
 				return fmt.Errorf(
 					"jsonapi: api tag of relationship %q of struct %q is invalid",
 					sf.Name,
@@ -91,9 +87,40 @@ func Check(v interface{}) error {
 	return nil
 }
 
+// checkFieldType returns an error if the type of the struct field cannot be
+// handled by the json package.
+func checkFieldType(sf reflect.StructField, resType string) error {
+	typ := sf.Type
+
+	switch typ.Kind() {
+	case reflect.Ptr:
+		typ = typ.Elem()
+		if typ.Kind() == reflect.Slice || typ.Kind() == reflect.Array {
+			typ = typ.Elem()
+		}
+	case reflect.Array, reflect.Slice:
+		typ = typ.Elem()
+	}
+
+	switch typ.Kind() {
+	// Basically all types which cannot be unmarshalled by the json package.
+	case reflect.Chan, reflect.Complex64, reflect.Complex128, reflect.Func, reflect.Interface:
+		return fmt.Errorf("jsonapi: attribute %q of type %q is of unsupported type",
+			sf.Name,
+			resType,
+		)
+	}
+
+	return nil
+}
+
 // BuildType takes a struct or a pointer to a struct to analyse and builds a
 // Type object that is returned.
 //
+// The fields of embedded structs without an api tag are promoted, so common
+// fields (like the ID) can be shared between several types. See the package
+// documentation for the format of the api tag.
+//
 // If an error is returned, the Type object will be empty.
 func BuildType(v interface{}) (Type, error) {
 	typ := Type{}
@@ -112,7 +139,13 @@ func BuildType(v interface{}) (Type, error) {
 		return typ, fmt.Errorf("jsonapi: invalid type: %q", err)
 	}
 
-	typ.Name, typ.Attrs, typ.Rels = getTypeInfo(val)
+	typ = getTypeInfo(val)
+
+	for _, attr := range typ.Attrs {
+		if err := checkAttrFormat(attr); err != nil {
+			return Type{}, err
+		}
+	}
 
 	// NewFunc
 	res := Wrap(reflect.New(val.Type()).Interface())
@@ -133,36 +166,49 @@ func MustBuildType(v interface{}) Type {
 	return typ
 }
 
-func getTypeInfo(val reflect.Value) (string, map[string]Attr, map[string]Rel) {
+// getTypeInfo returns the type described by the struct of val, without its
+// NewFunc.
+func getTypeInfo(val reflect.Value) Type {
 	idSF, _ := val.Type().FieldByName("ID")
-	typeName := idSF.Tag.Get("api")
+
+	// The struct is expected to have been validated with Check.
+	typ, _ := parseTypeTag(idSF)
+	typeName := typ.Name
+
+	// The struct is expected to have been validated with Check.
+	fields, _ := apiFields(val.Type())
 
 	attrs := map[string]Attr{}
 
-	for i := 0; i < val.NumField(); i++ {
-		fs := val.Type().Field(i)
-		jsonTag := fs.Tag.Get("json")
-		apiTag := fs.Tag.Get("api")
+	for _, fs := range fields {
+		if fs.tag.kind == "attr" {
+			attrType, arr, null := GetAttrType(fs.Type.String())
 
-		attr := strings.Split(apiTag, ",")
-		if attr[0] == "attr" {
-			typ, arr, null := GetAttrType(fs.Type.String())
+			if len(fs.tag.args) >= 1 {
+				// If the attribute type is not registered, attrType equals 0, which is the
+				// same as AttrTypeInvalid.
+				attrType = registry.namesR[fs.tag.args[0]]
+			}
 
-			if len(attr) >= 2 {
-				// If the attribute type is not registered, typ equals 0, which is the same
-				// as AttrTypeInvalid.
-				typ = registry.namesR[attr[1]]
+			if len(fs.tag.args) >= 2 {
+				arr = arr && fs.tag.args[1] != "no-array"
 			}
 
-			if len(attr) >= 3 {
-				arr = arr && attr[2] != "no-array"
+			if fs.tag.nullable != nil {
+				null = *fs.tag.nullable
 			}
 
-			attrs[jsonTag] = Attr{
-				Name:     jsonTag,
-				Type:     typ,
-				Array:    arr,
-				Nullable: null,
+			attrs[fs.name] = Attr{
+				Name:        fs.name,
+				Type:        attrType,
+				Array:       arr,
+				Nullable:    null,
+				Format:      fs.tag.format,
+				ReadOnly:    fs.tag.readOnly,
+				OmitEmpty:   fs.tag.omitEmpty,
+				Description: fs.tag.doc,
+				Example:     fs.tag.example,
+				Deprecated:  fs.tag.deprecated,
 			}
 		}
 	}
@@ -170,33 +216,29 @@ func getTypeInfo(val reflect.Value) (string, map[string]Attr, map[string]Rel) {
 	// Relationships
 	rels := map[string]Rel{}
 
-	for i := 0; i < val.NumField(); i++ {
-		fs := val.Type().Field(i)
-		jsonTag := fs.Tag.Get("json")
-		relTag := strings.Split(fs.Tag.Get("api"), ",")
-		invName := ""
-
-		if len(relTag) == 3 {
-			invName = relTag[2]
-		}
-
-		toOne := true
-		if fs.Type.String() == "[]string" {
-			toOne = false
-		}
+	for _, fs := range fields {
+		if fs.tag.kind == "rel" {
+			invName := ""
+			if len(fs.tag.args) == 2 {
+				invName = fs.tag.args[1]
+			}
 
-		if relTag[0] == "rel" {
-			rels[jsonTag] = Rel{
-				FromName: jsonTag,
-				ToType:   relTag[1],
-				ToOne:    toOne,
-				ToName:   invName,
-				FromType: typeName,
+			rels[fs.name] = Rel{
+				FromName:    fs.name,
+				ToType:      fs.tag.args[0],
+				ToOne:       fs.Type.String() != "[]string",
+				ToName:      invName,
+				FromType:    typeName,
+				Description: fs.tag.doc,
+				Example:     fs.tag.example,
+				Deprecated:  fs.tag.deprecated,
 			}
 		}
 	}
 
-	return typeName, attrs, rels
+	typ.Attrs, typ.Rels = attrs, rels
+
+	return typ
 }
 
 // ReduceRels removes redundant relationship paths.
@@ -1,7 +1,9 @@
 package jsonapi_test
 
 import (
+	"reflect"
 	"testing"
+	"time"
 
 	. "github.com/mark-hartmann/jsonapi"
 
@@ -40,6 +42,28 @@ func TestCheck(t *testing.T) {
 
 	err = Check(mockType5{})
 	assert.NoError(err)
+
+	err = Check(embeddingType{})
+	assert.NoError(err)
+
+	err = Check(unknownTagOption{})
+	assert.EqualError(err, "jsonapi: unknown option \"foo\" of field \"Attr\"")
+
+	err = Check(invalidTagName{})
+	assert.EqualError(err, "jsonapi: invalid name option \"_name\" of field \"Attr\"")
+
+	err = Check(invalidTagNullable{})
+	assert.EqualError(err, "jsonapi: invalid nullable option \"maybe\" of field \"Attr\"")
+
+	// Two embedded structs define the same field at the same depth. The struct
+	// is built at runtime because go vet rejects such a declaration.
+	conflicting := reflect.StructOf([]reflect.StructField{
+		{Name: "ID", Type: reflect.TypeOf(""), Tag: `json:"id" api:"conflicting"`},
+		{Name: "Timestamps", Type: reflect.TypeOf(timestamps{}), Anonymous: true},
+		{Name: "OtherTimestamps", Type: reflect.TypeOf(otherTimestamps{}), Anonymous: true},
+	})
+	err = Check(reflect.New(conflicting).Elem().Interface())
+	assert.EqualError(err, "jsonapi: field \"created-at\" of struct \"\" is defined more than once")
 }
 
 func TestBuildType(t *testing.T) {
@@ -75,6 +99,151 @@ func TestBuildType(t *testing.T) {
 	// Build from invalid struct
 	_, err = BuildType(invalidRelAPITag{})
 	assert.Error(err)
+
+	// Build from struct with embedded structs
+	typ, err = BuildType(embeddingType{})
+	assert.NoError(err)
+	assert.Equal("embedding", typ.Name)
+	assert.Equal([]string{"author", "created-at", "title", "updated-at"}, typ.Fields())
+	assert.Equal(Attr{Name: "title", Type: AttrTypeString}, typ.Attrs["title"])
+	assert.Equal(Attr{Name: "created-at", Type: AttrTypeTime}, typ.Attrs["created-at"])
+	assert.Equal(Rel{
+		FromType: "embedding",
+		FromName: "author",
+		ToOne:    true,
+		ToType:   "users",
+	}, typ.Rels["author"])
+}
+
+func TestBuildTypeTagOptions(t *testing.T) {
+	assert := assert.New(t)
+
+	typ, err := BuildType(tagOptionsType{})
+	assert.NoError(err)
+	assert.Equal([]string{"display-name", "full-text", "obj", "owner", "raw"}, typ.Fields())
+
+	assert.Equal(Attr{Name: "display-name", Type: AttrTypeString}, typ.Attrs["display-name"])
+	assert.Equal(Attr{Name: "full-text", Type: AttrTypeString}, typ.Attrs["full-text"])
+	assert.Equal(Attr{
+		Name:     "obj",
+		Type:     AttrTypeTestObject,
+		Nullable: false,
+	}, typ.Attrs["obj"])
+	assert.Equal(Attr{
+		Name:     "raw",
+		Type:     AttrTypeUint8,
+		Array:    true,
+		Nullable: true,
+	}, typ.Attrs["raw"])
+	assert.Equal(Rel{
+		FromType: "tagoptions",
+		FromName: "owner",
+		ToOne:    true,
+		ToType:   "users",
+		ToName:   "things",
+	}, typ.Rels["owner"])
+}
+
+func TestBuildTypeDoc(t *testing.T) {
+	assert := assert.New(t)
+
+	typ, err := BuildType(documentedType{})
+	assert.NoError(err)
+	assert.Equal("documented", typ.Name)
+	assert.Equal("A type, with a comma.", typ.Description)
+	assert.Equal("{}", typ.Example)
+	assert.True(typ.Deprecated)
+
+	assert.Equal(Attr{
+		Name:        "title",
+		Type:        AttrTypeString,
+		Description: "The title of the resource.",
+		Example:     "Hello",
+	}, typ.Attrs["title"])
+	assert.Equal(Attr{
+		Name:       "subtitle",
+		Type:       AttrTypeString,
+		ReadOnly:   true,
+		Deprecated: true,
+	}, typ.Attrs["subtitle"])
+	assert.Equal(Rel{
+		FromType:    "documented",
+		FromName:    "owner",
+		ToOne:       true,
+		ToType:      "users",
+		Description: "The owner of the resource.",
+		Deprecated:  true,
+	}, typ.Rels["owner"])
+
+	// Wrappers share the documentation.
+	assert.True(Wrap(&documentedType{}).GetType().Equal(typ))
+
+	// Unknown option of the type
+	_, err = BuildType(unknownTypeOption{})
+	assert.EqualError(err, `jsonapi: invalid type: "jsonapi: unknown option \"foo\" of field \"ID\""`)
+}
+
+type documentedType struct {
+	ID string `json:"id" api:"documented,deprecated" api-doc:"A type, with a comma." api-example:"{}"`
+
+	Title    string `json:"title" api:"attr" api-doc:"The title of the resource." api-example:"Hello"`
+	Subtitle string `json:"subtitle" api:"attr,readonly,deprecated"`
+	Owner    string `json:"owner" api:"rel,users,deprecated" api-doc:"The owner of the resource."`
+}
+
+type unknownTypeOption struct {
+	ID string `json:"id" api:"typename,foo"`
+}
+
+type tagOptionsType struct {
+	ID string `json:"id" api:"tagoptions"`
+
+	baseType `api:"-"`
+
+	Name  string       `json:"name" api:"attr,name=display-name"`
+	Text  string       `json:"text,omitempty" api:"attr,name=full-text"`
+	Obj   *testObjType `json:"obj" api:"attr,testObject,nullable=false"`
+	Raw   []uint8      `json:"raw" api:"attr,nullable"`
+	Owner string       `json:"owner" api:"rel,users,things,name=owner"`
+	Other string       `json:"other" api:"-"`
+}
+
+type unknownTagOption struct {
+	ID   string `json:"id" api:"typename"`
+	Attr string `json:"attr" api:"attr,foo=bar"`
+}
+
+type invalidTagName struct {
+	ID   string `json:"id" api:"typename"`
+	Attr string `json:"attr" api:"attr,name=_name"`
+}
+
+type invalidTagNullable struct {
+	ID   string `json:"id" api:"typename"`
+	Attr string `json:"attr" api:"attr,nullable=maybe"`
+}
+
+type timestamps struct {
+	CreatedAt time.Time `json:"created-at" api:"attr"`
+	UpdatedAt time.Time `json:"updated-at" api:"attr"`
+}
+
+type baseType struct {
+	ID string `json:"id" api:"embedding"`
+
+	timestamps
+
+	Author string `json:"author" api:"rel,users"`
+}
+
+type embeddingType struct {
+	baseType
+
+	Title string `json:"title" api:"attr"`
+}
+
+type otherTimestamps struct {
+	CreatedAt time.Time `json:"created-at" api:"attr"`
 }
 
 type emptyIDAPItag struct {
@@ -43,6 +43,52 @@ type Identifier struct {
 	Meta Meta   `json:"meta,omitempty"`
 }
 
+// A LinkageHolder can hold the resource identifiers of its relationships as
+// they were found in a payload.
+//
+// Relationships are stored as IDs on resources, so the other members of the
+// identifiers (like meta) are lost when a payload is unmarshaled. The
+// unmarshaling functions pass the complete identifiers of each relationship to
+// resources implementing this interface. When a resource is marshaled, the meta
+// of the identifiers whose ID matches the relationship value is added to the
+// resource linkage.
+//
+// Linkage returns an empty slice if the linkage of the relationship is unknown.
+type LinkageHolder interface {
+	Linkage(rel string) Identifiers
+	SetLinkage(rel string, idens Identifiers)
+}
+
+// linkageValue converts v into the value of a relationship as it is stored on a
+// resource (a string for a to-one relationship and a []string for a to-many
+// relationship) and the identifiers v holds.
+//
+// v can be an Identifier or a RelData for a to-one relationship, or an
+// Identifiers or a RelDataMany for a to-many relationship. Otherwise, ok is
+// false.
+func linkageValue(rel Rel, v interface{}) (val interface{}, idens Identifiers, ok bool) {
+	switch v2 := v.(type) {
+	case Identifier:
+		if rel.ToOne {
+			return v2.ID, Identifiers{v2}, true
+		}
+	case RelData:
+		if rel.ToOne {
+			return v2.Res.ID, Identifiers{v2.Res}, true
+		}
+	case Identifiers:
+		if !rel.ToOne {
+			return v2.IDs(), v2, true
+		}
+	case RelDataMany:
+		if !rel.ToOne {
+			return v2.Res.IDs(), v2.Res, true
+		}
+	}
+
+	return nil, nil, false
+}
+
 // RelData contains information about a to-one relationship, including links and metadata.
 type RelData struct {
 	Res   Identifier
@@ -0,0 +1,135 @@
+package jsonapi
+
+import "sort"
+
+// IncludedOrder is the order of the included resources of a marshaled
+// document (see MarshalOptions.IncludedOrder).
+type IncludedOrder int
+
+const (
+	// IncludedByID sorts the included resources by ID and then by type.
+	IncludedByID IncludedOrder = iota
+
+	// IncludedByType sorts the included resources by type and then by ID, so
+	// the resources of the same type are grouped.
+	IncludedByType
+
+	// IncludedByReference sorts the included resources in the order they are
+	// first referenced, following the relationships of the primary data and
+	// then the ones of the included resources, breadth first. The
+	// relationships of a resource are followed in the alphabetical order of
+	// their names and their linkage in the order it is marshaled. The
+	// resources that are not referenced come last, sorted by type and ID.
+	IncludedByReference
+)
+
+// sortIncluded returns the included resources of doc in the order of the
+// options. doc.Included is not modified.
+func (o MarshalOptions) sortIncluded(doc *Document) []Resource {
+	included := make([]Resource, len(doc.Included))
+	copy(included, doc.Included)
+
+	byType := func(i, j int) bool {
+		ti, tj := included[i].GetType().Name, included[j].GetType().Name
+		if ti != tj {
+			return ti < tj
+		}
+
+		return included[i].Get("id").(string) < included[j].Get("id").(string)
+	}
+
+	switch o.IncludedOrder {
+	case IncludedByType:
+		sort.SliceStable(included, byType)
+	case IncludedByReference:
+		rank := o.referenceRanks(doc)
+
+		sort.SliceStable(included, func(i, j int) bool {
+			ri, iok := rank[resourceKey(included[i])]
+			rj, jok := rank[resourceKey(included[j])]
+
+			switch {
+			case iok && jok:
+				return ri < rj
+			case iok != jok:
+				return iok
+			}
+
+			return byType(i, j)
+		})
+	default:
+		sort.SliceStable(included, func(i, j int) bool {
+			ii, ij := included[i].Get("id").(string), included[j].Get("id").(string)
+			if ii != ij {
+				return ii < ij
+			}
+
+			return included[i].GetType().Name < included[j].GetType().Name
+		})
+	}
+
+	return included
+}
+
+// referenceRanks returns the rank of the first reference to each resource
+// reachable from the primary data of doc through the included resources (see
+// IncludedByReference), by resource key.
+func (o MarshalOptions) referenceRanks(doc *Document) map[string]int {
+	var queue []Resource
+
+	switch data := doc.Data.(type) {
+	case Resource:
+		queue = append(queue, data)
+	case Collection:
+		for i := 0; i < data.Len(); i++ {
+			queue = append(queue, data.At(i))
+		}
+	}
+
+	rank := map[string]int{}
+
+	for _, res := range queue {
+		rank[resourceKey(res)] = -1
+	}
+
+	for len(queue) > 0 {
+		res := queue[0]
+		queue = queue[1:]
+
+		for _, name := range sortedRelNames(res.Rels()) {
+			for _, iden := range o.linkage(res, res.Rels()[name]) {
+				key := iden.Type + " " + iden.ID
+				if _, ok := rank[key]; ok {
+					continue
+				}
+
+				rank[key] = len(rank)
+
+				if inc := doc.FindIncluded(iden.Type, iden.ID); inc != nil {
+					queue = append(queue, inc)
+				}
+			}
+		}
+	}
+
+	return rank
+}
+
+// linkage returns the identifiers of the relationship rel of res in the order
+// they are marshaled.
+func (o MarshalOptions) linkage(res Resource, rel Rel) Identifiers {
+	idens := relIdentifiers(res, rel)
+
+	if !o.KeepLinkageOrder {
+		sort.SliceStable(idens, func(i, j int) bool {
+			return idens[i].ID < idens[j].ID
+		})
+	}
+
+	return idens
+}
+
+// resourceKey returns a key that identifies res by type and ID.
+func resourceKey(res Resource) string {
+	return res.GetType().Name + " " + res.Get("id").(string)
+}
@@ -0,0 +1,102 @@
+package jsonapi_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+
+	. "github.com/mark-hartmann/jsonapi"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMarshalOptionsIncludedOrder(t *testing.T) {
+	assert := assert.New(t)
+
+	articles := &Type{Name: "articles"}
+	articles.MustAddRel(Rel{FromName: "author", ToType: "people", ToOne: true})
+	articles.MustAddRel(Rel{FromName: "tags", ToType: "tags"})
+
+	people := &Type{Name: "people"}
+	people.MustAddRel(Rel{FromName: "avatar", ToType: "images", ToOne: true})
+
+	tags := &Type{Name: "tags"}
+	images := &Type{Name: "images"}
+
+	newRes := func(typ *Type, id string, rels map[string]interface{}) *SoftResource {
+		res := &SoftResource{Type: typ}
+		res.SetID(id)
+
+		for name, v := range rels {
+			res.Set(name, v)
+		}
+
+		return res
+	}
+
+	doc := &Document{
+		Data: newRes(articles, "a1", map[string]interface{}{
+			"author": "2",
+			"tags":   []string{"3", "1"},
+		}),
+		RelData: map[string][]string{"articles": {"tags"}},
+	}
+
+	doc.Include(newRes(tags, "3", nil))
+	doc.Include(newRes(images, "9", nil))
+	doc.Include(newRes(images, "1", nil))
+	doc.Include(newRes(tags, "1", nil))
+	doc.Include(newRes(people, "2", map[string]interface{}{"avatar": "9"}))
+
+	included := make([]Resource, len(doc.Included))
+	copy(included, doc.Included)
+
+	order := func(opts MarshalOptions) ([]string, []string) {
+		buf := &bytes.Buffer{}
+		assert.NoError(opts.MarshalDocument(buf, doc, nil))
+
+		var pl struct {
+			Data struct {
+				Relationships struct {
+					Tags struct {
+						Data []Identifier `json:"data"`
+					} `json:"tags"`
+				} `json:"relationships"`
+			} `json:"data"`
+			Included []Identifier `json:"included"`
+		}
+
+		assert.NoError(json.Unmarshal(buf.Bytes(), &pl))
+
+		inc := []string{}
+		for _, iden := range pl.Included {
+			inc = append(inc, iden.Type+"/"+iden.ID)
+		}
+
+		linkage := []string{}
+		for _, iden := range pl.Data.Relationships.Tags.Data {
+			linkage = append(linkage, iden.ID)
+		}
+
+		return inc, linkage
+	}
+
+	inc, linkage := order(MarshalOptions{})
+	assert.Equal([]string{"images/1", "tags/1", "people/2", "tags/3", "images/9"}, inc)
+	assert.Equal([]string{"1", "3"}, linkage)
+
+	inc, _ = order(MarshalOptions{IncludedOrder: IncludedByType})
+	assert.Equal([]string{"images/1", "images/9", "people/2", "tags/1", "tags/3"}, inc)
+
+	// author comes before tags and the avatar of the author is referenced
+	// by an included resource. images/1 is not referenced.
+	inc, _ = order(MarshalOptions{IncludedOrder: IncludedByReference})
+	assert.Equal([]string{"people/2", "tags/1", "tags/3", "images/9", "images/1"}, inc)
+
+	inc, linkage = order(MarshalOptions{IncludedOrder: IncludedByReference, KeepLinkageOrder: true})
+	assert.Equal([]string{"people/2", "tags/3", "tags/1", "images/9", "images/1"}, inc)
+	assert.Equal([]string{"3", "1"}, linkage)
+
+	// The document is not modified.
+	assert.Equal(included, doc.Included)
+}
@@ -0,0 +1,49 @@
+package jsonapi
+
+import (
+	"encoding/json"
+	"reflect"
+)
+
+// jsonAttrValue marshals v and returns the result as a value of the go-type of
+// attr, an attribute of type AttrTypeJSON.
+//
+// A json.RawMessage or []byte is considered to be already marshaled.
+func jsonAttrValue(attr Attr, v interface{}) (interface{}, error) {
+	data, err := marshalJSONValue(v)
+	if err != nil {
+		return nil, err
+	}
+
+	return UnmarshalToType(data, attr)
+}
+
+// setJSONField sets the struct field of an attribute of type AttrTypeJSON to
+// v by marshaling v and unmarshaling the result into a new value of the type of
+// the field.
+func setJSONField(field reflect.Value, v interface{}) error {
+	data, err := marshalJSONValue(v)
+	if err != nil {
+		return err
+	}
+
+	nv := reflect.New(field.Type())
+	if err := json.Unmarshal(data, nv.Interface()); err != nil {
+		return err
+	}
+
+	field.Set(nv.Elem())
+
+	return nil
+}
+
+func marshalJSONValue(v interface{}) ([]byte, error) {
+	switch raw := v.(type) {
+	case json.RawMessage:
+		return raw, nil
+	case []byte:
+		return raw, nil
+	}
+
+	return json.Marshal(v)
+}
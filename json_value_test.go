@@ -0,0 +1,102 @@
+package jsonapi_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+
+	. "github.com/mark-hartmann/jsonapi"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestJSONAttribute(t *testing.T) {
+	assert := assert.New(t)
+
+	typ := Type{Name: "users"}
+	typ.MustAddAttr(Attr{Name: "settings", Type: AttrTypeJSON})
+	typ.MustAddAttr(Attr{Name: "extra", Type: AttrTypeJSON, Nullable: true})
+	typ.MustAddAttr(Attr{Name: "history", Type: AttrTypeJSON, Array: true})
+
+	schema := &Schema{}
+	schema.MustAddType(typ)
+
+	res, err := UnmarshalResource([]byte(`{
+		"id": "u1",
+		"type": "users",
+		"attributes": {
+			"settings": {"theme": "dark", "sizes": [1, 2]},
+			"extra": null,
+			"history": [1, "two", {"three": 3}]
+		}
+	}`), schema)
+	assert.NoError(err)
+
+	assert.JSONEq(`{"theme": "dark", "sizes": [1, 2]}`,
+		string(res.Get("settings").(json.RawMessage)))
+	assert.Equal((*json.RawMessage)(nil), res.Get("extra"))
+	assert.Equal([]json.RawMessage{
+		json.RawMessage(`1`),
+		json.RawMessage(`"two"`),
+		json.RawMessage(`{"three": 3}`),
+	}, res.Get("history"))
+
+	// Null is not accepted if the attribute is not nullable.
+	_, err = UnmarshalResource([]byte(`{
+		"id": "u1",
+		"type": "users",
+		"attributes": {"settings": null}
+	}`), schema)
+	assert.Error(err)
+
+	// Any value can be set.
+	res.Set("extra", map[string]interface{}{"a": []int{1}})
+
+	buf := &bytes.Buffer{}
+	assert.NoError(MarshalDocument(buf, &Document{Data: res}, nil))
+	assert.Contains(buf.String(), `"attributes":{"extra":{"a":[1]},`+
+		`"history":[1,"two",{"three":3}],"settings":{"theme":"dark","sizes":[1,2]}}`)
+
+	// Values that cannot be marshaled are ignored.
+	res.Set("settings", make(chan int))
+	assert.JSONEq(`{"theme": "dark", "sizes": [1, 2]}`,
+		string(res.Get("settings").(json.RawMessage)))
+
+	res.Set("settings", []byte(`{"invalid"`))
+	assert.JSONEq(`{"theme": "dark", "sizes": [1, 2]}`,
+		string(res.Get("settings").(json.RawMessage)))
+
+	res.Set("settings", nil)
+	assert.Equal(json.RawMessage(`{}`), res.Get("settings"))
+}
+
+func TestJSONAttributeWrapper(t *testing.T) {
+	assert := assert.New(t)
+
+	type user struct {
+		ID       string                 `json:"id" api:"users"`
+		Settings map[string]interface{} `json:"settings" api:"attr"`
+		Extra    *json.RawMessage       `json:"extra" api:"attr"`
+	}
+
+	u := &user{}
+	wrap := Wrap(u)
+
+	assert.Equal(Attr{Name: "settings", Type: AttrTypeJSON}, wrap.Attr("settings"))
+	assert.Equal(Attr{Name: "extra", Type: AttrTypeJSON, Nullable: true}, wrap.Attr("extra"))
+
+	assert.Equal(json.RawMessage(`{}`), wrap.Get("settings"))
+
+	wrap.Set("settings", json.RawMessage(`{"theme":"dark"}`))
+	assert.Equal(map[string]interface{}{"theme": "dark"}, u.Settings)
+
+	extra := json.RawMessage(`[1,2]`)
+	wrap.Set("extra", &extra)
+	assert.Equal(&extra, u.Extra)
+
+	assert.Panics(func() { wrap.Set("settings", json.RawMessage(`[]`)) })
+
+	buf := &bytes.Buffer{}
+	assert.NoError(MarshalDocument(buf, &Document{Data: wrap}, nil))
+	assert.Contains(buf.String(), `"attributes":{"extra":[1,2],"settings":{"theme":"dark"}}`)
+}
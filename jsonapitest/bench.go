@@ -0,0 +1,110 @@
+package jsonapitest
+
+import (
+	"io"
+	"strconv"
+	"testing"
+
+	"github.com/mark-hartmann/jsonapi"
+)
+
+// benchSchema returns the schema used by the benchmarks: articles written by
+// people and commented.
+func benchSchema() *jsonapi.Schema {
+	articles := jsonapi.Type{Name: "articles"}
+	articles.MustAddAttr(jsonapi.Attr{Name: "title", Type: jsonapi.AttrTypeString})
+	articles.MustAddAttr(jsonapi.Attr{Name: "body", Type: jsonapi.AttrTypeString})
+	articles.MustAddAttr(jsonapi.Attr{Name: "views", Type: jsonapi.AttrTypeInt})
+	articles.MustAddAttr(jsonapi.Attr{Name: "rating", Type: jsonapi.AttrTypeFloat64})
+	articles.MustAddAttr(jsonapi.Attr{Name: "published", Type: jsonapi.AttrTypeBool})
+	articles.MustAddAttr(jsonapi.Attr{Name: "created-at", Type: jsonapi.AttrTypeTime})
+	articles.MustAddAttr(jsonapi.Attr{Name: "tags", Type: jsonapi.AttrTypeString, Array: true})
+	articles.MustAddRel(jsonapi.Rel{FromName: "author", ToType: "people", ToOne: true})
+	articles.MustAddRel(jsonapi.Rel{FromName: "comments", ToType: "comments"})
+
+	people := jsonapi.Type{Name: "people"}
+	people.MustAddAttr(jsonapi.Attr{Name: "name", Type: jsonapi.AttrTypeString})
+	people.MustAddAttr(jsonapi.Attr{Name: "email", Type: jsonapi.AttrTypeString, Nullable: true})
+
+	comments := jsonapi.Type{Name: "comments"}
+	comments.MustAddAttr(jsonapi.Attr{Name: "content", Type: jsonapi.AttrTypeString})
+	comments.MustAddAttr(jsonapi.Attr{Name: "created-at", Type: jsonapi.AttrTypeTime})
+
+	schema := &jsonapi.Schema{}
+	schema.MustAddType(articles)
+	schema.MustAddType(people)
+	schema.MustAddType(comments)
+
+	return schema
+}
+
+// BenchmarkSmallResource measures the marshaling of a single resource with a
+// few attributes and relationships according to opts.
+//
+// It is exported so that the applications can compare their own options and
+// so that the changes made to package jsonapi can be checked for regressions
+// with the same workload.
+func BenchmarkSmallResource(b *testing.B, opts jsonapi.MarshalOptions) {
+	schema := benchSchema()
+	typ := schema.GetType("articles")
+	res := jsonapi.GenerateResources(typ, 1, 1)[0]
+	relData := map[string][]string{"articles": {"author", "comments"}}
+
+	b.ReportAllocs()
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		_ = opts.MarshalResource(res, "https://example.org", typ.Fields(), relData)
+	}
+}
+
+// BenchmarkLargeDocument measures the marshaling of a document made of a
+// collection of 1,000 resources and the related resources it includes
+// according to opts. See BenchmarkSmallResource.
+func BenchmarkLargeDocument(b *testing.B, opts jsonapi.MarshalOptions) {
+	const n = 1000
+
+	schema := benchSchema()
+	ress := map[string]map[string]jsonapi.Resource{}
+
+	for _, typ := range schema.Types {
+		ress[typ.Name] = map[string]jsonapi.Resource{}
+
+		for _, res := range jsonapi.GenerateResources(typ, n, 1) {
+			ress[typ.Name][res.Get("id").(string)] = res
+		}
+	}
+
+	col := &jsonapi.Resources{}
+	for i := 1; i <= n; i++ {
+		col.Add(ress["articles"]["articles-"+strconv.Itoa(i)])
+	}
+
+	doc := &jsonapi.Document{
+		Data:    col,
+		RelData: map[string][]string{"articles": {"author", "comments"}},
+	}
+
+	for i := 0; i < col.Len(); i++ {
+		res := col.At(i)
+		doc.Include(ress["people"][res.Get("author").(string)])
+
+		for _, id := range res.Get("comments").([]string) {
+			doc.Include(ress["comments"][id])
+		}
+	}
+
+	url, err := jsonapi.NewURLFromRaw(schema, "/articles?include=author,comments")
+	if err != nil {
+		b.Fatal(err)
+	}
+
+	b.ReportAllocs()
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		if err := opts.MarshalDocument(io.Discard, doc, url); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
@@ -0,0 +1,397 @@
+package jsonapitest
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"sort"
+
+	"github.com/mark-hartmann/jsonapi"
+)
+
+// unknownName is the name used for the types, fields and media type
+// parameters that no schema or server is expected to know.
+const unknownName = "jsonapitest-unknown"
+
+// Cases returns the cases generated from the schema of the suite, sorted by
+// feature and then by type.
+func (s Suite) Cases() []Case {
+	names := make([]string, 0, len(s.Schema.Types))
+	for _, typ := range s.Schema.Types {
+		names = append(names, typ.Name)
+	}
+
+	sort.Strings(names)
+
+	if len(names) == 0 {
+		return nil
+	}
+
+	cases := negotiationCases(names[0])
+
+	for _, name := range names {
+		cases = append(cases, fetchCase(name))
+	}
+
+	for _, name := range names {
+		cases = append(cases, fieldsCases(s.Schema.GetType(name))...)
+	}
+
+	for _, name := range names {
+		cases = append(cases, sortCases(s.Schema.GetType(name))...)
+	}
+
+	for _, name := range names {
+		cases = append(cases, includeCases(s.Schema.GetType(name))...)
+	}
+
+	cases = append(cases, newCase("errors/unknown-type", "/"+unknownName,
+		expectError(http.StatusBadRequest, http.StatusNotFound)))
+
+	return cases
+}
+
+// newCase returns a case that fetches target with the JSON:API media type in
+// the Accept header.
+func newCase(name, target string, checks ...func(Suite, *response) []string) Case {
+	return Case{
+		Name:   name,
+		Method: http.MethodGet,
+		Target: target,
+		Header: http.Header{"Accept": {jsonapi.MediaType}},
+		check: func(s Suite, res *response) []string {
+			var failures []string
+			for _, check := range checks {
+				failures = append(failures, check(s, res)...)
+			}
+
+			return failures
+		},
+	}
+}
+
+// collectionTarget returns the path of the collection of typ with the given
+// query parameters.
+func collectionTarget(typ string, query url.Values) string {
+	target := "/" + typ
+	if len(query) > 0 {
+		target += "?" + query.Encode()
+	}
+
+	return target
+}
+
+func negotiationCases(typ string) []Case {
+	target := collectionTarget(typ, nil)
+	withParam := jsonapi.MediaType + "; " + unknownName + "=1"
+
+	acceptParam := newCase("negotiation/accept-params", target,
+		expectError(http.StatusNotAcceptable))
+	acceptParam.Header.Set("Accept", withParam)
+
+	acceptOne := newCase("negotiation/accept-one-valid", target, expectStatus(http.StatusOK))
+	acceptOne.Header.Set("Accept", withParam+", "+jsonapi.MediaType)
+
+	// A server that does not allow the creation of resources may refuse the
+	// method first.
+	contentTypeParam := newCase("negotiation/content-type-params", target,
+		expectStatus(http.StatusUnsupportedMediaType, http.StatusMethodNotAllowed))
+	contentTypeParam.Method = http.MethodPost
+	contentTypeParam.Header.Set("Content-Type", withParam)
+	contentTypeParam.Body = fmt.Sprintf(`{"data":{"type":%q}}`, typ)
+
+	return []Case{
+		newCase("negotiation/content-type", target, expectStatus(http.StatusOK)),
+		acceptParam,
+		acceptOne,
+		contentTypeParam,
+	}
+}
+
+func fetchCase(typ string) Case {
+	return newCase("fetch/"+typ, collectionTarget(typ, nil),
+		expectStatus(http.StatusOK),
+		checkCollection(func(ress []resourceObject) []string {
+			var failures []string
+
+			seen := map[string]bool{}
+
+			for _, res := range ress {
+				if res.Type != typ {
+					failures = append(failures,
+						fmt.Sprintf("resource %q is of type %q instead of %q", res.ID, res.Type, typ))
+				}
+
+				if seen[res.ID] {
+					failures = append(failures, fmt.Sprintf("resource %q is duplicated", res.ID))
+				}
+
+				seen[res.ID] = true
+			}
+
+			return failures
+		}),
+	)
+}
+
+func fieldsCases(typ jsonapi.Type) []Case {
+	cases := []Case{
+		newCase("fields/"+typ.Name+"/unknown",
+			collectionTarget(typ.Name, url.Values{"fields[" + typ.Name + "]": {unknownName}}),
+			expectError(http.StatusBadRequest)),
+	}
+
+	fields := typ.Fields()
+	if len(fields) == 0 {
+		return cases
+	}
+
+	field := fields[0]
+
+	return append(cases, newCase("fields/"+typ.Name,
+		collectionTarget(typ.Name, url.Values{"fields[" + typ.Name + "]": {field}}),
+		expectStatus(http.StatusOK),
+		checkCollection(func(ress []resourceObject) []string {
+			var failures []string
+
+			for _, res := range ress {
+				for name := range res.Attributes {
+					if name != field {
+						failures = append(failures, fmt.Sprintf(
+							"resource %q has attribute %q outside of the fieldset", res.ID, name))
+					}
+				}
+
+				for name := range res.Relationships {
+					if name != field {
+						failures = append(failures, fmt.Sprintf(
+							"resource %q has relationship %q outside of the fieldset", res.ID, name))
+					}
+				}
+			}
+
+			return failures
+		}),
+	))
+}
+
+func sortCases(typ jsonapi.Type) []Case {
+	cases := []Case{
+		newCase("sort/"+typ.Name+"/unknown",
+			collectionTarget(typ.Name, url.Values{"sort": {unknownName}}),
+			expectError(http.StatusBadRequest)),
+	}
+
+	attr, ok := sortableAttr(typ)
+	if !ok {
+		return cases
+	}
+
+	for _, desc := range []bool{false, true} {
+		name, rule := "sort/"+typ.Name+"/"+attr, attr
+		if desc {
+			name, rule = name+"/desc", "-"+attr
+		}
+
+		desc := desc
+
+		// A server may not support sorting, in which case it must answer
+		// with 400 Bad Request.
+		cases = append(cases, newCase(name,
+			collectionTarget(typ.Name, url.Values{"sort": {rule}}),
+			expectStatus(http.StatusOK, http.StatusBadRequest),
+			checkCollection(func(ress []resourceObject) []string {
+				return checkOrder(ress, attr, desc)
+			}),
+		))
+	}
+
+	return cases
+}
+
+// sortableAttr returns the name of the first attribute of typ that holds a
+// single string or number, whose order can be checked.
+func sortableAttr(typ jsonapi.Type) (string, bool) {
+	for _, field := range typ.Fields() {
+		attr, ok := typ.Attrs[field]
+		if !ok || attr.Array {
+			continue
+		}
+
+		switch attr.Type {
+		case jsonapi.AttrTypeString, jsonapi.AttrTypeInt, jsonapi.AttrTypeInt8,
+			jsonapi.AttrTypeInt16, jsonapi.AttrTypeInt32, jsonapi.AttrTypeInt64,
+			jsonapi.AttrTypeUint, jsonapi.AttrTypeUint8, jsonapi.AttrTypeUint16,
+			jsonapi.AttrTypeUint32, jsonapi.AttrTypeUint64, jsonapi.AttrTypeFloat32,
+			jsonapi.AttrTypeFloat64:
+			return attr.Name, true
+		}
+	}
+
+	return "", false
+}
+
+// checkOrder checks that the values of the attribute named attr of ress are
+// sorted. The null values and the resources without the attribute are
+// ignored.
+func checkOrder(ress []resourceObject, attr string, desc bool) []string {
+	var (
+		failures []string
+		prev     interface{}
+		prevID   string
+	)
+
+	for _, res := range ress {
+		var v interface{}
+		if err := json.Unmarshal(res.Attributes[attr], &v); err != nil || v == nil {
+			continue
+		}
+
+		if prev != nil && !ordered(prev, v, desc) {
+			failures = append(failures, fmt.Sprintf(
+				"resources %q and %q are not sorted by %q", prevID, res.ID, attr))
+		}
+
+		prev, prevID = v, res.ID
+	}
+
+	return failures
+}
+
+// ordered reports whether a can come before b in the given order.
+func ordered(a, b interface{}, desc bool) bool {
+	switch a := a.(type) {
+	case string:
+		if b, ok := b.(string); ok {
+			return a == b || (a < b) != desc
+		}
+	case float64:
+		if b, ok := b.(float64); ok {
+			return a == b || (a < b) != desc
+		}
+	}
+
+	return true
+}
+
+func includeCases(typ jsonapi.Type) []Case {
+	cases := []Case{
+		newCase("include/"+typ.Name+"/unknown",
+			collectionTarget(typ.Name, url.Values{"include": {unknownName}}),
+			expectError(http.StatusBadRequest)),
+	}
+
+	rels := make([]string, 0, len(typ.Rels))
+	for name := range typ.Rels {
+		rels = append(rels, name)
+	}
+
+	sort.Strings(rels)
+
+	for _, rel := range rels {
+		rel := rel
+
+		cases = append(cases, newCase("include/"+typ.Name+"/"+rel,
+			collectionTarget(typ.Name, url.Values{"include": {rel}}),
+			expectStatus(http.StatusOK),
+			func(_ Suite, res *response) []string {
+				ress, err := res.collection()
+				if err != nil {
+					return nil
+				}
+
+				return checkInclusions(ress, res.payload.Included, rel)
+			},
+		))
+	}
+
+	return cases
+}
+
+// checkInclusions checks that the included resources are unique and that each
+// of them is linked by the relationship named rel of a primary resource, which
+// must hold its linkage.
+func checkInclusions(ress, included []resourceObject, rel string) []string {
+	var failures []string
+
+	linked := map[string]bool{}
+
+	for _, res := range ress {
+		ro, ok := res.Relationships[rel]
+		if !ok || ro.Data == nil {
+			failures = append(failures, fmt.Sprintf(
+				"resource %q has no linkage for the included relationship %q", res.ID, rel))
+
+			continue
+		}
+
+		for _, iden := range ro.linkage() {
+			linked[iden.Type+" "+iden.ID] = true
+		}
+	}
+
+	seen := map[string]bool{}
+
+	for _, inc := range included {
+		key := inc.Type + " " + inc.ID
+
+		if seen[key] {
+			failures = append(failures, fmt.Sprintf(
+				"included resource %q of type %q is duplicated", inc.ID, inc.Type))
+		}
+
+		seen[key] = true
+
+		if !linked[key] {
+			failures = append(failures, fmt.Sprintf(
+				"included resource %q of type %q is not linked by %q", inc.ID, inc.Type, rel))
+		}
+	}
+
+	return failures
+}
+
+// expectStatus checks that the status code of the response is one of codes.
+func expectStatus(codes ...int) func(Suite, *response) []string {
+	return func(_ Suite, res *response) []string {
+		for _, code := range codes {
+			if res.status == code {
+				return nil
+			}
+		}
+
+		return []string{fmt.Sprintf("the status code is %d instead of %v", res.status, codes)}
+	}
+}
+
+// expectError checks that the status code of the response is one of codes and
+// that the body is an error document.
+func expectError(codes ...int) func(Suite, *response) []string {
+	return func(s Suite, res *response) []string {
+		failures := expectStatus(codes...)(s, res)
+
+		if res.payload == nil || len(res.payload.Errors) == 0 {
+			failures = append(failures, "the body is not an error document")
+		}
+
+		return failures
+	}
+}
+
+// checkCollection checks the primary data with check if the status code of
+// the response is 200 OK. The primary data must be an array.
+func checkCollection(check func([]resourceObject) []string) func(Suite, *response) []string {
+	return func(_ Suite, res *response) []string {
+		if res.status != http.StatusOK {
+			return nil
+		}
+
+		ress, err := res.collection()
+		if err != nil {
+			return []string{err.Error()}
+		}
+
+		return check(ress)
+	}
+}
@@ -0,0 +1,127 @@
+// Package jsonapitest checks that an HTTP handler serving the types of a
+// jsonapi.Schema conforms to the JSON:API specification.
+//
+// The cases are generated from the schema: content negotiation, fetching
+// collections, sparse fieldsets, sorting, inclusion of related resources and
+// error documents. Each case sends a request to the handler and checks the
+// response, without any knowledge of the data it serves.
+//
+//	func TestConformance(t *testing.T) {
+//		jsonapitest.Run(t, schema, handler)
+//	}
+//
+// The collections are fetched at /<type>, like jsonapi.URL expects, and the
+// member names of the payloads are the names of the fields of the schema.
+//
+// The package also provides the benchmarks of the marshaling, like
+// BenchmarkLargeDocument, which applications can run with their own options.
+package jsonapitest
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/mark-hartmann/jsonapi"
+)
+
+// A Case is a request sent to the handler along with the checks made on the
+// response.
+type Case struct {
+	// Name identifies the case, like "include/articles/author". The first
+	// part is the feature of the specification the case is about.
+	Name string
+
+	Method string
+	Target string
+	Header http.Header
+	Body   string
+
+	check func(s Suite, res *response) []string
+}
+
+// A Result is the outcome of a Case.
+type Result struct {
+	Case   string
+	Method string
+	Target string
+
+	// Status is the status code of the response.
+	Status int
+
+	// Failures describes the checks that failed. It is empty if the handler
+	// conforms.
+	Failures []string
+}
+
+// Passed reports whether all the checks of the case succeeded.
+func (r Result) Passed() bool {
+	return len(r.Failures) == 0
+}
+
+// A Suite runs the cases generated from Schema against Handler.
+type Suite struct {
+	Schema  *jsonapi.Schema
+	Handler http.Handler
+}
+
+// Run runs all the cases of the suite and returns their results, in the order
+// of Cases.
+func (s Suite) Run() []Result {
+	cases := s.Cases()
+	results := make([]Result, 0, len(cases))
+
+	for _, c := range cases {
+		results = append(results, s.RunCase(c))
+	}
+
+	return results
+}
+
+// RunCase sends the request of c to the handler and checks the response.
+func (s Suite) RunCase(c Case) Result {
+	req := httptest.NewRequest(c.Method, c.Target, strings.NewReader(c.Body))
+
+	for name, vals := range c.Header {
+		req.Header[name] = vals
+	}
+
+	rec := httptest.NewRecorder()
+	s.Handler.ServeHTTP(rec, req)
+
+	res := newResponse(rec)
+	failures := checkResponse(s, res)
+
+	if c.check != nil {
+		failures = append(failures, c.check(s, res)...)
+	}
+
+	return Result{
+		Case:     c.Name,
+		Method:   c.Method,
+		Target:   c.Target,
+		Status:   res.status,
+		Failures: failures,
+	}
+}
+
+// Run runs the cases generated from schema against handler as subtests of t.
+// Each failed check is reported as an error of the subtest of its case.
+func Run(t *testing.T, schema *jsonapi.Schema, handler http.Handler) {
+	t.Helper()
+
+	s := Suite{Schema: schema, Handler: handler}
+
+	for _, c := range s.Cases() {
+		c := c
+
+		t.Run(c.Name, func(t *testing.T) {
+			res := s.RunCase(c)
+
+			for _, f := range res.Failures {
+				t.Errorf("%s %s: %s", res.Method, res.Target, f)
+			}
+		})
+	}
+}
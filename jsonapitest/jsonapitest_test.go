@@ -0,0 +1,192 @@
+package jsonapitest_test
+
+import (
+	"bytes"
+	"fmt"
+	"net/http"
+	"sort"
+	"testing"
+
+	"github.com/mark-hartmann/jsonapi"
+	"github.com/mark-hartmann/jsonapi/jsonapitest"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func newSchema() *jsonapi.Schema {
+	articles := jsonapi.Type{Name: "articles"}
+	articles.MustAddAttr(jsonapi.Attr{Name: "title", Type: jsonapi.AttrTypeString})
+	articles.MustAddAttr(jsonapi.Attr{Name: "views", Type: jsonapi.AttrTypeInt})
+	articles.MustAddRel(jsonapi.Rel{FromName: "author", ToType: "people", ToOne: true})
+
+	people := jsonapi.Type{Name: "people"}
+	people.MustAddAttr(jsonapi.Attr{Name: "name", Type: jsonapi.AttrTypeString})
+
+	schema := &jsonapi.Schema{}
+	schema.MustAddType(articles)
+	schema.MustAddType(people)
+
+	return schema
+}
+
+// store is an in-memory ResourceGetter.
+type store map[string]*jsonapi.Resources
+
+func (s store) Get(typ, id string) (jsonapi.Resource, error) {
+	return s[typ].Find(typ, id), nil
+}
+
+func newStore(schema *jsonapi.Schema) store {
+	articles, people := schema.GetType("articles"), schema.GetType("people")
+	s := store{"articles": &jsonapi.Resources{}, "people": &jsonapi.Resources{}}
+
+	for i, name := range []string{"Ann", "Bob"} {
+		res := people.New()
+		res.Set("id", fmt.Sprintf("p%d", i+1))
+		res.Set("name", name)
+		s["people"].Add(res)
+	}
+
+	for i, title := range []string{"b", "c", "a"} {
+		res := articles.New()
+		res.Set("id", fmt.Sprintf("a%d", i+1))
+		res.Set("title", title)
+		res.Set("views", i)
+		res.Set("author", fmt.Sprintf("p%d", i%2+1))
+		s["articles"].Add(res)
+	}
+
+	return s
+}
+
+// conformingHandler serves the collections of s with the jsonapi package.
+func conformingHandler(schema *jsonapi.Schema, s store) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		req, err := jsonapi.ParseRequest(r, schema)
+		if err != nil {
+			_ = jsonapi.WriteError(w, (&jsonapi.ErrorMapper{}).Map(err))
+			return
+		}
+
+		if req.Method != http.MethodGet {
+			_ = jsonapi.WriteError(w, jsonapi.NewErrMethodNotAllowed())
+			return
+		}
+
+		col := append(jsonapi.Resources{}, *s[req.URL.ResType]...)
+
+		for _, rule := range req.URL.Params.SortRules {
+			rule := rule
+
+			sort.SliceStable(col, func(i, j int) bool {
+				a, b := col[i].Get(rule.Name), col[j].Get(rule.Name)
+				if rule.Desc {
+					a, b = b, a
+				}
+
+				if a, ok := a.(int); ok {
+					return a < b.(int)
+				}
+
+				return fmt.Sprint(a) < fmt.Sprint(b)
+			})
+		}
+
+		doc, err := jsonapi.DocumentBuilder{
+			Getter:  s,
+			Include: req.URL.Params.Include,
+		}.Build(&col)
+		if err != nil {
+			_ = jsonapi.WriteError(w, jsonapi.NewErrInternalServerError())
+			return
+		}
+
+		_ = jsonapi.WriteDocument(w, http.StatusOK, doc, req.URL)
+	})
+}
+
+func TestRun(t *testing.T) {
+	schema := newSchema()
+
+	jsonapitest.Run(t, schema, conformingHandler(schema, newStore(schema)))
+}
+
+func TestSuite(t *testing.T) {
+	assert := assert.New(t)
+
+	schema := newSchema()
+	s := newStore(schema)
+
+	suite := jsonapitest.Suite{Schema: schema, Handler: conformingHandler(schema, s)}
+	cases := suite.Cases()
+
+	names := make([]string, 0, len(cases))
+	for _, c := range cases {
+		names = append(names, c.Name)
+	}
+
+	assert.Equal([]string{
+		"negotiation/content-type",
+		"negotiation/accept-params",
+		"negotiation/accept-one-valid",
+		"negotiation/content-type-params",
+		"fetch/articles",
+		"fetch/people",
+		"fields/articles/unknown",
+		"fields/articles",
+		"fields/people/unknown",
+		"fields/people",
+		"sort/articles/unknown",
+		"sort/articles/title",
+		"sort/articles/title/desc",
+		"sort/people/unknown",
+		"sort/people/name",
+		"sort/people/name/desc",
+		"include/articles/unknown",
+		"include/articles/author",
+		"include/people/unknown",
+		"errors/unknown-type",
+	}, names)
+
+	for _, res := range suite.Run() {
+		assert.True(res.Passed(), "%s: %v", res.Case, res.Failures)
+	}
+
+	// A handler that ignores the parameters and the media types.
+	suite.Handler = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		doc := &jsonapi.Document{
+			Data:     s["articles"],
+			RelData:  map[string][]string{},
+			Included: []jsonapi.Resource{s["people"].At(0)},
+		}
+
+		buf := &bytes.Buffer{}
+		_ = jsonapi.MarshalDocument(buf, doc, nil)
+
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write(buf.Bytes())
+	})
+
+	failed := map[string]jsonapitest.Result{}
+
+	for _, res := range suite.Run() {
+		if !res.Passed() {
+			failed[res.Case] = res
+		}
+	}
+
+	assert.Contains(failed, "negotiation/accept-params")
+	assert.Contains(failed, "fetch/people")
+	assert.Contains(failed, "fields/articles")
+	assert.Contains(failed, "sort/articles/title")
+	assert.Contains(failed, "include/articles/author")
+	assert.Contains(failed, "errors/unknown-type")
+
+	assert.Equal(http.StatusOK, failed["fetch/people"].Status)
+	assert.Contains(failed["fetch/people"].Failures,
+		`the Content-Type header "application/json" is not the JSON:API media type`)
+	assert.Contains(failed["fetch/people"].Failures,
+		`resource "a1" is of type "articles" instead of "people"`)
+	assert.Contains(failed["include/articles/author"].Failures,
+		`resource "a1" has no linkage for the included relationship "author"`)
+}
@@ -0,0 +1,151 @@
+package jsonapitest
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+
+	"github.com/mark-hartmann/jsonapi"
+)
+
+// response is the response of the handler to the request of a case.
+type response struct {
+	status int
+	header http.Header
+	body   []byte
+
+	// payload is the decoded body. It is nil if the body is empty or is not
+	// a JSON object.
+	payload *payload
+}
+
+// payload holds the top-level members of a document that the cases check.
+type payload struct {
+	Data     json.RawMessage  `json:"data"`
+	Errors   []errorObject    `json:"errors"`
+	Meta     json.RawMessage  `json:"meta"`
+	Included []resourceObject `json:"included"`
+}
+
+// resourceObject holds the members of a resource object that the cases check.
+type resourceObject struct {
+	Type          string                     `json:"type"`
+	ID            string                     `json:"id"`
+	Attributes    map[string]json.RawMessage `json:"attributes"`
+	Relationships map[string]relObject       `json:"relationships"`
+}
+
+// relObject is a relationship object. Data is nil if it has no linkage.
+type relObject struct {
+	Data json.RawMessage `json:"data"`
+}
+
+// errorObject holds the members of an error object that the cases check.
+type errorObject struct {
+	Status string `json:"status"`
+}
+
+func newResponse(rec *httptest.ResponseRecorder) *response {
+	res := &response{
+		status: rec.Code,
+		header: rec.Header(),
+		body:   rec.Body.Bytes(),
+	}
+
+	pl := &payload{}
+	if err := json.Unmarshal(res.body, pl); err == nil {
+		res.payload = pl
+	}
+
+	return res
+}
+
+// collection returns the resource objects of the primary data, which must be
+// an array.
+func (r *response) collection() ([]resourceObject, error) {
+	if r.payload == nil || len(r.payload.Data) == 0 || r.payload.Data[0] != '[' {
+		return nil, fmt.Errorf("the primary data is not an array")
+	}
+
+	var ress []resourceObject
+	if err := json.Unmarshal(r.payload.Data, &ress); err != nil {
+		return nil, fmt.Errorf("the primary data is not an array of resource objects: %s", err)
+	}
+
+	return ress, nil
+}
+
+// linkage returns the resource identifiers of a relationship object, which
+// holds null, a resource identifier or an array of them.
+func (o relObject) linkage() []resourceObject {
+	data := bytes.TrimSpace(o.Data)
+
+	switch {
+	case len(data) == 0 || bytes.Equal(data, []byte("null")):
+		return nil
+	case data[0] == '[':
+		var idens []resourceObject
+		_ = json.Unmarshal(data, &idens)
+
+		return idens
+	}
+
+	var iden resourceObject
+	_ = json.Unmarshal(data, &iden)
+
+	return []resourceObject{iden}
+}
+
+// checkResponse makes the checks that apply to every response: the media type,
+// the top-level members and the validity of the document.
+func checkResponse(s Suite, res *response) []string {
+	var failures []string
+
+	fail := func(format string, args ...interface{}) {
+		failures = append(failures, fmt.Sprintf(format, args...))
+	}
+
+	if len(res.body) == 0 {
+		if res.status >= 200 && res.status < 300 && res.status != http.StatusNoContent {
+			fail("the response has no body")
+		}
+
+		return failures
+	}
+
+	if _, err := jsonapi.ParseMediaType(res.header.Get("Content-Type")); err != nil {
+		fail("the Content-Type header %q is not the JSON:API media type",
+			res.header.Get("Content-Type"))
+	}
+
+	pl := res.payload
+	if pl == nil {
+		return append(failures, "the body is not a JSON object")
+	}
+
+	if pl.Data == nil && pl.Errors == nil && pl.Meta == nil {
+		fail("the document has none of the data, errors and meta members")
+	}
+
+	if pl.Data != nil && pl.Errors != nil {
+		fail("the document has both the data and errors members")
+	}
+
+	if res.status >= 200 && res.status < 300 && pl.Data != nil {
+		if _, err := jsonapi.UnmarshalDocument(bytes.NewReader(res.body), s.Schema); err != nil {
+			fail("the document is invalid: %s", err)
+		}
+	}
+
+	if len(pl.Errors) == 1 && pl.Errors[0].Status != "" {
+		if pl.Errors[0].Status != strconv.Itoa(res.status) {
+			fail("the status of the error object is %q instead of \"%d\"",
+				pl.Errors[0].Status, res.status)
+		}
+	}
+
+	return failures
+}
@@ -1,7 +1,9 @@
 package jsonapi
 
 import (
+	"bytes"
 	"encoding/json"
+	"errors"
 	"strings"
 )
 
@@ -31,6 +33,41 @@ func (l Link) MarshalJSON() ([]byte, error) {
 	return json.Marshal(l.HRef)
 }
 
+// UnmarshalJSON unmarshals a link, which is either a string holding its URL or
+// an object with an href member and an optional meta member.
+func (l *Link) UnmarshalJSON(data []byte) error {
+	data = bytes.TrimSpace(data)
+
+	switch {
+	case bytes.Equal(data, []byte("null")):
+		return nil
+	case len(data) > 0 && data[0] == '"':
+		var href string
+		if err := json.Unmarshal(data, &href); err != nil {
+			return err
+		}
+
+		*l = Link{HRef: href}
+
+		return nil
+	case len(data) > 0 && data[0] == '{':
+		var obj struct {
+			HRef string                 `json:"href"`
+			Meta map[string]interface{} `json:"meta"`
+		}
+
+		if err := json.Unmarshal(data, &obj); err != nil {
+			return err
+		}
+
+		*l = Link{HRef: obj.HRef, Meta: obj.Meta}
+
+		return nil
+	}
+
+	return errors.New("jsonapi: link must be a string or an object")
+}
+
 // A LinkHolder can hold and return meta values. It is useful for a struct that represents a
 // resource type to implement this interface to have a custom links as part of its JSON output.
 type LinkHolder interface {
@@ -38,31 +75,60 @@ type LinkHolder interface {
 	SetLinks(map[string]Link)
 }
 
-// buildSelfLink builds a URL that points to the resource represented by the
-// value v.
-//
-// prepath is prepended to the path and usually represents a scheme and a
-// domain name.
-func buildSelfLink(res Resource, prepath string) string {
+// resourceLink builds a URL that points to the resource of type typ with the
+// given ID.
+func resourceLink(prepath, typ, id string) string {
 	link := prepath
 
 	if !strings.HasSuffix(prepath, "/") {
 		link += "/"
 	}
 
-	id, _ := res.Get("id").(string)
-	if id != "" && res.GetType().Name != "" {
-		link += res.GetType().Name + "/" + id
+	if id != "" && typ != "" {
+		link += typ + "/" + id
 	}
 
 	return link
 }
 
-// buildRelationshipLinks builds a links object (according to the JSON:API
-// specification) that include both the self and related members.
-func buildRelationshipLinks(res Resource, prepath, rel string) map[string]interface{} {
-	return map[string]interface{}{
-		"self":    Link{HRef: buildSelfLink(res, prepath) + "/relationships/" + rel},
-		"related": Link{HRef: buildSelfLink(res, prepath) + "/" + rel},
+// joinPath appends path, which starts with a slash, to prepath without doubling
+// the slash between them.
+func joinPath(prepath, path string) string {
+	return strings.TrimSuffix(prepath, "/") + path
+}
+
+// typePath returns the path of the collection of the type named typ, which is
+// /typ unless TypePaths holds another one.
+func (o MarshalOptions) typePath(typ string) string {
+	path, ok := o.TypePaths[typ]
+	if !ok {
+		return "/" + typ
 	}
+
+	return "/" + strings.Trim(path, "/")
+}
+
+// resourceLink builds the self link of the resource of type typ with the given
+// ID like the resourceLink function does, with the path of the type.
+func (o MarshalOptions) resourceLink(prepath, typ, id string) string {
+	if id == "" || typ == "" {
+		return resourceLink(prepath, typ, id)
+	}
+
+	return joinPath(prepath, o.typePath(typ)+"/"+id)
+}
+
+// urlLink builds a link to u, like the top-level self link of a document. The
+// first fragment of the path, which is a type name, is replaced by the path of
+// the type.
+func (o MarshalOptions) urlLink(prepath string, u *URL) string {
+	link := u.String()
+
+	if len(u.Fragments) > 0 {
+		if _, ok := o.TypePaths[u.Fragments[0]]; ok {
+			link = o.typePath(u.Fragments[0]) + link[len(u.Fragments[0])+1:]
+		}
+	}
+
+	return joinPath(prepath, link)
 }
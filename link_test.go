@@ -57,3 +57,62 @@ type badMarshaler struct{}
 func (b badMarshaler) MarshalJSON() ([]byte, error) {
 	return nil, errors.New("error")
 }
+
+func TestUnmarshalLink(t *testing.T) {
+	assert := assert.New(t)
+
+	tests := []struct {
+		payload      string
+		expectedLink jsonapi.Link
+		expectedErr  string
+	}{
+		{
+			payload:      `"example.org"`,
+			expectedLink: jsonapi.Link{HRef: "example.org"},
+		}, {
+			payload:      `{"href":"example.org"}`,
+			expectedLink: jsonapi.Link{HRef: "example.org"},
+		}, {
+			payload: `{"href":"example.org","meta":{"n":123,"s":"abc"}}`,
+			expectedLink: jsonapi.Link{
+				HRef: "example.org",
+				Meta: map[string]interface{}{"n": float64(123), "s": "abc"},
+			},
+		}, {
+			payload:     `123`,
+			expectedErr: "jsonapi: link must be a string or an object",
+		}, {
+			payload:     `{"href":123}`,
+			expectedErr: "json: cannot unmarshal number",
+		},
+	}
+
+	for _, test := range tests {
+		var link jsonapi.Link
+
+		err := link.UnmarshalJSON([]byte(test.payload))
+		if test.expectedErr != "" {
+			assert.Error(err)
+			assert.Contains(err.Error(), test.expectedErr)
+
+			continue
+		}
+
+		assert.NoError(err)
+		assert.Equal(test.expectedLink, link)
+
+		// Round trip
+		pl, err := link.MarshalJSON()
+		assert.NoError(err)
+
+		var link2 jsonapi.Link
+
+		assert.NoError(link2.UnmarshalJSON(pl))
+		assert.Equal(link, link2)
+	}
+
+	// A null link leaves the link untouched.
+	link := jsonapi.Link{HRef: "example.org"}
+	assert.NoError(link.UnmarshalJSON([]byte("null")))
+	assert.Equal(jsonapi.Link{HRef: "example.org"}, link)
+}
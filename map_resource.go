@@ -0,0 +1,270 @@
+package jsonapi
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+)
+
+// MapResource is a Resource whose values are stored in a map, like the
+// documents of a document store or generic JSON, along with the Type that
+// describes them.
+//
+// The WrapMap function can be used to wrap a map and make a MapResource
+// object.
+//
+// The ID is stored under the "id" key and the fields under their names. The
+// values of the attributes are converted to the Go types of the attributes
+// (see GetZeroValue) when they are set: any value whose JSON encoding can be
+// unmarshaled into the attribute is accepted, like a float64 for an attribute
+// of type AttrTypeInt or a string for an attribute of type AttrTypeTime. The
+// values of the relationships are IDs, given as a string for a to-one
+// relationship and as a []string or a []interface{} of strings for a to-many
+// relationship.
+type MapResource struct {
+	typ  *Type
+	data map[string]interface{}
+}
+
+// WrapMap wraps m and returns a MapResource whose type is typ. The values of m
+// are replaced by the converted values and the missing fields are set to their
+// zero values, so m can be read back after the resource has been modified.
+// m is initialized if it is nil.
+//
+// An *UnknownFieldError is returned if m holds a key that is not a field of
+// typ, and an *InvalidFieldValueError if a value cannot be converted. m is left
+// untouched when an error is returned.
+func WrapMap(typ *Type, m map[string]interface{}) (*MapResource, error) {
+	if m == nil {
+		m = map[string]interface{}{}
+	}
+
+	mr := &MapResource{typ: typ, data: m}
+
+	vals := make(map[string]interface{}, len(m))
+
+	for key, val := range m {
+		v, err := mr.convert(key, val)
+		if err != nil {
+			return nil, err
+		}
+
+		vals[key] = v
+	}
+
+	for key, v := range vals {
+		m[key] = v
+	}
+
+	if _, ok := m["id"]; !ok {
+		m["id"] = ""
+	}
+
+	for _, attr := range typ.Attrs {
+		if _, ok := m[attr.Name]; !ok {
+			m[attr.Name] = attrZeroValue(attr)
+		}
+	}
+
+	for _, rel := range typ.Rels {
+		if _, ok := m[rel.FromName]; !ok {
+			m[rel.FromName], _ = mapRelValue(rel, nil)
+		}
+	}
+
+	return mr, nil
+}
+
+// Attrs returns the resource's attributes.
+func (mr *MapResource) Attrs() map[string]Attr {
+	return mr.typ.Attrs
+}
+
+// Rels returns the resource's relationships.
+func (mr *MapResource) Rels() map[string]Rel {
+	return mr.typ.Rels
+}
+
+// GetType returns the resource's type.
+func (mr *MapResource) GetType() Type {
+	return *mr.typ
+}
+
+// Map returns the map that holds the values of the resource.
+func (mr *MapResource) Map() map[string]interface{} {
+	return mr.data
+}
+
+// Get returns the value associated to the field named after key.
+//
+// It panics if the field does not exist. See GetE.
+func (mr *MapResource) Get(key string) interface{} {
+	v, err := mr.GetE(key)
+	if err != nil {
+		panic(err)
+	}
+
+	return v
+}
+
+// GetE returns the value associated to the field named after key, or an
+// *UnknownFieldError if the field does not exist.
+func (mr *MapResource) GetE(key string) (interface{}, error) {
+	if err := mr.checkField(key); err != nil {
+		return nil, err
+	}
+
+	return mr.data[key], nil
+}
+
+// Set sets the value associated to the field named after key.
+//
+// It panics if the field does not exist or if the value cannot be converted.
+// See SetE.
+func (mr *MapResource) Set(key string, val interface{}) {
+	if err := mr.SetE(key, val); err != nil {
+		panic(err)
+	}
+}
+
+// SetE converts val and sets it as the value associated to the field named
+// after key. An *UnknownFieldError is returned if the field does not exist and
+// an *InvalidFieldValueError if the value cannot be converted.
+func (mr *MapResource) SetE(key string, val interface{}) error {
+	v, err := mr.convert(key, val)
+	if err != nil {
+		return err
+	}
+
+	mr.data[key] = v
+
+	return nil
+}
+
+// convert returns val converted to the type of the field named after key.
+func (mr *MapResource) convert(key string, val interface{}) (interface{}, error) {
+	if err := mr.checkField(key); err != nil {
+		return nil, err
+	}
+
+	var (
+		v   interface{}
+		err error
+	)
+
+	if key == "id" {
+		var ok bool
+		if v, ok = val.(string); !ok && val != nil {
+			err = fmt.Errorf("got value of type %T, not string", val)
+		}
+	} else if attr, ok := mr.typ.Attrs[key]; ok {
+		v, err = mapAttrValue(attr, val)
+	} else {
+		v, err = mapRelValue(mr.typ.Rels[key], val)
+	}
+
+	if err != nil {
+		attr, isAttr := mr.typ.Attrs[key]
+		name, _ := GetAttrTypeName(attr.Type, attr.Array, attr.Nullable)
+
+		return nil, &InvalidFieldValueError{
+			Type:      mr.typ.Name,
+			Field:     key,
+			FieldType: name,
+			Value:     fmt.Sprint(val),
+			asRel:     !isAttr && key != "id",
+			err:       err,
+		}
+	}
+
+	if v == nil && key == "id" {
+		v = ""
+	}
+
+	return v, nil
+}
+
+// New returns a new resource (of type *MapResource) with the same type and a
+// new map holding the zero values of the fields.
+func (mr *MapResource) New() Resource {
+	nmr, _ := WrapMap(mr.typ, nil)
+
+	return nmr
+}
+
+// Copy returns a new resource (of type *MapResource) with the same type and a
+// copy of the map.
+func (mr *MapResource) Copy() Resource {
+	return &MapResource{typ: mr.typ, data: copyValue(mr.data).(map[string]interface{})}
+}
+
+// checkField returns an *UnknownFieldError if key is not the name of a field
+// of the type of the resource.
+func (mr *MapResource) checkField(key string) error {
+	_, isAttr := mr.typ.Attrs[key]
+	_, isRel := mr.typ.Rels[key]
+
+	if key != "id" && !isAttr && !isRel {
+		return &UnknownFieldError{Type: mr.typ.Name, Field: key}
+	}
+
+	return nil
+}
+
+// mapAttrValue converts v to the Go type of attr. A value of another type is
+// converted through its JSON encoding.
+func mapAttrValue(attr Attr, v interface{}) (interface{}, error) {
+	zv := attrZeroValue(attr)
+
+	if isNil(v) {
+		return zv, nil
+	}
+
+	if reflect.TypeOf(v) == reflect.TypeOf(zv) {
+		return v, nil
+	}
+
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+
+	return UnmarshalToType(data, attr)
+}
+
+// mapRelValue converts v to the value of rel, a string for a to-one
+// relationship and a []string for a to-many relationship.
+func mapRelValue(rel Rel, v interface{}) (interface{}, error) {
+	if rel.ToOne {
+		switch v := v.(type) {
+		case nil:
+			return "", nil
+		case string:
+			return v, nil
+		}
+
+		return nil, fmt.Errorf("got value of type %T, not string", v)
+	}
+
+	switch v := v.(type) {
+	case nil:
+		return []string{}, nil
+	case []string:
+		return v, nil
+	case []interface{}:
+		ids := make([]string, len(v))
+
+		for i := range v {
+			id, ok := v[i].(string)
+			if !ok {
+				return nil, fmt.Errorf("got element of type %T, not string", v[i])
+			}
+
+			ids[i] = id
+		}
+
+		return ids, nil
+	}
+
+	return nil, fmt.Errorf("got value of type %T, not []string", v)
+}
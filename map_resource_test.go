@@ -0,0 +1,130 @@
+package jsonapi_test
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	. "github.com/mark-hartmann/jsonapi"
+
+	"github.com/stretchr/testify/assert"
+)
+
+var _ Resource = (*MapResource)(nil)
+var _ Copier = (*MapResource)(nil)
+var _ GetterE = (*MapResource)(nil)
+var _ SetterE = (*MapResource)(nil)
+
+func TestMapResource(t *testing.T) {
+	assert := assert.New(t)
+
+	typ := &Type{Name: "articles"}
+	typ.MustAddAttr(Attr{Name: "title", Type: AttrTypeString})
+	typ.MustAddAttr(Attr{Name: "views", Type: AttrTypeInt})
+	typ.MustAddAttr(Attr{Name: "rating", Type: AttrTypeFloat64, Nullable: true})
+	typ.MustAddAttr(Attr{Name: "created", Type: AttrTypeTime})
+	typ.MustAddAttr(Attr{Name: "extra", Type: AttrTypeJSON})
+	typ.MustAddRel(Rel{FromName: "author", ToType: "people", ToOne: true})
+	typ.MustAddRel(Rel{FromName: "tags", ToType: "tags"})
+
+	// Values as decoded from generic JSON
+	var m map[string]interface{}
+	assert.NoError(json.Unmarshal([]byte(`{
+		"id": "a1",
+		"title": "Hello",
+		"views": 3,
+		"created": "2019-11-19T23:17:01Z",
+		"extra": {"a": 1},
+		"tags": ["t1", "t2"]
+	}`), &m))
+
+	mr, err := WrapMap(typ, m)
+	assert.NoError(err)
+	assert.Equal("a1", mr.Get("id"))
+	assert.Equal("Hello", mr.Get("title"))
+	assert.Equal(3, mr.Get("views"))
+	assert.Equal((*float64)(nil), mr.Get("rating"))
+	assert.Equal(time.Date(2019, 11, 19, 23, 17, 1, 0, time.UTC), mr.Get("created"))
+	assert.Equal(json.RawMessage(`{"a":1}`), mr.Get("extra"))
+	assert.Equal("", mr.Get("author"))
+	assert.Equal([]string{"t1", "t2"}, mr.Get("tags"))
+
+	// The map holds the converted values.
+	assert.Equal(3, m["views"])
+	assert.Equal(m, mr.Map())
+
+	out := MarshalResource(mr, "", []string{"title", "views", "tags"}, map[string][]string{
+		"articles": {"tags"},
+	})
+	assert.JSONEq(`{
+		"attributes": {"title": "Hello", "views": 3},
+		"id": "a1",
+		"links": {"self": "/articles/a1"},
+		"relationships": {
+			"tags": {
+				"data": [
+					{"id": "t1", "type": "tags"},
+					{"id": "t2", "type": "tags"}
+				],
+				"links": {
+					"related": "/articles/a1/tags",
+					"self": "/articles/a1/relationships/tags"
+				}
+			}
+		},
+		"type": "articles"
+	}`, string(out))
+
+	// Set
+	mr.Set("rating", 4.5)
+	assert.Equal(4.5, *(mr.Get("rating").(*float64)))
+
+	mr.Set("author", "p1")
+	assert.Equal("p1", m["author"])
+
+	err = mr.SetE("views", "many")
+	assert.EqualError(err, `jsonapi: invalid value "many" for field "views": `+
+		`strconv.Atoi: parsing "\"many\"": invalid syntax`)
+
+	ive := &InvalidFieldValueError{}
+	assert.ErrorAs(err, &ive)
+	assert.True(ive.IsAttr())
+	assert.Equal("int", ive.FieldType)
+
+	err = mr.SetE("tags", []interface{}{"t1", 2})
+	assert.ErrorAs(err, &ive)
+	assert.False(ive.IsAttr())
+
+	assert.EqualError(mr.SetE("body", "World"),
+		`jsonapi: field "body" does not exist in resource type "articles"`)
+	assert.Panics(func() { mr.Set("views", "many") })
+	assert.Panics(func() { _ = mr.Get("body") })
+
+	// Copy and New
+	cp := mr.Copy()
+	cp.Set("tags", []string{"t3"})
+	assert.Equal([]string{"t1", "t2"}, mr.Get("tags"))
+	assert.Equal([]string{"t3"}, cp.Get("tags"))
+
+	nr := mr.New()
+	assert.Equal("", nr.Get("id"))
+	assert.Equal(0, nr.Get("views"))
+
+	// Invalid maps
+	_, err = WrapMap(typ, map[string]interface{}{"body": "World"})
+	assert.EqualError(err, `jsonapi: field "body" does not exist in resource type "articles"`)
+
+	_, err = WrapMap(typ, map[string]interface{}{"id": 1})
+	assert.EqualError(err, `jsonapi: invalid value "1" for field "id": `+
+		`got value of type int, not string`)
+
+	// The map is not modified when a value is invalid.
+	m = map[string]interface{}{"views": 3.0, "tags": []interface{}{"t1"}, "id": 1}
+	_, err = WrapMap(typ, m)
+	assert.Error(err)
+	assert.Equal(map[string]interface{}{
+		"views": 3.0,
+		"tags":  []interface{}{"t1"},
+		"id":    1,
+	}, m)
+}
@@ -0,0 +1,340 @@
+package jsonapi
+
+import (
+	"bytes"
+	"context"
+	"io"
+)
+
+// MarshalOptions configures how a document is encoded.
+//
+// The zero value produces the same output as MarshalDocument: compact JSON where
+// the HTML characters <, > and & are escaped, followed by a newline. Object
+// members are always written in alphabetical order, so the output is
+// deterministic regardless of the options.
+type MarshalOptions struct {
+	// Prefix and Indent are used to pretty-print the payload the same way
+	// json.Indent does. The payload is compact if both are empty.
+	Prefix string
+	Indent string
+
+	// DisableHTMLEscaping writes <, > and & as they are instead of escaping
+	// them as \u003c, \u003e and \u0026.
+	DisableHTMLEscaping bool
+
+	// OmitTrailingNewline removes the newline written after the payload.
+	OmitTrailingNewline bool
+
+	// DecimalAsNumber writes the values of the attributes of type
+	// AttrTypeDecimal as JSON numbers instead of strings. Clients decoding
+	// numbers as floating-point values may lose precision.
+	DecimalAsNumber bool
+
+	// MaxLinkage is the maximum number of resource identifiers written in the
+	// linkage of a to-many relationship. The linkage of a larger relationship
+	// is omitted, even if it is requested in RelData, and its size is added to
+	// the meta object of the relationship under the "count" key. There is no
+	// limit if MaxLinkage is 0.
+	//
+	// MaxLinkage only applies to the output. The unmarshaling functions accept
+	// linkages of any size, so it does not limit the size of the payloads sent
+	// by the clients.
+	MaxLinkage int
+
+	// UTCTimes converts the values of the attributes of type AttrTypeTime to
+	// UTC before writing them. By default, they keep their offsets.
+	UTCTimes bool
+
+	// Schema restricts the marshaled attributes and relationships to the ones
+	// of the types of the schema, which is usually a subset of a larger one
+	// (see Schema.Subset). The resources of the types that are not in the
+	// schema only keep their IDs. All the fields are marshaled if Schema is
+	// nil.
+	Schema *Schema
+
+	// DisablePooling makes the marshaling allocate new buffers every time
+	// instead of reusing them through a sync.Pool. The payloads returned by
+	// the marshaling functions are always owned by the caller.
+	DisablePooling bool
+
+	// IncludedOrder is the order of the included resources. They are sorted
+	// by ID by default.
+	IncludedOrder IncludedOrder
+
+	// KeepLinkageOrder writes the linkage of the to-many relationships in the
+	// order of the values of the resources, which matters for ordered
+	// relationships like the tracks of a playlist. By default, the resource
+	// identifiers are sorted by ID.
+	KeepLinkageOrder bool
+
+	// TypePaths holds the paths where the endpoints of some types are
+	// mounted, by type name, like /content/articles for articles. The path of
+	// a type replaces /<type> in the self links of its resources, in the
+	// links of their relationships and in the top-level links of the
+	// documents whose URL starts with the type. A versioned prefix common to
+	// all the types, like /api/v2, belongs to the PrePath of the document.
+	TypePaths map[string]string
+
+	// RelLinks selects the links written in the relationship objects, for
+	// the servers that do not implement all the relationship endpoints. All
+	// of them are written by default. RelLinksByType overrides it for the
+	// types it holds, by type name.
+	//
+	// A relationship object that ends up with no linkage, no links and no
+	// meta is omitted.
+	RelLinks       RelLinkMode
+	RelLinksByType map[string]RelLinkMode
+
+	// FieldMask removes fields from the marshaled resources, on top of the
+	// sparse fieldsets (see FieldMask). It also applies to the resources
+	// marshaled without a URL, like with MarshalResource.
+	FieldMask FieldMask
+
+	// OmitDeleted omits the deleted and archived resources (see IsDeleted and
+	// IsArchived) from the collections and the included resources of the
+	// marshaled documents. The URL of a document can bring them back with a
+	// filter[deleted]=true or filter[archived]=true parameter. A resource
+	// that is the primary data is always marshaled as a tombstone.
+	OmitDeleted bool
+
+	// ResourceMeta returns meta members to add to the meta object of each
+	// marshaled resource, like permissions or an ETag computed from the
+	// resource. The members of the resource itself (see MetaHolder) take
+	// precedence.
+	ResourceMeta func(r Resource) Meta
+
+	// ResourceLinks returns links to add to the links object of each
+	// marshaled resource. The links of the resource itself (see LinkHolder)
+	// take precedence and the self link is always the one built from the
+	// type and the ID.
+	ResourceLinks func(r Resource) map[string]Link
+
+	// Stats, if not nil, records what is marshaled (see MarshalStats).
+	// MarshalDocument resets it before marshaling a document, while the
+	// other marshaling functions add their resources to it. The options must
+	// not be used concurrently when Stats is set.
+	Stats *MarshalStats
+
+	// ctx is checked between the resources of the document by
+	// MarshalDocumentContext.
+	ctx context.Context
+}
+
+// RelLinkMode selects the links of the relationship objects (see
+// MarshalOptions.RelLinks).
+type RelLinkMode int
+
+const (
+	// RelLinksAll writes the self link, which points to the relationship
+	// endpoint like /articles/1/relationships/author, and the related link,
+	// which points to the related resources like /articles/1/author.
+	RelLinksAll RelLinkMode = iota
+
+	// RelLinksSelf only writes the self link.
+	RelLinksSelf
+
+	// RelLinksRelated only writes the related link.
+	RelLinksRelated
+
+	// RelLinksNone writes none of them.
+	RelLinksNone
+)
+
+// relLinks returns the self and related links of the relationship rel of a
+// resource of the type named typ whose self link is self. A link is empty if
+// it must not be written.
+func (o MarshalOptions) relLinks(typ string, rel Rel, self string) (string, string) {
+	mode := o.RelLinks
+	if m, ok := o.RelLinksByType[typ]; ok {
+		mode = m
+	}
+
+	name := o.Schema.memberName(rel.FromName)
+	relSelf, related := self+"/relationships/"+name, self+"/"+name
+
+	switch mode {
+	case RelLinksSelf:
+		return relSelf, ""
+	case RelLinksRelated:
+		return "", related
+	case RelLinksNone:
+		return "", ""
+	}
+
+	return relSelf, related
+}
+
+// defaultFields returns the default sparse fieldset of typ in o.Schema, or all
+// the fields of typ if there is none.
+func (o MarshalOptions) defaultFields(typ Type) []string {
+	if o.Schema != nil {
+		if fields, ok := o.Schema.defaultFields[typ.Name]; ok {
+			return fields
+		}
+	}
+
+	return typ.Fields()
+}
+
+// resourceMeta returns the meta object of r, merged with the members returned
+// by the ResourceMeta hook.
+func (o MarshalOptions) resourceMeta(r Resource) Meta {
+	var meta Meta
+	if mh, ok := r.(MetaHolder); ok {
+		meta = mh.Meta()
+	}
+
+	if v, ok := r.(Versioned); ok && v.Version() != "" {
+		versioned := make(Meta, len(meta)+1)
+		versioned.merge(meta, MergeOverwrite)
+		versioned[MetaVersion] = v.Version()
+		meta = versioned
+	}
+
+	if o.ResourceMeta == nil {
+		return meta
+	}
+
+	extra := o.ResourceMeta(r)
+	if len(extra) == 0 {
+		return meta
+	}
+
+	merged := make(Meta, len(extra)+len(meta))
+	merged.merge(extra, MergeOverwrite)
+	merged.merge(meta, MergeOverwrite)
+
+	return merged
+}
+
+// resourceLinks returns the links of r, merged with the links returned by the
+// ResourceLinks hook.
+func (o MarshalOptions) resourceLinks(r Resource) map[string]Link {
+	var links map[string]Link
+	if lh, ok := r.(LinkHolder); ok {
+		links = lh.Links()
+	}
+
+	if o.ResourceLinks == nil {
+		return links
+	}
+
+	extra := o.ResourceLinks(r)
+	if len(extra) == 0 {
+		return links
+	}
+
+	merged := make(map[string]Link, len(extra)+len(links))
+
+	for name, link := range extra {
+		merged[name] = link
+	}
+
+	for name, link := range links {
+		merged[name] = link
+	}
+
+	return merged
+}
+
+// schemaFields returns the fields among fields that exist in the type named
+// typ of the schema of the options, or fields itself if there is no schema.
+func (o MarshalOptions) schemaFields(typ string, fields []string) []string {
+	if o.Schema == nil {
+		return fields
+	}
+
+	styp := o.Schema.GetType(typ)
+	kept := make([]string, 0, len(fields))
+
+	for _, f := range fields {
+		_, isAttr := styp.Attrs[f]
+		_, isRel := styp.Rels[f]
+
+		if isAttr || isRel {
+			kept = append(kept, f)
+		}
+	}
+
+	return kept
+}
+
+// encode encodes v to JSON, applies the options to the result and writes it to
+// dst.
+func (o MarshalOptions) encode(dst io.Writer, v interface{}) error {
+	w := o.writer()
+	defer o.release(w)
+
+	// The encoder of the writer is reused. Its indentation is reset before the
+	// writer goes back to the pool.
+	w.enc.SetIndent(o.Prefix, o.Indent)
+	defer w.enc.SetIndent("", "")
+
+	if err := w.enc.Encode(v); err != nil {
+		return err
+	}
+
+	pl := w.buf.Bytes()
+
+	// Nested values implementing json.Marshaler are always escaped by the json
+	// package, so the escaping is reverted on the whole payload instead.
+	if o.DisableHTMLEscaping {
+		pl = unescapeHTML(pl)
+	}
+
+	if o.OmitTrailingNewline {
+		pl = bytes.TrimSuffix(pl, []byte("\n"))
+	}
+
+	if o.Stats != nil {
+		o.Stats.Size = len(pl)
+	}
+
+	_, err := dst.Write(pl)
+
+	return err
+}
+
+// unescapeHTML replaces the escape sequences of <, > and & found in the JSON
+// strings of pl by the characters themselves.
+func unescapeHTML(pl []byte) []byte {
+	if !bytes.Contains(pl, []byte(`\u00`)) {
+		return pl
+	}
+
+	out := make([]byte, 0, len(pl))
+
+	for i := 0; i < len(pl); i++ {
+		if pl[i] != '\\' || i+1 == len(pl) {
+			out = append(out, pl[i])
+			continue
+		}
+
+		if pl[i+1] == 'u' && i+6 <= len(pl) {
+			switch string(pl[i+2 : i+6]) {
+			case "003c":
+				out = append(out, '<')
+				i += 5
+
+				continue
+			case "003e":
+				out = append(out, '>')
+				i += 5
+
+				continue
+			case "0026":
+				out = append(out, '&')
+				i += 5
+
+				continue
+			}
+		}
+
+		// Any other escape sequence (including an escaped backslash) is kept
+		// as it is.
+		out = append(out, pl[i], pl[i+1])
+		i++
+	}
+
+	return out
+}
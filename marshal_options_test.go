@@ -0,0 +1,401 @@
+package jsonapi_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+
+	. "github.com/mark-hartmann/jsonapi"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMarshalOptions(t *testing.T) {
+	doc := &Document{
+		Data: Wrap(&mockType1{
+			ID:  "id1",
+			Str: `<b>&</b> \u003c`,
+		}),
+		Meta: Meta{
+			"html": "<b>&</b>",
+		},
+	}
+
+	url := &URL{
+		Fragments: []string{"mocktypes1", "id1"},
+		Params: &Params{
+			Fields: map[string][]string{"mocktypes1": {"str"}},
+		},
+	}
+
+	tests := []struct {
+		name     string
+		opts     MarshalOptions
+		expected string
+	}{
+		{
+			name: "default",
+			opts: MarshalOptions{},
+			expected: `{"data":{"attributes":{"str":"\u003cb\u003e\u0026\u003c/b\u003e ` +
+				`\\u003c"},"id":"id1","links":{"self":"/mocktypes1/id1"},` +
+				`"type":"mocktypes1"},"jsonapi":{"version":"1.0"},"links":{"self":` +
+				`"/mocktypes1/id1?fields%5Bmocktypes1%5D=str"},"meta":{"html":` +
+				`"\u003cb\u003e\u0026\u003c/b\u003e"}}` + "\n",
+		}, {
+			name: "without html escaping and newline",
+			opts: MarshalOptions{
+				DisableHTMLEscaping: true,
+				OmitTrailingNewline: true,
+			},
+			expected: `{"data":{"attributes":{"str":"<b>&</b> \\u003c"},` +
+				`"id":"id1","links":{"self":"/mocktypes1/id1"},"type":"mocktypes1"},` +
+				`"jsonapi":{"version":"1.0"},"links":{"self":` +
+				`"/mocktypes1/id1?fields%5Bmocktypes1%5D=str"},"meta":{"html":"<b>&</b>"}}`,
+		}, {
+			name: "indent",
+			opts: MarshalOptions{
+				Indent:              "  ",
+				DisableHTMLEscaping: true,
+				OmitTrailingNewline: true,
+			},
+			expected: `{
+  "data": {
+    "attributes": {
+      "str": "<b>&</b> \\u003c"
+    },
+    "id": "id1",
+    "links": {
+      "self": "/mocktypes1/id1"
+    },
+    "type": "mocktypes1"
+  },
+  "jsonapi": {
+    "version": "1.0"
+  },
+  "links": {
+    "self": "/mocktypes1/id1?fields%5Bmocktypes1%5D=str"
+  },
+  "meta": {
+    "html": "<b>&</b>"
+  }
+}`,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			assert := assert.New(t)
+
+			payload := &bytes.Buffer{}
+			err := test.opts.MarshalDocument(payload, doc, url)
+			assert.NoError(err)
+			assert.Equal(test.expected, payload.String())
+		})
+	}
+
+	// The zero value behaves like MarshalDocument.
+	pl1 := &bytes.Buffer{}
+	pl2 := &bytes.Buffer{}
+
+	assert.NoError(t, MarshalDocument(pl1, doc, url))
+	assert.NoError(t, MarshalOptions{}.MarshalDocument(pl2, doc, url))
+	assert.Equal(t, pl1.String(), pl2.String())
+}
+
+func TestMarshalOptionsMaxLinkage(t *testing.T) {
+	assert := assert.New(t)
+
+	typ := Type{Name: "articles"}
+	typ.MustAddRel(Rel{FromName: "comments", ToType: "comments"})
+	typ.MustAddRel(Rel{FromName: "tags", ToType: "tags"})
+	typ.MustAddRel(Rel{FromName: "author", ToType: "people", ToOne: true})
+
+	res := &SoftResource{Type: &typ}
+	res.SetID("a1")
+	res.Set("comments", RelDataMany{
+		Res:  Identifiers{{ID: "c1"}, {ID: "c2"}, {ID: "c3"}},
+		Meta: Meta{"sorted": true},
+	})
+	res.Set("tags", []string{"t1", "t2"})
+	res.Set("author", "p1")
+
+	opts := MarshalOptions{MaxLinkage: 2}
+	relData := map[string][]string{"articles": {"author", "comments", "tags"}}
+
+	out := string(opts.MarshalResource(res, "", typ.Fields(), relData))
+	assert.Contains(out, `"comments":{"links":{"related":"/articles/a1/comments",`+
+		`"self":"/articles/a1/relationships/comments"},"meta":{"count":3,"sorted":true}}`)
+	assert.Contains(out, `"tags":{"data":[{"id":"t1","type":"tags"},{"id":"t2","type":"tags"}]`)
+	assert.Contains(out, `"author":{"data":{"id":"p1","type":"people"}`)
+
+	// The meta of the resource is not modified.
+	assert.Equal(Meta{"sorted": true}, res.RelMeta("comments"))
+
+	col := &Resources{res}
+	out = string(MarshalOptions{MaxLinkage: 1}.MarshalCollection(col, "",
+		map[string][]string{"articles": typ.Fields()}, relData))
+	assert.Contains(out, `"tags":{"links":{"related":"/articles/a1/tags",`+
+		`"self":"/articles/a1/relationships/tags"},"meta":{"count":2}}`)
+
+	// No limit
+	out = string(MarshalOptions{}.MarshalResource(res, "", typ.Fields(), relData))
+	assert.Contains(out, `"comments":{"data":[`)
+}
+
+func TestMarshalOptionsResourceHooks(t *testing.T) {
+	assert := assert.New(t)
+
+	typ := Type{Name: "articles"}
+	typ.MustAddAttr(Attr{Name: "title", Type: AttrTypeString})
+
+	res := &SoftResource{Type: &typ}
+	res.SetID("a1")
+	res.Set("title", "Hello")
+	res.SetMeta(Meta{"etag": "own", "nested": Meta{"b": 2}})
+	res.SetLinks(map[string]Link{"edit": {HRef: "/own/edit"}})
+
+	opts := MarshalOptions{
+		ResourceMeta: func(r Resource) Meta {
+			return Meta{
+				"etag":    "computed",
+				"nested":  Meta{"a": 1},
+				"canEdit": r.Get("id") == "a1",
+			}
+		},
+		ResourceLinks: func(r Resource) map[string]Link {
+			return map[string]Link{
+				"self": {HRef: "/ignored"},
+				"edit": {HRef: "/computed/edit"},
+				"html": {HRef: "/" + r.GetType().Name + "/" + r.Get("id").(string) + ".html"},
+			}
+		},
+	}
+
+	out := string(opts.MarshalResource(res, "", typ.Fields(), nil))
+	assert.Contains(out, `"links":{"edit":"/own/edit","html":"/articles/a1.html",`+
+		`"self":"/articles/a1"}`)
+	assert.Contains(out, `"meta":{"canEdit":true,"etag":"own","nested":{"a":1,"b":2}}`)
+
+	// The meta and the links of the resource are not modified.
+	assert.Equal(Meta{"etag": "own", "nested": Meta{"b": 2}}, res.Meta())
+	assert.Equal(map[string]Link{"edit": {HRef: "/own/edit"}}, res.Links())
+
+	// Resources without meta or links
+	res2 := &SoftResource{Type: &typ}
+	res2.SetID("a2")
+
+	out = string(MarshalOptions{
+		ResourceMeta: opts.ResourceMeta,
+	}.MarshalCollection(&Resources{res2}, "", map[string][]string{"articles": {}}, nil))
+	assert.Contains(out, `"links":{"self":"/articles/a2"},"meta":{"canEdit":false,`+
+		`"etag":"computed","nested":{"a":1}}`)
+
+	// Empty hook results
+	out = string(MarshalOptions{
+		ResourceMeta:  func(Resource) Meta { return nil },
+		ResourceLinks: func(Resource) map[string]Link { return nil },
+	}.MarshalResource(res2, "", nil, nil))
+	assert.Equal(`{"id":"a2","links":{"self":"/articles/a2"},"type":"articles"}`, out)
+}
+
+func TestMarshalOptionsPooling(t *testing.T) {
+	assert := assert.New(t)
+
+	typ := Type{Name: "articles"}
+	typ.MustAddAttr(Attr{Name: "title", Type: AttrTypeString})
+
+	res1 := &SoftResource{Type: &typ}
+	res1.SetID("a1")
+	res1.Set("title", "First")
+
+	res2 := &SoftResource{Type: &typ}
+	res2.SetID("a2")
+	res2.Set("title", "Second")
+
+	// The returned payloads are not reused by the next calls.
+	out1 := MarshalResource(res1, "", typ.Fields(), nil)
+	expected := string(out1)
+	out2 := MarshalResource(res2, "", typ.Fields(), nil)
+	assert.Equal(expected, string(out1))
+	assert.NotEqual(string(out1), string(out2))
+
+	col := &Resources{res1, res2}
+	fields := map[string][]string{"articles": typ.Fields()}
+	colOut := MarshalCollection(col, "", fields, nil)
+	expectedCol := string(colOut)
+	_ = MarshalCollection(&Resources{res2}, "", fields, nil)
+	assert.Equal(expectedCol, string(colOut))
+
+	// The output does not depend on pooling.
+	opts := MarshalOptions{DisablePooling: true}
+	assert.Equal(expected, string(opts.MarshalResource(res1, "", typ.Fields(), nil)))
+	assert.Equal(expectedCol, string(opts.MarshalCollection(col, "", fields, nil)))
+
+	doc := &Document{Data: col}
+	buf1, buf2 := &bytes.Buffer{}, &bytes.Buffer{}
+	assert.NoError(MarshalOptions{Indent: "  "}.MarshalDocument(buf1, doc, nil))
+	assert.NoError(MarshalOptions{Indent: "  ", DisablePooling: true}.MarshalDocument(buf2, doc, nil))
+	assert.Equal(buf1.String(), buf2.String())
+
+	// The indentation of a pooled encoder does not leak into the next calls.
+	buf1.Reset()
+	buf2.Reset()
+	assert.NoError(MarshalDocument(buf1, doc, nil))
+	assert.NoError(opts.MarshalDocument(buf2, doc, nil))
+	assert.Equal(buf2.String(), buf1.String())
+	assert.Equal(expected, string(MarshalResource(res1, "", typ.Fields(), nil)))
+}
+
+func TestMarshalOptionsRelLinks(t *testing.T) {
+	assert := assert.New(t)
+
+	typ := &Type{Name: "articles"}
+	typ.MustAddRel(Rel{FromName: "author", ToType: "people", ToOne: true})
+	typ.MustAddRel(Rel{FromName: "tags", ToType: "tags"})
+
+	res := &SoftResource{Type: typ}
+	res.SetID("a1")
+	res.Set("author", "p1")
+
+	relData := map[string][]string{"articles": {"author"}}
+
+	marshal := func(opts MarshalOptions) string {
+		return string(opts.MarshalResource(res, "", typ.Fields(), relData))
+	}
+
+	assert.JSONEq(`{
+		"id": "a1",
+		"links": {"self": "/articles/a1"},
+		"relationships": {
+			"author": {
+				"data": {"id": "p1", "type": "people"},
+				"links": {"self": "/articles/a1/relationships/author"}
+			},
+			"tags": {
+				"links": {"self": "/articles/a1/relationships/tags"}
+			}
+		},
+		"type": "articles"
+	}`, marshal(MarshalOptions{RelLinks: RelLinksSelf}))
+
+	assert.JSONEq(`{
+		"id": "a1",
+		"links": {"self": "/articles/a1"},
+		"relationships": {
+			"author": {
+				"data": {"id": "p1", "type": "people"},
+				"links": {"related": "/articles/a1/author"}
+			},
+			"tags": {
+				"links": {"related": "/articles/a1/tags"}
+			}
+		},
+		"type": "articles"
+	}`, marshal(MarshalOptions{RelLinks: RelLinksRelated}))
+
+	// The relationships without linkage, links or meta are omitted.
+	assert.JSONEq(`{
+		"id": "a1",
+		"links": {"self": "/articles/a1"},
+		"relationships": {
+			"author": {
+				"data": {"id": "p1", "type": "people"}
+			}
+		},
+		"type": "articles"
+	}`, marshal(MarshalOptions{RelLinks: RelLinksNone}))
+
+	// By type
+	opts := MarshalOptions{RelLinksByType: map[string]RelLinkMode{"articles": RelLinksNone}}
+	relData = nil
+
+	assert.JSONEq(`{
+		"id": "a1",
+		"links": {"self": "/articles/a1"},
+		"type": "articles"
+	}`, marshal(opts))
+
+	// A relationship with meta is kept.
+	res.Set("tags", RelDataMany{Meta: Meta{"count": 0}})
+
+	assert.JSONEq(`{
+		"id": "a1",
+		"links": {"self": "/articles/a1"},
+		"relationships": {
+			"tags": {
+				"meta": {"count": 0}
+			}
+		},
+		"type": "articles"
+	}`, marshal(opts))
+}
+
+func TestMarshalOptionsTypePaths(t *testing.T) {
+	assert := assert.New(t)
+
+	typ := Type{Name: "articles"}
+	typ.MustAddAttr(Attr{Name: "title", Type: AttrTypeString})
+	typ.MustAddRel(Rel{FromName: "author", ToType: "people", ToOne: true})
+
+	schema := &Schema{}
+	schema.MustAddType(typ)
+	schema.MustAddType(Type{Name: "people"})
+
+	res := &SoftResource{Type: &typ}
+	res.SetID("a1")
+	res.Set("author", "p1")
+
+	url, err := NewURLFromRaw(schema, "/articles?page[number]=1&page[size]=1")
+	assert.NoError(err)
+
+	doc := &Document{
+		Data:       &Resources{res},
+		PrePath:    "https://example.org/api/v2/",
+		Pagination: &Pagination{Total: 2},
+	}
+
+	links := func(opts MarshalOptions) []string {
+		buf := &bytes.Buffer{}
+		assert.NoError(opts.MarshalDocument(buf, doc, url))
+
+		var pl struct {
+			Data []struct {
+				Links         map[string]string `json:"links"`
+				Relationships map[string]struct {
+					Links map[string]string `json:"links"`
+				} `json:"relationships"`
+			} `json:"data"`
+			Links map[string]string `json:"links"`
+		}
+
+		assert.NoError(json.Unmarshal(buf.Bytes(), &pl))
+
+		return []string{
+			pl.Links["self"],
+			pl.Links["next"],
+			pl.Data[0].Links["self"],
+			pl.Data[0].Relationships["author"].Links["self"],
+			pl.Data[0].Relationships["author"].Links["related"],
+		}
+	}
+
+	// The trailing slash of the prepath is not doubled.
+	assert.Equal([]string{
+		"https://example.org/api/v2/articles?page%5Bnumber%5D=1&page%5Bsize%5D=1",
+		"https://example.org/api/v2/articles?page%5Bnumber%5D=2&page%5Bsize%5D=1",
+		"https://example.org/api/v2/articles/a1",
+		"https://example.org/api/v2/articles/a1/relationships/author",
+		"https://example.org/api/v2/articles/a1/author",
+	}, links(MarshalOptions{}))
+
+	// Per-type paths
+	opts := MarshalOptions{TypePaths: map[string]string{"articles": "content/articles/"}}
+
+	assert.Equal([]string{
+		"https://example.org/api/v2/content/articles?page%5Bnumber%5D=1&page%5Bsize%5D=1",
+		"https://example.org/api/v2/content/articles?page%5Bnumber%5D=2&page%5Bsize%5D=1",
+		"https://example.org/api/v2/content/articles/a1",
+		"https://example.org/api/v2/content/articles/a1/relationships/author",
+		"https://example.org/api/v2/content/articles/a1/author",
+	}, links(opts))
+}
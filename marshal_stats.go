@@ -0,0 +1,58 @@
+package jsonapi
+
+import "sort"
+
+// MarshalStats describes what a marshaling function wrote (see
+// MarshalOptions.Stats), for logging, for computing cache keys or for keeping
+// the size of the payloads under control.
+type MarshalStats struct {
+	// Resources is the number of resource objects that were written,
+	// including the included ones.
+	Resources int
+
+	// Included is the number of included resources that were written.
+	Included int
+
+	// Types holds the number of resource objects written by type name.
+	Types map[string]int
+
+	// Fields holds the names of the attributes and relationships written by
+	// type name, in alphabetical order. A field is listed if it was written
+	// for at least one resource, so the fields removed by the sparse
+	// fieldsets, the field mask or the OmitEmpty option of the attributes are
+	// not listed, and neither are the relationships with empty objects.
+	Fields map[string][]string
+
+	// Size is the number of bytes of the payload written by MarshalDocument.
+	Size int
+}
+
+// add records a resource object of type typ whose fields were written.
+func (s *MarshalStats) add(typ string, fields []string) {
+	if s.Types == nil {
+		s.Types = map[string]int{}
+		s.Fields = map[string][]string{}
+	}
+
+	s.Resources++
+	s.Types[typ]++
+
+	known := s.Fields[typ]
+
+	for _, field := range fields {
+		i := sort.SearchStrings(known, field)
+		if i < len(known) && known[i] == field {
+			continue
+		}
+
+		known = append(known, "")
+		copy(known[i+1:], known[i:])
+		known[i] = field
+	}
+
+	if known == nil {
+		known = []string{}
+	}
+
+	s.Fields[typ] = known
+}
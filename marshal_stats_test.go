@@ -0,0 +1,100 @@
+package jsonapi_test
+
+import (
+	"bytes"
+	"testing"
+
+	. "github.com/mark-hartmann/jsonapi"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMarshalStats(t *testing.T) {
+	assert := assert.New(t)
+
+	articles := Type{Name: "articles"}
+	articles.MustAddAttr(Attr{Name: "title", Type: AttrTypeString})
+	articles.MustAddAttr(Attr{Name: "body", Type: AttrTypeString, OmitEmpty: true})
+	articles.MustAddAttr(Attr{Name: "views", Type: AttrTypeInt})
+	articles.MustAddRel(Rel{
+		FromType: "articles",
+		FromName: "author",
+		ToType:   "people",
+		ToOne:    true,
+	})
+	articles.MustAddRel(Rel{FromType: "articles", FromName: "tags", ToType: "tags"})
+
+	people := Type{Name: "people"}
+	people.MustAddAttr(Attr{Name: "name", Type: AttrTypeString})
+
+	schema := &Schema{}
+	schema.MustAddType(articles)
+	schema.MustAddType(people)
+	schema.MustAddType(Type{Name: "tags"})
+
+	a1 := &SoftResource{Type: &articles}
+	a1.SetID("a1")
+	a1.Set("title", "A")
+	a1.Set("author", "p1")
+
+	a2 := &SoftResource{Type: &articles}
+	a2.SetID("a2")
+	a2.Set("title", "B")
+	a2.Set("body", "Text")
+	a2.Set("author", "p1")
+
+	p1 := &SoftResource{Type: &people}
+	p1.SetID("p1")
+	p1.Set("name", "Ann")
+
+	url, err := NewURLFromRaw(schema,
+		"/articles?fields[articles]=title,body,author,tags&include=author")
+	assert.NoError(err)
+
+	doc := &Document{
+		Data:     &Resources{a1, a2},
+		Included: []Resource{p1},
+		RelData:  map[string][]string{"articles": {"author"}},
+	}
+
+	stats := &MarshalStats{}
+	opts := MarshalOptions{Stats: stats, RelLinks: RelLinksNone}
+	buf := &bytes.Buffer{}
+
+	assert.NoError(opts.MarshalDocument(buf, doc, url))
+	assert.Equal(MarshalStats{
+		Resources: 3,
+		Included:  1,
+		Types:     map[string]int{"articles": 2, "people": 1},
+		Fields: map[string][]string{
+			// The tags relationship has an empty object.
+			"articles": {"author", "body", "title"},
+			"people":   {"name"},
+		},
+		Size: buf.Len(),
+	}, *stats)
+
+	// The stats are reset.
+	buf.Reset()
+	doc = &Document{Data: a1}
+
+	assert.NoError(opts.MarshalDocument(buf, doc, nil))
+	assert.Equal(MarshalStats{
+		Resources: 1,
+		Types:     map[string]int{"articles": 1},
+		Fields:    map[string][]string{"articles": {"title", "views"}},
+		Size:      buf.Len(),
+	}, *stats)
+
+	// Errors
+	buf.Reset()
+	doc = &Document{Errors: []Error{NewErrNotFound()}}
+
+	assert.NoError(opts.MarshalDocument(buf, doc, nil))
+	assert.Equal(MarshalStats{Size: buf.Len()}, *stats)
+
+	// The other marshaling functions add to the stats.
+	_ = opts.MarshalResource(p1, "", []string{"name"}, nil)
+	assert.Equal(1, stats.Resources)
+	assert.Equal(map[string][]string{"people": {"name"}}, stats.Fields)
+}
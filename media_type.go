@@ -0,0 +1,211 @@
+package jsonapi
+
+import (
+	"fmt"
+	"mime"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// MediaType is the media type of JSON:API documents.
+const MediaType = "application/vnd.api+json"
+
+// MediaTypeOptions holds the parameters of the JSON:API media type.
+//
+// Ext and Profile are lists of URIs. They are the only parameters allowed by
+// the specification.
+type MediaTypeOptions struct {
+	Ext     []string
+	Profile []string
+}
+
+// String returns the full media type, like
+// `application/vnd.api+json; ext="https://example.com/ext"`.
+func (o MediaTypeOptions) String() string {
+	params := map[string]string{}
+
+	if len(o.Ext) > 0 {
+		params["ext"] = strings.Join(o.Ext, " ")
+	}
+
+	if len(o.Profile) > 0 {
+		params["profile"] = strings.Join(o.Profile, " ")
+	}
+
+	return mime.FormatMediaType(MediaType, params)
+}
+
+// ParseMediaType parses the value of a Content-Type header.
+//
+// The returned error is a 415 Unsupported Media Type Error if the media type is
+// not the JSON:API media type or if it has parameters other than ext and
+// profile, as required by the specification.
+func ParseMediaType(header string) (MediaTypeOptions, error) {
+	mediaType, params, err := mime.ParseMediaType(header)
+	if err != nil || mediaType != MediaType {
+		return MediaTypeOptions{}, mediaTypeErr(NewErrUnsupportedMediaType(), "Content-Type",
+			fmt.Sprintf("The media type must be %q.", MediaType))
+	}
+
+	if name := unknownMediaTypeParam(params); name != "" {
+		return MediaTypeOptions{}, mediaTypeErr(NewErrUnsupportedMediaType(), "Content-Type",
+			fmt.Sprintf("The media type parameter %q is not allowed.", name))
+	}
+
+	return newMediaTypeOptions(params), nil
+}
+
+// NegotiateAccept returns the options of the first instance of the JSON:API
+// media type found in the value of an Accept header that can be served.
+//
+// An instance can be served if its only parameters are ext and profile and if
+// all of its extensions are in supportedExt. Unknown profiles are ignored, as
+// allowed by the specification. An instance whose weight (q) is 0 is never
+// served.
+//
+// The returned error is a 406 Not Acceptable Error if the header contains
+// instances of the JSON:API media type and none of them can be served. If the
+// header does not mention the JSON:API media type, empty options are returned
+// and it is up to the server to decide what to do.
+func NegotiateAccept(header string, supportedExt ...string) (MediaTypeOptions, error) {
+	var (
+		found  bool
+		reason string
+	)
+
+	for _, part := range splitMediaTypes(header) {
+		mediaType, params, err := mime.ParseMediaType(part)
+		if err != nil || mediaType != MediaType {
+			continue
+		}
+
+		found = true
+
+		// q is an accept parameter, not a parameter of the media type. A
+		// weight of 0 means that the instance is not acceptable.
+		if q, err := strconv.ParseFloat(params["q"], 64); err == nil && q == 0 {
+			reason = "The media type is not acceptable."
+			continue
+		}
+
+		delete(params, "q")
+
+		if name := unknownMediaTypeParam(params); name != "" {
+			reason = fmt.Sprintf("The media type parameter %q is not allowed.", name)
+			continue
+		}
+
+		opts := newMediaTypeOptions(params)
+
+		if ext := firstUnsupported(opts.Ext, supportedExt); ext != "" {
+			reason = fmt.Sprintf("The extension %q is not supported.", ext)
+			continue
+		}
+
+		return opts, nil
+	}
+
+	if found {
+		return MediaTypeOptions{}, mediaTypeErr(NewErrNotAcceptable(), "Accept", reason)
+	}
+
+	return MediaTypeOptions{}, nil
+}
+
+// WriteContentType sets the Content-Type header of w to the JSON:API media type
+// with the given options. The Vary header is updated because the response
+// depends on the Accept header.
+func WriteContentType(w http.ResponseWriter, opts MediaTypeOptions) {
+	w.Header().Set("Content-Type", opts.String())
+	w.Header().Add("Vary", "Accept")
+}
+
+// newMediaTypeOptions returns the options found in the parameters of a media
+// type.
+func newMediaTypeOptions(params map[string]string) MediaTypeOptions {
+	var opts MediaTypeOptions
+
+	if ext := strings.Fields(params["ext"]); len(ext) > 0 {
+		opts.Ext = ext
+	}
+
+	if profile := strings.Fields(params["profile"]); len(profile) > 0 {
+		opts.Profile = profile
+	}
+
+	return opts
+}
+
+// mediaTypeErr sets the detail and the header source of e.
+func mediaTypeErr(e Error, header, detail string) Error {
+	e.Detail = detail
+	e.setSource(ErrorSource{Header: header})
+
+	return e
+}
+
+// unknownMediaTypeParam returns the name of the first parameter that is not
+// allowed in the JSON:API media type, or an empty string.
+func unknownMediaTypeParam(params map[string]string) string {
+	names := make([]string, 0, len(params))
+
+	for name := range params {
+		if name != "ext" && name != "profile" {
+			names = append(names, name)
+		}
+	}
+
+	if len(names) == 0 {
+		return ""
+	}
+
+	sort.Strings(names)
+
+	return names[0]
+}
+
+// firstUnsupported returns the first element of list that is not in supported.
+func firstUnsupported(list, supported []string) string {
+	for _, s := range list {
+		found := false
+
+		for _, s2 := range supported {
+			if s == s2 {
+				found = true
+				break
+			}
+		}
+
+		if !found {
+			return s
+		}
+	}
+
+	return ""
+}
+
+// splitMediaTypes splits the value of an Accept header into media ranges.
+// Commas found in quoted strings do not separate ranges.
+func splitMediaTypes(header string) []string {
+	var (
+		parts   []string
+		start   int
+		inQuote bool
+	)
+
+	for i := 0; i < len(header); i++ {
+		switch c := header[i]; {
+		case c == '\\' && inQuote:
+			i++
+		case c == '"':
+			inQuote = !inQuote
+		case c == ',' && !inQuote:
+			parts = append(parts, strings.TrimSpace(header[start:i]))
+			start = i + 1
+		}
+	}
+
+	return append(parts, strings.TrimSpace(header[start:]))
+}
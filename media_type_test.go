@@ -0,0 +1,127 @@
+package jsonapi_test
+
+import (
+	"net/http/httptest"
+	"testing"
+
+	. "github.com/mark-hartmann/jsonapi"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseMediaType(t *testing.T) {
+	assert := assert.New(t)
+
+	opts, err := ParseMediaType("application/vnd.api+json")
+	assert.NoError(err)
+	assert.Equal(MediaTypeOptions{}, opts)
+
+	opts, err = ParseMediaType(
+		`Application/VND.API+JSON; ext="https://a.com/ext1 https://a.com/ext2"; ` +
+			`profile="https://a.com/p"`,
+	)
+	assert.NoError(err)
+	assert.Equal(MediaTypeOptions{
+		Ext:     []string{"https://a.com/ext1", "https://a.com/ext2"},
+		Profile: []string{"https://a.com/p"},
+	}, opts)
+
+	for _, header := range []string{
+		"",
+		"application/json",
+		"application/vnd.api+json; charset=utf-8",
+		`application/vnd.api+json; ext="a`,
+	} {
+		_, err = ParseMediaType(header)
+
+		e, ok := err.(Error)
+		if assert.True(ok, header) {
+			assert.Equal("415", e.Status, header)
+			assert.Equal("Content-Type", e.Source["header"], header)
+		}
+	}
+}
+
+func TestNegotiateAccept(t *testing.T) {
+	assert := assert.New(t)
+
+	tests := []struct {
+		header   string
+		ext      []string
+		expected MediaTypeOptions
+		status   string
+	}{
+		{
+			header: "",
+		}, {
+			header: "*/*",
+		}, {
+			header: "text/html, application/json;q=0.9",
+		}, {
+			header: "application/vnd.api+json",
+		}, {
+			header: "application/vnd.api+json; q=0.5",
+		}, {
+			header:   `application/vnd.api+json; profile="https://a.com/p1,x https://a.com/p2"`,
+			expected: MediaTypeOptions{Profile: []string{"https://a.com/p1,x", "https://a.com/p2"}},
+		}, {
+			header: `application/vnd.api+json; ext="https://a.com/ext", ` +
+				`application/vnd.api+json; profile="https://a.com/p"`,
+			expected: MediaTypeOptions{Profile: []string{"https://a.com/p"}},
+		}, {
+			header:   `application/vnd.api+json; ext="https://a.com/ext"`,
+			ext:      []string{"https://a.com/ext"},
+			expected: MediaTypeOptions{Ext: []string{"https://a.com/ext"}},
+		}, {
+			header: `application/vnd.api+json; ext="https://a.com/ext"`,
+			status: "406",
+		}, {
+			header: "application/vnd.api+json; charset=utf-8, */*",
+			status: "406",
+		}, {
+			header: "application/vnd.api+json; q=0, */*",
+			status: "406",
+		}, {
+			header: `application/vnd.api+json; q=0.0; ext="https://a.com/ext", ` +
+				`application/vnd.api+json`,
+		},
+	}
+
+	for _, test := range tests {
+		opts, err := NegotiateAccept(test.header, test.ext...)
+
+		if test.status == "" {
+			assert.NoError(err, test.header)
+			assert.Equal(test.expected, opts, test.header)
+
+			continue
+		}
+
+		e, ok := err.(Error)
+		if assert.True(ok, test.header) {
+			assert.Equal(test.status, e.Status, test.header)
+			assert.Equal("Accept", e.Source["header"], test.header)
+			assert.NotEmpty(e.Detail, test.header)
+		}
+	}
+}
+
+func TestWriteContentType(t *testing.T) {
+	assert := assert.New(t)
+
+	w := httptest.NewRecorder()
+	WriteContentType(w, MediaTypeOptions{})
+	assert.Equal("application/vnd.api+json", w.Header().Get("Content-Type"))
+	assert.Equal("Accept", w.Header().Get("Vary"))
+
+	w = httptest.NewRecorder()
+	WriteContentType(w, MediaTypeOptions{
+		Ext:     []string{"https://a.com/ext"},
+		Profile: []string{"https://a.com/p1", "https://a.com/p2"},
+	})
+	assert.Equal(
+		`application/vnd.api+json; ext="https://a.com/ext"; `+
+			`profile="https://a.com/p1 https://a.com/p2"`,
+		w.Header().Get("Content-Type"),
+	)
+}
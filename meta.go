@@ -1,13 +1,36 @@
 package jsonapi
 
 import (
+	"encoding/json"
 	"fmt"
+	"math"
+	"sort"
 	"time"
 )
 
 // Meta holds meta information.
+//
+// Like any other map, it is marshaled with its keys sorted in alphabetical
+// order, so the output is deterministic.
+//
+// The values of a Meta map that was unmarshaled from JSON are of the types
+// chosen by the json package (float64 for numbers, map[string]interface{} for
+// objects, etc). The Lookup methods take care of the conversions.
 type Meta map[string]interface{}
 
+// MergePolicy defines what Meta.Merge does when a key exists in both maps.
+type MergePolicy int
+
+// Merge policies
+const (
+	// MergeOverwrite replaces the existing value by the new one.
+	MergeOverwrite MergePolicy = iota
+	// MergeKeep keeps the existing value.
+	MergeKeep
+	// MergeError makes Merge return an error.
+	MergeError
+)
+
 // Has reports whether the Meta map contains or not the given key.
 func (m Meta) Has(key string) bool {
 	_, ok := m[key]
@@ -54,6 +77,212 @@ func (m Meta) GetTime(key string) time.Time {
 	return t
 }
 
+// LookupString returns the string associated with the given key and whether it
+// was found. Unlike GetString, no conversion is made.
+func (m Meta) LookupString(key string) (string, bool) {
+	s, ok := m[key].(string)
+	return s, ok
+}
+
+// LookupInt returns the int associated with the given key and whether it was
+// found.
+//
+// All integer types are accepted, as well as float64 and json.Number values
+// that represent an integer, which is what the json package produces.
+func (m Meta) LookupInt(key string) (int, bool) {
+	switch v := m[key].(type) {
+	case int:
+		return v, true
+	case int8:
+		return int(v), true
+	case int16:
+		return int(v), true
+	case int32:
+		return int(v), true
+	case int64:
+		return int64ToInt(v)
+	case uint:
+		return uint64ToInt(uint64(v))
+	case uint8:
+		return int(v), true
+	case uint16:
+		return int(v), true
+	case uint32:
+		return uint64ToInt(uint64(v))
+	case uint64:
+		return uint64ToInt(v)
+	case float32:
+		return float64ToInt(float64(v))
+	case float64:
+		return float64ToInt(v)
+	case json.Number:
+		if i, err := v.Int64(); err == nil {
+			return int64ToInt(i)
+		}
+	}
+
+	return 0, false
+}
+
+// LookupFloat returns the float64 associated with the given key and whether it
+// was found. All integer and float types are accepted, as well as json.Number.
+func (m Meta) LookupFloat(key string) (float64, bool) {
+	switch v := m[key].(type) {
+	case float64:
+		return v, true
+	case float32:
+		return float64(v), true
+	case json.Number:
+		f, err := v.Float64()
+		return f, err == nil
+	}
+
+	if i, ok := m.LookupInt(key); ok {
+		return float64(i), true
+	}
+
+	return 0, false
+}
+
+// LookupBool returns the bool associated with the given key and whether it was
+// found.
+func (m Meta) LookupBool(key string) (bool, bool) {
+	b, ok := m[key].(bool)
+	return b, ok
+}
+
+// LookupTime returns the time.Time associated with the given key and whether it
+// was found. The value can be a time.Time or a string that can be parsed with
+// time.RFC3339Nano.
+func (m Meta) LookupTime(key string) (time.Time, bool) {
+	switch v := m[key].(type) {
+	case time.Time:
+		return v, true
+	case string:
+		t, err := time.Parse(time.RFC3339Nano, v)
+		return t, err == nil
+	}
+
+	return time.Time{}, false
+}
+
+// LookupMeta returns the object associated with the given key as a Meta map and
+// whether it was found.
+func (m Meta) LookupMeta(key string) (Meta, bool) {
+	switch v := m[key].(type) {
+	case Meta:
+		return v, true
+	case map[string]interface{}:
+		return v, true
+	}
+
+	return nil, false
+}
+
+// Merge copies the values of src into m.
+//
+// Objects (Meta or map[string]interface{} values) found under the same key in
+// both maps are merged recursively into a new Meta value, so the maps
+// themselves are not modified. For any other value, the policy decides which
+// value is kept. With MergeError, an error is returned for the first conflict
+// found and m is left untouched.
+func (m Meta) Merge(src Meta, policy MergePolicy) error {
+	if policy == MergeError {
+		if key := m.conflict(src, ""); key != "" {
+			return fmt.Errorf("jsonapi: meta key %q is already set", key)
+		}
+	}
+
+	m.merge(src, policy)
+
+	return nil
+}
+
+func (m Meta) merge(src Meta, policy MergePolicy) {
+	for key, val := range src {
+		if _, ok := m[key]; !ok {
+			m[key] = val
+			continue
+		}
+
+		curObj, ok1 := m.LookupMeta(key)
+		valObj, ok2 := src.LookupMeta(key)
+
+		if ok1 && ok2 {
+			obj := make(Meta, len(curObj)+len(valObj))
+			obj.merge(curObj, MergeOverwrite)
+			obj.merge(valObj, policy)
+			m[key] = obj
+
+			continue
+		}
+
+		if policy == MergeOverwrite {
+			m[key] = val
+		}
+	}
+}
+
+// conflict returns the path of the first key of src (in alphabetical order)
+// that would conflict with a value of m, or an empty string.
+func (m Meta) conflict(src Meta, prefix string) string {
+	keys := make([]string, 0, len(src))
+	for key := range src {
+		keys = append(keys, key)
+	}
+
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		if _, ok := m[key]; !ok {
+			continue
+		}
+
+		curObj, ok1 := m.LookupMeta(key)
+		valObj, ok2 := src.LookupMeta(key)
+
+		if ok1 && ok2 {
+			if path := curObj.conflict(valObj, prefix+key+"."); path != "" {
+				return path
+			}
+
+			continue
+		}
+
+		return prefix + key
+	}
+
+	return ""
+}
+
+// int64ToInt converts i to an int and reports whether it fits.
+func int64ToInt(i int64) (int, bool) {
+	if int64(int(i)) != i {
+		return 0, false
+	}
+
+	return int(i), true
+}
+
+// uint64ToInt converts u to an int and reports whether it fits.
+func uint64ToInt(u uint64) (int, bool) {
+	if u > math.MaxInt64 {
+		return 0, false
+	}
+
+	return int64ToInt(int64(u))
+}
+
+// float64ToInt converts f to an int and reports whether it is an integer that
+// fits.
+func float64ToInt(f float64) (int, bool) {
+	if f != math.Trunc(f) || f < math.MinInt64 || f >= math.MaxInt64 {
+		return 0, false
+	}
+
+	return int64ToInt(int64(f))
+}
+
 // A MetaHolder can hold and return meta values.
 //
 // It is useful for a struct that represents a resource type to implement this
@@ -64,3 +293,32 @@ type MetaHolder interface {
 	Meta() Meta
 	SetMeta(Meta)
 }
+
+// A RelMetaHolder can hold the meta values of its relationship objects.
+//
+// The unmarshaling functions pass the meta object of each relationship found in
+// a payload to resources implementing this interface, and the meta values are
+// added to the relationship objects when the resource is marshaled. The meta of
+// a RelData or a RelDataMany value takes precedence.
+//
+// RelMeta returns nil if the relationship has no meta values.
+type RelMetaHolder interface {
+	RelMeta(rel string) Meta
+	SetRelMeta(rel string, meta Meta)
+}
+
+// A RelCountHolder knows the number of resources in its to-many relationships,
+// even when their linkage is not loaded.
+//
+// When a resource implementing this interface is marshaled, the count of each
+// to-many relationship is written as the "count" member of the meta object of
+// the relationship, whether its linkage is written or not (see the relData
+// argument of the marshaling functions). The unmarshaling functions read the
+// count back from the meta object of each to-many relationship when it is a
+// non-negative integer.
+//
+// RelCount returns false if the count of the relationship is unknown.
+type RelCountHolder interface {
+	RelCount(rel string) (int, bool)
+	SetRelCount(rel string, n int)
+}
@@ -1,6 +1,8 @@
 package jsonapi_test
 
 import (
+	"encoding/json"
+	"math"
 	"testing"
 	"time"
 
@@ -90,3 +92,174 @@ func TestMeta(t *testing.T) {
 	assert.Equal(time.Time{}, meta.GetTime("bool"))
 	assert.Equal(tm, meta.GetTime("time"))
 }
+
+func TestMetaLookup(t *testing.T) {
+	assert := assert.New(t)
+
+	tm, _ := time.Parse(time.RFC3339Nano, "2012-05-16T17:45:28.2539Z")
+
+	meta := jsonapi.Meta{}
+	err := json.Unmarshal([]byte(`{
+		"string": "str",
+		"int": 12,
+		"float": 1.5,
+		"big": 1e300,
+		"bool": true,
+		"time": "2012-05-16T17:45:28.2539Z",
+		"obj": {"key": "val"},
+		"null": null
+	}`), &meta)
+	assert.NoError(err)
+
+	meta["int8"] = int8(-8)
+	meta["uint64"] = uint64(64)
+	meta["overflow"] = uint64(math.MaxUint64)
+	meta["number"] = json.Number("42")
+	meta["time.Time"] = tm
+	meta["meta"] = jsonapi.Meta{"key": "val"}
+
+	s, ok := meta.LookupString("string")
+	assert.True(ok)
+	assert.Equal("str", s)
+
+	_, ok = meta.LookupString("int")
+	assert.False(ok)
+	_, ok = meta.LookupString("unknown")
+	assert.False(ok)
+
+	for key, expected := range map[string]int{
+		"int":    12,
+		"int8":   -8,
+		"uint64": 64,
+		"number": 42,
+	} {
+		i, ok := meta.LookupInt(key)
+		assert.True(ok, key)
+		assert.Equal(expected, i, key)
+	}
+
+	for _, key := range []string{"float", "big", "overflow", "string", "null", "unknown"} {
+		i, ok := meta.LookupInt(key)
+		assert.False(ok, key)
+		assert.Equal(0, i, key)
+	}
+
+	f, ok := meta.LookupFloat("float")
+	assert.True(ok)
+	assert.Equal(1.5, f)
+
+	f, ok = meta.LookupFloat("int8")
+	assert.True(ok)
+	assert.Equal(-8.0, f)
+
+	f, ok = meta.LookupFloat("number")
+	assert.True(ok)
+	assert.Equal(42.0, f)
+
+	_, ok = meta.LookupFloat("string")
+	assert.False(ok)
+
+	b, ok := meta.LookupBool("bool")
+	assert.True(ok)
+	assert.True(b)
+
+	_, ok = meta.LookupBool("null")
+	assert.False(ok)
+
+	tm2, ok := meta.LookupTime("time")
+	assert.True(ok)
+	assert.True(tm.Equal(tm2))
+
+	tm2, ok = meta.LookupTime("time.Time")
+	assert.True(ok)
+	assert.Equal(tm, tm2)
+
+	_, ok = meta.LookupTime("string")
+	assert.False(ok)
+
+	obj, ok := meta.LookupMeta("obj")
+	assert.True(ok)
+	assert.Equal(jsonapi.Meta{"key": "val"}, obj)
+
+	obj, ok = meta.LookupMeta("meta")
+	assert.True(ok)
+	assert.Equal(jsonapi.Meta{"key": "val"}, obj)
+
+	_, ok = meta.LookupMeta("string")
+	assert.False(ok)
+}
+
+func TestMetaMerge(t *testing.T) {
+	newMeta := func() jsonapi.Meta {
+		return jsonapi.Meta{
+			"a": 1,
+			"b": map[string]interface{}{
+				"c": 2,
+				"d": 3,
+			},
+			"e": 4,
+		}
+	}
+
+	src := jsonapi.Meta{
+		"a": 10,
+		"b": jsonapi.Meta{
+			"d": 30,
+			"f": 50,
+		},
+		"g": 60,
+	}
+
+	t.Run("overwrite", func(t *testing.T) {
+		meta := newMeta()
+		assert.NoError(t, meta.Merge(src, jsonapi.MergeOverwrite))
+		assert.Equal(t, jsonapi.Meta{
+			"a": 10,
+			"b": jsonapi.Meta{"c": 2, "d": 30, "f": 50},
+			"e": 4,
+			"g": 60,
+		}, meta)
+	})
+
+	t.Run("keep", func(t *testing.T) {
+		meta := newMeta()
+		assert.NoError(t, meta.Merge(src, jsonapi.MergeKeep))
+		assert.Equal(t, jsonapi.Meta{
+			"a": 1,
+			"b": jsonapi.Meta{"c": 2, "d": 3, "f": 50},
+			"e": 4,
+			"g": 60,
+		}, meta)
+	})
+
+	t.Run("error", func(t *testing.T) {
+		meta := newMeta()
+		err := meta.Merge(jsonapi.Meta{
+			"b": jsonapi.Meta{"d": 30},
+			"z": 0,
+		}, jsonapi.MergeError)
+		assert.EqualError(t, err, `jsonapi: meta key "b.d" is already set`)
+		assert.Equal(t, newMeta(), meta)
+
+		meta = newMeta()
+		err = meta.Merge(jsonapi.Meta{
+			"b": jsonapi.Meta{"f": 50},
+			"g": 60,
+		}, jsonapi.MergeError)
+		assert.NoError(t, err)
+		assert.Equal(t, jsonapi.Meta{
+			"a": 1,
+			"b": jsonapi.Meta{"c": 2, "d": 3, "f": 50},
+			"e": 4,
+			"g": 60,
+		}, meta)
+	})
+
+	// The source and the nested maps are not modified.
+	assert.Equal(t, jsonapi.Meta{"d": 30, "f": 50}, src["b"])
+
+	// Deterministic marshaling
+	pl, err := json.Marshal(jsonapi.Meta{"b": 1, "c": jsonapi.Meta{"z": 1, "a": 2}, "a": 3})
+	assert.NoError(t, err)
+	assert.Equal(t, `{"a":3,"b":1,"c":{"a":2,"z":1}}`, string(pl))
+}
@@ -0,0 +1,64 @@
+package jsonapi
+
+import (
+	"context"
+	"net/http"
+)
+
+// urlKey is the context key under which URLMiddleware stores the URL.
+type urlKey struct{}
+
+// URLMiddleware returns a middleware that parses the URL of each request with
+// schema and stores the result in the context of the request, where handlers
+// find it with URLFromContext and ParamsFromContext.
+//
+// A request whose URL cannot be parsed does not reach the next handler. A
+// document holding the corresponding Error is written instead, as returned by
+// an empty ErrorMapper: 404 Not Found for an unknown path and 400 Bad Request
+// for an invalid query parameter.
+//
+// The middleware only deals with the URL. Use ParseRequest to also negotiate
+// the media types and unmarshal the body.
+func URLMiddleware(schema *Schema) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			su, err := NewSimpleURL(r.URL)
+
+			var url *URL
+			if err == nil {
+				url, err = NewURL(schema, su)
+			}
+
+			if err != nil {
+				_ = WriteError(w, (&ErrorMapper{}).Map(err))
+				return
+			}
+
+			next.ServeHTTP(w, r.WithContext(ContextWithURL(r.Context(), url)))
+		})
+	}
+}
+
+// ContextWithURL returns a copy of ctx that holds url, like the contexts of the
+// requests handled by URLMiddleware.
+func ContextWithURL(ctx context.Context, url *URL) context.Context {
+	return context.WithValue(ctx, urlKey{}, url)
+}
+
+// URLFromContext returns the URL stored in ctx by URLMiddleware or
+// ContextWithURL and whether there is one.
+func URLFromContext(ctx context.Context) (*URL, bool) {
+	url, ok := ctx.Value(urlKey{}).(*URL)
+	return url, ok && url != nil
+}
+
+// ParamsFromContext returns the parameters of the URL stored in ctx by
+// URLMiddleware or ContextWithURL and whether there is one.
+func ParamsFromContext(ctx context.Context) (*Params, bool) {
+	url, ok := URLFromContext(ctx)
+	if !ok || url.Params == nil {
+		return nil, false
+	}
+
+	return url.Params, true
+}
@@ -0,0 +1,69 @@
+package jsonapi_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	. "github.com/mark-hartmann/jsonapi"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestURLMiddleware(t *testing.T) {
+	assert := assert.New(t)
+
+	var (
+		got    *URL
+		params *Params
+	)
+
+	handler := URLMiddleware(newMockSchema())(http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+			got, _ = URLFromContext(r.Context())
+			params, _ = ParamsFromContext(r.Context())
+			w.WriteHeader(http.StatusNoContent)
+		},
+	))
+
+	// Valid URL
+	req := httptest.NewRequest(http.MethodGet, "/mocktypes1/mt1?fields[mocktypes1]=str", nil)
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+	assert.Equal(http.StatusNoContent, rec.Code)
+
+	if assert.NotNil(got) && assert.NotNil(params) {
+		assert.Equal("mocktypes1", got.ResType)
+		assert.Equal("mt1", got.ResID)
+		assert.Equal([]string{"str"}, params.Fields["mocktypes1"])
+		assert.Same(got.Params, params)
+	}
+
+	// Unknown type
+	got = nil
+	req = httptest.NewRequest(http.MethodGet, "/unknown", nil)
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+	assert.Nil(got)
+	assert.Equal(http.StatusNotFound, rec.Code)
+	assert.Equal(MediaType, rec.Header().Get("Content-Type"))
+	assert.Contains(rec.Body.String(), `"errors"`)
+
+	// Invalid parameter
+	req = httptest.NewRequest(http.MethodGet, "/mocktypes1?fields[mocktypes1]=unknown", nil)
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+	assert.Nil(got)
+	assert.Equal(http.StatusBadRequest, rec.Code)
+	assert.Contains(rec.Body.String(), `"parameter":"fields"`)
+
+	// Empty context
+	_, ok := URLFromContext(context.Background())
+	assert.False(ok)
+	_, ok = ParamsFromContext(context.Background())
+	assert.False(ok)
+
+	_, ok = URLFromContext(ContextWithURL(context.Background(), nil))
+	assert.False(ok)
+}
@@ -130,15 +130,36 @@ func newMockSchema() *Schema {
 	typ = MustBuildType(mockType3{})
 	_ = schema.AddType(typ)
 
-	for t, typ := range schema.Types {
-		for r, rel := range typ.Rels {
-			invType := schema.GetType(rel.ToType)
-			rel := schema.Types[t].Rels[r]
-			rel.FromOne = invType.Rels[rel.ToName].ToOne
-			schema.Types[t].Rels[r] = rel
-		}
+	schema.ResolveRels()
+
+	errs := schema.Check()
+	if len(errs) > 0 {
+		panic(errs[0])
 	}
 
+	return schema
+}
+
+// newPeopleSchema returns a schema with a single type whose relationships are
+// self-referential: manager and reports are inverses of each other and friends
+// is its own inverse.
+func newPeopleSchema() *Schema {
+	schema := &Schema{}
+	schema.MustAddType(Type{Name: "people"})
+	schema.MustAddTwoWayRel(Rel{
+		FromType: "people",
+		FromName: "manager",
+		ToOne:    true,
+		ToType:   "people",
+		ToName:   "reports",
+	})
+	schema.MustAddTwoWayRel(Rel{
+		FromType: "people",
+		FromName: "friends",
+		ToType:   "people",
+		ToName:   "friends",
+	})
+
 	errs := schema.Check()
 	if len(errs) > 0 {
 		panic(errs[0])
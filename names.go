@@ -0,0 +1,164 @@
+package jsonapi
+
+import (
+	"sort"
+	"strings"
+	"unicode"
+)
+
+// A NameMapper returns the member name used in payloads for the attribute or
+// relationship named name.
+//
+// A mapper must return a different member name for each field of a type, so
+// that the payloads can be mapped back.
+type NameMapper func(name string) string
+
+// KebabCase is a NameMapper that returns names like "created-at".
+func KebabCase(name string) string {
+	return strings.Join(lowerWords(name), "-")
+}
+
+// SnakeCase is a NameMapper that returns names like "created_at".
+func SnakeCase(name string) string {
+	return strings.Join(lowerWords(name), "_")
+}
+
+// CamelCase is a NameMapper that returns names like "createdAt".
+func CamelCase(name string) string {
+	words := lowerWords(name)
+
+	for i := 1; i < len(words); i++ {
+		r := []rune(words[i])
+		r[0] = unicode.ToUpper(r[0])
+		words[i] = string(r)
+	}
+
+	return strings.Join(words, "")
+}
+
+// lowerWords splits name into lowercase words. The words are separated by
+// dashes, underscores, or a change of case like in "createdAt" or "HTTPCode".
+// Digits belong to the word they follow.
+func lowerWords(name string) []string {
+	var (
+		words []string
+		word  []rune
+	)
+
+	runes := []rune(name)
+
+	for i, r := range runes {
+		if r == '-' || r == '_' {
+			if len(word) > 0 {
+				words = append(words, string(word))
+				word = nil
+			}
+
+			continue
+		}
+
+		if unicode.IsUpper(r) && len(word) > 0 {
+			prev := runes[i-1]
+			nextIsLower := i+1 < len(runes) && unicode.IsLower(runes[i+1])
+
+			if !unicode.IsUpper(prev) || nextIsLower {
+				words = append(words, string(word))
+				word = nil
+			}
+		}
+
+		word = append(word, unicode.ToLower(r))
+	}
+
+	if len(word) > 0 {
+		words = append(words, string(word))
+	}
+
+	return words
+}
+
+// memberName returns the member name of the field named name according to the
+// NameMapper of the schema, if any. s can be nil.
+func (s *Schema) memberName(name string) string {
+	if s == nil || s.NameMapper == nil {
+		return name
+	}
+
+	return s.NameMapper(name)
+}
+
+// sortByMemberName sorts names, the names of fields, by the member names they
+// are written under according to the NameMapper of the schema. s can be nil.
+func (s *Schema) sortByMemberName(names []string) {
+	if s == nil || s.NameMapper == nil {
+		sort.Strings(names)
+		return
+	}
+
+	sort.Slice(names, func(i, j int) bool {
+		return s.NameMapper(names[i]) < s.NameMapper(names[j])
+	})
+}
+
+// fieldName returns a function that returns the name of the attribute or
+// relationship of typ whose member name is member according to the NameMapper
+// of the schema, or an empty string if there is none. Without a NameMapper, the
+// function returns member. s can be nil.
+func (s *Schema) fieldName(typ Type) func(member string) string {
+	if s == nil || s.NameMapper == nil {
+		return func(member string) string { return member }
+	}
+
+	names := make(map[string]string, len(typ.Attrs)+len(typ.Rels))
+
+	for name := range typ.Attrs {
+		names[s.NameMapper(name)] = name
+	}
+
+	for name := range typ.Rels {
+		names[s.NameMapper(name)] = name
+	}
+
+	return func(member string) string { return names[member] }
+}
+
+// fieldNameOrMember returns a function like the one returned by fieldName,
+// except that member is returned as is if no field of typ has it as member
+// name, like for "id", as long as it is not the name of a field whose member
+// name is different. s can be nil.
+func (s *Schema) fieldNameOrMember(typ Type) func(member string) string {
+	if s == nil || s.NameMapper == nil {
+		return func(member string) string { return member }
+	}
+
+	fieldName := s.fieldName(typ)
+
+	return func(member string) string {
+		if name := fieldName(member); name != "" {
+			return name
+		}
+
+		if _, ok := typ.Attrs[member]; ok {
+			return ""
+		}
+
+		if _, ok := typ.Rels[member]; ok {
+			return ""
+		}
+
+		return member
+	}
+}
+
+// memberNames returns a function that returns the member name of the field
+// named name according to names, or name itself if names is nil or name is
+// "id".
+func memberNames(names NameMapper) func(name string) string {
+	return func(name string) string {
+		if names == nil || name == "id" {
+			return name
+		}
+
+		return names(name)
+	}
+}
@@ -0,0 +1,148 @@
+package jsonapi_test
+
+import (
+	"strings"
+	"testing"
+
+	. "github.com/mark-hartmann/jsonapi"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNameMappers(t *testing.T) {
+	assert := assert.New(t)
+
+	tests := []struct {
+		name, kebab, snake, camel string
+	}{
+		{"name", "name", "name", "name"},
+		{"created-at", "created-at", "created_at", "createdAt"},
+		{"created_at", "created-at", "created_at", "createdAt"},
+		{"createdAt", "created-at", "created_at", "createdAt"},
+		{"CreatedAt", "created-at", "created_at", "createdAt"},
+		{"HTTPCode", "http-code", "http_code", "httpCode"},
+		{"userID", "user-id", "user_id", "userId"},
+		{"address2-line", "address2-line", "address2_line", "address2Line"},
+		{"a--b", "a-b", "a_b", "aB"},
+	}
+
+	for _, test := range tests {
+		assert.Equal(test.kebab, KebabCase(test.name), test.name)
+		assert.Equal(test.snake, SnakeCase(test.name), test.name)
+		assert.Equal(test.camel, CamelCase(test.name), test.name)
+	}
+}
+
+func TestSchemaNameMapper(t *testing.T) {
+	assert := assert.New(t)
+
+	typ := Type{Name: "articles"}
+	typ.MustAddAttr(Attr{Name: "title", Type: AttrTypeString})
+	typ.MustAddAttr(Attr{Name: "created-at", Type: AttrTypeInt})
+	typ.MustAddRel(Rel{FromName: "main-author", ToType: "people", ToOne: true})
+
+	schema := &Schema{NameMapper: CamelCase}
+	schema.MustAddType(typ)
+	schema.MustAddType(Type{Name: "people"})
+
+	res := &SoftResource{Type: &typ}
+	res.SetID("a1")
+	res.Set("title", "Title")
+	res.Set("created-at", 10)
+	res.Set("main-author", "p1")
+
+	out := MarshalOptions{Schema: schema}.MarshalResource(res, "", typ.Fields(),
+		map[string][]string{"articles": {"main-author"}})
+	assert.Equal(`{"attributes":{"createdAt":10,"title":"Title"},"id":"a1",`+
+		`"links":{"self":"/articles/a1"},"relationships":{"mainAuthor":{`+
+		`"data":{"id":"p1","type":"people"},"links":{"related":"/articles/a1/mainAuthor",`+
+		`"self":"/articles/a1/relationships/mainAuthor"}}},"type":"articles"}`, string(out))
+
+	// Without a schema, the names are not mapped.
+	out = MarshalResource(res, "", []string{"created-at"}, nil)
+	assert.Contains(string(out), `"created-at":10`)
+
+	// Unmarshaling
+	res2, err := UnmarshalResource(out, schema)
+	assert.Error(err)
+	assert.Nil(res2)
+
+	payload := `{"id":"a1","type":"articles","attributes":{"createdAt":10,"title":"Title"},` +
+		`"relationships":{"mainAuthor":{"data":{"id":"p1","type":"people"}}}}`
+
+	res2, err = UnmarshalResource([]byte(payload), schema)
+	assert.NoError(err)
+	assert.True(EqualStrict(res, res2))
+
+	partial, err := UnmarshalPartialResource([]byte(payload), schema)
+	assert.NoError(err)
+	ptyp := partial.GetType()
+	assert.ElementsMatch([]string{"created-at", "main-author", "title"}, ptyp.Fields())
+
+	// The source of an error is the member name.
+	payload = `{"id":"a1","type":"articles","attributes":{"createdAt":"ten"}}`
+
+	_, err = UnmarshalDocument(strings.NewReader(`{"data":`+payload+`}`), schema)
+
+	var srcErr srcError
+	if assert.ErrorAs(err, &srcErr) {
+		src, _ := srcErr.Source()
+		assert.Equal("/data/attributes/createdAt", src)
+	}
+
+	// Subsets keep the mapper.
+	sub, err := schema.Subset("articles")
+	assert.NoError(err)
+
+	_, err = UnmarshalResource([]byte(`{"id":"a1","type":"articles",`+
+		`"attributes":{"createdAt":3}}`), sub)
+	assert.NoError(err)
+
+	// URLs
+	url, err := NewURLFromRaw(schema,
+		"/articles?fields[articles]=createdAt,mainAuthor&include=mainAuthor&sort=-createdAt")
+	assert.NoError(err)
+	assert.Equal([]string{"created-at", "main-author"}, url.Params.Fields["articles"])
+	assert.Equal("main-author", url.Params.Include[0][0].FromName)
+	assert.Equal("created-at", url.Params.SortRules[0].Name)
+	assert.Equal("/articles?fields[articles]=createdAt,mainAuthor&include=mainAuthor"+
+		"&sort=-createdAt", url.UnescapedString())
+
+	url, err = NewURLFromRaw(schema, "/articles/a1/relationships/mainAuthor")
+	assert.NoError(err)
+	assert.Equal("main-author", url.Rel.FromName)
+
+	// The names of the fields are not member names.
+	for _, raw := range []string{
+		"/articles?fields[articles]=created-at",
+		"/articles?include=main-author",
+		"/articles?sort=created-at",
+		"/articles/a1/main-author",
+	} {
+		_, err = NewURLFromRaw(schema, raw)
+		assert.Error(err, raw)
+	}
+}
+
+func TestSchemaNameMapperOrder(t *testing.T) {
+	assert := assert.New(t)
+
+	// "a-b" comes before "a0", but "a_b" comes after it.
+	typ := Type{Name: "things"}
+	typ.MustAddAttr(Attr{Name: "a-b", Type: AttrTypeInt})
+	typ.MustAddAttr(Attr{Name: "a0", Type: AttrTypeInt})
+	typ.MustAddRel(Rel{FromName: "b-c", ToType: "things", ToOne: true})
+	typ.MustAddRel(Rel{FromName: "b0", ToType: "things", ToOne: true})
+
+	schema := &Schema{NameMapper: SnakeCase}
+	schema.MustAddType(typ)
+
+	res := &SoftResource{Type: &typ}
+	res.SetID("t1")
+
+	out := MarshalOptions{Schema: schema, RelLinks: RelLinksNone}.MarshalResource(res, "",
+		typ.Fields(), map[string][]string{"things": {"b-c", "b0"}})
+	assert.Equal(`{"attributes":{"a0":0,"a_b":0},"id":"t1","links":{"self":"/things/t1"},`+
+		`"relationships":{"b0":{"data":null},"b_c":{"data":null}},"type":"things"}`,
+		string(out))
+}
@@ -0,0 +1,232 @@
+package jsonapi
+
+import (
+	"encoding/json"
+	"io"
+	"math"
+)
+
+// OpenAPIVersion is the version of the OpenAPI specification followed by
+// WriteOpenAPI.
+const OpenAPIVersion = "3.0.3"
+
+// OpenAPISchemas returns the OpenAPI schema objects that describe the resource
+// objects of the types of the schema, keyed by type name. They are meant to be
+// used as the components of an OpenAPI document (see WriteOpenAPI).
+//
+// The attributes and the relationships are written under their member names
+// (see Schema.NameMapper) and carry their documentation: Description, Example
+// and Deprecated. An example that is valid JSON is decoded, unless it is the
+// example of a string, so that it has the type of the value it illustrates.
+//
+// The attributes of type AttrTypeJSON, of a type that was not registered by
+// this package, or that have a Marshaler, can hold any value.
+func (s *Schema) OpenAPISchemas() map[string]interface{} {
+	schemas := make(map[string]interface{}, len(s.Types))
+
+	for _, typ := range s.Types {
+		props := map[string]interface{}{
+			"type":  map[string]interface{}{"type": "string", "enum": []string{typ.Name}},
+			"id":    map[string]interface{}{"type": "string"},
+			"links": map[string]interface{}{"type": "object"},
+			"meta":  map[string]interface{}{"type": "object"},
+		}
+
+		if len(typ.Attrs) > 0 {
+			attrs := make(map[string]interface{}, len(typ.Attrs))
+			for _, attr := range typ.Attrs {
+				attrs[s.memberName(attr.Name)] = openAPIAttr(attr)
+			}
+
+			props["attributes"] = map[string]interface{}{"type": "object", "properties": attrs}
+		}
+
+		if len(typ.Rels) > 0 {
+			rels := make(map[string]interface{}, len(typ.Rels))
+			for _, rel := range typ.Rels {
+				rels[s.memberName(rel.FromName)] = openAPIRel(rel)
+			}
+
+			props["relationships"] = map[string]interface{}{"type": "object", "properties": rels}
+		}
+
+		sch := map[string]interface{}{
+			"type":       "object",
+			"required":   []string{"type", "id"},
+			"properties": props,
+		}
+		setOpenAPIDoc(sch, typ.Description, typ.Example, typ.Deprecated)
+
+		schemas[typ.Name] = sch
+	}
+
+	return schemas
+}
+
+// WriteOpenAPI writes to w an OpenAPI document in JSON whose components are the
+// schema objects of the types of schema (see Schema.OpenAPISchemas). title and
+// version are the ones of the API. The document has no paths, they can be
+// added by the caller.
+func WriteOpenAPI(w io.Writer, schema *Schema, title, version string) error {
+	doc := openAPIDocument{
+		OpenAPI: OpenAPIVersion,
+		Paths:   map[string]interface{}{},
+	}
+	doc.Info.Title = title
+	doc.Info.Version = version
+	doc.Components.Schemas = schema.OpenAPISchemas()
+
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+
+	return enc.Encode(doc)
+}
+
+// openAPIDocument is the document written by WriteOpenAPI.
+type openAPIDocument struct {
+	OpenAPI string `json:"openapi"`
+	Info    struct {
+		Title   string `json:"title"`
+		Version string `json:"version"`
+	} `json:"info"`
+	Paths      map[string]interface{} `json:"paths"`
+	Components struct {
+		Schemas map[string]interface{} `json:"schemas"`
+	} `json:"components"`
+}
+
+// openAPIInts holds the format and the bounds of the integer attribute types
+// whose bounds can be represented exactly by a JSON number.
+var openAPIInts = map[int]struct {
+	format   string
+	min, max float64
+}{
+	AttrTypeInt8:   {"int32", math.MinInt8, math.MaxInt8},
+	AttrTypeInt16:  {"int32", math.MinInt16, math.MaxInt16},
+	AttrTypeInt32:  {"int32", math.MinInt32, math.MaxInt32},
+	AttrTypeUint8:  {"int32", 0, math.MaxUint8},
+	AttrTypeUint16: {"int32", 0, math.MaxUint16},
+	AttrTypeUint32: {"int64", 0, math.MaxUint32},
+}
+
+// openAPIAttr returns the schema object of the values of attr.
+func openAPIAttr(attr Attr) map[string]interface{} {
+	sch := map[string]interface{}{}
+
+	switch attr.Type {
+	case AttrTypeString:
+		sch["type"] = "string"
+	case AttrTypeInt8, AttrTypeInt16, AttrTypeInt32,
+		AttrTypeUint8, AttrTypeUint16, AttrTypeUint32:
+		i := openAPIInts[attr.Type]
+		sch["type"], sch["format"] = "integer", i.format
+		sch["minimum"], sch["maximum"] = i.min, i.max
+	case AttrTypeInt, AttrTypeInt64:
+		sch["type"], sch["format"] = "integer", "int64"
+	case AttrTypeUint, AttrTypeUint64:
+		sch["type"], sch["format"] = "integer", "int64"
+		sch["minimum"] = 0
+	case AttrTypeFloat32:
+		sch["type"], sch["format"] = "number", "float"
+	case AttrTypeFloat64:
+		sch["type"], sch["format"] = "number", "double"
+	case AttrTypeBool:
+		sch["type"] = "boolean"
+	case AttrTypeTime:
+		switch attr.Format {
+		case TimeFormatDate:
+			sch["type"], sch["format"] = "string", "date"
+		case TimeFormatUnix, TimeFormatUnixMilli:
+			sch["type"], sch["format"] = "integer", "int64"
+		default:
+			sch["type"], sch["format"] = "string", "date-time"
+		}
+	case AttrTypeBytes:
+		sch["type"], sch["format"] = "string", "byte"
+	case AttrTypeDecimal:
+		sch["type"], sch["format"] = "string", "decimal"
+	case AttrTypeUUID:
+		sch["type"], sch["format"] = "string", "uuid"
+	case AttrTypeDuration:
+		sch["type"] = "string"
+		if attr.Format == DurationFormatISO8601 {
+			sch["format"] = "duration"
+		}
+	}
+
+	if attr.Marshaler != nil {
+		// The values can be encoded in any way.
+		sch = map[string]interface{}{}
+	}
+
+	if attr.Array {
+		sch = map[string]interface{}{"type": "array", "items": sch}
+	}
+
+	if attr.Nullable {
+		sch["nullable"] = true
+	}
+
+	if attr.ReadOnly {
+		sch["readOnly"] = true
+	}
+
+	setOpenAPIDoc(sch, attr.Description, attr.Example, attr.Deprecated)
+
+	return sch
+}
+
+// openAPIRel returns the schema object of the relationship object of rel.
+func openAPIRel(rel Rel) map[string]interface{} {
+	var data map[string]interface{}
+
+	ident := map[string]interface{}{
+		"type":     "object",
+		"required": []string{"type", "id"},
+		"properties": map[string]interface{}{
+			"type": map[string]interface{}{"type": "string", "enum": []string{rel.ToType}},
+			"id":   map[string]interface{}{"type": "string"},
+			"meta": map[string]interface{}{"type": "object"},
+		},
+	}
+
+	if rel.ToOne {
+		data = ident
+		data["nullable"] = true
+	} else {
+		data = map[string]interface{}{"type": "array", "items": ident}
+	}
+
+	sch := map[string]interface{}{
+		"type": "object",
+		"properties": map[string]interface{}{
+			"data":  data,
+			"links": map[string]interface{}{"type": "object"},
+			"meta":  map[string]interface{}{"type": "object"},
+		},
+	}
+	setOpenAPIDoc(sch, rel.Description, rel.Example, rel.Deprecated)
+
+	return sch
+}
+
+// setOpenAPIDoc sets the documentation of the schema object sch. The example
+// is decoded if it is valid JSON, unless sch describes a string.
+func setOpenAPIDoc(sch map[string]interface{}, desc, example string, deprecated bool) {
+	if desc != "" {
+		sch["description"] = desc
+	}
+
+	if example != "" {
+		var v interface{}
+		if sch["type"] != "string" && json.Unmarshal([]byte(example), &v) == nil {
+			sch["example"] = v
+		} else {
+			sch["example"] = example
+		}
+	}
+
+	if deprecated {
+		sch["deprecated"] = true
+	}
+}
@@ -0,0 +1,154 @@
+package jsonapi_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+
+	. "github.com/mark-hartmann/jsonapi"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWriteOpenAPI(t *testing.T) {
+	assert := assert.New(t)
+
+	articles := Type{
+		Name:        "articles",
+		Description: "A blog post.",
+		Example:     `{"type":"articles","id":"a1"}`,
+	}
+	articles.MustAddAttr(Attr{
+		Name:        "title",
+		Type:        AttrTypeString,
+		Description: "The title.",
+		Example:     "123",
+	})
+	articles.MustAddAttr(Attr{Name: "views", Type: AttrTypeUint8, ReadOnly: true})
+	articles.MustAddAttr(Attr{Name: "scores", Type: AttrTypeFloat64, Array: true, Example: "[1.5]"})
+	articles.MustAddAttr(Attr{
+		Name:     "publishedAt",
+		Type:     AttrTypeTime,
+		Format:   TimeFormatDate,
+		Nullable: true,
+	})
+	articles.MustAddAttr(Attr{Name: "extra", Type: AttrTypeJSON})
+	articles.MustAddRel(Rel{
+		FromType:    "articles",
+		FromName:    "author",
+		ToType:      "people",
+		ToOne:       true,
+		Description: "The author.",
+	})
+	articles.MustAddRel(Rel{
+		FromType:   "articles",
+		FromName:   "comments",
+		ToType:     "comments",
+		Deprecated: true,
+	})
+
+	schema := &Schema{NameMapper: KebabCase}
+	schema.MustAddType(articles)
+	schema.MustAddType(Type{Name: "people", Deprecated: true})
+
+	buf := &bytes.Buffer{}
+	assert.NoError(WriteOpenAPI(buf, schema, "Blog", "1.0"))
+
+	var doc map[string]interface{}
+	assert.NoError(json.Unmarshal(buf.Bytes(), &doc))
+
+	assert.Equal(OpenAPIVersion, doc["openapi"])
+	assert.Equal(map[string]interface{}{"title": "Blog", "version": "1.0"}, doc["info"])
+	assert.Equal(map[string]interface{}{}, doc["paths"])
+
+	schemas := doc["components"].(map[string]interface{})["schemas"].(map[string]interface{})
+	assert.Len(schemas, 2)
+
+	// A type without fields
+	people, _ := json.Marshal(schemas["people"])
+	assert.JSONEq(`{
+		"type": "object",
+		"required": ["type", "id"],
+		"deprecated": true,
+		"properties": {
+			"type": {"type": "string", "enum": ["people"]},
+			"id": {"type": "string"},
+			"links": {"type": "object"},
+			"meta": {"type": "object"}
+		}
+	}`, string(people))
+
+	// Attributes
+	sch := schemas["articles"].(map[string]interface{})
+	assert.Equal("A blog post.", sch["description"])
+	assert.Equal(map[string]interface{}{"type": "articles", "id": "a1"}, sch["example"])
+
+	props := sch["properties"].(map[string]interface{})
+	attrs, _ := json.Marshal(props["attributes"])
+	assert.JSONEq(`{
+		"type": "object",
+		"properties": {
+			"title": {"type": "string", "description": "The title.", "example": "123"},
+			"views": {
+				"type": "integer",
+				"format": "int32",
+				"minimum": 0,
+				"maximum": 255,
+				"readOnly": true
+			},
+			"scores": {
+				"type": "array",
+				"items": {"type": "number", "format": "double"},
+				"example": [1.5]
+			},
+			"published-at": {"type": "string", "format": "date", "nullable": true},
+			"extra": {}
+		}
+	}`, string(attrs))
+
+	// Relationships
+	rels, _ := json.Marshal(props["relationships"])
+	assert.JSONEq(`{
+		"type": "object",
+		"properties": {
+			"author": {
+				"type": "object",
+				"description": "The author.",
+				"properties": {
+					"data": {
+						"type": "object",
+						"nullable": true,
+						"required": ["type", "id"],
+						"properties": {
+							"type": {"type": "string", "enum": ["people"]},
+							"id": {"type": "string"},
+							"meta": {"type": "object"}
+						}
+					},
+					"links": {"type": "object"},
+					"meta": {"type": "object"}
+				}
+			},
+			"comments": {
+				"type": "object",
+				"deprecated": true,
+				"properties": {
+					"data": {
+						"type": "array",
+						"items": {
+							"type": "object",
+							"required": ["type", "id"],
+							"properties": {
+								"type": {"type": "string", "enum": ["comments"]},
+								"id": {"type": "string"},
+								"meta": {"type": "object"}
+							}
+						}
+					},
+					"links": {"type": "object"},
+					"meta": {"type": "object"}
+				}
+			}
+		}
+	}`, string(rels))
+}
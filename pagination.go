@@ -0,0 +1,284 @@
+package jsonapi
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+)
+
+// maxInt is the largest value of an int.
+const maxInt = int(^uint(0) >> 1)
+
+// Pagination holds the information needed to build the pagination links (first,
+// prev, next, and last) of a document whose primary data is a page of a
+// collection.
+//
+// JSON:API is agnostic about the pagination strategy. The links are built for
+// the two most common ones, based on the page parameters of the URL:
+//
+//	page[number] and page[size] (numbers start at 1)
+//	page[offset] and page[limit] (offsets start at 0)
+//
+// If the URL has no page parameters, page[number] and page[size] are used with
+// Size as the page size.
+type Pagination struct {
+	// Total is the total number of resources in the collection.
+	Total int
+
+	// Size is the page size used when the URL does not specify one.
+	Size int
+}
+
+// links returns the pagination links for the collection described by url. The
+// URLs of the pages are turned into links with href. No links are returned if
+// the page parameters are invalid or if the size of the pages is unknown.
+func (p Pagination) links(url *URL, href func(*URL) string) map[string]Link {
+	if url == nil || url.Params == nil || !url.IsCol {
+		return nil
+	}
+
+	page := url.Params.Page
+
+	var (
+		offsetKey, sizeKey string
+		offset, size, step int
+		err                error
+	)
+
+	switch {
+	case page["offset"] != "" || page["limit"] != "":
+		// Offset based strategy
+		offsetKey, sizeKey = "offset", "limit"
+		offset, err = pageParam(page, offsetKey, 0)
+
+		if err == nil {
+			size, err = pageParam(page, sizeKey, p.Size)
+		}
+
+		step = size
+	default:
+		// Page number based strategy
+		offsetKey, sizeKey = "number", "size"
+
+		var number int
+		number, err = pageParam(page, offsetKey, 1)
+
+		if err == nil {
+			size, err = pageParam(page, sizeKey, p.Size)
+		}
+
+		if size > 0 && number-1 > maxInt/size {
+			return nil
+		}
+
+		offset = (number - 1) * size
+		step = 1
+	}
+
+	if err != nil || size <= 0 || offset < 0 {
+		return nil
+	}
+
+	// Converts an offset into the value of the page parameter.
+	param := func(off int) string {
+		if step == 1 {
+			return strconv.Itoa(off/size + 1)
+		}
+
+		return strconv.Itoa(off)
+	}
+
+	lastOffset := 0
+	if p.Total > 0 {
+		lastOffset = (p.Total - 1) / size * size
+	}
+
+	link := func(off int) Link {
+		u := *url
+		params := *url.Params
+		params.Page = map[string]string{}
+
+		for k, v := range page {
+			params.Page[k] = v
+		}
+
+		params.Page[offsetKey] = param(off)
+		params.Page[sizeKey] = strconv.Itoa(size)
+		u.Params = &params
+
+		return Link{HRef: href(&u)}
+	}
+
+	links := map[string]Link{
+		"first": link(0),
+		"last":  link(lastOffset),
+	}
+
+	if offset > 0 {
+		prev := offset - size
+		if prev < 0 {
+			prev = 0
+		}
+
+		links["prev"] = link(prev)
+	}
+
+	if offset+size <= lastOffset {
+		links["next"] = link(offset + size)
+	}
+
+	return links
+}
+
+// pageParam returns the value of the page parameter named key as an int, or
+// def if the parameter is not set.
+func pageParam(page map[string]string, key string, def int) (int, error) {
+	if v, ok := page[key]; ok {
+		return strconv.Atoi(v)
+	}
+
+	return def, nil
+}
+
+// A PageSpec defines the page parameters accepted by ParsePage.
+type PageSpec struct {
+	// DefaultSize is the page size used when the parameters do not specify
+	// one.
+	DefaultSize int
+
+	// MinSize and MaxSize are the bounds of the page size given by
+	// page[size] or page[limit]. MinSize is 1 if it is not set, and the size
+	// is not limited if MaxSize is 0.
+	MinSize int
+	MaxSize int
+
+	// Cursor allows the page[cursor] parameter, which can be combined with
+	// page[size] but not with the other parameters.
+	Cursor bool
+}
+
+// A Page holds the page parameters validated by ParsePage.
+//
+// Number and Offset are both set whatever the strategy of the request: an
+// offset that is not a multiple of the size belongs to the page it starts in.
+// This requires the size to be known, so if the parameters do not specify one
+// and PageSpec.DefaultSize is 0, only the one given by the parameters is set.
+type Page struct {
+	Number int
+	Offset int
+	Size   int
+	Cursor string
+}
+
+// ParsePage validates the page parameters of a request, like the Page map of
+// Params, according to spec and returns them as a Page.
+//
+// The parameters are page[number] and page[size] (numbers start at 1),
+// page[offset] and page[limit] (offsets start at 0), and page[cursor] if spec
+// allows it. The strategies cannot be mixed.
+//
+// The returned error is an Error: NewErrInvalidPageNumberParameter for an
+// invalid page[number] or page[offset], NewErrInvalidPageSizeParameter for an
+// invalid page[size] or page[limit], and NewErrUnknownParameter for any other
+// page parameter. Its source is the parameter.
+func ParsePage(page map[string]string, spec PageSpec) (Page, error) {
+	keys := make([]string, 0, len(page))
+	for k := range page {
+		keys = append(keys, k)
+	}
+
+	sort.Strings(keys)
+
+	for _, k := range keys {
+		switch k {
+		case "number", "size", "offset", "limit":
+		case "cursor":
+			if spec.Cursor {
+				break
+			}
+
+			fallthrough
+		default:
+			return Page{}, NewErrUnknownParameter("page[" + k + "]")
+		}
+	}
+
+	// The parameters must belong to the same strategy.
+	for i := range keys {
+		for _, k := range keys[i+1:] {
+			if !samePageStrategy(keys[i], k) {
+				return Page{}, pageErr(NewErrBadRequest("Conflicting page parameters",
+					fmt.Sprintf("The parameters page[%s] and page[%s] cannot be combined.",
+						keys[i], k)), "page["+k+"]")
+			}
+		}
+	}
+
+	p := Page{Number: 1, Size: spec.DefaultSize, Cursor: page["cursor"]}
+
+	minSize := spec.MinSize
+	if minSize < 1 {
+		minSize = 1
+	}
+
+	for _, k := range keys {
+		v := page[k]
+		n, err := strconv.Atoi(v)
+
+		switch k {
+		case "number":
+			if err != nil || n < 1 {
+				return Page{}, NewErrInvalidPageNumberParameter(v)
+			}
+
+			p.Number = n
+		case "offset":
+			if err != nil || n < 0 {
+				return Page{}, pageErr(NewErrInvalidPageNumberParameter(v), "page[offset]")
+			}
+
+			p.Offset = n
+		case "size", "limit":
+			if err != nil || n < minSize || (spec.MaxSize > 0 && n > spec.MaxSize) {
+				return Page{}, pageErr(NewErrInvalidPageSizeParameter(v), "page["+k+"]")
+			}
+
+			p.Size = n
+		}
+	}
+
+	if p.Size > 0 {
+		if _, ok := page["offset"]; ok {
+			p.Number = p.Offset/p.Size + 1
+		} else {
+			if p.Number-1 > maxInt/p.Size {
+				return Page{}, NewErrInvalidPageNumberParameter(page["number"])
+			}
+
+			p.Offset = (p.Number - 1) * p.Size
+		}
+	}
+
+	return p, nil
+}
+
+// samePageStrategy reports whether the page parameters k1 and k2 can be
+// combined.
+func samePageStrategy(k1, k2 string) bool {
+	strategies := [][]string{{"number", "size"}, {"limit", "offset"}, {"cursor", "size"}}
+
+	for _, keys := range strategies {
+		if containsString(keys, k1) && containsString(keys, k2) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// pageErr sets the parameter named param as the source of e.
+func pageErr(e Error, param string) Error {
+	e.setSource(ErrorSource{Parameter: param})
+
+	return e
+}
@@ -0,0 +1,239 @@
+package jsonapi_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+
+	. "github.com/mark-hartmann/jsonapi"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPaginationLinks(t *testing.T) {
+	schema := newMockSchema()
+
+	tests := []struct {
+		name       string
+		url        string
+		pagination Pagination
+		expected   map[string]string
+	}{
+		{
+			name:       "page number",
+			url:        "/mocktypes1?page[number]=2&page[size]=10",
+			pagination: Pagination{Total: 35},
+			expected: map[string]string{
+				"first": "/mocktypes1?page%5Bnumber%5D=1&page%5Bsize%5D=10",
+				"prev":  "/mocktypes1?page%5Bnumber%5D=1&page%5Bsize%5D=10",
+				"next":  "/mocktypes1?page%5Bnumber%5D=3&page%5Bsize%5D=10",
+				"last":  "/mocktypes1?page%5Bnumber%5D=4&page%5Bsize%5D=10",
+			},
+		}, {
+			name:       "first page with default size",
+			url:        "/mocktypes1",
+			pagination: Pagination{Total: 35, Size: 20},
+			expected: map[string]string{
+				"first": "/mocktypes1?page%5Bnumber%5D=1&page%5Bsize%5D=20",
+				"next":  "/mocktypes1?page%5Bnumber%5D=2&page%5Bsize%5D=20",
+				"last":  "/mocktypes1?page%5Bnumber%5D=2&page%5Bsize%5D=20",
+			},
+		}, {
+			name:       "last page",
+			url:        "/mocktypes1?page[number]=4&page[size]=10&sort=str",
+			pagination: Pagination{Total: 40},
+			expected: map[string]string{
+				"first": "/mocktypes1?page%5Bnumber%5D=1&page%5Bsize%5D=10&sort=str",
+				"prev":  "/mocktypes1?page%5Bnumber%5D=3&page%5Bsize%5D=10&sort=str",
+				"last":  "/mocktypes1?page%5Bnumber%5D=4&page%5Bsize%5D=10&sort=str",
+			},
+		}, {
+			name:       "offset",
+			url:        "/mocktypes1?page[offset]=5&page[limit]=10",
+			pagination: Pagination{Total: 30},
+			expected: map[string]string{
+				"first": "/mocktypes1?page%5Blimit%5D=10&page%5Boffset%5D=0",
+				"prev":  "/mocktypes1?page%5Blimit%5D=10&page%5Boffset%5D=0",
+				"next":  "/mocktypes1?page%5Blimit%5D=10&page%5Boffset%5D=15",
+				"last":  "/mocktypes1?page%5Blimit%5D=10&page%5Boffset%5D=20",
+			},
+		}, {
+			name:       "empty collection",
+			url:        "/mocktypes1?page[size]=10",
+			pagination: Pagination{Total: 0},
+			expected: map[string]string{
+				"first": "/mocktypes1?page%5Bnumber%5D=1&page%5Bsize%5D=10",
+				"last":  "/mocktypes1?page%5Bnumber%5D=1&page%5Bsize%5D=10",
+			},
+		}, {
+			name:       "unknown size",
+			url:        "/mocktypes1?page[number]=2",
+			pagination: Pagination{Total: 35},
+			expected:   map[string]string{},
+		}, {
+			name:       "invalid page number",
+			url:        "/mocktypes1?page[number]=abc&page[size]=10",
+			pagination: Pagination{Total: 35},
+			expected:   map[string]string{},
+		}, {
+			name:       "page number too large",
+			url:        "/mocktypes1?page[number]=9223372036854775807&page[size]=100",
+			pagination: Pagination{Total: 35},
+			expected:   map[string]string{},
+		}, {
+			name:       "not a collection",
+			url:        "/mocktypes1/id1",
+			pagination: Pagination{Total: 35, Size: 10},
+			expected: map[string]string{
+				"self": "/mocktypes1/id1",
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			assert := assert.New(t)
+
+			url, err := NewURLFromRaw(schema, test.url)
+			assert.NoError(err)
+
+			doc := &Document{
+				Pagination: &test.pagination,
+			}
+
+			if url.IsCol {
+				doc.Data = &Resources{}
+				doc.OmitSelfLink = true
+			}
+
+			payload := &bytes.Buffer{}
+			err = MarshalDocument(payload, doc, url)
+			assert.NoError(err)
+
+			var pl struct {
+				Links map[string]string `json:"links"`
+			}
+
+			err = json.Unmarshal(payload.Bytes(), &pl)
+			assert.NoError(err)
+
+			if pl.Links == nil {
+				pl.Links = map[string]string{}
+			}
+
+			assert.Equal(test.expected, pl.Links)
+		})
+	}
+}
+
+func TestParsePage(t *testing.T) {
+	spec := PageSpec{DefaultSize: 10, MaxSize: 50, Cursor: true}
+
+	tests := []struct {
+		name   string
+		page   map[string]string
+		spec   *PageSpec
+		expect Page
+		err    string
+		param  string
+	}{
+		{
+			name:   "no parameters",
+			expect: Page{Number: 1, Size: 10},
+		}, {
+			name:   "number and size",
+			page:   map[string]string{"number": "3", "size": "20"},
+			expect: Page{Number: 3, Offset: 40, Size: 20},
+		}, {
+			name:   "offset and limit",
+			page:   map[string]string{"offset": "25", "limit": "10"},
+			expect: Page{Number: 3, Offset: 25, Size: 10},
+		}, {
+			name:   "cursor and size",
+			page:   map[string]string{"cursor": "abc", "size": "5"},
+			expect: Page{Number: 1, Size: 5, Cursor: "abc"},
+		}, {
+			name:   "no default size",
+			page:   map[string]string{"number": "2"},
+			spec:   &PageSpec{},
+			expect: Page{Number: 2},
+		}, {
+			name:  "invalid number",
+			page:  map[string]string{"number": "0"},
+			err:   `400 Bad Request: The page number parameter "0" is not valid.`,
+			param: "page[number]",
+		}, {
+			name:  "number too large",
+			page:  map[string]string{"number": "9223372036854775807", "size": "50"},
+			err:   `400 Bad Request: The page number parameter "9223372036854775807" is not valid.`,
+			param: "page[number]",
+		}, {
+			name:  "invalid offset",
+			page:  map[string]string{"offset": "-1"},
+			err:   `400 Bad Request: The page number parameter "-1" is not valid.`,
+			param: "page[offset]",
+		}, {
+			name:  "size too large",
+			page:  map[string]string{"size": "51"},
+			err:   `400 Bad Request: The page size parameter "51" is not valid.`,
+			param: "page[size]",
+		}, {
+			name:  "size too small",
+			page:  map[string]string{"limit": "4"},
+			spec:  &PageSpec{MinSize: 5},
+			err:   `400 Bad Request: The page size parameter "4" is not valid.`,
+			param: "page[limit]",
+		}, {
+			name:  "invalid size",
+			page:  map[string]string{"size": "ten"},
+			err:   `400 Bad Request: The page size parameter "ten" is not valid.`,
+			param: "page[size]",
+		}, {
+			name:  "mixed strategies",
+			page:  map[string]string{"number": "1", "limit": "10"},
+			err:   `400 Bad Request: The parameters page[limit] and page[number] cannot be combined.`,
+			param: "page[number]",
+		}, {
+			name:  "cursor not allowed",
+			page:  map[string]string{"cursor": "abc"},
+			spec:  &PageSpec{},
+			err:   `400 Bad Request: The query parameter "page[cursor]" is not supported.`,
+			param: "page[cursor]",
+		}, {
+			name:  "unknown parameter",
+			page:  map[string]string{"number": "1", "from": "2"},
+			err:   `400 Bad Request: The query parameter "page[from]" is not supported.`,
+			param: "page[from]",
+		},
+	}
+
+	for _, test := range tests {
+		test := test
+
+		t.Run(test.name, func(t *testing.T) {
+			assert := assert.New(t)
+
+			s := spec
+			if test.spec != nil {
+				s = *test.spec
+			}
+
+			p, err := ParsePage(test.page, s)
+
+			if test.err == "" {
+				assert.NoError(err)
+				assert.Equal(test.expect, p)
+
+				return
+			}
+
+			assert.EqualError(err, test.err)
+
+			e, ok := err.(Error)
+			if assert.True(ok) {
+				assert.Equal(test.param, e.Src.Parameter)
+				assert.Equal(test.param, e.Source["parameter"])
+			}
+		})
+	}
+}
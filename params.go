@@ -9,23 +9,27 @@ import (
 // NewParams creates and returns a Params object built from a SimpleURL and a
 // given resource type. A schema is used for validation.
 //
+// The default sparse fieldsets of the schema (see Schema.SetDefaultFields) are
+// used for the types without a fields parameter.
+//
 // If validation is not expected, it is recommended to simply build a SimpleURL
 // object with NewSimpleURL.
 func NewParams(schema *Schema, su SimpleURL, resType string) (*Params, error) {
+	return URLOptions{}.NewParams(schema, su, resType)
+}
+
+// NewParams creates a Params object like the NewParams function does, according
+// to the options.
+func (o URLOptions) NewParams(schema *Schema, su SimpleURL, resType string) (*Params, error) {
 	params := &Params{}
 
 	// Include
 	if su.Include != nil {
-		incs := make([]string, len(su.Include))
-		copy(incs, su.Include)
-		sort.Strings(incs)
-
-		// Remove duplicates and unnecessary includes
-		for i := len(incs) - 1; i >= 0; i-- {
-			if i > 0 && strings.HasPrefix(incs[i], incs[i-1]) {
-				incs = append(incs[:i-1], incs[i:]...)
-			}
-		}
+		// Remove duplicates and unnecessary includes. A path is unnecessary if
+		// another path starts with it, like author for author.articles. The
+		// paths are not reduced otherwise, so a path that goes through the same
+		// relationship several times, like manager.manager, is kept as it is.
+		incs := removeIncludePrefixes(su.Include)
 
 		// Check inclusions
 		params.Include = make([][]Rel, len(incs))
@@ -40,7 +44,7 @@ func NewParams(schema *Schema, su SimpleURL, resType string) (*Params, error) {
 				typ := schema.GetType(incRel.ToType)
 
 				var ok bool
-				if incRel, ok = typ.Rels[word]; !ok {
+				if incRel, ok = typ.Rels[schema.fieldNameOrMember(typ)(word)]; !ok {
 					return nil, &srcError{src: "include", error: &UnknownFieldError{
 						Type:    typ.Name,
 						Field:   word,
@@ -51,19 +55,53 @@ func NewParams(schema *Schema, su SimpleURL, resType string) (*Params, error) {
 
 				params.Include[i][j] = incRel
 			}
+
+			if err := o.authorizePath("include", params.Include[i]); err != nil {
+				return nil, err
+			}
 		}
 	}
 
 	// After these checks, only valid fields remain, representing either the resource ID or
 	// one of the attributes or relations.
 	for typeName, fields := range su.Fields {
-		fields = removeDuplicates(fields)
-
 		typ := schema.GetType(typeName)
 		if typeName != resType && typ.Name == "" {
 			return nil, &srcError{src: "fields", error: &UnknownTypeError{Type: typeName}}
 		}
 
+		// Member names (see Schema.NameMapper)
+		fieldName := schema.fieldNameOrMember(typ)
+		names := make([]string, len(fields))
+
+		for i, member := range fields {
+			if names[i] = member; strings.HasPrefix(member, "@") {
+				continue
+			}
+
+			if names[i] = fieldName(member); names[i] == "" {
+				return nil, &srcError{src: "fields", error: &UnknownFieldError{
+					Type:  typ.Name,
+					Field: member,
+				}}
+			}
+		}
+
+		// Field groups (see Schema.SetFieldGroup)
+		fields, err := schema.expandFieldGroups(typeName, names)
+		if err != nil {
+			return nil, &srcError{src: "fields", error: err}
+		}
+
+		fields = removeDuplicates(fields)
+
+		if o.StrictFields && !typeIncluded(typeName, resType, params.Include) {
+			return nil, &IllegalParameterError{
+				Param: "fields",
+				err:   fmt.Errorf("type %q is neither the type of the endpoint nor included", typeName),
+			}
+		}
+
 		// Check if the sparse fieldset contains any fields that does not exist on the type.
 		if field := findFirstDifference(fields, typ.Fields()); field != "" && field != "id" {
 			return nil, &srcError{src: "fields", error: &UnknownFieldError{
@@ -79,6 +117,19 @@ func NewParams(schema *Schema, su SimpleURL, resType string) (*Params, error) {
 		params.Fields[typeName] = fields
 	}
 
+	// Default sparse fieldsets of the types without a fields parameter.
+	for typeName, fields := range schema.defaultFields {
+		if _, ok := params.Fields[typeName]; ok {
+			continue
+		}
+
+		if len(params.Fields) == 0 {
+			params.Fields = map[string][]string{}
+		}
+
+		params.Fields[typeName] = append([]string{}, fields...)
+	}
+
 	// Separate the passed fields into attributes and relationships.
 	for typeName, fields := range params.Fields {
 		// This should always return a type since
@@ -143,6 +194,12 @@ func NewParams(schema *Schema, su SimpleURL, resType string) (*Params, error) {
 			}
 		}
 
+		if len(sr.Path) > 0 {
+			if err := o.authorizePath("sort", sr.Path); err != nil {
+				return nil, err
+			}
+		}
+
 		params.SortRules = append(params.SortRules, sr)
 	}
 
@@ -164,6 +221,10 @@ func NewParams(schema *Schema, su SimpleURL, resType string) (*Params, error) {
 	}
 
 	// Off-Spec query params
+	if err := o.checkParams(su.Params); err != nil {
+		return nil, err
+	}
+
 	if len(su.Params) > 0 {
 		params.Params = make(map[string][]string, len(su.Params))
 		for n, p := range su.Params {
@@ -175,6 +236,48 @@ func NewParams(schema *Schema, su SimpleURL, resType string) (*Params, error) {
 	return params, nil
 }
 
+// typeIncluded reports whether the resources of the type named typ can be part
+// of a response whose primary data is of type resType, given the include paths.
+func typeIncluded(typ, resType string, include [][]Rel) bool {
+	if typ == resType {
+		return true
+	}
+
+	for _, path := range include {
+		for _, rel := range path {
+			if rel.ToType == typ {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+// removeIncludePrefixes returns the sorted include paths of incs without the
+// duplicates and the paths another path starts with.
+func removeIncludePrefixes(incs []string) []string {
+	incs = removeDuplicates(incs)
+	kept := incs[:0]
+
+	for i, inc := range incs {
+		unnecessary := false
+
+		for _, other := range incs[i+1:] {
+			if strings.HasPrefix(other, inc+".") {
+				unnecessary = true
+				break
+			}
+		}
+
+		if !unnecessary {
+			kept = append(kept, inc)
+		}
+	}
+
+	return kept
+}
+
 // removeDuplicates creates a sorted copy of s without duplicates.
 func removeDuplicates(s []string) []string {
 	s2 := make([]string, len(s))
@@ -209,6 +312,10 @@ func findFirstDifference(a, b []string) string {
 type Params struct {
 	// Fields contains the names of all attributes and relationships that are included in the
 	// sparse field sets.
+	//
+	// A type without an entry has no sparse fieldset, so all of its fields are included. A
+	// type with an empty entry has none of its fields included. The query of a URL only
+	// holds fields[type]= for an entry that is empty but not nil.
 	Fields map[string][]string
 
 	// Attrs contains all attributes found in Fields, grouped by the name of the resource type
@@ -236,3 +343,227 @@ type Params struct {
 	// Params contains all off-spec query parameters.
 	Params map[string][]string
 }
+
+// Clone returns a deep copy of p. Modifying the copy does not affect p.
+func (p *Params) Clone() *Params {
+	if p == nil {
+		return nil
+	}
+
+	c := &Params{
+		Fields: cloneStringsMap(p.Fields),
+		Filter: cloneStringsMap(p.Filter),
+		Params: cloneStringsMap(p.Params),
+	}
+
+	if p.Attrs != nil {
+		c.Attrs = make(map[string][]Attr, len(p.Attrs))
+		for typ, attrs := range p.Attrs {
+			c.Attrs[typ] = append([]Attr(nil), attrs...)
+		}
+	}
+
+	if p.Rels != nil {
+		c.Rels = make(map[string][]Rel, len(p.Rels))
+		for typ, rels := range p.Rels {
+			c.Rels[typ] = append([]Rel(nil), rels...)
+		}
+	}
+
+	if p.SortRules != nil {
+		c.SortRules = make([]SortRule, len(p.SortRules))
+		for i, sr := range p.SortRules {
+			c.SortRules[i] = sr
+			if sr.Path != nil {
+				c.SortRules[i].Path = append([]Rel(nil), sr.Path...)
+			}
+		}
+	}
+
+	if p.Page != nil {
+		c.Page = make(map[string]string, len(p.Page))
+		for k, v := range p.Page {
+			c.Page[k] = v
+		}
+	}
+
+	if p.Include != nil {
+		c.Include = make([][]Rel, len(p.Include))
+		for i, rels := range p.Include {
+			c.Include[i] = append([]Rel(nil), rels...)
+		}
+	}
+
+	return c
+}
+
+// Merge merges other into p according to policy, like Meta.Merge does.
+//
+// Maps are merged key by key, so the sparse fieldset of a type or a filter can
+// be overridden without affecting the others. The attributes and relationships
+// of a type always follow its fields. Sort rules and inclusions are merged as a
+// whole. With MergeError, an error is returned for the first parameter defined
+// by both objects and p is left untouched.
+//
+// The values taken from other are copied, so the two objects do not share any
+// state afterwards.
+func (p *Params) Merge(other *Params, policy MergePolicy) error {
+	if other == nil {
+		return nil
+	}
+
+	if policy == MergeError {
+		if param := p.conflict(other); param != "" {
+			return fmt.Errorf("jsonapi: query parameter %q is already set", param)
+		}
+	}
+
+	o := other.Clone()
+
+	// Fields, Attrs, Rels
+	for typ, fields := range o.Fields {
+		if _, ok := p.Fields[typ]; ok && policy == MergeKeep {
+			continue
+		}
+
+		if p.Fields == nil {
+			p.Fields = map[string][]string{}
+		}
+
+		p.Fields[typ] = fields
+
+		if attrs, ok := o.Attrs[typ]; ok {
+			if p.Attrs == nil {
+				p.Attrs = map[string][]Attr{}
+			}
+
+			p.Attrs[typ] = attrs
+		} else {
+			delete(p.Attrs, typ)
+		}
+
+		if rels, ok := o.Rels[typ]; ok {
+			if p.Rels == nil {
+				p.Rels = map[string][]Rel{}
+			}
+
+			p.Rels[typ] = rels
+		} else {
+			delete(p.Rels, typ)
+		}
+	}
+
+	// Filter, Params
+	p.Filter = mergeStringsMap(p.Filter, o.Filter, policy)
+	p.Params = mergeStringsMap(p.Params, o.Params, policy)
+
+	// Page
+	for k, v := range o.Page {
+		if _, ok := p.Page[k]; ok && policy == MergeKeep {
+			continue
+		}
+
+		if p.Page == nil {
+			p.Page = map[string]string{}
+		}
+
+		p.Page[k] = v
+	}
+
+	// SortRules, Include
+	if len(o.SortRules) > 0 && (len(p.SortRules) == 0 || policy != MergeKeep) {
+		p.SortRules = o.SortRules
+	}
+
+	if len(o.Include) > 0 && (len(p.Include) == 0 || policy != MergeKeep) {
+		p.Include = o.Include
+	}
+
+	return nil
+}
+
+// conflict returns the name of the first query parameter defined by both p and
+// other, or an empty string if there is none.
+func (p *Params) conflict(other *Params) string {
+	for _, typ := range sortedKeys(other.Fields) {
+		if _, ok := p.Fields[typ]; ok {
+			return "fields[" + typ + "]"
+		}
+	}
+
+	for _, name := range sortedKeys(other.Filter) {
+		if _, ok := p.Filter[name]; ok {
+			return name
+		}
+	}
+
+	pages := make([]string, 0, len(other.Page))
+	for k := range other.Page {
+		if _, ok := p.Page[k]; ok {
+			pages = append(pages, "page["+k+"]")
+		}
+	}
+
+	if len(pages) > 0 {
+		sort.Strings(pages)
+		return pages[0]
+	}
+
+	switch {
+	case len(p.SortRules) > 0 && len(other.SortRules) > 0:
+		return "sort"
+	case len(p.Include) > 0 && len(other.Include) > 0:
+		return "include"
+	}
+
+	for _, name := range sortedKeys(other.Params) {
+		if _, ok := p.Params[name]; ok {
+			return name
+		}
+	}
+
+	return ""
+}
+
+// cloneStringsMap returns a deep copy of m.
+func cloneStringsMap(m map[string][]string) map[string][]string {
+	if m == nil {
+		return nil
+	}
+
+	c := make(map[string][]string, len(m))
+	for k, v := range m {
+		c[k] = append([]string{}, v...)
+	}
+
+	return c
+}
+
+// mergeStringsMap merges src into dst according to policy and returns dst.
+func mergeStringsMap(dst, src map[string][]string, policy MergePolicy) map[string][]string {
+	for k, v := range src {
+		if _, ok := dst[k]; ok && policy == MergeKeep {
+			continue
+		}
+
+		if dst == nil {
+			dst = map[string][]string{}
+		}
+
+		dst[k] = v
+	}
+
+	return dst
+}
+
+// sortedKeys returns the keys of m in increasing order.
+func sortedKeys(m map[string][]string) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+
+	sort.Strings(keys)
+
+	return keys
+}
@@ -2,6 +2,7 @@ package jsonapi_test
 
 import (
 	"net/url"
+	"strings"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -36,6 +37,20 @@ func TestNewParams_Invalid(t *testing.T) {
 		assert.False(t, unknownTypeErr.InPath())
 	})
 
+	t.Run("invalid names in sparse fieldset", func(t *testing.T) {
+		_, err := NewParams(schema, newSimpleURL("?fields[mocktypes1!]=int8"), "mocktypes1")
+
+		var unknownTypeErr *UnknownTypeError
+		assert.ErrorAs(t, err, &unknownTypeErr)
+		assert.Equal(t, "mocktypes1!", unknownTypeErr.Type)
+
+		_, err = NewParams(schema, newSimpleURL("?fields[mocktypes1]=-int8"), "mocktypes1")
+
+		var unknownFieldErr *UnknownFieldError
+		assert.ErrorAs(t, err, &unknownFieldErr)
+		assert.Equal(t, "-int8", unknownFieldErr.Field)
+	})
+
 	t.Run("unknown field in sparse fieldset", func(t *testing.T) {
 		_, err := NewParams(schema, newSimpleURL("?fields[mocktypes1]=int8,unknown-field"),
 			"mocktypes1")
@@ -470,3 +485,149 @@ func getExpectedAttrsAndRels(schema *Schema, fieldMap map[string][]string) (
 
 	return
 }
+
+func TestNewParamsDefaultFields(t *testing.T) {
+	assert := assert.New(t)
+
+	schema := newMockSchema()
+	assert.NoError(schema.SetDefaultFields("mocktypes1", "str", "to-one"))
+	assert.NoError(schema.SetDefaultFields("mocktypes2", "strptr"))
+
+	u, err := url.Parse("/mocktypes1?fields[mocktypes2]=")
+	assert.NoError(err)
+
+	su, err := NewSimpleURL(u)
+	assert.NoError(err)
+
+	params, err := NewParams(schema, su, "mocktypes1")
+	assert.NoError(err)
+
+	// The default fieldset only applies without a fields parameter.
+	assert.Equal(map[string][]string{
+		"mocktypes1": {"str", "to-one"},
+		"mocktypes2": {},
+	}, params.Fields)
+
+	mt1 := schema.GetType("mocktypes1")
+	assert.Equal([]Attr{mt1.Attrs["str"]}, params.Attrs["mocktypes1"])
+	assert.Equal([]Rel{mt1.Rels["to-one"]}, params.Rels["mocktypes1"])
+}
+
+func TestNewParamsInclude(t *testing.T) {
+	assert := assert.New(t)
+
+	include := func(schema *Schema, resType, raw string) []string {
+		su, err := NewSimpleURL(&url.URL{RawQuery: "include=" + raw})
+		assert.NoError(err)
+
+		params, err := NewParams(schema, su, resType)
+		assert.NoError(err)
+
+		paths := []string{}
+
+		for _, path := range params.Include {
+			names := []string{}
+			for _, rel := range path {
+				names = append(names, rel.FromName)
+			}
+
+			paths = append(paths, strings.Join(names, "."))
+		}
+
+		return paths
+	}
+
+	// A name that starts with another name is not a longer path.
+	assert.Equal(
+		[]string{"to-one", "to-one-from-one.to-many-from-one"},
+		include(newMockSchema(), "mocktypes1", "to-one-from-one.to-many-from-one,to-one,to-one"),
+	)
+
+	// Self-referential paths are not reduced.
+	assert.Equal(
+		[]string{"friends.friends", "manager.reports.manager"},
+		include(newPeopleSchema(), "people", "manager,friends.friends,manager.reports.manager,friends"),
+	)
+}
+
+func TestParamsCloneMerge(t *testing.T) {
+	assert := assert.New(t)
+
+	schema := newMockSchema()
+
+	defaults, err := NewParams(schema, newSimpleURL(`
+		?fields[mocktypes1]=str,int
+		&fields[mocktypes2]=strptr
+		&sort=-str
+		&page[size]=10
+		&filter[a]=1
+		&include=to-one
+	`), "mocktypes1")
+	assert.NoError(err)
+
+	// Clone
+	clone := defaults.Clone()
+	assert.Equal(defaults, clone)
+
+	clone.Fields["mocktypes1"][0] = "bool"
+	clone.Attrs["mocktypes1"][0].Name = "bool"
+	clone.SortRules[0].Desc = false
+	clone.Include[0][0].FromName = "to-many"
+	clone.Page["size"] = "20"
+	clone.Filter["filter[a]"][0] = "2"
+	assert.Equal([]string{"int", "str"}, defaults.Fields["mocktypes1"])
+	assert.Equal("int", defaults.Attrs["mocktypes1"][0].Name)
+	assert.True(defaults.SortRules[0].Desc)
+	assert.Equal("to-one", defaults.Include[0][0].FromName)
+	assert.Equal("10", defaults.Page["size"])
+	assert.Equal([]string{"1"}, defaults.Filter["filter[a]"])
+	assert.Nil((*Params)(nil).Clone())
+
+	req, err := NewParams(schema, newSimpleURL(`
+		?fields[mocktypes1]=bool
+		&page[number]=2
+		&page[size]=50
+		&filter[b]=3
+		&custom=x
+	`), "mocktypes1")
+	assert.NoError(err)
+
+	// MergeOverwrite
+	params := defaults.Clone()
+	assert.NoError(params.Merge(req, MergeOverwrite))
+	assert.Equal(map[string][]string{
+		"mocktypes1": {"bool"},
+		"mocktypes2": {"strptr"},
+	}, params.Fields)
+	assert.Len(params.Attrs["mocktypes1"], 1)
+	assert.Equal("bool", params.Attrs["mocktypes1"][0].Name)
+	assert.Equal(map[string]string{"number": "2", "size": "50"}, params.Page)
+	assert.Equal(map[string][]string{"filter[a]": {"1"}, "filter[b]": {"3"}}, params.Filter)
+	assert.Equal(map[string][]string{"custom": {"x"}}, params.Params)
+	assert.Equal(defaults.SortRules, params.SortRules)
+	assert.Equal(defaults.Include, params.Include)
+
+	// The merged values are copies.
+	params.Fields["mocktypes1"][0] = "int"
+	assert.Equal([]string{"bool"}, req.Fields["mocktypes1"])
+
+	// MergeKeep
+	params = defaults.Clone()
+	assert.NoError(params.Merge(req, MergeKeep))
+	assert.Equal([]string{"int", "str"}, params.Fields["mocktypes1"])
+	assert.Equal(map[string]string{"number": "2", "size": "10"}, params.Page)
+	assert.Equal(map[string][]string{"custom": {"x"}}, params.Params)
+
+	// MergeError
+	params = defaults.Clone()
+	assert.EqualError(
+		params.Merge(req, MergeError),
+		`jsonapi: query parameter "fields[mocktypes1]" is already set`,
+	)
+	assert.Equal(defaults, params)
+
+	other := &Params{Filter: map[string][]string{"filter[c]": {"4"}}}
+	assert.NoError(params.Merge(other, MergeError))
+	assert.Equal([]string{"4"}, params.Filter["filter[c]"])
+	assert.NoError(params.Merge(nil, MergeError))
+}
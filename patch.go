@@ -0,0 +1,88 @@
+package jsonapi
+
+import (
+	"bytes"
+	"fmt"
+)
+
+// DiffResources returns a partial resource that only holds the attributes and
+// relationships of to whose values are different from the ones of from, like
+// the body of a PATCH request that turns from into to.
+//
+// The values are compared like Equal does. The read-only attributes and the
+// fields of from that to does not have are left out, since they cannot be
+// changed by a PATCH request. The linkage of a relationship is kept if to is a
+// LinkageHolder. The returned resource has no field if the resources are equal.
+//
+// An error is returned if the resources do not have the same type and ID.
+func DiffResources(from, to Resource) (*SoftResource, error) {
+	typ := to.GetType()
+	id := to.Get("id").(string)
+
+	if name := from.GetType().Name; name != typ.Name {
+		return nil, fmt.Errorf("jsonapi: cannot diff resources of types %q and %q",
+			name, typ.Name)
+	}
+
+	if fromID := from.Get("id").(string); fromID != id {
+		return nil, fmt.Errorf("jsonapi: cannot diff resources with IDs %q and %q", fromID, id)
+	}
+
+	patch := &SoftResource{Type: &Type{Name: typ.Name}}
+	patch.SetID(id)
+
+	attrs, rels := to.Attrs(), to.Rels()
+
+	for _, d := range DiffOpts(from, to, EqualOptions{}) {
+		if d.Missing2 {
+			continue
+		}
+
+		if attr, ok := attrs[d.Field]; ok {
+			if attr.ReadOnly || from.Attrs()[d.Field].ReadOnly {
+				continue
+			}
+
+			patch.AddAttr(attr)
+			patch.Set(d.Field, d.Value2)
+
+			continue
+		}
+
+		rel := rels[d.Field]
+		patch.AddRel(rel)
+		patch.Set(d.Field, d.Value2)
+
+		if lh, ok := to.(LinkageHolder); ok {
+			if idens := lh.Linkage(d.Field); idens != nil {
+				patch.SetLinkage(d.Field, idens)
+			}
+		}
+	}
+
+	return patch, nil
+}
+
+// MarshalPatch marshals res into the body of a PATCH request, which is a
+// document whose primary data is res with the linkage of all its relationships.
+// res is usually the result of DiffResources.
+func MarshalPatch(res Resource) ([]byte, error) {
+	return MarshalOptions{}.MarshalPatch(res)
+}
+
+// MarshalPatch marshals res like the MarshalPatch function does, according to
+// the options.
+func (o MarshalOptions) MarshalPatch(res Resource) ([]byte, error) {
+	typ := res.GetType()
+	doc := &Document{
+		Data:    res,
+		RelData: map[string][]string{typ.Name: sortedRelNames(typ.Rels)},
+	}
+
+	buf := &bytes.Buffer{}
+	if err := o.MarshalDocument(buf, doc, nil); err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
@@ -0,0 +1,91 @@
+package jsonapi_test
+
+import (
+	"testing"
+
+	. "github.com/mark-hartmann/jsonapi"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDiffResources(t *testing.T) {
+	assert := assert.New(t)
+
+	typ := Type{Name: "articles"}
+	typ.MustAddAttr(Attr{Name: "title", Type: AttrTypeString})
+	typ.MustAddAttr(Attr{Name: "body", Type: AttrTypeString})
+	typ.MustAddAttr(Attr{Name: "views", Type: AttrTypeInt, ReadOnly: true})
+	typ.MustAddRel(Rel{FromName: "author", ToType: "people", ToOne: true})
+	typ.MustAddRel(Rel{FromName: "tags", ToType: "tags"})
+
+	from := &SoftResource{Type: &typ}
+	from.SetID("a1")
+	from.Set("title", "Hello")
+	from.Set("body", "World")
+	from.Set("views", 1)
+	from.Set("author", "p1")
+	from.Set("tags", []string{"t1"})
+
+	to := from.Copy().(*SoftResource)
+	to.Set("title", "Hi")
+	to.Set("views", 2)
+	to.Set("author", "")
+
+	patch, err := DiffResources(from, to)
+	assert.NoError(err)
+	assert.Equal("a1", patch.GetID())
+	assert.Equal("articles", patch.GetType().Name)
+	assert.Equal([]string{"author", "title"}, patch.Type.Fields())
+	assert.Equal("Hi", patch.Get("title"))
+	assert.Equal("", patch.Get("author"))
+
+	body, err := MarshalPatch(patch)
+	assert.NoError(err)
+	assert.JSONEq(`{
+		"data": {
+			"attributes": {"title": "Hi"},
+			"id": "a1",
+			"links": {"self": "/articles/a1"},
+			"relationships": {
+				"author": {
+					"data": null,
+					"links": {
+						"related": "/articles/a1/author",
+						"self": "/articles/a1/relationships/author"
+					}
+				}
+			},
+			"type": "articles"
+		},
+		"jsonapi": {"version": "1.0"}
+	}`, string(body))
+
+	// The patch can be applied.
+	res := from.Copy()
+	changed, err := ApplyPartial(res, patch)
+	assert.NoError(err)
+	assert.Equal([]string{"author", "title"}, changed)
+
+	// Linkage
+	to = from.Copy().(*SoftResource)
+	to.Set("tags", Identifiers{{ID: "t2", Type: "labels"}})
+
+	patch, err = DiffResources(from, to)
+	assert.NoError(err)
+	assert.Equal(Identifiers{{ID: "t2", Type: "labels"}}, patch.Linkage("tags"))
+
+	// Equal resources
+	patch, err = DiffResources(from, from.Copy())
+	assert.NoError(err)
+	assert.Empty(patch.Type.Fields())
+
+	// Different IDs
+	other := from.Copy().(*SoftResource)
+	other.SetID("a2")
+	_, err = DiffResources(from, other)
+	assert.EqualError(err, `jsonapi: cannot diff resources with IDs "a1" and "a2"`)
+
+	// Different types
+	_, err = DiffResources(from, &SoftResource{Type: &Type{Name: "people"}})
+	assert.EqualError(err, `jsonapi: cannot diff resources of types "articles" and "people"`)
+}
@@ -37,6 +37,10 @@ func init() {
 	RegisterAttrType(AttrTypeBool, "bool", basicZeroValueFunc, basicUnmarshalerFunc)
 	RegisterAttrType(AttrTypeTime, "time", basicZeroValueFunc, basicUnmarshalerFunc)
 	RegisterAttrType(AttrTypeBytes, "bytes", basicZeroValueFunc, basicUnmarshalerFunc)
+	RegisterAttrType(AttrTypeDecimal, "decimal", basicZeroValueFunc, basicUnmarshalerFunc)
+	RegisterAttrType(AttrTypeUUID, "uuid", basicZeroValueFunc, basicUnmarshalerFunc)
+	RegisterAttrType(AttrTypeJSON, "json", basicZeroValueFunc, basicUnmarshalerFunc)
+	RegisterAttrType(AttrTypeDuration, "duration", basicZeroValueFunc, basicUnmarshalerFunc)
 }
 
 // NameFunc receives the name of an attribute type and can replace or extend it to add context.
@@ -49,6 +53,62 @@ type ZeroValueFunc func(typ int, array, nullable bool) interface{}
 // TypeUnmarshalerFunc will unmarshal attribute payload to an appropriate golang type.
 type TypeUnmarshalerFunc func(data []byte, attr Attr) (interface{}, error)
 
+// TypeUnmarshaler provides the zero values of an attribute and unmarshals its payload. It can be
+// set on a single attribute with Attr.Unmarshaler to take precedence over the functions of the
+// registered attribute type. ReflectTypeUnmarshaler is an implementation based on reflection.
+type TypeUnmarshaler interface {
+	GetZeroValue(typ int, array, nullable bool) interface{}
+	UnmarshalToType(data []byte, attr Attr) (interface{}, error)
+}
+
+// TypeMarshaler marshals the value of an attribute. It can be set on a single attribute with
+// Attr.Marshaler to replace the default encoding of the json package.
+//
+// The returned payload must be valid JSON.
+type TypeMarshaler interface {
+	MarshalAttr(v interface{}, attr Attr) ([]byte, error)
+}
+
+// A Transformer changes the values of an attribute. It can be set on a single attribute with
+// Attr.Transformer. v and the returned values have the Go type of the attribute.
+//
+// TransformIn is called on the unmarshaled values of the payloads before they are set, and
+// TransformOut on the values of the resources before they are marshaled.
+type Transformer interface {
+	TransformIn(v interface{}, attr Attr) (interface{}, error)
+	TransformOut(v interface{}, attr Attr) (interface{}, error)
+}
+
+// TransformFunc transforms the value of an attribute (see TransformFuncs).
+type TransformFunc func(v interface{}, attr Attr) (interface{}, error)
+
+// TransformFuncs is a Transformer made of two functions. A nil function leaves the values
+// unchanged. It is meant to be used as a pointer, which keeps Attr comparable:
+//
+//	attr.Transformer = &TransformFuncs{In: lowercase}
+type TransformFuncs struct {
+	In  TransformFunc
+	Out TransformFunc
+}
+
+// TransformIn calls In if it is not nil.
+func (t *TransformFuncs) TransformIn(v interface{}, attr Attr) (interface{}, error) {
+	if t.In == nil {
+		return v, nil
+	}
+
+	return t.In(v, attr)
+}
+
+// TransformOut calls Out if it is not nil.
+func (t *TransformFuncs) TransformOut(v interface{}, attr Attr) (interface{}, error) {
+	if t.Out == nil {
+		return v, nil
+	}
+
+	return t.Out(v, attr)
+}
+
 type typeRegistry struct {
 	names       map[int]string
 	namesR      map[string]int
@@ -88,7 +148,14 @@ func GetZeroValue(typ int, array, nullable bool) (interface{}, error) {
 }
 
 // UnmarshalToType unmarshalls the data into a value of the type represented by the attribute.
+//
+// If the attribute has its own Unmarshaler, it is used instead of the TypeUnmarshalerFunc of the
+// registered attribute type.
 func UnmarshalToType(data []byte, attr Attr) (interface{}, error) {
+	if attr.Unmarshaler != nil {
+		return attr.Unmarshaler.UnmarshalToType(data, attr)
+	}
+
 	fn, ok := registry.unmarshaler[attr.Type]
 	if !ok {
 		return nil, fmt.Errorf("jsonapi: unregistered attribute type %q", attr.Type)
@@ -97,6 +164,19 @@ func UnmarshalToType(data []byte, attr Attr) (interface{}, error) {
 	return fn(data, attr)
 }
 
+// attrZeroValue returns the zero value of attr. The Unmarshaler of the attribute
+// is used if it is set, otherwise GetZeroValue is called. nil is returned if
+// the attribute type is not registered.
+func attrZeroValue(attr Attr) interface{} {
+	if attr.Unmarshaler != nil {
+		return attr.Unmarshaler.GetZeroValue(attr.Type, attr.Array, attr.Nullable)
+	}
+
+	zv, _ := GetZeroValue(attr.Type, attr.Array, attr.Nullable)
+
+	return zv
+}
+
 // GetAttrTypeName returns the public name for the attribute type. If set, the name is processed
 // by the NameFunc before it is returned.
 func GetAttrTypeName(typ int, array, nullable bool) (string, error) {
@@ -122,6 +202,12 @@ func attrTypeRegistered(typ int) bool {
 	return ok
 }
 
+// attrTypeByName returns the attribute type registered under name.
+func attrTypeByName(name string) (int, bool) {
+	typ, ok := registry.namesR[name]
+	return typ, ok
+}
+
 // DefaultNameFunc appends to the attribute type in human-readable form whether this is
 // an array, nullable, or both. Arrays are suffixed with "[]", if the type is nullable
 // "(nullable)" is appended.
@@ -210,13 +296,13 @@ func basicUnmarshalerFunc(data []byte, attr Attr) (interface{}, error) {
 				v = ia
 			}
 		} else {
-			v, err = strconv.Atoi(string(data))
+			v, err = strconv.ParseInt(string(data), 10, 8)
 
 			if attr.Nullable {
-				n := int8(v.(int))
+				n := int8(v.(int64))
 				v = &n
 			} else {
-				v = int8(v.(int))
+				v = int8(v.(int64))
 			}
 		}
 	case AttrTypeInt16:
@@ -230,13 +316,13 @@ func basicUnmarshalerFunc(data []byte, attr Attr) (interface{}, error) {
 				v = ia
 			}
 		} else {
-			v, err = strconv.Atoi(string(data))
+			v, err = strconv.ParseInt(string(data), 10, 16)
 
 			if attr.Nullable {
-				n := int16(v.(int))
+				n := int16(v.(int64))
 				v = &n
 			} else {
-				v = int16(v.(int))
+				v = int16(v.(int64))
 			}
 		}
 	case AttrTypeInt32:
@@ -250,13 +336,13 @@ func basicUnmarshalerFunc(data []byte, attr Attr) (interface{}, error) {
 				v = ia
 			}
 		} else {
-			v, err = strconv.Atoi(string(data))
+			v, err = strconv.ParseInt(string(data), 10, 32)
 
 			if attr.Nullable {
-				n := int32(v.(int))
+				n := int32(v.(int64))
 				v = &n
 			} else {
-				v = int32(v.(int))
+				v = int32(v.(int64))
 			}
 		}
 	case AttrTypeInt64:
@@ -270,13 +356,13 @@ func basicUnmarshalerFunc(data []byte, attr Attr) (interface{}, error) {
 				v = ia
 			}
 		} else {
-			v, err = strconv.Atoi(string(data))
+			v, err = strconv.ParseInt(string(data), 10, 64)
 
 			if attr.Nullable {
-				n := int64(v.(int))
+				n := int64(v.(int64))
 				v = &n
 			} else {
-				v = int64(v.(int))
+				v = int64(v.(int64))
 			}
 		}
 	case AttrTypeUint:
@@ -290,7 +376,7 @@ func basicUnmarshalerFunc(data []byte, attr Attr) (interface{}, error) {
 				v = ia
 			}
 		} else {
-			v, err = strconv.ParseUint(string(data), 10, 64)
+			v, err = strconv.ParseUint(string(data), 10, strconv.IntSize)
 
 			if attr.Nullable {
 				n := uint(v.(uint64))
@@ -381,8 +467,13 @@ func basicUnmarshalerFunc(data []byte, attr Attr) (interface{}, error) {
 		}
 	case AttrTypeFloat32:
 		if attr.Array {
-			var fa []float32
-			err = json.Unmarshal(data, &fa)
+			fa := []float32{}
+			err = unmarshalArray(data, func(elem []byte) error {
+				f, err := parseFloat32(elem)
+				fa = append(fa, f)
+
+				return err
+			})
 
 			if attr.Nullable {
 				v = &fa
@@ -390,13 +481,13 @@ func basicUnmarshalerFunc(data []byte, attr Attr) (interface{}, error) {
 				v = fa
 			}
 		} else {
-			var f64 float64
-			f64, err = strconv.ParseFloat(string(data), 32)
+			var f float32
+			f, err = parseFloat32(data)
+
 			if attr.Nullable {
-				n := float32(f64)
-				v = &n
+				v = &f
 			} else {
-				v = float32(f64)
+				v = f
 			}
 		}
 	case AttrTypeFloat64:
@@ -446,7 +537,12 @@ func basicUnmarshalerFunc(data []byte, attr Attr) (interface{}, error) {
 	case AttrTypeTime:
 		if attr.Array {
 			var ta []time.Time
-			err = json.Unmarshal(data, &ta)
+			err = unmarshalArray(data, func(elem []byte) error {
+				t, err := parseTime(elem, attr.Format)
+				ta = append(ta, t)
+
+				return err
+			})
 
 			if attr.Nullable {
 				v = &ta
@@ -455,7 +551,7 @@ func basicUnmarshalerFunc(data []byte, attr Attr) (interface{}, error) {
 			}
 		} else {
 			var t time.Time
-			err = json.Unmarshal(data, &t)
+			t, err = parseTime(data, attr.Format)
 
 			if attr.Nullable {
 				v = &t
@@ -463,6 +559,95 @@ func basicUnmarshalerFunc(data []byte, attr Attr) (interface{}, error) {
 				v = t
 			}
 		}
+	case AttrTypeDuration:
+		if attr.Array {
+			var da []time.Duration
+			err = unmarshalArray(data, func(elem []byte) error {
+				d, err := parseDuration(elem)
+				da = append(da, d)
+
+				return err
+			})
+
+			if attr.Nullable {
+				v = &da
+			} else {
+				v = da
+			}
+		} else {
+			var d time.Duration
+			d, err = parseDuration(data)
+
+			if attr.Nullable {
+				v = &d
+			} else {
+				v = d
+			}
+		}
+	case AttrTypeDecimal:
+		if attr.Array {
+			var da []Decimal
+			err = json.Unmarshal(data, &da)
+
+			if attr.Nullable {
+				v = &da
+			} else {
+				v = da
+			}
+		} else {
+			var d Decimal
+			err = json.Unmarshal(data, &d)
+
+			if attr.Nullable {
+				v = &d
+			} else {
+				v = d
+			}
+		}
+	case AttrTypeUUID:
+		if attr.Array {
+			var ua []UUID
+			err = json.Unmarshal(data, &ua)
+
+			if attr.Nullable {
+				v = &ua
+			} else {
+				v = ua
+			}
+		} else {
+			var u UUID
+			err = json.Unmarshal(data, &u)
+
+			if attr.Nullable {
+				v = &u
+			} else {
+				v = u
+			}
+		}
+	case AttrTypeJSON:
+		if attr.Array {
+			var ja []json.RawMessage
+			err = json.Unmarshal(data, &ja)
+
+			if attr.Nullable {
+				v = &ja
+			} else {
+				v = ja
+			}
+		} else {
+			j := make(json.RawMessage, len(data))
+			copy(j, data)
+
+			if !json.Valid(j) {
+				err = errors.New("invalid JSON value")
+			}
+
+			if attr.Nullable {
+				v = &j
+			} else {
+				v = j
+			}
+		}
 	case AttrTypeBytes:
 		s := make([]byte, len(data))
 		err := json.Unmarshal(data, &s)
@@ -485,6 +670,43 @@ func basicUnmarshalerFunc(data []byte, attr Attr) (interface{}, error) {
 	return v, nil
 }
 
+// parseFloat32 parses the JSON number data as a float32. Unlike a conversion,
+// an error is returned if the number is too big for a float32, or if it is not
+// zero but too small to be anything else than zero.
+func parseFloat32(data []byte) (float32, error) {
+	f, err := strconv.ParseFloat(string(data), 32)
+	if errors.Is(err, strconv.ErrRange) {
+		return 0, fmt.Errorf("%s is out of range for float32", data)
+	} else if err != nil {
+		return 0, err
+	}
+
+	if f == 0 {
+		// The number might only be zero because of the precision of float32.
+		if f64, _ := strconv.ParseFloat(string(data), 64); f64 != 0 {
+			return 0, fmt.Errorf("%s is out of range for float32", data)
+		}
+	}
+
+	return float32(f), nil
+}
+
+// unmarshalArray calls fn with each element of the JSON array data.
+func unmarshalArray(data []byte, fn func(elem []byte) error) error {
+	var elems []json.RawMessage
+	if err := json.Unmarshal(data, &elems); err != nil {
+		return err
+	}
+
+	for _, elem := range elems {
+		if err := fn(elem); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
 // basicZeroValueFunc is the default ZeroValueFunc for all attribute types that are supported by
 // jsonapi out of the box (see constants).
 func basicZeroValueFunc(t int, array, nullable bool) interface{} {
@@ -658,6 +880,50 @@ func basicZeroValueFunc(t int, array, nullable bool) interface{} {
 		}
 
 		return time.Time{}
+	case AttrTypeDecimal:
+		switch {
+		case nullable && array:
+			return (*[]Decimal)(nil)
+		case array:
+			return []Decimal{}
+		case nullable:
+			return (*Decimal)(nil)
+		}
+
+		return Decimal{}
+	case AttrTypeUUID:
+		switch {
+		case nullable && array:
+			return (*[]UUID)(nil)
+		case array:
+			return []UUID{}
+		case nullable:
+			return (*UUID)(nil)
+		}
+
+		return UUID{}
+	case AttrTypeJSON:
+		switch {
+		case nullable && array:
+			return (*[]json.RawMessage)(nil)
+		case array:
+			return []json.RawMessage{}
+		case nullable:
+			return (*json.RawMessage)(nil)
+		}
+
+		return json.RawMessage("{}")
+	case AttrTypeDuration:
+		switch {
+		case nullable && array:
+			return (*[]time.Duration)(nil)
+		case array:
+			return []time.Duration{}
+		case nullable:
+			return (*time.Duration)(nil)
+		}
+
+		return time.Duration(0)
 	default:
 		return nil
 	}
@@ -2,7 +2,9 @@ package jsonapi_test
 
 import (
 	"encoding/json"
+	"fmt"
 	"math"
+	"reflect"
 	"testing"
 	"time"
 
@@ -33,6 +35,10 @@ func TestGetZeroValue(t *testing.T) {
 			{val: float64(0), typ: AttrTypeFloat64, arr: false, null: false},
 			{val: false, typ: AttrTypeBool, arr: false, null: false},
 			{val: time.Time{}, typ: AttrTypeTime, arr: false, null: false},
+			{val: Decimal{}, typ: AttrTypeDecimal, arr: false, null: false},
+			{val: UUID{}, typ: AttrTypeUUID, arr: false, null: false},
+			{val: json.RawMessage("{}"), typ: AttrTypeJSON, arr: false, null: false},
+			{val: time.Duration(0), typ: AttrTypeDuration, arr: false, null: false},
 		},
 		"array": {
 			{val: []string{}, typ: AttrTypeString, arr: true, null: false},
@@ -50,6 +56,10 @@ func TestGetZeroValue(t *testing.T) {
 			{val: []float64{}, typ: AttrTypeFloat64, arr: true, null: false},
 			{val: []bool{}, typ: AttrTypeBool, arr: true, null: false},
 			{val: []time.Time{}, typ: AttrTypeTime, arr: true, null: false},
+			{val: []Decimal{}, typ: AttrTypeDecimal, arr: true, null: false},
+			{val: []UUID{}, typ: AttrTypeUUID, arr: true, null: false},
+			{val: []json.RawMessage{}, typ: AttrTypeJSON, arr: true, null: false},
+			{val: []time.Duration{}, typ: AttrTypeDuration, arr: true, null: false},
 		},
 		"nullable": {
 			{val: (*string)(nil), typ: AttrTypeString, arr: false, null: true},
@@ -67,6 +77,10 @@ func TestGetZeroValue(t *testing.T) {
 			{val: (*float64)(nil), typ: AttrTypeFloat64, arr: false, null: true},
 			{val: (*bool)(nil), typ: AttrTypeBool, arr: false, null: true},
 			{val: (*time.Time)(nil), typ: AttrTypeTime, arr: false, null: true},
+			{val: (*Decimal)(nil), typ: AttrTypeDecimal, arr: false, null: true},
+			{val: (*UUID)(nil), typ: AttrTypeUUID, arr: false, null: true},
+			{val: (*json.RawMessage)(nil), typ: AttrTypeJSON, arr: false, null: true},
+			{val: (*time.Duration)(nil), typ: AttrTypeDuration, arr: false, null: true},
 		},
 		"nullable array": {
 			{val: (*[]string)(nil), typ: AttrTypeString, arr: true, null: true},
@@ -84,6 +98,10 @@ func TestGetZeroValue(t *testing.T) {
 			{val: (*[]float64)(nil), typ: AttrTypeFloat64, arr: true, null: true},
 			{val: (*[]bool)(nil), typ: AttrTypeBool, arr: true, null: true},
 			{val: (*[]time.Time)(nil), typ: AttrTypeTime, arr: true, null: true},
+			{val: (*[]Decimal)(nil), typ: AttrTypeDecimal, arr: true, null: true},
+			{val: (*[]UUID)(nil), typ: AttrTypeUUID, arr: true, null: true},
+			{val: (*[]json.RawMessage)(nil), typ: AttrTypeJSON, arr: true, null: true},
+			{val: (*[]time.Duration)(nil), typ: AttrTypeDuration, arr: true, null: true},
 		},
 		"bytes": {
 			{val: []uint8{}, typ: AttrTypeBytes, arr: false, null: false},
@@ -273,6 +291,97 @@ func TestUnmarshalToType(t *testing.T) {
 	})
 }
 
+func TestUnmarshalToTypeIntBounds(t *testing.T) {
+	tests := []struct {
+		typ      int
+		min, max string
+		under    string
+		over     string
+	}{
+		{typ: AttrTypeInt8, min: "-128", max: "127", under: "-129", over: "128"},
+		{typ: AttrTypeInt16, min: "-32768", max: "32767", under: "-32769", over: "32768"},
+		{
+			typ: AttrTypeInt32,
+			min: "-2147483648", max: "2147483647",
+			under: "-2147483649", over: "2147483648",
+		},
+		{
+			typ: AttrTypeInt64,
+			min: "-9223372036854775808", max: "9223372036854775807",
+			under: "-9223372036854775809", over: "9223372036854775808",
+		},
+		{typ: AttrTypeUint8, min: "0", max: "255", under: "-1", over: "256"},
+		{typ: AttrTypeUint16, min: "0", max: "65535", under: "-1", over: "65536"},
+		{typ: AttrTypeUint32, min: "0", max: "4294967295", under: "-1", over: "4294967296"},
+		{
+			typ: AttrTypeUint64,
+			min: "0", max: "18446744073709551615",
+			under: "-1", over: "18446744073709551616",
+		},
+	}
+
+	for _, test := range tests {
+		name, _ := GetAttrTypeName(test.typ, false, false)
+
+		t.Run(name, func(t *testing.T) {
+			assert := assert.New(t)
+
+			for _, nullable := range []bool{false, true} {
+				attr := Attr{Type: test.typ, Nullable: nullable}
+
+				for _, data := range []string{test.min, test.max} {
+					_, err := UnmarshalToType([]byte(data), attr)
+					assert.NoError(err, data)
+				}
+
+				for _, data := range []string{test.under, test.over} {
+					_, err := UnmarshalToType([]byte(data), attr)
+					assert.Error(err, data)
+				}
+
+				attr.Array = true
+
+				_, err := UnmarshalToType([]byte("["+test.min+","+test.max+"]"), attr)
+				assert.NoError(err)
+
+				_, err = UnmarshalToType([]byte("["+test.min+","+test.over+"]"), attr)
+				assert.Error(err)
+			}
+		})
+	}
+}
+
+func TestUnmarshalToTypeFloat32Range(t *testing.T) {
+	assert := assert.New(t)
+
+	attr := Attr{Type: AttrTypeFloat32}
+	arrAttr := Attr{Type: AttrTypeFloat32, Array: true}
+
+	for _, data := range []string{"3.4028235e38", "-3.4028235e38", "1e-45", "0", "-0.0"} {
+		_, err := UnmarshalToType([]byte(data), attr)
+		assert.NoError(err, data)
+
+		_, err = UnmarshalToType([]byte("["+data+"]"), arrAttr)
+		assert.NoError(err, data)
+	}
+
+	for _, data := range []string{"3.5e38", "-3.5e38", "1e-50", "1e400"} {
+		_, err := UnmarshalToType([]byte(data), attr)
+		assert.Error(err, data)
+
+		_, err = UnmarshalToType([]byte("[1,"+data+"]"), arrAttr)
+		assert.Error(err, data)
+	}
+
+	_, err := UnmarshalToType([]byte("3.5e38"), attr)
+	assert.EqualError(err, "3.5e38 is out of range for float32")
+
+	// float64 is not affected.
+	v, err := UnmarshalToType([]byte("3.5e38"), Attr{Type: AttrTypeFloat64})
+	assert.NoError(err)
+	assert.Equal(3.5e38, v)
+}
+
 func TestRegisterAttrType(t *testing.T) {
 	assert.Panics(t, func() {
 		RegisterAttrType(AttrTypeInvalid, "test", nil, nil)
@@ -321,3 +430,78 @@ func goLikeNameFunc(name string, array, nullable bool) string {
 
 	return name
 }
+
+// point is an attribute value marshaled as a "x,y" string.
+type point struct{ X, Y int }
+
+type pointMarshaler struct{}
+
+func (pointMarshaler) MarshalAttr(v interface{}, attr Attr) ([]byte, error) {
+	if p, ok := v.(*point); ok {
+		if p == nil {
+			return []byte("null"), nil
+		}
+
+		v = *p
+	}
+
+	p := v.(point)
+
+	return json.Marshal(fmt.Sprintf("%d,%d", p.X, p.Y))
+}
+
+func TestAttrUnmarshalerMarshaler(t *testing.T) {
+	assert := assert.New(t)
+
+	// The attribute type does not need to be registered.
+	attr := Attr{
+		Name:        "position",
+		Type:        9000,
+		Unmarshaler: ReflectTypeUnmarshaler{Type: reflect.TypeOf(point{})},
+		Marshaler:   pointMarshaler{},
+	}
+
+	v, err := UnmarshalToType([]byte(`{"X":1,"Y":2}`), attr)
+	assert.NoError(err)
+	assert.Equal(point{X: 1, Y: 2}, v)
+
+	typ := Type{Name: "shapes"}
+	typ.MustAddAttr(attr)
+	typ.MustAddAttr(Attr{
+		Name:        "origin",
+		Type:        9000,
+		Nullable:    true,
+		Unmarshaler: ReflectTypeUnmarshaler{Type: reflect.TypeOf(point{})},
+		Marshaler:   pointMarshaler{},
+	})
+	assert.Error(typ.AddAttr(Attr{Name: "other", Type: 9000}))
+
+	// SoftResource
+	res := &SoftResource{Type: &typ}
+	assert.Equal(point{}, res.Get("position"))
+	assert.Equal((*point)(nil), res.Get("origin"))
+
+	res.Set("position", point{X: 3, Y: 4})
+	res.Set("origin", "invalid")
+	assert.Equal(point{X: 3, Y: 4}, res.Get("position"))
+	assert.Equal((*point)(nil), res.Get("origin"))
+
+	// Marshaling
+	res.SetID("s1")
+
+	pl := MarshalResource(res, "", []string{"position", "origin"}, nil)
+	assert.Contains(string(pl), `"attributes":{"origin":null,"position":"3,4"}`)
+
+	// Unmarshaling
+	schema := &Schema{}
+	schema.MustAddType(typ)
+
+	r, err := UnmarshalResource([]byte(`{
+		"id": "s1",
+		"type": "shapes",
+		"attributes": {"origin": {"X": 5, "Y": 6}}
+	}`), schema)
+	assert.NoError(err)
+	assert.Equal(&point{X: 5, Y: 6}, r.Get("origin"))
+	assert.Equal(point{}, r.Get("position"))
+}
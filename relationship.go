@@ -0,0 +1,82 @@
+package jsonapi
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+)
+
+// MarshalRelationship marshals the relationship named rel of r into the payload
+// of a relationship endpoint, like /articles/1/relationships/author.
+//
+// The payload is the relationship object with its linkage as primary data, its
+// self and related links, and its meta values if any. An error is returned if
+// r has no relationship named rel.
+func MarshalRelationship(r Resource, rel string, prepath string) ([]byte, error) {
+	return MarshalOptions{}.MarshalRelationship(r, rel, prepath)
+}
+
+// MarshalRelationship marshals a relationship like the MarshalRelationship
+// function does, according to the options. The linkage is always written, even
+// if it is larger than MaxLinkage.
+func (o MarshalOptions) MarshalRelationship(r Resource, rel string,
+	prepath string) ([]byte, error) {
+	rl, ok := r.Rels()[rel]
+	if !ok {
+		return nil, fmt.Errorf("jsonapi: type %q has no relationship %q", r.GetType().Name, rel)
+	}
+
+	o.MaxLinkage = 0
+
+	w := o.writer()
+	defer o.release(w)
+
+	self := o.resourceLink(prepath, r.GetType().Name, r.Get("id").(string))
+	w.relationship(r, rl, self, true)
+
+	if w.err != nil {
+		return nil, w.err
+	}
+
+	return o.bytes(w), nil
+}
+
+var errMissingRelData = errors.New("jsonapi: missing data member in relationship payload")
+
+// UnmarshalRelationship reads the payload of a request made to a relationship
+// endpoint, like the body of a PATCH request that replaces the linkage of rel.
+//
+// The returned value is a RelData if rel is a to-one relationship or a
+// RelDataMany if it is a to-many relationship. It holds the identifiers and the
+// meta values found in the payload and can be given as it is to SoftResource.Set.
+//
+// The data member is required and must hold null or a resource identifier for a
+// to-one relationship, or an array of resource identifiers for a to-many one.
+// The types of the identifiers must be the type the relationship points to.
+func UnmarshalRelationship(data []byte, rel Rel, schema *Schema) (interface{}, error) {
+	var ske RawRelationship
+
+	if err := json.Unmarshal(data, &ske); err != nil {
+		return nil, payloadErr(err)
+	}
+
+	if ske.Data == nil {
+		return nil, payloadErr(errMissingRelData)
+	}
+
+	_, idens, err := unmarshalLinkage(ske.Data, rel.FromType, rel, schema)
+	if err != nil {
+		return nil, &srcError{ptr: true, src: "/data", error: payloadErr(err)}
+	}
+
+	if rel.ToOne {
+		rd := RelData{Meta: ske.Meta}
+		if len(idens) > 0 {
+			rd.Res = idens[0]
+		}
+
+		return rd, nil
+	}
+
+	return RelDataMany{Res: idens, Meta: ske.Meta}, nil
+}
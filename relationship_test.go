@@ -0,0 +1,116 @@
+package jsonapi_test
+
+import (
+	"testing"
+
+	. "github.com/mark-hartmann/jsonapi"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMarshalRelationship(t *testing.T) {
+	assert := assert.New(t)
+
+	typ := Type{Name: "articles"}
+	typ.MustAddRel(Rel{FromName: "author", ToType: "people", ToOne: true})
+	typ.MustAddRel(Rel{FromName: "tags", ToType: "tags"})
+
+	res := &SoftResource{Type: &typ}
+	res.SetID("a1")
+	res.Set("author", "p1")
+	res.Set("tags", RelDataMany{
+		Res:  Identifiers{{ID: "t2", Type: "tags"}, {ID: "t1", Type: "tags"}},
+		Meta: Meta{"sorted": false},
+	})
+
+	out, err := MarshalRelationship(res, "author", "https://example.org")
+	assert.NoError(err)
+	assert.Equal(`{"data":{"id":"p1","type":"people"},"links":{`+
+		`"related":"https://example.org/articles/a1/author",`+
+		`"self":"https://example.org/articles/a1/relationships/author"}}`, string(out))
+
+	// The linkage is always written.
+	out, err = MarshalOptions{MaxLinkage: 1}.MarshalRelationship(res, "tags", "")
+	assert.NoError(err)
+	assert.Equal(`{"data":[{"id":"t1","type":"tags"},{"id":"t2","type":"tags"}],"links":{`+
+		`"related":"/articles/a1/tags","self":"/articles/a1/relationships/tags"},`+
+		`"meta":{"sorted":false}}`, string(out))
+
+	// Empty relationships
+	res.Set("author", "")
+	res.Set("tags", []string{})
+
+	out, err = MarshalRelationship(res, "author", "")
+	assert.NoError(err)
+	assert.Contains(string(out), `{"data":null,`)
+
+	out, err = MarshalRelationship(res, "tags", "")
+	assert.NoError(err)
+	assert.Contains(string(out), `{"data":[],`)
+
+	_, err = MarshalRelationship(res, "unknown", "")
+	assert.EqualError(err, `jsonapi: type "articles" has no relationship "unknown"`)
+}
+
+func TestUnmarshalRelationship(t *testing.T) {
+	assert := assert.New(t)
+
+	typ := Type{Name: "articles"}
+	typ.MustAddRel(Rel{FromName: "author", ToType: "people", ToOne: true})
+	typ.MustAddRel(Rel{FromName: "tags", ToType: "tags"})
+
+	schema := &Schema{}
+	schema.MustAddType(typ)
+	schema.MustAddType(Type{Name: "people"})
+	schema.MustAddType(Type{Name: "tags"})
+
+	author, tags := typ.Rels["author"], typ.Rels["tags"]
+
+	v, err := UnmarshalRelationship([]byte(`{"data":{"id":"p1","type":"people"}}`), author, schema)
+	assert.NoError(err)
+	assert.Equal(RelData{Res: Identifier{ID: "p1", Type: "people"}}, v)
+
+	v, err = UnmarshalRelationship([]byte(`{"data":null,"meta":{"a":1}}`), author, schema)
+	assert.NoError(err)
+	assert.Equal(RelData{Meta: Meta{"a": float64(1)}}, v)
+
+	v, err = UnmarshalRelationship(
+		[]byte(`{"data":[{"id":"t2","type":"tags"},{"id":"t1","type":"tags"}]}`), tags, schema)
+	assert.NoError(err)
+	assert.Equal(RelDataMany{
+		Res: Identifiers{{ID: "t2", Type: "tags"}, {ID: "t1", Type: "tags"}},
+	}, v)
+
+	// The value can be set on a resource.
+	res := &SoftResource{Type: &typ}
+	res.Set("tags", v)
+	assert.Equal([]string{"t2", "t1"}, res.Get("tags"))
+
+	v, err = UnmarshalRelationship([]byte(`{"data":[]}`), tags, schema)
+	assert.NoError(err)
+	assert.Equal(RelDataMany{Res: Identifiers{}}, v)
+
+	// Invalid payloads
+	for _, test := range []struct {
+		payload string
+		rel     Rel
+		err     string
+	}{
+		{`{}`, author, "jsonapi: missing data member in relationship payload"},
+		{
+			`{"data":[]}`, author,
+			"json: cannot unmarshal array into Go value of type jsonapi.Identifier",
+		},
+		{
+			`{"data":{"id":"t1","type":"tags"}}`, tags,
+			"json: cannot unmarshal object into Go value of type jsonapi.Identifiers",
+		},
+		{`{"data":[{"id":"t1"}]}`, tags, "jsonapi: resource identifier has no type"},
+		{`{"data":[{"id":"t1","type":"unknown"}]}`, tags, `jsonapi: resource type "unknown" does not exist`},
+		{`invalid`, tags, "invalid character 'i' looking for beginning of value"},
+	} {
+		_, err := UnmarshalRelationship([]byte(test.payload), test.rel, schema)
+		assert.EqualError(err, test.err, test.payload)
+		assert.ErrorIs(err, ErrInvalidPayload, test.payload)
+	}
+}
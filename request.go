@@ -1,10 +1,18 @@
 package jsonapi
 
 import (
+	"bytes"
+	"encoding/json"
 	"fmt"
+	"io"
+	"io/ioutil"
 	"net/http"
 )
 
+// DefaultMaxBodySize is the maximum size in bytes of a request body accepted by
+// ParseRequest when RequestOptions.MaxBodySize is 0.
+const DefaultMaxBodySize = 1 << 20
+
 // NewRequest builds a *Request based on a *http.Request and validated by a *Schema.
 func NewRequest(r *http.Request, schema *Schema) (*Request, error) {
 	su, err := NewSimpleURL(r.URL)
@@ -36,8 +44,313 @@ func NewRequest(r *http.Request, schema *Schema) (*Request, error) {
 }
 
 // A Request represents a JSON:API request.
+//
+// ContentType and Accept are only set by ParseRequest.
 type Request struct {
 	Method string
 	URL    *URL
 	Doc    *Document
+
+	// ContentType holds the options of the media type of the body.
+	ContentType MediaTypeOptions
+
+	// Accept holds the options of the media type negotiated for the response.
+	Accept MediaTypeOptions
+}
+
+// RequestOptions configures how ParseRequest reads a request.
+type RequestOptions struct {
+	// MaxBodySize is the maximum size in bytes of the body. DefaultMaxBodySize
+	// is used if it is 0 and the size is not limited if it is negative.
+	MaxBodySize int64
+
+	// SupportedExt lists the extensions supported by the server. It is passed
+	// to NegotiateAccept.
+	SupportedExt []string
+
+	// URL is used to parse the URL.
+	URL URLOptions
+
+	// Unmarshal is used to unmarshal the body.
+	Unmarshal UnmarshalOptions
+}
+
+// ParseRequest reads r and returns a *Request validated by schema. It is a
+// shortcut for RequestOptions{}.ParseRequest.
+func ParseRequest(r *http.Request, schema *Schema) (*Request, error) {
+	return RequestOptions{}.ParseRequest(r, schema)
+}
+
+// ParseRequest reads r and returns a *Request validated by schema.
+//
+// Unlike NewRequest, it checks the Accept header with NegotiateAccept and, if
+// the request has a body, the Content-Type header with ParseMediaType. The body
+// is then unmarshaled according to the method and the endpoint:
+//
+//	POST on a collection            a resource (Doc.Data is a Resource)
+//	PATCH on a resource             a partial resource (Doc.Data is a *SoftResource)
+//	PATCH on a to-one relationship  an Identifier or null (Doc.Data is an Identifier or nil)
+//	POST, PATCH or DELETE on a
+//	to-many relationship            Identifiers (Doc.Data is Identifiers)
+//
+// Any other method that comes with a body is refused with a 405 Method Not
+// Allowed Error. The resource of a POST or a PATCH request must be of the type
+// of the endpoint, and the resource of a PATCH request must have the ID found
+// in the URL, otherwise a 409 Conflict Error is returned. The identifiers sent
+// to a relationship must be of the target type of the relationship.
+//
+// The returned errors are either Error objects or errors that an ErrorMapper
+// converts into Error objects with the right status and source.
+func (o RequestOptions) ParseRequest(r *http.Request, schema *Schema) (*Request, error) {
+	accept, err := NegotiateAccept(r.Header.Get("Accept"), o.SupportedExt...)
+	if err != nil {
+		return nil, err
+	}
+
+	su, err := NewSimpleURL(r.URL)
+	if err != nil {
+		return nil, err
+	}
+
+	url, err := o.URL.NewURL(schema, su)
+	if err != nil {
+		return nil, err
+	}
+
+	req := &Request{
+		Method: r.Method,
+		URL:    url,
+		Accept: accept,
+	}
+
+	if !hasBody(r.Method, url) {
+		return req, nil
+	}
+
+	req.ContentType, err = ParseMediaType(r.Header.Get("Content-Type"))
+	if err != nil {
+		return nil, err
+	}
+
+	body, err := o.readBody(r.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	switch {
+	case url.RelKind == "self":
+		req.Doc, err = unmarshalRelBody(r.Method, body, url, schema)
+	case r.Method == http.MethodPost && url.IsCol && url.RelKind == "":
+		req.Doc, err = o.unmarshalPostBody(body, url, schema)
+	case r.Method == http.MethodPatch && !url.IsCol && url.RelKind == "":
+		req.Doc, err = o.unmarshalPatchBody(body, url, schema)
+	default:
+		return nil, NewErrMethodNotAllowed()
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	return req, nil
+}
+
+// hasBody reports whether a request with the given method and URL is expected
+// to have a body.
+func hasBody(method string, url *URL) bool {
+	switch method {
+	case http.MethodPost, http.MethodPatch:
+		return true
+	case http.MethodDelete:
+		return url.RelKind == "self"
+	}
+
+	return false
+}
+
+// readBody reads the body while enforcing the maximum size.
+func (o RequestOptions) readBody(body io.Reader) ([]byte, error) {
+	if body == nil {
+		return nil, payloadErr(errMissingPrimaryMember)
+	}
+
+	max := o.MaxBodySize
+	if max == 0 {
+		max = DefaultMaxBodySize
+	}
+
+	if max < 0 {
+		return ioutil.ReadAll(body)
+	}
+
+	data, err := ioutil.ReadAll(io.LimitReader(body, max+1))
+	if err != nil {
+		return nil, err
+	}
+
+	if int64(len(data)) > max {
+		return nil, NewErrPayloadTooLarge()
+	}
+
+	return data, nil
+}
+
+// unmarshalPostBody unmarshals the body of a request that creates a resource.
+func (o RequestOptions) unmarshalPostBody(
+	body []byte, url *URL, schema *Schema,
+) (*Document, error) {
+	doc, err := o.Unmarshal.UnmarshalDocument(bytes.NewReader(body), schema)
+	if err != nil {
+		return nil, err
+	}
+
+	res, ok := doc.Data.(Resource)
+	if !ok {
+		return nil, &srcError{ptr: true, src: "/data", error: payloadErr(errMemberDataType)}
+	}
+
+	if typ := res.GetType().Name; typ != url.ResType {
+		return nil, typeConflict(typ, url.ResType)
+	}
+
+	return doc, nil
+}
+
+// unmarshalPatchBody unmarshals the body of a request that updates a resource.
+func (o RequestOptions) unmarshalPatchBody(
+	body []byte, url *URL, schema *Schema,
+) (*Document, error) {
+	ske, err := unmarshalBodySkeleton(body)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(ske.Data) == 0 || ske.Data[0] != '{' {
+		return nil, &srcError{ptr: true, src: "/data", error: payloadErr(errMemberDataType)}
+	}
+
+	// The type and the ID are checked first because the fields can only be
+	// validated against the right type.
+	var iden Identifier
+	_ = json.Unmarshal(ske.Data, &iden)
+
+	if iden.Type != url.ResType {
+		return nil, typeConflict(iden.Type, url.ResType)
+	}
+
+	if iden.ID != url.ResID {
+		e := NewErrConflict()
+		e.Detail = fmt.Sprintf("The ID %q does not match the ID %q of the URL.", iden.ID, url.ResID)
+		e.setSource(ErrorSource{Pointer: "/data/id"})
+
+		return nil, e
+	}
+
+	res, err := o.Unmarshal.UnmarshalPartialResource(ske.Data, schema)
+	if fe, ok := err.(FieldErrors); ok {
+		return nil, fe.withPrefix("/data")
+	} else if err != nil {
+		return nil, &srcError{ptr: true, src: "/data", error: err}
+	}
+
+	doc := newRequestDocument(ske)
+	doc.Data = res
+
+	return doc, nil
+}
+
+// unmarshalRelBody unmarshals the body of a request that modifies a
+// relationship.
+func unmarshalRelBody(method string, body []byte, url *URL, schema *Schema) (*Document, error) {
+	ske, err := unmarshalBodySkeleton(body)
+	if err != nil {
+		return nil, err
+	}
+
+	if ske.Data == nil {
+		return nil, payloadErr(errMissingPrimaryMember)
+	}
+
+	doc := newRequestDocument(ske)
+
+	if url.Rel.ToOne {
+		if method != http.MethodPatch {
+			return nil, NewErrMethodNotAllowed()
+		}
+
+		if string(ske.Data) == "null" {
+			return doc, nil
+		}
+
+		iden, err := UnmarshalIdentifier(ske.Data, schema)
+		if err != nil {
+			return nil, &srcError{ptr: true, src: "/data", error: payloadErr(err)}
+		}
+
+		if iden.Type != url.Rel.ToType {
+			return nil, identifierTypeErr("/data/type", iden.Type, url.Rel)
+		}
+
+		doc.Data = iden
+
+		return doc, nil
+	}
+
+	idens, err := UnmarshalIdentifiers(ske.Data, schema)
+	if err != nil {
+		return nil, &srcError{ptr: true, src: "/data", error: payloadErr(err)}
+	}
+
+	for i, iden := range idens {
+		if iden.Type != url.Rel.ToType {
+			return nil, identifierTypeErr(fmt.Sprintf("/data/%d/type", i), iden.Type, url.Rel)
+		}
+	}
+
+	doc.Data = idens
+
+	return doc, nil
+}
+
+// unmarshalBodySkeleton decodes the top-level members of a request body.
+func unmarshalBodySkeleton(body []byte) (*payloadSkeleton, error) {
+	ske := &payloadSkeleton{}
+	if err := json.Unmarshal(body, ske); err != nil {
+		return nil, payloadErr(err)
+	}
+
+	return ske, nil
+}
+
+// newRequestDocument returns a document holding the meta of ske.
+func newRequestDocument(ske *payloadSkeleton) *Document {
+	doc := &Document{
+		Included:  []Resource{},
+		Resources: map[string]map[string]struct{}{},
+		Links:     map[string]Link{},
+		RelData:   map[string][]string{},
+		Meta:      ske.Meta,
+	}
+
+	return doc
+}
+
+// typeConflict returns the error for a resource of type typ sent to an endpoint
+// of type expected.
+func typeConflict(typ, expected string) Error {
+	e := NewErrConflict()
+	e.Detail = fmt.Sprintf("The type %q does not match the type %q of the endpoint.",
+		typ, expected)
+	e.setSource(ErrorSource{Pointer: "/data/type"})
+
+	return e
+}
+
+// identifierTypeErr returns the error for an identifier of type typ sent to the
+// relationship rel.
+func identifierTypeErr(ptr, typ string, rel Rel) error {
+	err := fmt.Errorf("jsonapi: type %q does not match the type %q of relationship %q",
+		typ, rel.ToType, rel.FromName)
+
+	return &srcError{ptr: true, src: ptr, error: payloadErr(err)}
 }
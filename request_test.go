@@ -3,7 +3,9 @@ package jsonapi_test
 import (
 	"bytes"
 	"errors"
+	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
 
 	. "github.com/mark-hartmann/jsonapi"
@@ -91,6 +93,180 @@ func TestNewRequestInvalidBody(t *testing.T) {
 	assert.Nil(doc)
 }
 
+func TestParseRequest(t *testing.T) {
+	assert := assert.New(t)
+
+	schema := newMockSchema()
+	mapper := &ErrorMapper{}
+
+	newReq := func(method, url, body string) *http.Request {
+		req := httptest.NewRequest(method, url, strings.NewReader(body))
+		if body != "" {
+			req.Header.Set("Content-Type", MediaType)
+		}
+
+		return req
+	}
+
+	tests := []struct {
+		name   string
+		req    *http.Request
+		data   interface{}
+		status string
+		src    map[string]interface{}
+	}{
+		{
+			name: "get collection",
+			req:  newReq("GET", "/mocktypes1", ""),
+		}, {
+			name: "create resource",
+			req: newReq("POST", "/mocktypes1", `{
+				"data": {"type": "mocktypes1", "id": "mt1", "attributes": {"str": "a"}}
+			}`),
+			data: func() interface{} {
+				res := Wrap(&mockType1{ID: "mt1", Str: "a"})
+				return res
+			}(),
+		}, {
+			name: "update resource",
+			req: newReq("PATCH", "/mocktypes1/mt1", `{
+				"data": {"type": "mocktypes1", "id": "mt1", "attributes": {"int": 3}}
+			}`),
+			data: func() interface{} {
+				typ := Type{Name: "mocktypes1"}
+				typ.MustAddAttr(Attr{Name: "int", Type: AttrTypeInt})
+				res := &SoftResource{Type: &typ}
+				res.SetID("mt1")
+				res.Set("int", 3)
+
+				return res
+			}(),
+		}, {
+			name: "update to-one relationship",
+			req: newReq("PATCH", "/mocktypes1/mt1/relationships/to-one", `{
+				"data": {"type": "mocktypes2", "id": "mt2"}
+			}`),
+			data: Identifier{Type: "mocktypes2", ID: "mt2"},
+		}, {
+			name: "clear to-one relationship",
+			req:  newReq("PATCH", "/mocktypes1/mt1/relationships/to-one", `{"data": null}`),
+			data: nil,
+		}, {
+			name: "add to to-many relationship",
+			req: newReq("POST", "/mocktypes1/mt1/relationships/to-many", `{
+				"data": [{"type": "mocktypes2", "id": "mt2"}]
+			}`),
+			data: Identifiers{{Type: "mocktypes2", ID: "mt2"}},
+		}, {
+			name: "remove from to-many relationship",
+			req: newReq("DELETE", "/mocktypes1/mt1/relationships/to-many", `{
+				"data": []
+			}`),
+			data: Identifiers{},
+		}, {
+			name:   "unknown type",
+			req:    newReq("GET", "/unknown", ""),
+			status: "404",
+		}, {
+			name: "missing content type",
+			req: func() *http.Request {
+				req := newReq("POST", "/mocktypes1", `{"data": null}`)
+				req.Header.Del("Content-Type")
+
+				return req
+			}(),
+			status: "415",
+			src:    map[string]interface{}{"header": "Content-Type"},
+		}, {
+			name: "unacceptable",
+			req: func() *http.Request {
+				req := newReq("GET", "/mocktypes1", "")
+				req.Header.Set("Accept", MediaType+`; ext="https://example.org/ext"`)
+
+				return req
+			}(),
+			status: "406",
+			src:    map[string]interface{}{"header": "Accept"},
+		}, {
+			name:   "body on a resource",
+			req:    newReq("POST", "/mocktypes1/mt1", `{"data": null}`),
+			status: "405",
+		}, {
+			name:   "post to a to-one relationship",
+			req:    newReq("POST", "/mocktypes1/mt1/relationships/to-one", `{"data": null}`),
+			status: "405",
+		}, {
+			name: "type conflict",
+			req: newReq("POST", "/mocktypes1", `{
+				"data": {"type": "mocktypes2", "id": "mt2"}
+			}`),
+			status: "409",
+			src:    map[string]interface{}{"pointer": "/data/type"},
+		}, {
+			name: "id conflict",
+			req: newReq("PATCH", "/mocktypes1/mt1", `{
+				"data": {"type": "mocktypes1", "id": "mt2"}
+			}`),
+			status: "409",
+			src:    map[string]interface{}{"pointer": "/data/id"},
+		}, {
+			name: "invalid attribute",
+			req: newReq("PATCH", "/mocktypes1/mt1", `{
+				"data": {"type": "mocktypes1", "id": "mt1", "attributes": {"int": "a"}}
+			}`),
+			status: "400",
+			src:    map[string]interface{}{"pointer": "/data/attributes/int"},
+		}, {
+			name: "wrong identifier type",
+			req: newReq("POST", "/mocktypes1/mt1/relationships/to-many", `{
+				"data": [{"type": "mocktypes2", "id": "mt2"}, {"type": "mocktypes1", "id": "mt3"}]
+			}`),
+			status: "400",
+			src:    map[string]interface{}{"pointer": "/data/1/type"},
+		}, {
+			name:   "invalid json",
+			req:    newReq("PATCH", "/mocktypes1/mt1/relationships/to-one", `{`),
+			status: "400",
+		},
+	}
+
+	for _, test := range tests {
+		req, err := ParseRequest(test.req, schema)
+
+		if test.status != "" {
+			assert.Nil(req, test.name)
+
+			e := mapper.Map(err)
+			assert.Equal(test.status, e.Status, test.name)
+
+			if test.src != nil {
+				assert.Equal(test.src, e.Source, test.name)
+			}
+
+			continue
+		}
+
+		assert.NoError(err, test.name)
+		assert.Equal(test.req.Method, req.Method, test.name)
+
+		if req.Doc == nil {
+			assert.Nil(test.data, test.name)
+			continue
+		}
+
+		if res, ok := test.data.(Resource); ok {
+			assert.True(Equal(res, req.Doc.Data.(Resource)), test.name)
+		} else {
+			assert.Equal(test.data, req.Doc.Data, test.name)
+		}
+	}
+
+	// Body size
+	opts := RequestOptions{MaxBodySize: 10}
+	_, err := opts.ParseRequest(newReq("POST", "/mocktypes1", `{"data": null}`), schema)
+	assert.Equal("413", mapper.Map(err).Status)
+}
+
 type badReader struct {
 }
 
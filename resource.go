@@ -2,6 +2,7 @@ package jsonapi
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
 	"reflect"
 	"sort"
@@ -21,298 +22,151 @@ type Resource interface {
 	Set(key string, val interface{})
 }
 
-// MarshalResource marshals a Resource into a JSON-encoded payload.
-func MarshalResource(r Resource, prepath string, fields []string, relData map[string][]string) []byte {
-	mapPl := map[string]interface{}{}
-
-	mapPl["id"] = r.Get("id").(string)
-	mapPl["type"] = r.GetType().Name
-
-	// Attributes
-	attrs := map[string]interface{}{}
-
-	for _, attr := range r.Attrs() {
-		for _, field := range fields {
-			if field == attr.Name {
-				// AttrTypeUint8(Array=true) is handled like any other array.
-				// todo: check if there's a better way to do this
-				if attr.Type == AttrTypeUint8 && attr.Array {
-					v := r.Get(attr.Name)
-
-					var d *[]uint8
-
-					if attr.Nullable {
-						d = v.(*[]uint8)
-					} else {
-						a := v.([]uint8)
-						d = &a
-					}
-
-					attrs[attr.Name] = uint8Array{
-						Data:     d,
-						Nullable: attr.Nullable,
-					}
-
-					break
-				}
+// A GetterE is a Resource whose values can be read without panicking. GetE
+// returns an error, like an *UnknownFieldError, where Get would panic.
+//
+// The marshaling functions use GetE when a resource implements it, and return
+// its errors.
+type GetterE interface {
+	GetE(key string) (interface{}, error)
+}
 
-				attrs[attr.Name] = r.Get(attr.Name)
+// A SetterE is a Resource whose values can be set without panicking or being
+// silently ignored. SetE returns an error, like an *UnknownFieldError or an
+// *InvalidFieldValueError, where Set would not set the value.
+//
+// The unmarshaling functions use SetE when a resource implements it, and
+// return its errors.
+type SetterE interface {
+	SetE(key string, val interface{}) error
+}
 
-				break
-			}
-		}
+// getValue returns the value of the field named key of r, with GetE if r
+// implements GetterE.
+func getValue(r Resource, key string) (interface{}, error) {
+	if g, ok := r.(GetterE); ok {
+		return g.GetE(key)
 	}
 
-	if len(attrs) > 0 {
-		mapPl["attributes"] = attrs
-	}
+	return r.Get(key), nil
+}
 
-	// Relationships
-	rels := map[string]*json.RawMessage{}
+// setValue sets the value of the field named key of r, with SetE if r
+// implements SetterE.
+func setValue(r Resource, key string, val interface{}) error {
+	if s, ok := r.(SetterE); ok {
+		return s.SetE(key, val)
+	}
 
-	for _, rel := range r.Rels() {
-		include := false
+	r.Set(key, val)
 
-		for _, field := range fields {
-			if field == rel.FromName {
-				include = true
-				break
-			}
-		}
+	return nil
+}
 
-		if include {
-			var raw json.RawMessage
+// MarshalResource marshals a Resource into a JSON-encoded payload.
+func MarshalResource(r Resource, prepath string, fields []string, relData map[string][]string) []byte {
+	return MarshalOptions{}.MarshalResource(r, prepath, fields, relData)
+}
 
-			if rel.ToOne {
-				s := map[string]map[string]interface{}{
-					"links": buildRelationshipLinks(r, prepath, rel.FromName),
-				}
+// MarshalResource marshals a Resource like the MarshalResource function does,
+// according to the options.
+func (o MarshalOptions) MarshalResource(r Resource, prepath string, fields []string,
+	relData map[string][]string) []byte {
+	// NOTE An error should not happen.
+	payload, _ := o.marshalResource(r, prepath, fields, relData)
 
-				for _, n := range relData[r.GetType().Name] {
-					if n == rel.FromName {
-						id := r.Get(rel.FromName)
-
-						switch t := id.(type) {
-						case RelData:
-							// Relationship object meta.
-							if len(t.Meta) > 0 {
-								s["meta"] = t.Meta
-							}
-
-							// Relationship object links.
-							if len(t.Links) > 0 {
-								for s2, link := range t.Links {
-									// Don't override the rel links
-									if s2 != "self" && s2 != "related" {
-										s["links"][s2] = link
-									}
-								}
-							}
-
-							// Relationship data.
-							d := map[string]interface{}{
-								"id":   t.Res.ID,
-								"type": rel.ToType,
-							}
-
-							if len(t.Res.Meta) > 0 {
-								d["meta"] = t.Res.Meta
-							}
-
-							s["data"] = d
-						case string:
-							if t != "" {
-								s["data"] = map[string]interface{}{
-									"id":   r.Get(rel.FromName).(string),
-									"type": rel.ToType,
-								}
-							} else {
-								s["data"] = nil
-							}
-						}
-
-						break
-					}
-				}
+	return payload
+}
 
-				raw, _ = json.Marshal(s)
-				rels[rel.FromName] = &raw
-			} else {
-				s := map[string]interface{}{}
-				l := buildRelationshipLinks(r, prepath, rel.FromName)
-				for _, n := range relData[r.GetType().Name] {
-					if n == rel.FromName {
-						data := []map[string]interface{}{}
-						ids := r.Get(rel.FromName)
-
-						switch t := ids.(type) {
-						case RelDataMany:
-							// Relationship object meta.
-							if len(t.Meta) > 0 {
-								s["meta"] = t.Meta
-							}
-
-							// Relationship object links.
-							if len(t.Links) > 0 {
-								for s2, link := range t.Links {
-									// Don't override the rel links
-									if s2 != "self" && s2 != "related" {
-										l[s2] = link
-									}
-								}
-								s["links"] = l
-							}
-
-							// Relationship data.
-							sort.Slice(t.Res, func(i, j int) bool {
-								return t.Res[i].ID < t.Res[j].ID
-							})
-							for _, rd := range t.Res {
-								d := map[string]interface{}{
-									"id":   rd.ID,
-									"type": rel.ToType,
-								}
-
-								if len(rd.Meta) > 0 {
-									d["meta"] = rd.Meta
-								}
-								data = append(data, d)
-							}
-						case []string:
-							sort.Strings(ids.([]string))
-							for _, id := range ids.([]string) {
-								data = append(data, map[string]interface{}{
-									"id":   id,
-									"type": rel.ToType,
-								})
-							}
-						}
-
-						s["data"] = data
-						break
-					}
-				}
-				s["links"] = l
+// marshalResource marshals r like MarshalResource does, but returns the error
+// of an attribute Marshaler or Transformer instead of a nil payload.
+func (o MarshalOptions) marshalResource(r Resource, prepath string, fields []string,
+	relData map[string][]string) ([]byte, error) {
+	w := o.writer()
+	defer o.release(w)
 
-				raw, _ = json.Marshal(s)
-				rels[rel.FromName] = &raw
-			}
-		}
-	}
+	w.resource(r, prepath, fields, relData)
 
-	if len(rels) > 0 {
-		mapPl["relationships"] = rels
+	if w.err != nil {
+		return nil, w.err
 	}
 
-	// Links
-	if lh, ok := r.(LinkHolder); ok && len(lh.Links()) > 0 {
-		links := lh.Links()
-		links["self"] = Link{HRef: buildSelfLink(r, prepath)}
-		mapPl["links"] = links
-	} else {
-		mapPl["links"] = map[string]string{
-			"self": buildSelfLink(r, prepath),
-		}
-	}
+	return o.bytes(w), nil
+}
 
-	// Meta
-	if m, ok := r.(MetaHolder); ok {
-		if len(m.Meta()) > 0 {
-			mapPl["meta"] = m.Meta()
-		}
-	}
+// UnmarshalResource unmarshalls a JSON-encoded payload into a Resource.
+func UnmarshalResource(data []byte, schema *Schema) (Resource, error) {
+	return UnmarshalOptions{}.UnmarshalResource(data, schema)
+}
 
-	// NOTE An error should not happen.
-	pl, _ := json.Marshal(mapPl)
+// UnmarshalResource unmarshalls a JSON-encoded payload into a Resource like the
+// UnmarshalResource function does, according to the options.
+func (o UnmarshalOptions) UnmarshalResource(data []byte, schema *Schema) (Resource, error) {
+	res, _, err := o.unmarshalResource(data, schema)
 
-	return pl
+	return res, err
 }
 
-// UnmarshalResource unmarshalls a JSON-encoded payload into a Resource.
-func UnmarshalResource(data []byte, schema *Schema) (Resource, error) {
+// unmarshalResource unmarshals data like UnmarshalResource does and also
+// returns the unknown fields that were skipped.
+func (o UnmarshalOptions) unmarshalResource(data []byte,
+	schema *Schema) (Resource, []*UnknownFieldError, error) {
 	var rske resourceSkeleton
 	err := json.Unmarshal(data, &rske)
 
 	if err != nil {
-		return nil, payloadErr(err)
+		return nil, nil, payloadErr(err)
+	}
+
+	if err := o.checkMetaNames(&rske); err != nil {
+		return nil, nil, err
 	}
 
 	typ := schema.GetType(rske.Type)
 	res := typ.New()
 
-	res.Set("id", rske.ID)
+	// setErr is the first error returned by SetE.
+	var setErr error
 
-	for a, v := range rske.Attributes {
-		attr, ok := typ.Attrs[a]
-		if !ok {
-			return nil, &srcError{ptr: true, src: "/attributes", error: &UnknownFieldError{
-				Type:  typ.Name,
-				Field: a,
-			}}
+	set := func(key string, val interface{}) {
+		if err := setValue(res, key, val); err != nil && setErr == nil {
+			setErr = err
 		}
+	}
 
-		var val interface{}
+	set("id", rske.ID)
 
-		if val, err = UnmarshalToType(v, attr); err != nil {
-			name, _ := GetAttrTypeName(attr.Type, attr.Array, attr.Nullable)
-
-			return nil, &srcError{
-				src: "/attributes/" + attr.Name,
-				ptr: true,
-				error: &InvalidFieldValueError{
-					Type:      typ.Name,
-					Field:     attr.Name,
-					FieldType: name,
-					Value:     string(v),
-					asRel:     false,
-					err:       err,
-				},
-			}
-		}
+	skipped, err := o.unmarshalFields(&rske, typ, schema, func(attr Attr, val interface{}) {
+		set(attr.Name, val)
+	}, func(rel Rel, val interface{}, idens Identifiers) {
+		set(rel.FromName, val)
+		setLinkage(res, rel.FromName, idens)
+	})
+	if err != nil {
+		return nil, nil, err
+	}
 
-		res.Set(attr.Name, val)
+	if setErr != nil {
+		return nil, nil, fmt.Errorf("jsonapi: failed to set field: %w", setErr)
 	}
 
-	for r, v := range rske.Relationships {
-		if rel, ok := typ.Rels[r]; ok {
-			if len(v.Data) > 0 {
-				if rel.ToOne {
-					var iden Identifier
-					err = json.Unmarshal(v.Data, &iden)
-					res.Set(rel.FromName, iden.ID)
-				} else {
-					var idens Identifiers
-					err = json.Unmarshal(v.Data, &idens)
-					ids := make([]string, len(idens))
-					for i := range idens {
-						ids[i] = idens[i].ID
-					}
-					res.Set(rel.FromName, ids)
-				}
-			}
+	setRelMeta(res, typ, schema, &rske)
+	setRawRels(res, &rske)
 
-			if err != nil {
-				return nil, &srcError{
-					ptr:   true,
-					src:   "/relationships/" + rel.FromName,
-					error: payloadErr(err),
-				}
-			}
-		} else {
-			return nil, &srcError{src: "/relationships", ptr: true, error: &UnknownFieldError{
-				Type:  typ.Name,
-				Field: r,
-				asRel: true,
-			}}
-		}
+	if err := o.setLinks(res, &rske); err != nil {
+		return nil, nil, err
 	}
 
+	o.setSkippedFields(res, skipped)
+
 	// Meta
 	if m, ok := res.(MetaHolder); ok {
 		m.SetMeta(rske.Meta)
 	}
 
-	return res, nil
+	setVersion(res, rske.Meta)
+
+	return res, skipped, nil
 }
 
 // UnmarshalPartialResource unmarshalls the given payload into a *SoftResource.
@@ -327,6 +181,14 @@ func UnmarshalResource(data []byte, schema *Schema) (Resource, error) {
 // are added and set to their zero value, but UnmarshalPartialResource does not
 // do that. Therefore, the user is able to tell which fields have been set.
 func UnmarshalPartialResource(data []byte, schema *Schema) (*SoftResource, error) {
+	return UnmarshalOptions{}.UnmarshalPartialResource(data, schema)
+}
+
+// UnmarshalPartialResource unmarshalls the given payload into a *SoftResource
+// like the UnmarshalPartialResource function does, according to the options.
+func (o UnmarshalOptions) UnmarshalPartialResource(
+	data []byte, schema *Schema,
+) (*SoftResource, error) {
 	var rske resourceSkeleton
 	err := json.Unmarshal(data, &rske)
 
@@ -334,6 +196,10 @@ func UnmarshalPartialResource(data []byte, schema *Schema) (*SoftResource, error
 		return nil, payloadErr(err)
 	}
 
+	if err := o.checkMetaNames(&rske); err != nil {
+		return nil, err
+	}
+
 	typ := schema.GetType(rske.Type)
 	newType := Type{
 		Name: typ.Name,
@@ -343,23 +209,89 @@ func UnmarshalPartialResource(data []byte, schema *Schema) (*SoftResource, error
 		id:   rske.ID,
 	}
 
+	skipped, err := o.unmarshalFields(&rske, typ, schema, func(attr Attr, val interface{}) {
+		_ = newType.AddAttr(attr)
+		res.Set(attr.Name, val)
+	}, func(rel Rel, val interface{}, idens Identifiers) {
+		_ = newType.AddRel(rel)
+		res.Set(rel.FromName, val)
+		setLinkage(res, rel.FromName, idens)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	setRelMeta(res, typ, schema, &rske)
+	setRawRels(res, &rske)
+	o.setSkippedFields(res, skipped)
+
+	// The version is kept so that it can be checked (see CheckVersion).
+	if version, ok := rske.Meta.LookupString(MetaVersion); ok {
+		res.SetMeta(Meta{MetaVersion: version})
+	}
+
+	return res, nil
+}
+
+// unmarshalFields unmarshals the attributes and relationships of rske
+// according to typ and passes the values to setAttr and setRel.
+//
+// The first error is returned, unless the options require all the errors to be
+// collected, in which case a FieldErrors is returned. The unknown fields are
+// returned if the options allow them to be skipped.
+func (o UnmarshalOptions) unmarshalFields(
+	rske *resourceSkeleton, typ Type, schema *Schema,
+	setAttr func(Attr, interface{}), setRel func(Rel, interface{}, Identifiers),
+) ([]*UnknownFieldError, error) {
+	var (
+		errs    FieldErrors
+		skipped []*UnknownFieldError
+	)
+
+	// fail records err and returns true if the unmarshaling must stop.
+	fail := func(err error) bool {
+		errs = append(errs, err)
+		return !o.CollectFieldErrors
+	}
+
+	fieldName := schema.fieldName(typ)
+
 	for a, v := range rske.Attributes {
-		attr, ok := typ.Attrs[a]
+		attr, ok := typ.Attrs[fieldName(a)]
 		if !ok {
-			return nil, &srcError{ptr: true, src: "/attributes", error: &UnknownFieldError{
-				Type:  typ.Name,
-				Field: a,
-			}}
+			ufe := &UnknownFieldError{Type: typ.Name, Field: a}
+
+			if o.UnknownFields != UnknownFieldsReject && !memberRegexp.MatchString(a) {
+				if fail(&srcError{ptr: true, src: "/attributes", error: memberNameErr(a)}) {
+					break
+				}
+
+				continue
+			}
+
+			if o.UnknownFields != UnknownFieldsReject {
+				skipped = append(skipped, ufe)
+				continue
+			}
+
+			if fail(&srcError{ptr: true, src: "/attributes", error: ufe}) {
+				break
+			}
+
+			continue
 		}
 
-		var val interface{}
+		val, err := o.UnmarshalToType(v, attr)
+		if err == nil && attr.Transformer != nil {
+			val, err = attr.Transformer.TransformIn(val, attr)
+		}
 
-		if val, err = UnmarshalToType(v, attr); err != nil {
+		if err != nil {
 			name, _ := GetAttrTypeName(attr.Type, attr.Array, attr.Nullable)
 
-			return nil, &srcError{
+			if fail(&srcError{
 				ptr: true,
-				src: "/attributes/" + attr.Name,
+				src: "/attributes/" + a,
 				error: &InvalidFieldValueError{
 					Type:      typ.Name,
 					Field:     attr.Name,
@@ -367,166 +299,381 @@ func UnmarshalPartialResource(data []byte, schema *Schema) (*SoftResource, error
 					Value:     string(v),
 					err:       err,
 				},
+			}) {
+				break
 			}
+
+			continue
 		}
 
-		_ = newType.AddAttr(attr)
-		res.Set(attr.Name, val)
+		if o.UTCTimes && attr.Type == AttrTypeTime {
+			val = timesToUTC(val)
+		}
+
+		setAttr(attr, val)
+	}
+
+	if len(errs) > 0 && !o.CollectFieldErrors {
+		return nil, errs[0]
 	}
 
 	for r, v := range rske.Relationships {
-		if rel, ok := typ.Rels[r]; ok {
-			if len(v.Data) > 0 {
-				if rel.ToOne {
-					var iden Identifier
-					err = json.Unmarshal(v.Data, &iden)
-					_ = newType.AddRel(rel)
-					res.Set(rel.FromName, iden.ID)
-				} else {
-					var idens Identifiers
-					err = json.Unmarshal(v.Data, &idens)
-					ids := make([]string, len(idens))
-					for i := range idens {
-						ids[i] = idens[i].ID
-					}
-					_ = newType.AddRel(rel)
-					res.Set(rel.FromName, ids)
+		rel, ok := typ.Rels[fieldName(r)]
+		if !ok {
+			ufe := &UnknownFieldError{Type: typ.Name, Field: r, asRel: true}
+
+			if o.UnknownFields != UnknownFieldsReject && !memberRegexp.MatchString(r) {
+				if fail(&srcError{ptr: true, src: "/relationships", error: memberNameErr(r)}) {
+					break
 				}
+
+				continue
 			}
 
-			if err != nil {
-				return nil, &srcError{
-					ptr:   true,
-					src:   "/relationships/" + rel.FromName,
-					error: payloadErr(err),
-				}
+			if o.UnknownFields != UnknownFieldsReject {
+				skipped = append(skipped, ufe)
+				continue
 			}
-		} else {
-			return nil, &srcError{src: "/relationships", ptr: true, error: &UnknownFieldError{
-				Type:  typ.Name,
-				Field: r,
-				asRel: true,
-			}}
+
+			if fail(&srcError{src: "/relationships", ptr: true, error: ufe}) {
+				break
+			}
+
+			continue
 		}
+
+		if len(v.Data) == 0 {
+			continue
+		}
+
+		val, idens, err := unmarshalLinkage(v.Data, typ.Name, rel, schema)
+		if err != nil {
+			if fail(&srcError{
+				ptr:   true,
+				src:   "/relationships/" + r,
+				error: payloadErr(err),
+			}) {
+				break
+			}
+
+			continue
+		}
+
+		setRel(rel, val, idens)
 	}
 
-	return res, nil
+	switch {
+	case len(errs) == 0:
+		return skipped, nil
+	case !o.CollectFieldErrors:
+		return nil, errs[0]
+	}
+
+	errs.sort()
+
+	return nil, errs
 }
 
-// Equal reports whether r1 and r2 are equal.
+// unmarshalLinkage unmarshals the resource linkage of a relationship. It
+// returns the value to set on the resource (a string or a []string depending on
+// the relationship) and the identifiers as they are found in the payload.
 //
-// Two resources are equal if their types are equal, all the attributes are
-// equal (same type and same value), and all the relationships are equal.
-//
-// IDs are ignored.
-func Equal(r1, r2 Resource) bool {
-	// Type
-	if r1.GetType().Name != r2.GetType().Name {
-		return false
+// An error is returned if the type of an identifier is missing or unknown to
+// the schema, and an *InvalidFieldValueError if it is not the type rel points
+// to. typ is the name of the type of the resource that holds rel.
+func unmarshalLinkage(
+	data json.RawMessage, typ string, rel Rel, schema *Schema,
+) (interface{}, Identifiers, error) {
+	var idens Identifiers
+
+	if rel.ToOne {
+		var iden *Identifier
+		if err := json.Unmarshal(data, &iden); err != nil {
+			return nil, nil, err
+		}
+
+		if iden != nil {
+			idens = Identifiers{*iden}
+		}
+	} else if err := json.Unmarshal(data, &idens); err != nil {
+		return nil, nil, err
 	}
 
-	// Attributes
-	attrs := r1.Attrs()
-	r1Attrs := make([]Attr, 0, len(attrs))
+	if idens == nil {
+		idens = Identifiers{}
+	}
+
+	for _, iden := range idens {
+		if iden.Type == "" {
+			return nil, nil, errIdentifierType
+		}
 
-	for name := range attrs {
-		r1Attrs = append(r1Attrs, attrs[name])
+		if !schema.HasType(iden.Type) {
+			return nil, nil, &UnknownTypeError{Type: iden.Type}
+		}
+
+		if iden.Type != rel.ToType {
+			return nil, nil, &InvalidFieldValueError{
+				Type:      typ,
+				Field:     rel.FromName,
+				FieldType: rel.ToType,
+				Value:     iden.Type,
+				asRel:     true,
+				err:       fmt.Errorf("the relationship points to type %q", rel.ToType),
+			}
+		}
 	}
 
-	sort.Slice(r1Attrs, func(i, j int) bool {
-		return r1Attrs[i].Name < r1Attrs[j].Name
-	})
+	if rel.ToOne {
+		if len(idens) == 0 {
+			return "", idens, nil
+		}
+
+		return idens[0].ID, idens, nil
+	}
 
-	attrs = r2.Attrs()
-	r2Attrs := make([]Attr, 0, len(attrs))
+	return idens.IDs(), idens, nil
+}
 
-	for name := range attrs {
-		r2Attrs = append(r2Attrs, attrs[name])
+// setLinkage sets the identifiers of the relationship named rel if res is a
+// LinkageHolder.
+func setLinkage(res Resource, rel string, idens Identifiers) {
+	if lh, ok := res.(LinkageHolder); ok {
+		lh.SetLinkage(rel, idens)
 	}
+}
 
-	sort.Slice(r2Attrs, func(i, j int) bool {
-		return r2Attrs[i].Name < r2Attrs[j].Name
-	})
+// setRelMeta sets the meta values of the relationships of rske whose meta
+// object is not empty if res is a RelMetaHolder, and the counts of the to-many
+// relationships found in those objects if res is a RelCountHolder. Unknown
+// relationships are ignored.
+func setRelMeta(res Resource, typ Type, schema *Schema, rske *resourceSkeleton) {
+	rmh, _ := res.(RelMetaHolder)
+	rch, _ := res.(RelCountHolder)
+	fieldName := schema.fieldName(typ)
+
+	for member, relSke := range rske.Relationships {
+		rel, ok := typ.Rels[fieldName(member)]
+		if !ok || len(relSke.Meta) == 0 {
+			continue
+		}
+
+		name := rel.FromName
+
+		if rmh != nil {
+			rmh.SetRelMeta(name, relSke.Meta)
+		}
+
+		if rch == nil || rel.ToOne {
+			continue
+		}
+
+		if n, ok := relSke.Meta.LookupInt("count"); ok && n >= 0 {
+			rch.SetRelCount(name, n)
+		}
+	}
+}
 
-	if len(r1Attrs) != len(r2Attrs) {
-		return false
+// checkMetaNames returns an error if the options require the names of the
+// members of the meta objects to be checked and one of the meta objects of
+// rske has an invalid member name.
+func (o UnmarshalOptions) checkMetaNames(rske *resourceSkeleton) error {
+	if !o.CheckMetaNames {
+		return nil
 	}
 
-	for i, attr1 := range r1Attrs {
-		attr2 := r2Attrs[i]
-		if !reflect.DeepEqual(r1.Get(attr1.Name), r2.Get(attr2.Name)) {
-			// TODO Fix the following condition one day. Basically, all
-			// nils (nil pointer, nil slice, etc) should be considered
-			// equal to a nil empty interface.
-			v1 := r1.Get(attr1.Name) //
-			v2 := r2.Get(attr1.Name)
+	if name := invalidMemberName(rske.Meta); name != "" {
+		return &srcError{ptr: true, src: "/meta", error: memberNameErr(name)}
+	}
 
-			if fmt.Sprintf("%v", v1) == "<nil>" && fmt.Sprintf("%v", v2) == "<nil>" {
-				continue
+	rels := make([]string, 0, len(rske.Relationships))
+	for r := range rske.Relationships {
+		rels = append(rels, r)
+	}
+
+	sort.Strings(rels)
+
+	for _, r := range rels {
+		if name := invalidMemberName(rske.Relationships[r].Meta); name != "" {
+			return &srcError{
+				ptr:   true,
+				src:   "/relationships/" + r + "/meta",
+				error: memberNameErr(name),
 			}
+		}
+	}
+
+	return nil
+}
 
-			fmt.Println(r1.GetType().Name, attr1.Name, fmt.Sprintf("%v", v1), fmt.Sprintf("%v", v2))
+// invalidMemberName returns the first name of a member of v, or of the objects
+// v holds, that is not a valid member name. An empty string is returned if all
+// the names are valid.
+func invalidMemberName(v interface{}) string {
+	switch v := v.(type) {
+	case Meta:
+		return invalidMemberName(map[string]interface{}(v))
+	case map[string]interface{}:
+		keys := make([]string, 0, len(v))
+		for key := range v {
+			keys = append(keys, key)
+		}
+
+		sort.Strings(keys)
+
+		for _, key := range keys {
+			if !memberRegexp.MatchString(key) {
+				return key
+			}
 
-			return false
+			if name := invalidMemberName(v[key]); name != "" {
+				return name
+			}
+		}
+	case []interface{}:
+		for _, elem := range v {
+			if name := invalidMemberName(elem); name != "" {
+				return name
+			}
 		}
 	}
 
-	// Relationships
-	rels := r1.Rels()
-	r1Rels := make([]Rel, 0, len(rels))
+	return ""
+}
+
+// memberNameErr returns the error for a member name found in a payload that
+// does not meet the member name requirements.
+func memberNameErr(name string) error {
+	return payloadErr(fmt.Errorf("jsonapi: member name %q is invalid", name))
+}
 
-	for name := range rels {
-		r1Rels = append(r1Rels, rels[name])
+// setRawRels passes the relationship objects of rske to res if it is a
+// RawRelsHolder.
+func setRawRels(res Resource, rske *resourceSkeleton) {
+	if rrh, ok := res.(RawRelsHolder); ok && len(rske.Relationships) > 0 {
+		rrh.SetRawRels(rske.Relationships)
 	}
+}
 
-	sort.Slice(r1Rels, func(i, j int) bool {
-		return r1Rels[i].FromName < r1Rels[j].FromName
-	})
+// setLinks passes the links of rske to res if it is a LinkHolder and
+// o.KeepLinks is set. The null links are left out.
+func (o UnmarshalOptions) setLinks(res Resource, rske *resourceSkeleton) error {
+	lh, ok := res.(LinkHolder)
+	if !o.KeepLinks || !ok || len(rske.Links) == 0 {
+		return nil
+	}
 
-	rels = r2.Rels()
-	r2Rels := make([]Rel, 0, len(rels))
+	var raw map[string]*Link
 
-	for name := range rels {
-		r2Rels = append(r2Rels, rels[name])
+	if err := json.Unmarshal(rske.Links, &raw); err != nil {
+		return payloadErr(err)
 	}
 
-	sort.Slice(r2Rels, func(i, j int) bool {
-		return r2Rels[i].FromName < r2Rels[j].FromName
-	})
+	if len(raw) == 0 {
+		return nil
+	}
+
+	links := make(map[string]Link, len(raw))
 
-	if len(r1Rels) != len(r2Rels) {
-		return false
+	for name, link := range raw {
+		if link != nil {
+			links[name] = *link
+		}
 	}
 
-	for i, rel1 := range r1Rels {
-		rel2 := r2Rels[i]
-		if rel1.ToOne != rel2.ToOne {
-			return false
+	lh.SetLinks(links)
+
+	return nil
+}
+
+// ApplyPartial copies the fields found in src, a resource returned by
+// UnmarshalPartialResource, to dst and returns the names of the fields whose
+// value has changed in alphabetical order.
+//
+// An error is returned and dst is left untouched if src contains a field that
+// dst does not have, an attribute that is read-only in either resource, or a
+// field whose definition differs between the resources, like an attribute of
+// another type. The linkage of the relationships is also copied if both
+// resources are LinkageHolders.
+func ApplyPartial(dst Resource, src *SoftResource) ([]string, error) {
+	dstAttrs, dstRels := dst.Attrs(), dst.Rels()
+	typ := dst.GetType().Name
+
+	for _, attr := range src.Attrs() {
+		dstAttr, ok := dstAttrs[attr.Name]
+		if !ok {
+			return nil, &srcError{ptr: true, src: "/attributes", error: &UnknownFieldError{
+				Type:  typ,
+				Field: attr.Name,
+			}}
 		}
 
-		if rel1.ToOne {
-			if r1.Get(rel1.FromName).(string) != r2.Get(rel2.FromName).(string) {
-				return false
-			}
-		} else {
-			v1 := r1.Get(rel1.FromName).([]string)
-			v2 := r2.Get(rel2.FromName).([]string)
-			if len(v1) != 0 || len(v2) != 0 {
-				if !reflect.DeepEqual(v1, v2) {
-					return false
-				}
+		if attr.ReadOnly || dstAttr.ReadOnly {
+			return nil, &srcError{
+				ptr:   true,
+				src:   "/attributes/" + attr.Name,
+				error: &ReadOnlyFieldError{Type: typ, Field: attr.Name},
 			}
 		}
+
+		if attr.Type != dstAttr.Type || attr.Array != dstAttr.Array ||
+			attr.Nullable != dstAttr.Nullable {
+			srcType, _ := GetAttrTypeName(attr.Type, attr.Array, attr.Nullable)
+			dstType, _ := GetAttrTypeName(dstAttr.Type, dstAttr.Array, dstAttr.Nullable)
+
+			return nil, &srcError{ptr: true, src: "/attributes/" + attr.Name,
+				error: &InvalidFieldValueError{
+					Type:      typ,
+					Field:     attr.Name,
+					FieldType: dstType,
+					Value:     fmt.Sprint(src.Get(attr.Name)),
+					err:       fmt.Errorf("got attribute of type %q, not %q", srcType, dstType),
+				}}
+		}
 	}
 
-	return true
-}
+	for _, rel := range src.Rels() {
+		dstRel, ok := dstRels[rel.FromName]
+		if !ok {
+			return nil, &srcError{ptr: true, src: "/relationships", error: &UnknownFieldError{
+				Type:  typ,
+				Field: rel.FromName,
+				asRel: true,
+			}}
+		}
 
-// EqualStrict is like Equal, but it also considers IDs.
-func EqualStrict(r1, r2 Resource) bool {
-	if r1.Get("id").(string) != r2.Get("id").(string) {
-		return false
+		if rel.ToOne != dstRel.ToOne {
+			return nil, &srcError{ptr: true, src: "/relationships/" + rel.FromName,
+				error: &InvalidFieldValueError{
+					Type:      typ,
+					Field:     rel.FromName,
+					FieldType: dstRel.ToType,
+					Value:     fmt.Sprint(src.Get(rel.FromName)),
+					asRel:     true,
+					err:       errors.New("got relationship of another cardinality"),
+				}}
+		}
 	}
 
-	return Equal(r1, r2)
+	changed := []string{}
+
+	for _, field := range src.fields() {
+		if v := src.Get(field); !reflect.DeepEqual(dst.Get(field), v) {
+			dst.Set(field, v)
+
+			changed = append(changed, field)
+		}
+	}
+
+	if lh, ok := dst.(LinkageHolder); ok {
+		for _, rel := range src.Rels() {
+			if idens := src.Linkage(rel.FromName); idens != nil {
+				lh.SetLinkage(rel.FromName, idens)
+			}
+		}
+	}
+
+	sort.Strings(changed)
+
+	return changed, nil
 }
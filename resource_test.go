@@ -1,6 +1,10 @@
 package jsonapi_test
 
 import (
+	"bytes"
+	"errors"
+	"math"
+	"strings"
 	"testing"
 	"time"
 
@@ -376,11 +380,11 @@ func TestEqual(t *testing.T) {
 
 	sr4 := &SoftResource{}
 	sr4.AddAttr(Attr{
-		Name:     "nil2",
+		Name:     "nil",
 		Type:     AttrTypeInt,
 		Nullable: true,
 	})
-	sr3.Set("nil", (*int)(nil))
+	sr4.Set("nil", (*int)(nil))
 
 	assert.Equal(true, Equal(sr3, sr4))
 }
@@ -409,3 +413,913 @@ func TestEqualStrict(t *testing.T) {
 	assert.True(Equal(sr1, sr2))
 	assert.False(EqualStrict(sr1, sr2))
 }
+
+func BenchmarkMarshalResource(b *testing.B) {
+	res := Wrap(&mocktype{
+		ID:       "id1",
+		Str:      "str",
+		Int:      10,
+		Float64:  1.5,
+		Bool:     true,
+		Time:     getTime(),
+		To1:      "id2",
+		To1From1: "id3",
+		ToX:      []string{"id2", "id3", "id4"},
+		ToXFrom1: []string{"id4"},
+	})
+
+	typ := res.GetType()
+	fields := typ.Fields()
+	relData := map[string][]string{
+		"mocktype": {"to-1", "to-1-from-1", "to-x", "to-x-from-1"},
+	}
+
+	b.ReportAllocs()
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		_ = MarshalResource(res, "https://example.org", fields, relData)
+	}
+}
+
+func TestUnmarshalResourceLinkage(t *testing.T) {
+	schema := newMockSchema()
+
+	payload := `{
+		"id": "id1",
+		"type": "mocktypes1",
+		"relationships": {
+			"to-one": {
+				"data": {"id": "id2", "type": "mocktypes2", "meta": {"role": "owner"}}
+			},
+			"to-one-from-one": {
+				"data": null
+			},
+			"to-many": {
+				"data": [
+					{"id": "id4", "type": "mocktypes2"},
+					{"id": "id3", "type": "mocktypes2", "meta": {"pos": 1}}
+				]
+			}
+		}
+	}`
+
+	for _, partial := range []bool{false, true} {
+		assert := assert.New(t)
+
+		var (
+			res Resource
+			err error
+		)
+
+		if partial {
+			res, err = UnmarshalPartialResource([]byte(payload), schema)
+		} else {
+			res, err = UnmarshalResource([]byte(payload), schema)
+		}
+
+		assert.NoError(err)
+		assert.Equal("id2", res.Get("to-one"))
+		assert.Equal([]string{"id4", "id3"}, res.Get("to-many"))
+
+		lh, ok := res.(LinkageHolder)
+		assert.True(ok)
+		assert.Equal(Identifiers{
+			{ID: "id2", Type: "mocktypes2", Meta: Meta{"role": "owner"}},
+		}, lh.Linkage("to-one"))
+		assert.Equal(Identifiers{}, lh.Linkage("to-one-from-one"))
+		assert.Equal(Identifiers{
+			{ID: "id4", Type: "mocktypes2"},
+			{ID: "id3", Type: "mocktypes2", Meta: Meta{"pos": float64(1)}},
+		}, lh.Linkage("to-many"))
+		assert.Empty(lh.Linkage("to-many-from-one"))
+
+		// The meta values are part of the output.
+		out := MarshalResource(res, "", []string{"to-one", "to-many"}, map[string][]string{
+			"mocktypes1": {"to-one", "to-many"},
+		})
+		assert.JSONEq(`{
+			"id": "id1",
+			"type": "mocktypes1",
+			"links": {"self": "/mocktypes1/id1"},
+			"relationships": {
+				"to-one": {
+					"data": {"id": "id2", "type": "mocktypes2", "meta": {"role": "owner"}},
+					"links": {
+						"related": "/mocktypes1/id1/to-one",
+						"self": "/mocktypes1/id1/relationships/to-one"
+					}
+				},
+				"to-many": {
+					"data": [
+						{"id": "id3", "type": "mocktypes2", "meta": {"pos": 1}},
+						{"id": "id4", "type": "mocktypes2"}
+					],
+					"links": {
+						"related": "/mocktypes1/id1/to-many",
+						"self": "/mocktypes1/id1/relationships/to-many"
+					}
+				}
+			}
+		}`, string(out))
+	}
+}
+
+func TestUnmarshalResourceLinks(t *testing.T) {
+	assert := assert.New(t)
+
+	typ := &Type{Name: "articles"}
+	typ.MustAddAttr(Attr{Name: "title", Type: AttrTypeString})
+
+	schema := &Schema{}
+	schema.MustAddType(*typ)
+
+	sr := &SoftResource{Type: typ}
+	sr.SetID("a1")
+	sr.Set("title", "Hello")
+	sr.SetLinks(map[string]Link{
+		"describedby": {HRef: "/schemas/articles"},
+		"cover":       {HRef: "/covers/a1.png", Meta: map[string]interface{}{"width": 640}},
+	})
+
+	doc := &Document{Data: sr, Included: []Resource{}}
+
+	pl1 := &bytes.Buffer{}
+	assert.NoError(MarshalDocument(pl1, doc, nil))
+
+	// The links are ignored by default.
+	doc2, err := UnmarshalDocument(bytes.NewReader(pl1.Bytes()), schema)
+	assert.NoError(err)
+	assert.Empty(doc2.Data.(Resource).(*SoftResource).Links())
+
+	opts := UnmarshalOptions{KeepLinks: true}
+
+	doc2, err = opts.UnmarshalDocument(bytes.NewReader(pl1.Bytes()), schema)
+	assert.NoError(err)
+
+	res := doc2.Data.(Resource).(*SoftResource)
+	assert.Equal(map[string]Link{
+		"self":        {HRef: "/articles/a1"},
+		"describedby": {HRef: "/schemas/articles"},
+		"cover": {
+			HRef: "/covers/a1.png",
+			Meta: map[string]interface{}{"width": float64(640)},
+		},
+	}, res.Links())
+
+	// The links survive a round trip.
+	assert.JSONEq(string(MarshalResource(sr, "", []string{"title"}, nil)),
+		string(MarshalResource(res, "", []string{"title"}, nil)))
+
+	// Null links
+	r, err := opts.UnmarshalResource([]byte(`{
+		"id": "a1",
+		"type": "articles",
+		"links": {"self": "/articles/a1", "describedby": null}
+	}`), schema)
+	assert.NoError(err)
+	assert.Equal(map[string]Link{"self": {HRef: "/articles/a1"}}, r.(LinkHolder).Links())
+
+	// Invalid links
+	invalid := []byte(`{"id":"a1","type":"articles","links":{"self":1}}`)
+
+	_, err = opts.UnmarshalResource(invalid, schema)
+	assert.EqualError(err, "jsonapi: link must be a string or an object")
+	assert.True(errors.Is(err, ErrInvalidPayload))
+
+	r, err = UnmarshalResource(invalid, schema)
+	assert.NoError(err)
+	assert.Empty(r.(LinkHolder).Links())
+}
+
+func TestUnmarshalResourceLinkageType(t *testing.T) {
+	schema := newMockSchema()
+
+	tests := []struct {
+		name string
+		rel  string
+		err  string
+	}{
+		{
+			name: "missing type",
+			rel:  `"to-one": {"data": {"id": "id2"}}`,
+			err:  "jsonapi: resource identifier has no type",
+		}, {
+			name: "unknown type",
+			rel: `"to-many": {"data": [` +
+				`{"id": "id2", "type": "mocktypes2"}, {"id": "id3", "type": "unknown"}]}`,
+			err: `jsonapi: resource type "unknown" does not exist`,
+		}, {
+			name: "other type",
+			rel:  `"to-one": {"data": {"id": "id2", "type": "mocktypes1"}}`,
+			err: `jsonapi: invalid value "mocktypes1" for field "to-one": ` +
+				`the relationship points to type "mocktypes2"`,
+		}, {
+			name: "other type in to-many",
+			rel: `"to-many": {"data": [` +
+				`{"id": "id2", "type": "mocktypes2"}, {"id": "id3", "type": "mocktypes1"}]}`,
+			err: `jsonapi: invalid value "mocktypes1" for field "to-many": ` +
+				`the relationship points to type "mocktypes2"`,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			assert := assert.New(t)
+
+			payload := `{"id": "id1", "type": "mocktypes1", "relationships": {` + test.rel + `}}`
+
+			for _, unmarshal := range []func([]byte, *Schema) (Resource, error){
+				UnmarshalResource,
+				func(payload []byte, schema *Schema) (Resource, error) {
+					return UnmarshalPartialResource(payload, schema)
+				},
+			} {
+				_, err := unmarshal([]byte(payload), schema)
+				assert.EqualError(err, test.err)
+				assert.ErrorIs(err, ErrInvalidPayload)
+
+				var srcErr srcError
+				assert.ErrorAs(err, &srcErr)
+
+				src, isPtr := srcErr.Source()
+				assert.True(isPtr)
+				assert.Contains(src, "/relationships/to-")
+			}
+		})
+	}
+}
+
+func TestUnmarshalLinkageTypeMismatch(t *testing.T) {
+	assert := assert.New(t)
+
+	schema := newMockSchema()
+
+	_, err := UnmarshalResource([]byte(`{
+		"id": "id1",
+		"type": "mocktypes1",
+		"relationships": {"to-one": {"data": {"id": "id2", "type": "mocktypes1"}}}
+	}`), schema)
+
+	ive := &InvalidFieldValueError{}
+	assert.ErrorAs(err, &ive)
+	assert.False(ive.IsAttr())
+	assert.Equal("mocktypes1", ive.Type)
+	assert.Equal("to-one", ive.Field)
+	assert.Equal("mocktypes2", ive.FieldType)
+
+	e := (&ErrorMapper{}).Map(err)
+	assert.Equal("400", e.Status)
+	assert.Equal("Invalid field value", e.Title)
+	assert.Equal("/relationships/to-one", e.Source["pointer"])
+
+	// Relationship endpoint
+	rel := schema.GetType("mocktypes1").Rels["to-many"]
+
+	_, err = UnmarshalRelationship([]byte(`{"data": [{"id": "id2", "type": "mocktypes1"}]}`),
+		rel, schema)
+	assert.ErrorAs(err, &ive)
+	assert.Equal("to-many", ive.Field)
+}
+
+func TestApplyPartial(t *testing.T) {
+	assert := assert.New(t)
+
+	type article struct {
+		ID     string    `json:"id" api:"articles"`
+		Title  string    `json:"title" api:"attr"`
+		Body   string    `json:"body" api:"attr"`
+		Views  int       `json:"views" api:"attr,readonly"`
+		Author string    `json:"author" api:"rel,users"`
+		Tags   []string  `json:"tags" api:"rel,tags"`
+		Date   time.Time `json:"date" api:"attr"`
+	}
+
+	typ := MustBuildType(article{})
+	assert.True(typ.Attrs["views"].ReadOnly)
+
+	schema := &Schema{}
+	schema.MustAddType(typ)
+	schema.MustAddType(Type{Name: "users"})
+	schema.MustAddType(Type{Name: "tags"})
+
+	art := &article{ID: "a1", Title: "Title", Body: "Body", Author: "u1"}
+	dst := Wrap(art)
+
+	src, err := UnmarshalPartialResource([]byte(`{
+		"id": "a1",
+		"type": "articles",
+		"attributes": {"title": "New title", "body": "Body"},
+		"relationships": {
+			"author": {"data": {"type": "users", "id": "u2"}},
+			"tags": {"data": [{"type": "tags", "id": "t1"}]}
+		}
+	}`), schema)
+	assert.NoError(err)
+
+	changed, err := ApplyPartial(dst, src)
+	assert.NoError(err)
+	assert.Equal([]string{"author", "tags", "title"}, changed)
+	assert.Equal("New title", art.Title)
+	assert.Equal("u2", art.Author)
+	assert.Equal([]string{"t1"}, art.Tags)
+	assert.Equal(Identifiers{{Type: "tags", ID: "t1"}}, dst.Linkage("tags"))
+
+	// Nothing changes the second time.
+	changed, err = ApplyPartial(dst, src)
+	assert.NoError(err)
+	assert.Equal([]string{}, changed)
+
+	// Read-only attribute
+	src, err = UnmarshalPartialResource([]byte(`{
+		"id": "a1",
+		"type": "articles",
+		"attributes": {"title": "Other title", "views": 100}
+	}`), schema)
+	assert.NoError(err)
+
+	_, err = ApplyPartial(dst, src)
+
+	var roErr *ReadOnlyFieldError
+
+	assert.True(errors.As(err, &roErr))
+	assert.EqualError(err, `jsonapi: field "views" of type "articles" is read-only`)
+	assert.Equal("New title", art.Title)
+
+	e := (&ErrorMapper{}).Map(err)
+	assert.Equal("403", e.Status)
+	assert.Equal("/attributes/views", e.Source["pointer"])
+
+	// Unknown field
+	sr := &SoftResource{Type: &Type{Name: "articles"}}
+	sr.AddAttr(Attr{Name: "unknown", Type: AttrTypeString})
+
+	_, err = ApplyPartial(dst, sr)
+	assert.Error(err)
+
+	var ufErr *UnknownFieldError
+
+	assert.True(errors.As(err, &ufErr))
+
+	// Attribute of another type
+	sr = &SoftResource{Type: &Type{Name: "articles"}}
+	sr.AddAttr(Attr{Name: "body", Type: AttrTypeString})
+	sr.AddAttr(Attr{Name: "title", Type: AttrTypeInt})
+	sr.Set("body", "Other body")
+	sr.Set("title", 1)
+
+	_, err = ApplyPartial(dst, sr)
+	assert.EqualError(err, `jsonapi: invalid value "1" for field "title": `+
+		`got attribute of type "int", not "string"`)
+	assert.Equal("Body", art.Body)
+
+	var ifvErr *InvalidFieldValueError
+
+	assert.True(errors.As(err, &ifvErr))
+	assert.Equal("/attributes/title", (&ErrorMapper{}).Map(err).Source["pointer"])
+
+	// Relationship of another cardinality
+	sr = &SoftResource{Type: &Type{Name: "articles"}}
+	sr.AddRel(Rel{FromName: "author", ToType: "users"})
+	sr.Set("author", []string{"u3"})
+
+	_, err = ApplyPartial(dst, sr)
+	assert.True(errors.As(err, &ifvErr))
+	assert.Equal("u2", art.Author)
+}
+
+func TestUnmarshalRelMeta(t *testing.T) {
+	assert := assert.New(t)
+
+	typ := Type{Name: "articles"}
+	typ.MustAddRel(Rel{FromName: "author", ToType: "people", ToOne: true})
+	typ.MustAddRel(Rel{FromName: "tags", ToType: "tags"})
+	typ.MustAddRel(Rel{FromName: "comments", ToType: "comments"})
+
+	schema := &Schema{}
+	schema.MustAddType(typ)
+	schema.MustAddType(Type{Name: "people"})
+	schema.MustAddType(Type{Name: "tags"})
+	schema.MustAddType(Type{Name: "comments"})
+
+	payload := `{
+		"id": "a1",
+		"type": "articles",
+		"relationships": {
+			"author": {"data": {"type": "people", "id": "p1"}, "meta": {"since": 2020}},
+			"tags": {"data": [], "meta": {"count": 0}},
+			"comments": {"meta": {"count": 12}}
+		}
+	}`
+
+	res, err := UnmarshalResource([]byte(payload), schema)
+	assert.NoError(err)
+
+	sr := res.(*SoftResource)
+	assert.Equal(Meta{"since": float64(2020)}, sr.RelMeta("author"))
+	assert.Equal(Meta{"count": float64(0)}, sr.RelMeta("tags"))
+	assert.Equal(Meta{"count": float64(12)}, sr.RelMeta("comments"))
+
+	// The meta values are marshaled again.
+	out := MarshalResource(res, "", []string{"author", "tags", "comments"}, map[string][]string{
+		"articles": {"author", "tags"},
+	})
+	assert.Contains(string(out), `"author":{"data":{"id":"p1","type":"people"},`+
+		`"links":{"related":"/articles/a1/author","self":"/articles/a1/relationships/author"},`+
+		`"meta":{"since":2020}}`)
+	assert.Contains(string(out), `"comments":{"links":{"related":"/articles/a1/comments",`+
+		`"self":"/articles/a1/relationships/comments"},"meta":{"count":12}}`)
+
+	// Setting a RelData replaces the meta values.
+	sr.Set("author", RelData{Res: Identifier{ID: "p2"}, Meta: Meta{"own": true}})
+	out = MarshalResource(res, "", []string{"author"}, map[string][]string{
+		"articles": {"author"},
+	})
+	assert.Contains(string(out), `"meta":{"own":true}`)
+
+	partial, err := UnmarshalPartialResource([]byte(payload), schema)
+	assert.NoError(err)
+	assert.Equal(Meta{"count": float64(12)}, partial.RelMeta("comments"))
+}
+
+func TestMarshalResourceOmitEmpty(t *testing.T) {
+	assert := assert.New(t)
+
+	type article struct {
+		ID     string     `json:"id" api:"articles"`
+		Title  string     `json:"title" api:"attr,omitempty"`
+		Views  int        `json:"views" api:"attr,omitempty"`
+		Tags   []string   `json:"tags" api:"attr,omitempty"`
+		Date   time.Time  `json:"date" api:"attr,omitempty"`
+		Score  *int       `json:"score" api:"attr,omitempty"`
+		Price  Decimal    `json:"price" api:"attr,omitempty"`
+		Body   string     `json:"body" api:"attr"`
+		Edited *time.Time `json:"edited" api:"attr"`
+	}
+
+	typ := MustBuildType(article{})
+	assert.True(typ.Attrs["title"].OmitEmpty)
+	assert.False(typ.Attrs["body"].OmitEmpty)
+
+	// Empty values are omitted, but not the attributes without the option.
+	art := &article{ID: "a1"}
+	out := MarshalResource(Wrap(art), "", typ.Fields(), nil)
+	assert.JSONEq(`{
+		"attributes": {"body": "", "edited": null},
+		"id": "a1",
+		"links": {"self": "/articles/a1"},
+		"type": "articles"
+	}`, string(out))
+
+	// A pointer to a zero value is not empty.
+	zero := 0
+	art.Score = &zero
+	art.Tags = []string{"a"}
+	art.Price = MustParseDecimal("1.5")
+	out = MarshalResource(Wrap(art), "", typ.Fields(), nil)
+	assert.JSONEq(`{
+		"attributes": {
+			"body": "",
+			"edited": null,
+			"price": "1.5",
+			"score": 0,
+			"tags": ["a"]
+		},
+		"id": "a1",
+		"links": {"self": "/articles/a1"},
+		"type": "articles"
+	}`, string(out))
+
+	// The attributes object is omitted if all attributes are empty.
+	out = MarshalResource(Wrap(&article{ID: "a1"}), "", []string{"title", "views"}, nil)
+	assert.JSONEq(`{
+		"id": "a1",
+		"links": {"self": "/articles/a1"},
+		"type": "articles"
+	}`, string(out))
+
+	// The sparse fieldset still applies to the non-empty values.
+	art.Title = "Title"
+	out = MarshalResource(Wrap(art), "", []string{"views"}, nil)
+	assert.JSONEq(`{
+		"id": "a1",
+		"links": {"self": "/articles/a1"},
+		"type": "articles"
+	}`, string(out))
+}
+
+func TestMarshalResourceFloats(t *testing.T) {
+	assert := assert.New(t)
+
+	type measure struct {
+		ID     string     `json:"id" api:"measures"`
+		F32    float32    `json:"f32" api:"attr"`
+		F64    float64    `json:"f64" api:"attr"`
+		F32Arr []float32  `json:"f32arr" api:"attr"`
+		F64Ptr *float64   `json:"f64ptr" api:"attr"`
+		F64Arr *[]float64 `json:"f64arr" api:"attr"`
+	}
+
+	typ := MustBuildType(measure{})
+
+	small := 1e-7
+	m := &measure{
+		ID:     "m1",
+		F32:    math.MaxFloat32,
+		F64:    1e21,
+		F32Arr: []float32{0.1, -2, 1e-6},
+		F64Ptr: &small,
+		F64Arr: &[]float64{100, 0.000001, -0},
+	}
+
+	out := MarshalResource(Wrap(m), "", typ.Fields(), nil)
+	assert.Equal(`{"attributes":{`+
+		`"f32":3.4028235e+38,`+
+		`"f32arr":[0.1,-2,0.000001],`+
+		`"f64":1e+21,`+
+		`"f64arr":[100,0.000001,0],`+
+		`"f64ptr":1e-7},`+
+		`"id":"m1","links":{"self":"/measures/m1"},"type":"measures"}`, string(out))
+
+	// The value round-trips.
+	v, err := UnmarshalToType([]byte("3.4028235e+38"), typ.Attrs["f32"])
+	assert.NoError(err)
+	assert.Equal(float32(math.MaxFloat32), v)
+
+	// Values that are not numbers cannot be marshaled.
+	m.F64 = math.NaN()
+	assert.Nil(MarshalResource(Wrap(m), "", typ.Fields(), nil))
+}
+
+func TestRelCount(t *testing.T) {
+	assert := assert.New(t)
+
+	typ := Type{Name: "articles"}
+	typ.MustAddRel(Rel{FromName: "author", ToType: "people", ToOne: true})
+	typ.MustAddRel(Rel{FromName: "tags", ToType: "tags"})
+	typ.MustAddRel(Rel{FromName: "comments", ToType: "comments"})
+
+	schema := &Schema{}
+	schema.MustAddType(typ)
+	schema.MustAddType(Type{Name: "people"})
+	schema.MustAddType(Type{Name: "tags"})
+	schema.MustAddType(Type{Name: "comments"})
+
+	res := &SoftResource{Type: &typ}
+	res.SetID("a1")
+	res.Set("tags", []string{"t1"})
+	res.SetRelCount("tags", 1)
+	res.SetRelCount("comments", 250)
+
+	// The count is written with or without the linkage.
+	out := string(MarshalResource(res, "", typ.Fields(), map[string][]string{
+		"articles": {"tags"},
+	}))
+	assert.Contains(out, `"comments":{"links":{"related":"/articles/a1/comments",`+
+		`"self":"/articles/a1/relationships/comments"},"meta":{"count":250}}`)
+	assert.Contains(out, `"tags":{"data":[{"id":"t1","type":"tags"}],"links":{`+
+		`"related":"/articles/a1/tags","self":"/articles/a1/relationships/tags"},`+
+		`"meta":{"count":1}}`)
+
+	// The count takes precedence over the count of MaxLinkage.
+	res.Set("comments", []string{"c1", "c2"})
+	out = string(MarshalOptions{MaxLinkage: 1}.MarshalResource(res, "", typ.Fields(),
+		map[string][]string{"articles": {"comments"}}))
+	assert.Contains(out, `"meta":{"count":250}`)
+
+	// An unknown count is not written.
+	res.SetRelCount("comments", -1)
+
+	n, ok := res.RelCount("comments")
+	assert.False(ok)
+	assert.Equal(0, n)
+
+	out = string(MarshalResource(res, "", []string{"comments"}, nil))
+	assert.NotContains(out, `"meta"`)
+
+	// Unmarshaling
+	payload := `{
+		"id": "a1",
+		"type": "articles",
+		"relationships": {
+			"author": {"data": null, "meta": {"count": 1}},
+			"tags": {"data": [], "meta": {"count": 0}},
+			"comments": {"meta": {"count": 12, "other": true}}
+		}
+	}`
+
+	res2, err := UnmarshalResource([]byte(payload), schema)
+	assert.NoError(err)
+
+	sr := res2.(*SoftResource)
+
+	n, ok = sr.RelCount("comments")
+	assert.True(ok)
+	assert.Equal(12, n)
+
+	n, ok = sr.RelCount("tags")
+	assert.True(ok)
+	assert.Equal(0, n)
+
+	_, ok = sr.RelCount("author")
+	assert.False(ok)
+
+	// Invalid counts are ignored.
+	for _, count := range []string{`-1`, `1.5`, `"3"`, `null`} {
+		payload := `{"id":"a1","type":"articles","relationships":{` +
+			`"comments":{"meta":{"count":` + count + `}}}}`
+
+		partial, err := UnmarshalPartialResource([]byte(payload), schema)
+		assert.NoError(err)
+
+		_, ok := partial.RelCount("comments")
+		assert.False(ok, count)
+	}
+}
+
+func TestUnmarshalRawRels(t *testing.T) {
+	assert := assert.New(t)
+
+	typ := Type{Name: "articles"}
+	typ.MustAddRel(Rel{FromName: "author", ToType: "people", ToOne: true})
+	typ.MustAddRel(Rel{FromName: "tags", ToType: "tags"})
+
+	schema := &Schema{}
+	schema.MustAddType(typ)
+	schema.MustAddType(Type{Name: "people"})
+	schema.MustAddType(Type{Name: "tags"})
+
+	payload := `{
+		"id": "a1",
+		"type": "articles",
+		"relationships": {
+			"author": {"data": null, "links": {"related": "/articles/a1/author"}},
+			"tags": {"data": [{"type": "tags", "lid": "local-1"}], "meta": {"n": 1}},
+			"unknown": {"data": {"type": "people", "id": "p1"}}
+		}
+	}`
+
+	opts := UnmarshalOptions{UnknownFields: UnknownFieldsIgnore}
+
+	res, err := opts.UnmarshalResource([]byte(payload), schema)
+	assert.NoError(err)
+
+	rels := res.(RawRelsHolder).RawRels()
+	assert.Len(rels, 3)
+	assert.Equal(`null`, string(rels["author"].Data))
+	assert.Equal(`"/articles/a1/author"`, string(rels["author"].Links["related"]))
+	assert.JSONEq(`[{"type": "tags", "lid": "local-1"}]`, string(rels["tags"].Data))
+	assert.Equal(Meta{"n": float64(1)}, rels["tags"].Meta)
+	assert.JSONEq(`{"type": "people", "id": "p1"}`, string(rels["unknown"].Data))
+
+	partial, err := opts.UnmarshalPartialResource([]byte(payload), schema)
+	assert.NoError(err)
+	assert.Equal(rels, partial.RawRels())
+
+	// No relationships
+	res, err = UnmarshalResource([]byte(`{"id":"a1","type":"articles"}`), schema)
+	assert.NoError(err)
+	assert.Nil(res.(*SoftResource).RawRels())
+
+	// A relationship without data
+	res, err = UnmarshalResource(
+		[]byte(`{"id":"a1","type":"articles","relationships":{"tags":{"meta":{}}}}`), schema)
+	assert.NoError(err)
+	assert.Nil(res.(*SoftResource).RawRels()["tags"].Data)
+}
+
+func TestUnmarshalMemberNames(t *testing.T) {
+	assert := assert.New(t)
+
+	typ := Type{Name: "articles"}
+	typ.MustAddAttr(Attr{Name: "title", Type: AttrTypeString})
+	typ.MustAddRel(Rel{FromName: "tags", ToType: "tags"})
+
+	schema := &Schema{}
+	schema.MustAddType(typ)
+	schema.MustAddType(Type{Name: "tags"})
+
+	tests := []struct {
+		name    string
+		opts    UnmarshalOptions
+		payload string
+		err     string
+		src     string
+	}{
+		{
+			name:    "skipped attribute",
+			opts:    UnmarshalOptions{UnknownFields: UnknownFieldsIgnore},
+			payload: `{"id":"a1","type":"articles","attributes":{"bad name":1}}`,
+			err:     `jsonapi: member name "bad name" is invalid`,
+			src:     "/attributes",
+		}, {
+			name:    "skipped relationship",
+			opts:    UnmarshalOptions{UnknownFields: UnknownFieldsReport},
+			payload: `{"id":"a1","type":"articles","relationships":{"-rel":{}}}`,
+			err:     `jsonapi: member name "-rel" is invalid`,
+			src:     "/relationships",
+		}, {
+			name:    "resource meta",
+			opts:    UnmarshalOptions{CheckMetaNames: true},
+			payload: `{"id":"a1","type":"articles","meta":{"ok":{"list":[{"bad!":1}]}}}`,
+			err:     `jsonapi: member name "bad!" is invalid`,
+			src:     "/meta",
+		}, {
+			name: "relationship meta",
+			opts: UnmarshalOptions{CheckMetaNames: true},
+			payload: `{"id":"a1","type":"articles","relationships":{` +
+				`"tags":{"data":[],"meta":{"n_":1}}}}`,
+			err: `jsonapi: member name "n_" is invalid`,
+			src: "/relationships/tags/meta",
+		},
+	}
+
+	for _, test := range tests {
+		_, err := test.opts.UnmarshalResource([]byte(test.payload), schema)
+		assert.EqualError(err, test.err, test.name)
+		assert.ErrorIs(err, ErrInvalidPayload, test.name)
+
+		var srcErr srcError
+		if assert.ErrorAs(err, &srcErr, test.name) {
+			src, isPtr := srcErr.Source()
+			assert.True(isPtr, test.name)
+			assert.Equal(test.src, src, test.name)
+		}
+	}
+
+	// Meta names are not checked by default.
+	_, err := UnmarshalResource(
+		[]byte(`{"id":"a1","type":"articles","meta":{"bad name":1}}`), schema)
+	assert.NoError(err)
+
+	// Document meta
+	payload := `{"data":null,"meta":{"bad name":true}}`
+
+	_, err = UnmarshalDocument(strings.NewReader(payload), schema)
+	assert.NoError(err)
+
+	_, err = UnmarshalOptions{CheckMetaNames: true}.UnmarshalDocument(
+		strings.NewReader(payload), schema)
+	assert.EqualError(err, `jsonapi: member name "bad name" is invalid`)
+}
+
+func TestAttrTransform(t *testing.T) {
+	assert := assert.New(t)
+
+	// The email is stored in lowercase and the secret is "encrypted" by
+	// reversing it.
+	reverse := func(v interface{}, _ Attr) (interface{}, error) {
+		s := []rune(v.(string))
+		for i, j := 0, len(s)-1; i < j; i, j = i+1, j-1 {
+			s[i], s[j] = s[j], s[i]
+		}
+
+		return string(s), nil
+	}
+
+	typ := Type{Name: "users"}
+	typ.MustAddAttr(Attr{
+		Name: "email",
+		Type: AttrTypeString,
+		Transformer: &TransformFuncs{
+			In: func(v interface{}, _ Attr) (interface{}, error) {
+				return strings.ToLower(v.(string)), nil
+			},
+		},
+	})
+	typ.MustAddAttr(Attr{
+		Name:        "secret",
+		Type:        AttrTypeString,
+		Transformer: &TransformFuncs{In: reverse, Out: reverse},
+	})
+
+	schema := &Schema{}
+	schema.MustAddType(typ)
+
+	// The transformers are ignored by Equal, and the attributes stay
+	// comparable.
+	ctyp := typ.Copy()
+	assert.True(ctyp.Equal(ctyp.Copy()))
+	assert.True(typ.Attrs["secret"] == ctyp.Attrs["secret"])
+
+	res, err := UnmarshalResource([]byte(`{
+		"id": "u1",
+		"type": "users",
+		"attributes": {"email": "Ann@Example.com", "secret": "abc"}
+	}`), schema)
+	assert.NoError(err)
+	assert.Equal("ann@example.com", res.Get("email"))
+	assert.Equal("cba", res.Get("secret"))
+
+	payload := MarshalResource(res, "", typ.Fields(), nil)
+	assert.JSONEq(`{
+		"attributes": {"email": "ann@example.com", "secret": "abc"},
+		"id": "u1",
+		"links": {"self": "/users/u1"},
+		"type": "users"
+	}`, string(payload))
+
+	// Errors
+	fail := func(v interface{}, _ Attr) (interface{}, error) {
+		return nil, errors.New("no key")
+	}
+
+	typ.Attrs["secret"] = Attr{
+		Name:        "secret",
+		Type:        AttrTypeString,
+		Transformer: &TransformFuncs{In: fail},
+	}
+
+	_, err = UnmarshalResource([]byte(`{
+		"id": "u1",
+		"type": "users",
+		"attributes": {"secret": "abc"}
+	}`), schema)
+	assert.Error(err)
+	assert.Contains(err.Error(), "no key")
+
+	typ.Attrs["secret"] = Attr{
+		Name:        "secret",
+		Type:        AttrTypeString,
+		Transformer: &TransformFuncs{Out: fail},
+	}
+	res = &SoftResource{Type: &typ}
+
+	assert.Nil(MarshalResource(res, "", typ.Fields(), nil))
+
+	err = MarshalDocument(&strings.Builder{}, &Document{Data: res}, nil)
+	assert.EqualError(err,
+		`jsonapi: failed to transform attribute "secret" of type "users": no key`)
+}
+
+// failingResource is a SoftResource whose GetE and SetE fail for the title.
+type failingResource struct {
+	*SoftResource
+}
+
+func (r failingResource) GetE(key string) (interface{}, error) {
+	if key == "title" {
+		return nil, errors.New("no title")
+	}
+
+	return r.SoftResource.GetE(key)
+}
+
+func (r failingResource) SetE(key string, v interface{}) error {
+	if key == "title" {
+		return errors.New("no title")
+	}
+
+	return r.SoftResource.SetE(key, v)
+}
+
+func TestResourceGetterSetterE(t *testing.T) {
+	assert := assert.New(t)
+
+	typ := Type{Name: "articles"}
+	typ.MustAddAttr(Attr{Name: "title", Type: AttrTypeString})
+	typ.MustAddAttr(Attr{Name: "views", Type: AttrTypeInt})
+
+	schema := &Schema{}
+	schema.MustAddType(typ)
+
+	res := failingResource{SoftResource: &SoftResource{Type: &typ}}
+	res.SetID("a1")
+
+	// Marshaling
+	doc := &Document{Data: Resource(res)}
+	err := MarshalDocument(&strings.Builder{}, doc, nil)
+	assert.EqualError(err,
+		`jsonapi: failed to get attribute "title" of type "articles": no title`)
+
+	out := MarshalResource(res, "", []string{"views"}, nil)
+	assert.JSONEq(`{
+		"attributes": {"views": 0},
+		"id": "a1",
+		"links": {"self": "/articles/a1"},
+		"type": "articles"
+	}`, string(out))
+
+	// Unmarshaling
+	typ.NewFunc = func() Resource {
+		return failingResource{SoftResource: &SoftResource{Type: &typ}}
+	}
+
+	schema = &Schema{}
+	schema.MustAddType(typ)
+
+	_, err = UnmarshalResource([]byte(`{
+		"type": "articles",
+		"id": "a1",
+		"attributes": {"title": "Hello"}
+	}`), schema)
+	assert.EqualError(err, "jsonapi: failed to set field: no title")
+
+	res2, err := UnmarshalResource([]byte(`{
+		"type": "articles",
+		"id": "a1",
+		"attributes": {"views": 2}
+	}`), schema)
+	assert.NoError(err)
+	assert.Equal(2, res2.Get("views"))
+}
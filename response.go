@@ -0,0 +1,129 @@
+package jsonapi
+
+import (
+	"bytes"
+	"errors"
+	"net/http"
+)
+
+// WriteDocument marshals doc with MarshalDocument and writes it to w with the
+// given status code. The Content-Type header is set to the JSON:API media type
+// unless it was already set, for example with WriteContentType to advertise
+// extensions.
+//
+// If doc cannot be marshaled, nothing from doc is written. A document holding
+// a 500 Internal Server Error is sent instead and the error is returned.
+func WriteDocument(w http.ResponseWriter, status int, doc *Document, url *URL) error {
+	return MarshalOptions{}.WriteDocument(w, status, doc, url)
+}
+
+// WriteDocument writes doc like the WriteDocument function does, marshaled
+// according to the options.
+func (o MarshalOptions) WriteDocument(w http.ResponseWriter, status int, doc *Document,
+	url *URL) error {
+	buf := &bytes.Buffer{}
+
+	if err := o.MarshalDocument(buf, doc, url); err != nil {
+		writeInternalError(w)
+		return err
+	}
+
+	writePayload(w, status, buf.Bytes())
+
+	return nil
+}
+
+// WriteCreated writes doc like WriteDocument with a 201 Created status code.
+//
+// The primary data of doc must be a Resource. The Location header is built
+// from the PrePath of doc and the type and ID of the resource, like its self
+// link. The links held by the resource (see LinkHolder) are not used, since
+// they may come from the request.
+func WriteCreated(w http.ResponseWriter, doc *Document, url *URL) error {
+	return MarshalOptions{}.WriteCreated(w, doc, url)
+}
+
+// WriteCreated writes doc like the WriteCreated function does, marshaled
+// according to the options. The Location header honors TypePaths, like the self
+// link of the resource in the body.
+func (o MarshalOptions) WriteCreated(w http.ResponseWriter, doc *Document, url *URL) error {
+	res, ok := doc.Data.(Resource)
+	if !ok {
+		writeInternalError(w)
+		return errors.New("jsonapi: primary data of a created document must be a resource")
+	}
+
+	buf := &bytes.Buffer{}
+
+	if err := o.MarshalDocument(buf, doc, url); err != nil {
+		writeInternalError(w)
+		return err
+	}
+
+	id, _ := res.Get("id").(string)
+	location := o.resourceLink(doc.PrePath, res.GetType().Name, id)
+
+	w.Header().Set("Location", location)
+	writePayload(w, http.StatusCreated, buf.Bytes())
+
+	return nil
+}
+
+// WriteNoContent writes a response with a 204 No Content status code and no
+// body.
+func WriteNoContent(w http.ResponseWriter) {
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// WriteError writes a document holding e. The status code is the status of e.
+func WriteError(w http.ResponseWriter, e Error) error {
+	return MarshalOptions{}.WriteErrors(w, e)
+}
+
+// WriteError writes a document holding e like the WriteError function does,
+// marshaled according to the options.
+func (o MarshalOptions) WriteError(w http.ResponseWriter, e Error) error {
+	return o.WriteErrors(w, e)
+}
+
+// WriteErrors writes a document holding errs. The status code is the one
+// returned by ErrorList.Status. Identical errors are only written once.
+//
+// A 500 Internal Server Error is written if errs is empty.
+func WriteErrors(w http.ResponseWriter, errs ...Error) error {
+	return MarshalOptions{}.WriteErrors(w, errs...)
+}
+
+// WriteErrors writes a document holding errs like the WriteErrors function
+// does, marshaled according to the options.
+func (o MarshalOptions) WriteErrors(w http.ResponseWriter, errs ...Error) error {
+	list := &ErrorList{}
+	list.Add(errs...)
+
+	if list.Len() == 0 {
+		list.Add(NewErrInternalServerError())
+	}
+
+	return o.WriteDocument(w, list.Status(), list.Document(), nil)
+}
+
+// writePayload writes the headers, the status code and the payload.
+func writePayload(w http.ResponseWriter, status int, pl []byte) {
+	if w.Header().Get("Content-Type") == "" {
+		WriteContentType(w, MediaTypeOptions{})
+	}
+
+	w.WriteHeader(status)
+	_, _ = w.Write(pl)
+}
+
+// writeInternalError writes a document holding a 500 Internal Server Error.
+func writeInternalError(w http.ResponseWriter) {
+	buf := &bytes.Buffer{}
+	doc := &Document{Errors: []Error{NewErrInternalServerError()}}
+
+	// A document with a single error can always be marshaled.
+	_ = MarshalDocument(buf, doc, nil)
+
+	writePayload(w, http.StatusInternalServerError, buf.Bytes())
+}
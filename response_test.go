@@ -0,0 +1,126 @@
+package jsonapi_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	. "github.com/mark-hartmann/jsonapi"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWriteDocument(t *testing.T) {
+	assert := assert.New(t)
+
+	res := Wrap(&mockType1{ID: "mt1"})
+	url, _ := NewURLFromRaw(newMockSchema(), "/mocktypes1/mt1?fields[mocktypes1]=str")
+
+	rec := httptest.NewRecorder()
+	err := WriteDocument(rec, http.StatusOK, &Document{Data: res}, url)
+	assert.NoError(err)
+	assert.Equal(http.StatusOK, rec.Code)
+	assert.Equal(MediaType, rec.Header().Get("Content-Type"))
+	assert.Equal("Accept", rec.Header().Get("Vary"))
+	assert.JSONEq(`{
+		"data": {
+			"attributes": {"str": ""},
+			"id": "mt1",
+			"links": {"self": "/mocktypes1/mt1"},
+			"type": "mocktypes1"
+		},
+		"jsonapi": {"version": "1.0"},
+		"links": {"self": "/mocktypes1/mt1?fields%5Bmocktypes1%5D=str"}
+	}`, rec.Body.String())
+
+	// A Content-Type set beforehand is kept.
+	rec = httptest.NewRecorder()
+	WriteContentType(rec, MediaTypeOptions{Ext: []string{"https://example.org/ext"}})
+	assert.NoError(WriteDocument(rec, http.StatusOK, &Document{Data: res}, url))
+	assert.Equal(MediaType+`; ext="https://example.org/ext"`, rec.Header().Get("Content-Type"))
+
+	// Marshaling error
+	rec = httptest.NewRecorder()
+	err = WriteDocument(rec, http.StatusOK, &Document{Data: "invalid"}, nil)
+	assert.EqualError(err, "data contains an unknown type")
+	assert.Equal(http.StatusInternalServerError, rec.Code)
+	assert.Equal(MediaType, rec.Header().Get("Content-Type"))
+	assert.JSONEq(`{
+		"errors": [{"status": "500", "title": "Internal server error"}],
+		"jsonapi": {"version": "1.0"}
+	}`, rec.Body.String())
+}
+
+func TestWriteCreated(t *testing.T) {
+	assert := assert.New(t)
+
+	res := Wrap(&mockType1{ID: "mt1"})
+
+	rec := httptest.NewRecorder()
+	err := WriteCreated(rec, &Document{Data: res, PrePath: "https://example.org"}, nil)
+	assert.NoError(err)
+	assert.Equal(http.StatusCreated, rec.Code)
+	assert.Equal("https://example.org/mocktypes1/mt1", rec.Header().Get("Location"))
+	assert.Contains(rec.Body.String(), `"id":"mt1"`)
+
+	// The Location header and the self link honor the paths of the types.
+	opts := MarshalOptions{TypePaths: map[string]string{"mocktypes1": "/content/mocktypes"}}
+
+	rec = httptest.NewRecorder()
+	err = opts.WriteCreated(rec, &Document{Data: res, PrePath: "https://example.org"}, nil)
+	assert.NoError(err)
+	assert.Equal("https://example.org/content/mocktypes/mt1", rec.Header().Get("Location"))
+	assert.Contains(rec.Body.String(),
+		`"links":{"self":"https://example.org/content/mocktypes/mt1"}`)
+
+	// The links held by the resource are not used, even when they are kept
+	// from the request.
+	schema := &Schema{}
+	schema.MustAddType(Type{Name: "articles"})
+
+	res2, err := UnmarshalOptions{KeepLinks: true}.UnmarshalResource([]byte(`{
+		"id": "a1",
+		"type": "articles",
+		"links": {"self": "https://evil.example/x"}
+	}`), schema)
+	assert.NoError(err)
+	assert.Equal("https://evil.example/x", res2.(LinkHolder).Links()["self"].HRef)
+
+	rec = httptest.NewRecorder()
+	assert.NoError(WriteCreated(rec, &Document{Data: res2, PrePath: "https://api.example"}, nil))
+	assert.Equal("https://api.example/articles/a1", rec.Header().Get("Location"))
+
+	// Not a resource
+	rec = httptest.NewRecorder()
+	err = WriteCreated(rec, &Document{Data: Identifier{Type: "mocktypes1", ID: "mt1"}}, nil)
+	assert.Error(err)
+	assert.Equal(http.StatusInternalServerError, rec.Code)
+	assert.Empty(rec.Header().Get("Location"))
+}
+
+func TestWriteNoContent(t *testing.T) {
+	rec := httptest.NewRecorder()
+	WriteNoContent(rec)
+	assert.Equal(t, http.StatusNoContent, rec.Code)
+	assert.Empty(t, rec.Body.String())
+}
+
+func TestWriteErrors(t *testing.T) {
+	assert := assert.New(t)
+
+	rec := httptest.NewRecorder()
+	assert.NoError(WriteError(rec, NewErrNotFound()))
+	assert.Equal(http.StatusNotFound, rec.Code)
+	assert.Equal(MediaType, rec.Header().Get("Content-Type"))
+	assert.Contains(rec.Body.String(), `"status":"404"`)
+
+	rec = httptest.NewRecorder()
+	assert.NoError(WriteErrors(rec, NewErrNotFound(), NewErrForbidden(), NewErrNotFound()))
+	assert.Equal(http.StatusBadRequest, rec.Code)
+	assert.Equal(2, strings.Count(rec.Body.String(), `"status"`))
+
+	rec = httptest.NewRecorder()
+	assert.NoError(WriteErrors(rec))
+	assert.Equal(http.StatusInternalServerError, rec.Code)
+}
@@ -0,0 +1,177 @@
+package jsonapi
+
+import (
+	"sort"
+	"strings"
+)
+
+// A RouteKind is the kind of endpoint a Route represents.
+type RouteKind int
+
+// The kinds of routes.
+const (
+	// RouteCollection is a collection of resources, like /articles.
+	RouteCollection RouteKind = iota
+	// RouteResource is a single resource, like /articles/{id}.
+	RouteResource
+	// RouteRelated is the resources a relationship points to, like
+	// /articles/{id}/author.
+	RouteRelated
+	// RouteRelationship is the linkage of a relationship, like
+	// /articles/{id}/relationships/author.
+	RouteRelationship
+)
+
+// String returns the name of the kind.
+func (k RouteKind) String() string {
+	switch k {
+	case RouteCollection:
+		return "collection"
+	case RouteResource:
+		return "resource"
+	case RouteRelated:
+		return "related"
+	case RouteRelationship:
+		return "relationship"
+	}
+
+	return "unknown"
+}
+
+// RouteIDParam is the placeholder of the resource ID in the path of a Route.
+const RouteIDParam = "{id}"
+
+// A Route is an endpoint supported by a schema, with the query parameters it
+// accepts. It does not say anything about the HTTP methods.
+type Route struct {
+	// Path is the path of the endpoint, where RouteIDParam stands for the ID
+	// of the resource.
+	Path string
+
+	Kind RouteKind
+
+	// Type is the name of the type of the resources returned by the
+	// endpoint. For a related or relationship route, it is the type the
+	// relationship points to.
+	Type string
+
+	// Rel is the relationship of a related or relationship route.
+	Rel Rel
+
+	// Collection is true if the endpoint returns a collection of resources
+	// or identifiers.
+	Collection bool
+
+	// FieldTypes holds the names of the types that can be given to a
+	// fields[...] parameter, which are Type and the types reachable from
+	// it through relationships, in alphabetical order.
+	FieldTypes []string
+
+	// Includes holds the names of the relationships of Type that can be
+	// given to the include parameter, in alphabetical order. Longer paths
+	// can be built from the relationships of the included types.
+	Includes []string
+
+	// Sort holds the fields a collection can be sorted by, in alphabetical
+	// order. It is empty if Collection is false.
+	Sort []string
+}
+
+// Pattern returns the path of the route where RouteIDParam is replaced by
+// param, like ":id" for routers that use that syntax.
+func (r Route) Pattern(param string) string {
+	return strings.Replace(r.Path, RouteIDParam, param, 1)
+}
+
+// Routes returns the routes supported by the schema, sorted by type and then
+// relationship name. Each type has a collection route and a resource route,
+// and each of its relationships has a related route and a relationship route.
+//
+// It is meant to register the endpoints of an application on a router or to
+// document them.
+func (s *Schema) Routes() []Route {
+	types := make([]string, 0, len(s.Types))
+	for _, typ := range s.Types {
+		types = append(types, typ.Name)
+	}
+
+	sort.Strings(types)
+
+	var routes []Route
+
+	for _, name := range types {
+		typ := s.GetType(name)
+		col := s.route(RouteCollection, "/"+typ.Name, typ.Name, true)
+		col.Sort = append(sortedAttrNames(typ.Attrs), "id")
+		sort.Strings(col.Sort)
+
+		routes = append(routes,
+			col,
+			s.route(RouteResource, "/"+typ.Name+"/"+RouteIDParam, typ.Name, false),
+		)
+
+		for _, relName := range sortedRelNames(typ.Rels) {
+			rel := typ.Rels[relName]
+			path := "/" + typ.Name + "/" + RouteIDParam + "/"
+
+			related := s.route(RouteRelated, path+rel.FromName, rel.ToType, !rel.ToOne)
+			related.Rel = rel
+
+			if related.Collection {
+				related.Sort = append(sortedAttrNames(s.GetType(rel.ToType).Attrs), "id")
+				sort.Strings(related.Sort)
+			}
+
+			relationship := Route{
+				Path:       path + "relationships/" + rel.FromName,
+				Kind:       RouteRelationship,
+				Type:       rel.ToType,
+				Rel:        rel,
+				Collection: !rel.ToOne,
+			}
+
+			routes = append(routes, related, relationship)
+		}
+	}
+
+	return routes
+}
+
+// route returns a route that returns resources of the type named typ.
+func (s *Schema) route(kind RouteKind, path, typ string, col bool) Route {
+	return Route{
+		Path:       path,
+		Kind:       kind,
+		Type:       typ,
+		Collection: col,
+		FieldTypes: s.reachableTypes(typ),
+		Includes:   sortedRelNames(s.GetType(typ).Rels),
+	}
+}
+
+// reachableTypes returns the name of the type named typ and the names of the
+// types reachable from it through relationships, in alphabetical order.
+func (s *Schema) reachableTypes(typ string) []string {
+	seen := map[string]bool{typ: true}
+	queue := []string{typ}
+
+	for len(queue) > 0 {
+		for _, rel := range s.GetType(queue[0]).Rels {
+			if !seen[rel.ToType] {
+				seen[rel.ToType] = true
+				queue = append(queue, rel.ToType)
+			}
+		}
+
+		queue = queue[1:]
+	}
+
+	names := make([]string, 0, len(seen))
+	for name := range seen {
+		names = append(names, name)
+	}
+
+	sort.Strings(names)
+
+	return names
+}
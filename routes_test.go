@@ -0,0 +1,88 @@
+package jsonapi_test
+
+import (
+	"testing"
+
+	. "github.com/mark-hartmann/jsonapi"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSchemaRoutes(t *testing.T) {
+	assert := assert.New(t)
+
+	schema := &Schema{}
+
+	articles := Type{Name: "articles"}
+	articles.MustAddAttr(Attr{Name: "title", Type: AttrTypeString})
+	articles.MustAddRel(Rel{FromName: "author", ToType: "people", ToOne: true})
+	schema.MustAddType(articles)
+
+	people := Type{Name: "people"}
+	people.MustAddAttr(Attr{Name: "name", Type: AttrTypeString})
+	people.MustAddRel(Rel{FromName: "pets", ToType: "pets"})
+	schema.MustAddType(people)
+
+	schema.MustAddType(Type{Name: "pets"})
+
+	routes := schema.Routes()
+
+	paths := make([]string, 0, len(routes))
+	for _, r := range routes {
+		paths = append(paths, r.Kind.String()+" "+r.Path)
+	}
+
+	assert.Equal([]string{
+		"collection /articles",
+		"resource /articles/{id}",
+		"related /articles/{id}/author",
+		"relationship /articles/{id}/relationships/author",
+		"collection /people",
+		"resource /people/{id}",
+		"related /people/{id}/pets",
+		"relationship /people/{id}/relationships/pets",
+		"collection /pets",
+		"resource /pets/{id}",
+	}, paths)
+
+	// Collection
+	assert.Equal(Route{
+		Path:       "/articles",
+		Kind:       RouteCollection,
+		Type:       "articles",
+		Collection: true,
+		FieldTypes: []string{"articles", "people", "pets"},
+		Includes:   []string{"author"},
+		Sort:       []string{"id", "title"},
+	}, routes[0])
+
+	// Related
+	assert.Equal(Route{
+		Path:       "/articles/{id}/author",
+		Kind:       RouteRelated,
+		Type:       "people",
+		Rel:        articles.Rels["author"],
+		FieldTypes: []string{"people", "pets"},
+		Includes:   []string{"pets"},
+	}, routes[2])
+	assert.Equal([]string{"id"}, routes[6].Sort)
+
+	// Relationship
+	assert.Equal(Route{
+		Path:       "/people/{id}/relationships/pets",
+		Kind:       RouteRelationship,
+		Type:       "pets",
+		Rel:        people.Rels["pets"],
+		Collection: true,
+	}, routes[7])
+
+	// Pattern
+	assert.Equal("/articles/:id/author", routes[2].Pattern(":id"))
+	assert.Equal("unknown", RouteKind(-1).String())
+
+	// Every path is a valid URL.
+	for _, r := range routes {
+		_, err := NewURLFromRaw(schema, r.Pattern("1"))
+		assert.NoError(err, r.Path)
+	}
+}
@@ -9,10 +9,23 @@ import (
 // A Schema contains a list of types. It makes sure that all types are valid and
 // their relationships are consistent.
 //
-// Check can be used to validate the relationships between the types.
+// Check can be used to validate the relationships between the types and
+// OnChange to be notified of modifications.
 type Schema struct {
 	Types []Type
 
+	// NameMapper maps the names of the attributes and relationships of the
+	// types to the member names found in payloads and URLs, like CamelCase.
+	// The names are mapped when resources are unmarshaled with the schema or
+	// marshaled with MarshalOptions.Schema set to it, in the links of the
+	// relationships, and when a URL is made with the schema and written with
+	// URL.String. The names are used as they are if NameMapper is nil.
+	//
+	// The types keep their names, which are the ones found in Params, in the
+	// types built from structs and in the sparse fieldsets given to the
+	// marshaling functions.
+	NameMapper NameMapper
+
 	// Rels stores the relationships found in the schema's types. For
 	// two-way relationships, only one is chosen to be part of this
 	// map. The chosen one is the one that comes first when sorting
@@ -27,32 +40,66 @@ type Schema struct {
 	// duplication (the information is already accessible through the
 	// inverse relationship).
 	rels map[string]Rel
+
+	// defaultFields stores the default sparse fieldsets by type name. See
+	// SetDefaultFields.
+	defaultFields map[string][]string
+
+	// fieldGroups stores the named sparse fieldsets by type name and group
+	// name. See SetFieldGroup.
+	fieldGroups map[string]map[string][]string
+
+	// listeners are called when the schema is modified. See OnChange.
+	listeners []func(SchemaChange)
 }
 
 // AddType adds a type to the schema.
+//
+// The FromOne field of any relationship in the schema, including those of the
+// types already added, is set to true if it is unset and the inverse
+// relationship is a to-one relationship. Existing types can therefore be
+// modified by this method.
 func (s *Schema) AddType(typ Type) error {
 	// Validation
 	if typ.Name == "" {
 		return errors.New("jsonapi: type name is empty")
 	}
 
+	if !memberRegexp.MatchString(typ.Name) {
+		return &InvalidTypeNameError{TypeName: typ.Name}
+	}
+
 	// Make sure the name isn't already used
 	for i := range s.Types {
 		if s.Types[i].Name == typ.Name {
-			return fmt.Errorf("jsonapi: type name %q is already used", typ.Name)
+			return &DuplicateTypeError{TypeName: typ.Name}
 		}
 	}
 
 	s.Types = append(s.Types, typ)
+	s.resolveFromOne()
+	s.notify(OpAddType, typ.Name, "")
 
 	return nil
 }
 
+// MustAddType calls AddType and panics if an error is returned.
+func (s *Schema) MustAddType(typ Type) {
+	if err := s.AddType(typ); err != nil {
+		panic(err)
+	}
+}
+
 // RemoveType removes a type from the schema.
 func (s *Schema) RemoveType(typ string) {
 	for i := range s.Types {
 		if s.Types[i].Name == typ {
 			s.Types = append(s.Types[0:i], s.Types[i+1:]...)
+			delete(s.defaultFields, typ)
+			delete(s.fieldGroups, typ)
+			s.notify(OpRemoveType, typ, "")
+
+			return
 		}
 	}
 }
@@ -61,50 +108,90 @@ func (s *Schema) RemoveType(typ string) {
 func (s *Schema) AddAttr(typ string, attr Attr) error {
 	for i := range s.Types {
 		if s.Types[i].Name == typ {
-			return s.Types[i].AddAttr(attr)
+			if err := s.Types[i].AddAttr(attr); err != nil {
+				return err
+			}
+
+			s.notify(OpAddAttr, typ, attr.Name)
+
+			return nil
 		}
 	}
 
 	return fmt.Errorf("jsonapi: type %q does not exist", typ)
 }
 
+// MustAddAttr calls AddAttr and panics if an error is returned.
+func (s *Schema) MustAddAttr(typ string, attr Attr) {
+	if err := s.AddAttr(typ, attr); err != nil {
+		panic(err)
+	}
+}
+
 // RemoveAttr removes an attribute from the specified type.
 func (s *Schema) RemoveAttr(typ string, attr string) {
 	for i := range s.Types {
-		if s.Types[i].Name == typ {
+		if _, ok := s.Types[i].Attrs[attr]; ok && s.Types[i].Name == typ {
 			s.Types[i].RemoveAttr(attr)
+			s.removeDefaultField(typ, attr)
+			s.removeGroupField(typ, attr)
+			s.notify(OpRemoveAttr, typ, attr)
 		}
 	}
 }
 
 // AddRel adds a relationship to the specified type.
+//
+// Like AddType, it sets the FromOne field of the relationships whose inverse is
+// a to-one relationship, which can modify the relationships of other types.
 func (s *Schema) AddRel(typ string, rel Rel) error {
 	for i := range s.Types {
 		if s.Types[i].Name == typ {
-			return s.Types[i].AddRel(rel)
+			if err := s.Types[i].AddRel(rel); err != nil {
+				return err
+			}
+
+			s.resolveFromOne()
+			s.notify(OpAddRel, typ, rel.FromName)
+
+			return nil
 		}
 	}
 
 	return fmt.Errorf("jsonapi: type %q does not exist", typ)
 }
 
+// MustAddRel calls AddRel and panics if an error is returned.
+func (s *Schema) MustAddRel(typ string, rel Rel) {
+	if err := s.AddRel(typ, rel); err != nil {
+		panic(err)
+	}
+}
+
 // RemoveRel removes a relationship from the specified type.
 func (s *Schema) RemoveRel(typ string, rel string) {
 	for i := range s.Types {
-		if s.Types[i].Name == typ {
+		if _, ok := s.Types[i].Rels[rel]; ok && s.Types[i].Name == typ {
 			s.Types[i].RemoveRel(rel)
+			s.removeDefaultField(typ, rel)
+			s.removeGroupField(typ, rel)
+			s.notify(OpRemoveRel, typ, rel)
 		}
 	}
 }
 
 // AddTwoWayRel adds a two-way relationship to both types involved.
 //
-// The types must already exist in the schema.
+// The types must already exist in the schema. Both relationships can belong to
+// the same type, like the manager and reports relationships of a people type.
+// A relationship that is its own inverse, like friends, is only added once.
 func (s *Schema) AddTwoWayRel(rel Rel) error {
 	rel1 := rel.Normalize()
-	rel2 := rel.Invert()
+	// Normalize may already return the inverse of rel.
+	rel2 := rel1.Invert()
 	found1 := false
 	found2 := false
+	self := rel1.FromType == rel2.FromType && rel1.FromName == rel2.FromName
 
 	for i := range s.Types {
 		if s.Types[i].Name == rel1.FromType {
@@ -114,13 +201,23 @@ func (s *Schema) AddTwoWayRel(rel Rel) error {
 			if err != nil {
 				return err
 			}
-		} else if s.Types[i].Name == rel2.FromType {
+
+			s.notify(OpAddRel, rel1.FromType, rel1.FromName)
+		}
+
+		if s.Types[i].Name == rel2.FromType {
 			found2 = true
 
+			if self {
+				continue
+			}
+
 			err := s.Types[i].AddRel(rel2)
 			if err != nil {
 				return err
 			}
+
+			s.notify(OpAddRel, rel2.FromType, rel2.FromName)
 		}
 	}
 
@@ -134,6 +231,13 @@ func (s *Schema) AddTwoWayRel(rel Rel) error {
 	)
 }
 
+// MustAddTwoWayRel calls AddTwoWayRel and panics if an error is returned.
+func (s *Schema) MustAddTwoWayRel(rel Rel) {
+	if err := s.AddTwoWayRel(rel); err != nil {
+		panic(err)
+	}
+}
+
 // Rels returns all the relationships from the schema's types. For two-way
 // relationships (two types where each has a relationship pointing to the other
 // type), only one of the two relationships will appear in the list.
@@ -181,73 +285,431 @@ func (s *Schema) GetType(name string) Type {
 	return Type{}
 }
 
-// Check checks the integrity of all the relationships between the types and
-// returns all the errors that were found.
+// Subset returns a new schema that only contains the types named in types. The
+// relationships of those types that point to other types are left out, so the
+// subset passes Check if s does.
+//
+// A subset is a view of s for a single API version or a single tenant. It can
+// be given to NewURL, NewParams, ParseRequest and the unmarshaling functions
+// like any schema, and to MarshalOptions.Schema so that only its fields are
+// marshaled. The types are copies, so the subset can be tailored further with
+// RemoveAttr and RemoveRel without affecting s. The attributes and the NewFunc
+// of the types are shared. The default fieldsets are copied, but not the
+// listeners.
+//
+// An *UnknownTypeError is returned if a type does not exist in s.
+func (s *Schema) Subset(types ...string) (*Schema, error) {
+	keep := make(map[string]bool, len(types))
+
+	for _, name := range types {
+		if !s.HasType(name) {
+			return nil, &UnknownTypeError{Type: name}
+		}
+
+		keep[name] = true
+	}
+
+	sub := &Schema{NameMapper: s.NameMapper}
+
+	for _, typ := range s.Types {
+		if !keep[typ.Name] {
+			continue
+		}
+
+		ctyp := typ.Copy()
+
+		for name, rel := range ctyp.Rels {
+			if !keep[rel.ToType] {
+				delete(ctyp.Rels, name)
+			}
+		}
+
+		sub.Types = append(sub.Types, ctyp)
+
+		if fields, ok := s.DefaultFields(typ.Name); ok {
+			if sub.defaultFields == nil {
+				sub.defaultFields = map[string][]string{}
+			}
+
+			// The fieldset is kept even if it becomes empty.
+			sub.defaultFields[typ.Name] = typeFields(ctyp, fields)
+		}
+
+		for name, fields := range s.fieldGroups[typ.Name] {
+			kept := typeFields(ctyp, fields)
+
+			if sub.fieldGroups == nil {
+				sub.fieldGroups = map[string]map[string][]string{}
+			}
+
+			if sub.fieldGroups[typ.Name] == nil {
+				sub.fieldGroups[typ.Name] = map[string][]string{}
+			}
+
+			sub.fieldGroups[typ.Name][name] = kept
+		}
+	}
+
+	return sub, nil
+}
+
+// SetDefaultFields sets the sparse fieldset used for the type named typ when a
+// request does not have a fields[typ] parameter. NewParams then sets it in
+// Params.Fields like if it had been given in the URL, so MarshalDocument only
+// marshals those fields. The default fieldset does not apply when the URL has
+// its own fieldset for the type, even if it is empty.
+//
+// MarshalDocument also uses the default fieldsets of MarshalOptions.Schema for
+// the types the URL has no fieldset for, including when there is no URL.
+//
+// Calling SetDefaultFields without any field removes the default fieldset, so
+// all fields are marshaled again. An error is returned if the type or one of
+// the fields does not exist.
+func (s *Schema) SetDefaultFields(typ string, fields ...string) error {
+	t := s.GetType(typ)
+	if t.Name == "" {
+		return &UnknownTypeError{Type: typ}
+	}
+
+	if len(fields) == 0 {
+		delete(s.defaultFields, typ)
+		return nil
+	}
+
+	fields = removeDuplicates(fields)
+
+	if field := findFirstDifference(fields, t.Fields()); field != "" && field != "id" {
+		return &UnknownFieldError{Type: typ, Field: field}
+	}
+
+	if s.defaultFields == nil {
+		s.defaultFields = map[string][]string{}
+	}
+
+	s.defaultFields[typ] = fields
+
+	return nil
+}
+
+// DefaultFields returns the default sparse fieldset of the type named typ and
+// whether there is one. See SetDefaultFields.
+func (s *Schema) DefaultFields(typ string) ([]string, bool) {
+	fields, ok := s.defaultFields[typ]
+	if !ok {
+		return nil, false
+	}
+
+	return append([]string{}, fields...), true
+}
+
+// removeDefaultField removes field from the default fieldset of typ. The
+// fieldset is kept if it becomes empty, so that the fields of typ are not all
+// marshaled again.
+func (s *Schema) removeDefaultField(typ, field string) {
+	fields, ok := s.defaultFields[typ]
+	if !ok {
+		return
+	}
+
+	kept := make([]string, 0, len(fields))
+
+	for _, f := range fields {
+		if f != field {
+			kept = append(kept, f)
+		}
+	}
+
+	s.defaultFields[typ] = kept
+}
+
+// typeFields returns the fields of fields that are id or a field of typ.
+func typeFields(typ Type, fields []string) []string {
+	kept := make([]string, 0, len(fields))
+
+	for _, f := range fields {
+		_, isAttr := typ.Attrs[f]
+		_, isRel := typ.Rels[f]
+
+		if f == "id" || isAttr || isRel {
+			kept = append(kept, f)
+		}
+	}
+
+	return kept
+}
+
+// Check checks the integrity of all the types and relationships and returns
+// all the errors that were found. Each error is a SchemaCheckError and they are
+// sorted by type and field.
+//
+// The names of the types must be unique and valid member names, like the names
+// of the attributes. The target type of each relationship must exist. A
+// relationship with an inverse must be the inverse of its inverse and both must
+// agree on the cardinalities, meaning that ToOne of one relationship is FromOne
+// of the other. AddType and AddRel set FromOne when the inverse is a to-one
+// relationship, and ResolveRels can be used to set it on any schema.
 func (s *Schema) Check() []error {
 	var (
-		errs = []error{}
+		errs  = []error{}
+		names = map[string]bool{}
 	)
 
-	// Check the inverse relationships
 	for _, typ := range s.Types {
+		if names[typ.Name] {
+			errs = append(errs, &DuplicateTypeError{TypeName: typ.Name})
+		}
+
+		names[typ.Name] = true
+
+		if !memberRegexp.MatchString(typ.Name) {
+			errs = append(errs, &InvalidTypeNameError{TypeName: typ.Name})
+		}
+
+		// Attributes
+		for _, key := range sortedAttrNames(typ.Attrs) {
+			name := typ.Attrs[key].Name
+			if !memberRegexp.MatchString(name) || name == "id" || name == "type" {
+				errs = append(errs, &InvalidAttrNameError{TypeName: typ.Name, FieldName: name})
+			}
+		}
+
 		// Relationships
-		for _, rel := range typ.Rels {
-			var targetType Type
-
-			// Does the relationship point to a type that exists?
-			if targetType = s.GetType(rel.ToType); targetType.Name == "" {
-				errs = append(errs, fmt.Errorf(
-					"jsonapi: field ToType of relationship %q of type %q does not exist",
-					rel.FromName,
-					typ.Name,
-				))
+		for _, name := range sortedRelNames(typ.Rels) {
+			errs = append(errs, s.checkRel(typ, typ.Rels[name])...)
+		}
+	}
+
+	sort.SliceStable(errs, func(i, j int) bool {
+		ei, ej := errs[i].(SchemaCheckError), errs[j].(SchemaCheckError)
+		if ei.Type() != ej.Type() {
+			return ei.Type() < ej.Type()
+		}
+
+		return ei.Field() < ej.Field()
+	})
+
+	return errs
+}
+
+// Validate calls Check and returns a *CheckError holding the errors, or nil if
+// the schema is valid.
+func (s *Schema) Validate() error {
+	errs := s.Check()
+	if len(errs) == 0 {
+		return nil
+	}
+
+	ce := &CheckError{Errs: make([]SchemaCheckError, 0, len(errs))}
+	for _, err := range errs {
+		ce.Errs = append(ce.Errs, err.(SchemaCheckError))
+	}
+
+	return ce
+}
+
+// checkRel returns the errors found in the relationship rel of typ.
+func (s *Schema) checkRel(typ Type, rel Rel) []error {
+	var errs []error
+
+	// Does the relationship point to a type that exists?
+	targetType := s.GetType(rel.ToType)
+	if targetType.Name == "" {
+		errs = append(errs, &UnknownRelTypeError{
+			TypeName:  typ.Name,
+			FieldName: rel.FromName,
+			ToType:    rel.ToType,
+		})
+	}
+
+	// SPEC 5.2.2
+	for _, attr := range typ.Attrs {
+		if attr.Name == rel.FromName {
+			errs = append(errs, &FieldNameConflictError{TypeName: typ.Name, FieldName: rel.FromName})
+		}
+	}
+
+	// Skip to next relationship here if there's no inverse
+	if rel.ToName == "" {
+		return errs
+	}
+
+	// Is the inverse relationship type the same as its type name?
+	if rel.FromType != typ.Name {
+		return append(errs, &InvalidRelFromTypeError{
+			TypeName:  typ.Name,
+			FieldName: rel.FromName,
+			FromType:  rel.FromType,
+		})
+	}
+
+	// Do both relationships (current and inverse) point to each other?
+	invRel, found := targetType.Rels[rel.ToName]
+	if !found || invRel.ToType != typ.Name || invRel.ToName != rel.FromName {
+		return append(errs, &MissingInverseRelError{
+			TypeName:  typ.Name,
+			FieldName: rel.FromName,
+			ToType:    rel.ToType,
+			ToName:    rel.ToName,
+		})
+	}
+
+	// Do both relationships agree on the cardinalities?
+	if rel.ToOne != invRel.FromOne || rel.FromOne != invRel.ToOne {
+		errs = append(errs, &InverseCardinalityError{TypeName: typ.Name, FieldName: rel.FromName})
+	}
+
+	return errs
+}
+
+// ResolveRels sets the FromOne field of every relationship that has an inverse
+// according to the ToOne field of that inverse.
+//
+// Types built with BuildType do not know the cardinality of the inverse
+// relationships, so this method should be called once all types are added and
+// before Check.
+func (s *Schema) ResolveRels() {
+	for t, typ := range s.Types {
+		for name, rel := range typ.Rels {
+			if rel.ToName == "" {
+				continue
 			}
 
-			// SPEC 5.2.2
-			for _, attr := range typ.Attrs {
-				if attr.Name == rel.FromName {
-					errs = append(errs, fmt.Errorf("jsonapi: type %q can not have an attribute "+
-						"and relationship with the same name %q", typ.Name, rel.FromName))
-				}
+			invRel, ok := s.GetType(rel.ToType).Rels[rel.ToName]
+			if ok && invRel.ToType == typ.Name && invRel.ToName == rel.FromName {
+				rel.FromOne = invRel.ToOne
+				s.Types[t].Rels[name] = rel
 			}
+		}
+	}
+}
 
-			// Skip to next relationship here if there's no inverse
-			if rel.ToName == "" {
+// resolveFromOne sets the FromOne field of the relationships whose inverse is a
+// to-one relationship, like ResolveRels does, so that the types built with
+// BuildType pass Check once they are added. A FromOne field that is already
+// true is left untouched for Check to report a mismatch.
+func (s *Schema) resolveFromOne() {
+	for t, typ := range s.Types {
+		for name, rel := range typ.Rels {
+			if rel.ToName == "" || rel.FromOne {
 				continue
 			}
 
-			// Is the inverse relationship type the same as its
-			// type name?
-			if rel.FromType != typ.Name {
-				errs = append(errs, fmt.Errorf(
-					"jsonapi: "+
-						"field FromType of relationship %q must be its type's name (%q, not %q)",
-					rel.FromName,
-					typ.Name,
-					rel.FromType,
-				))
-			} else {
-				// Do both relationships (current and inverse) point
-				// to each other?
-				var found bool
-				for _, invRel := range targetType.Rels {
-					if rel.FromName == invRel.ToName && rel.ToName == invRel.FromName {
-						found = true
-					}
-				}
-				if !found {
-					errs = append(errs, fmt.Errorf(
-						"jsonapi: "+
-							"relationship %q of type %q and its inverse do not point each other",
-						rel.FromName,
-						typ.Name,
-					))
-				}
+			invRel, ok := s.GetType(rel.ToType).Rels[rel.ToName]
+			if ok && invRel.ToOne && invRel.ToType == typ.Name && invRel.ToName == rel.FromName {
+				rel.FromOne = true
+				s.Types[t].Rels[name] = rel
 			}
 		}
 	}
+}
 
-	return errs
+// LinkResources adds b to the relationship named rel of a and, if the
+// relationship has an inverse, adds a to the inverse relationship of b.
+//
+// A to-one relationship is replaced, but the resource it pointed to before is
+// not updated. UnlinkResources can be called first to keep it consistent.
+func (s *Schema) LinkResources(a Resource, rel string, b Resource) error {
+	r, inv, err := s.linkRels(a, rel, b)
+	if err != nil {
+		return err
+	}
+
+	addRelID(a, r, b.Get("id").(string))
+
+	if inv.FromName != "" {
+		addRelID(b, inv, a.Get("id").(string))
+	}
+
+	return nil
+}
+
+// UnlinkResources removes b from the relationship named rel of a and, if the
+// relationship has an inverse, removes a from the inverse relationship of b.
+//
+// Nothing happens to a relationship that does not point to the other resource.
+func (s *Schema) UnlinkResources(a Resource, rel string, b Resource) error {
+	r, inv, err := s.linkRels(a, rel, b)
+	if err != nil {
+		return err
+	}
+
+	removeRelID(a, r, b.Get("id").(string))
+
+	if inv.FromName != "" {
+		removeRelID(b, inv, a.Get("id").(string))
+	}
+
+	return nil
+}
+
+// linkRels returns the relationship named rel of the type of a and its
+// inverse, if any. An error is returned if the relationship does not exist or
+// does not point to the type of b.
+func (s *Schema) linkRels(a Resource, rel string, b Resource) (Rel, Rel, error) {
+	typ := s.GetType(a.GetType().Name)
+	if typ.Name == "" {
+		return Rel{}, Rel{}, &UnknownTypeError{Type: a.GetType().Name}
+	}
+
+	r, ok := typ.Rels[rel]
+	if !ok {
+		return Rel{}, Rel{}, &UnknownFieldError{Type: typ.Name, Field: rel, asRel: true}
+	}
+
+	if bType := b.GetType().Name; bType != r.ToType {
+		return Rel{}, Rel{}, fmt.Errorf(
+			"jsonapi: relationship %q of type %q cannot point to type %q",
+			rel, typ.Name, bType,
+		)
+	}
+
+	var inv Rel
+	if r.ToName != "" {
+		inv = s.GetType(r.ToType).Rels[r.ToName]
+	}
+
+	return r, inv, nil
+}
+
+// addRelID adds id to the relationship rel of res.
+func addRelID(res Resource, rel Rel, id string) {
+	if rel.ToOne {
+		res.Set(rel.FromName, id)
+		return
+	}
+
+	ids, _ := res.Get(rel.FromName).([]string)
+	for _, i := range ids {
+		if i == id {
+			return
+		}
+	}
+
+	res.Set(rel.FromName, append(append([]string{}, ids...), id))
+}
+
+// removeRelID removes id from the relationship rel of res.
+func removeRelID(res Resource, rel Rel, id string) {
+	if rel.ToOne {
+		if res.Get(rel.FromName) == id {
+			res.Set(rel.FromName, "")
+		}
+
+		return
+	}
+
+	ids, _ := res.Get(rel.FromName).([]string)
+	nids := make([]string, 0, len(ids))
+
+	for _, i := range ids {
+		if i != id {
+			nids = append(nids, i)
+		}
+	}
+
+	if len(nids) != len(ids) {
+		res.Set(rel.FromName, nids)
+	}
 }
 
 // buildRels builds the set of normalized relationships that is returned by
@@ -0,0 +1,74 @@
+package jsonapi
+
+// SchemaBuilder builds a Schema through a fluent API.
+//
+// It is meant for schemas defined in code, where an invalid type or field is a
+// programming error. Therefore, all methods panic as soon as an error occurs.
+// The error-returning methods of Schema and Type should be used for schemas
+// that are built dynamically.
+//
+//	schema := NewSchemaBuilder().
+//		Struct(User{}).
+//		Struct(Article{}).
+//		MustBuild()
+type SchemaBuilder struct {
+	schema *Schema
+}
+
+// NewSchemaBuilder returns a new *SchemaBuilder for an empty schema.
+func NewSchemaBuilder() *SchemaBuilder {
+	return &SchemaBuilder{schema: &Schema{}}
+}
+
+// Type adds the type to the schema.
+func (b *SchemaBuilder) Type(typ Type) *SchemaBuilder {
+	b.schema.MustAddType(typ)
+	return b
+}
+
+// Struct builds a type from v with BuildType and adds it to the schema.
+func (b *SchemaBuilder) Struct(v interface{}) *SchemaBuilder {
+	b.schema.MustAddType(MustBuildType(v))
+	return b
+}
+
+// Attr adds the attribute to the type named typ.
+func (b *SchemaBuilder) Attr(typ string, attr Attr) *SchemaBuilder {
+	b.schema.MustAddAttr(typ, attr)
+	return b
+}
+
+// Rel adds the relationship to the type named typ.
+func (b *SchemaBuilder) Rel(typ string, rel Rel) *SchemaBuilder {
+	b.schema.MustAddRel(typ, rel)
+	return b
+}
+
+// TwoWayRel adds the relationship and its inverse to both types involved.
+func (b *SchemaBuilder) TwoWayRel(rel Rel) *SchemaBuilder {
+	b.schema.MustAddTwoWayRel(rel)
+	return b
+}
+
+// Build resolves the inverse relationships with Schema.ResolveRels, checks the
+// schema with Schema.Check and returns it. The first error found by Check is
+// returned if the schema is not valid.
+func (b *SchemaBuilder) Build() (*Schema, error) {
+	b.schema.ResolveRels()
+
+	if errs := b.schema.Check(); len(errs) > 0 {
+		return nil, errs[0]
+	}
+
+	return b.schema, nil
+}
+
+// MustBuild calls Build and panics if an error is returned.
+func (b *SchemaBuilder) MustBuild() *Schema {
+	schema, err := b.Build()
+	if err != nil {
+		panic(err)
+	}
+
+	return schema
+}
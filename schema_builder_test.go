@@ -0,0 +1,63 @@
+package jsonapi_test
+
+import (
+	"testing"
+
+	. "github.com/mark-hartmann/jsonapi"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSchemaBuilder(t *testing.T) {
+	assert := assert.New(t)
+
+	schema, err := NewSchemaBuilder().
+		Struct(mockType1{}).
+		Struct(&mockType2{}).
+		Type(Type{Name: "type1"}).
+		Attr("type1", Attr{Name: "attr1", Type: AttrTypeString}).
+		Rel("type1", Rel{FromName: "rel1", ToType: "mocktypes1"}).
+		TwoWayRel(Rel{
+			FromType: "type1",
+			FromName: "rel2",
+			ToType:   "mocktypes2",
+			ToName:   "rel3",
+		}).
+		Build()
+	assert.NoError(err)
+	assert.True(schema.HasType("mocktypes1"))
+	assert.True(schema.HasType("mocktypes2"))
+	assert.Contains(schema.GetType("type1").Attrs, "attr1")
+	assert.Contains(schema.GetType("type1").Rels, "rel1")
+	assert.Contains(schema.GetType("mocktypes2").Rels, "rel3")
+
+	// Invalid schema (missing target type)
+	b := NewSchemaBuilder().
+		Type(Type{Name: "type1"}).
+		Rel("type1", Rel{FromName: "rel1", ToType: "type2"})
+
+	schema, err = b.Build()
+	assert.Nil(schema)
+	assert.EqualError(err, `jsonapi: field ToType of relationship "rel1" `+
+		`of type "type1" does not exist`)
+	assert.Panics(func() { b.MustBuild() })
+
+	// Programmer errors
+	assert.PanicsWithError(`jsonapi: type name "type1" is already used`, func() {
+		NewSchemaBuilder().
+			Type(Type{Name: "type1"}).
+			Type(Type{Name: "type1"})
+	})
+	assert.Panics(func() {
+		NewSchemaBuilder().Struct("not a struct")
+	})
+	assert.Panics(func() {
+		NewSchemaBuilder().Attr("type1", Attr{Name: "attr1", Type: AttrTypeString})
+	})
+	assert.Panics(func() {
+		NewSchemaBuilder().Rel("type1", Rel{FromName: "rel1", ToType: "type1"})
+	})
+	assert.Panics(func() {
+		NewSchemaBuilder().TwoWayRel(Rel{FromType: "type1", FromName: "rel1", ToType: "type2"})
+	})
+}
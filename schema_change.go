@@ -0,0 +1,72 @@
+package jsonapi
+
+// SchemaOp is the kind of modification described by a SchemaChange.
+type SchemaOp int
+
+// Schema modifications
+const (
+	OpAddType SchemaOp = iota + 1
+	OpRemoveType
+	OpAddAttr
+	OpRemoveAttr
+	OpAddRel
+	OpRemoveRel
+)
+
+// String returns a short description of the operation.
+func (o SchemaOp) String() string {
+	switch o {
+	case OpAddType:
+		return "add type"
+	case OpRemoveType:
+		return "remove type"
+	case OpAddAttr:
+		return "add attribute"
+	case OpRemoveAttr:
+		return "remove attribute"
+	case OpAddRel:
+		return "add relationship"
+	case OpRemoveRel:
+		return "remove relationship"
+	default:
+		return "unknown"
+	}
+}
+
+// A SchemaChange describes a modification that was successfully applied to a
+// Schema.
+type SchemaChange struct {
+	Op SchemaOp
+
+	// Type is the name of the type that was added, removed, or modified.
+	Type string
+
+	// Name is the name of the attribute or relationship that was added or
+	// removed. It is empty for OpAddType and OpRemoveType.
+	Name string
+}
+
+// OnChange registers a function that is called every time the schema is
+// modified through one of its methods (AddType, RemoveType, AddAttr,
+// RemoveAttr, AddRel, RemoveRel, AddTwoWayRel, and their Must variants).
+//
+// This allows a server updating its schema at runtime to invalidate caches or
+// check the schema again. Modifying the Types field directly does not trigger
+// any notification.
+//
+// The functions are called synchronously in the order they were registered,
+// after the change is applied.
+func (s *Schema) OnChange(fn func(SchemaChange)) {
+	s.listeners = append(s.listeners, fn)
+}
+
+// notify calls the registered listeners with the given change.
+func (s *Schema) notify(op SchemaOp, typ, name string) {
+	for _, fn := range s.listeners {
+		fn(SchemaChange{
+			Op:   op,
+			Type: typ,
+			Name: name,
+		})
+	}
+}
@@ -0,0 +1,74 @@
+package jsonapi_test
+
+import (
+	"testing"
+
+	. "github.com/mark-hartmann/jsonapi"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSchemaOnChange(t *testing.T) {
+	assert := assert.New(t)
+
+	schema := &Schema{}
+
+	var changes []SchemaChange
+
+	schema.OnChange(func(c SchemaChange) {
+		changes = append(changes, c)
+	})
+
+	calls := 0
+
+	schema.OnChange(func(SchemaChange) {
+		calls++
+	})
+
+	schema.MustAddType(Type{Name: "type1"})
+	schema.MustAddType(Type{Name: "type2"})
+	schema.MustAddAttr("type1", Attr{Name: "attr1", Type: AttrTypeString})
+	schema.MustAddRel("type1", Rel{FromName: "rel1", ToType: "type2"})
+	schema.MustAddTwoWayRel(Rel{
+		FromType: "type1",
+		FromName: "rel2",
+		ToType:   "type2",
+		ToName:   "rel3",
+	})
+	schema.RemoveAttr("type1", "attr1")
+	schema.RemoveRel("type1", "rel1")
+	schema.RemoveType("type2")
+
+	// Failed or ineffective modifications are not notified.
+	_ = schema.AddType(Type{Name: "type1"})
+	_ = schema.AddAttr("type3", Attr{Name: "attr1", Type: AttrTypeString})
+	_ = schema.AddRel("type1", Rel{FromName: "rel2", ToType: "type1"})
+	schema.RemoveAttr("type1", "attr1")
+	schema.RemoveRel("type1", "rel1")
+	schema.RemoveType("type2")
+
+	assert.Equal([]SchemaChange{
+		{Op: OpAddType, Type: "type1"},
+		{Op: OpAddType, Type: "type2"},
+		{Op: OpAddAttr, Type: "type1", Name: "attr1"},
+		{Op: OpAddRel, Type: "type1", Name: "rel1"},
+		{Op: OpAddRel, Type: "type1", Name: "rel2"},
+		{Op: OpAddRel, Type: "type2", Name: "rel3"},
+		{Op: OpRemoveAttr, Type: "type1", Name: "attr1"},
+		{Op: OpRemoveRel, Type: "type1", Name: "rel1"},
+		{Op: OpRemoveType, Type: "type2"},
+	}, changes)
+	assert.Equal(len(changes), calls)
+}
+
+func TestSchemaOpString(t *testing.T) {
+	assert := assert.New(t)
+
+	assert.Equal("add type", OpAddType.String())
+	assert.Equal("remove type", OpRemoveType.String())
+	assert.Equal("add attribute", OpAddAttr.String())
+	assert.Equal("remove attribute", OpRemoveAttr.String())
+	assert.Equal("add relationship", OpAddRel.String())
+	assert.Equal("remove relationship", OpRemoveRel.String())
+	assert.Equal("unknown", SchemaOp(0).String())
+}
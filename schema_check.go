@@ -0,0 +1,225 @@
+package jsonapi
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// A SchemaCheckError is an error found by Schema.Check.
+//
+// Type returns the name of the type where the problem was found and Field the
+// name of the attribute or relationship, if any. Each implementation has its own
+// fields with more details.
+type SchemaCheckError interface {
+	error
+	Type() string
+	Field() string
+}
+
+// DuplicateTypeError is returned if two types of a schema have the same name.
+type DuplicateTypeError struct {
+	TypeName string
+}
+
+// Error returns the error message.
+func (e *DuplicateTypeError) Error() string {
+	return fmt.Sprintf("jsonapi: type name %q is already used", e.TypeName)
+}
+
+// Type returns the name of the type.
+func (e *DuplicateTypeError) Type() string { return e.TypeName }
+
+// Field returns an empty string.
+func (e *DuplicateTypeError) Field() string { return "" }
+
+// InvalidTypeNameError is returned if the name of a type does not meet the
+// member name requirements.
+type InvalidTypeNameError struct {
+	TypeName string
+}
+
+// Error returns the error message.
+func (e *InvalidTypeNameError) Error() string {
+	return fmt.Sprintf("jsonapi: name of type %q is invalid", e.TypeName)
+}
+
+// Type returns the name of the type.
+func (e *InvalidTypeNameError) Type() string { return e.TypeName }
+
+// Field returns an empty string.
+func (e *InvalidTypeNameError) Field() string { return "" }
+
+// InvalidAttrNameError is returned if the name of an attribute does not meet
+// the member name requirements or is a reserved name like id or type.
+type InvalidAttrNameError struct {
+	TypeName  string
+	FieldName string
+}
+
+// Error returns the error message.
+func (e *InvalidAttrNameError) Error() string {
+	return fmt.Sprintf("jsonapi: name of attribute %q of type %q is invalid",
+		e.FieldName, e.TypeName)
+}
+
+// Type returns the name of the type.
+func (e *InvalidAttrNameError) Type() string { return e.TypeName }
+
+// Field returns the name of the attribute.
+func (e *InvalidAttrNameError) Field() string { return e.FieldName }
+
+// FieldNameConflictError is returned if a type has an attribute and a
+// relationship of the same name.
+type FieldNameConflictError struct {
+	TypeName  string
+	FieldName string
+}
+
+// Error returns the error message.
+func (e *FieldNameConflictError) Error() string {
+	return fmt.Sprintf("jsonapi: type %q can not have an attribute "+
+		"and relationship with the same name %q", e.TypeName, e.FieldName)
+}
+
+// Type returns the name of the type.
+func (e *FieldNameConflictError) Type() string { return e.TypeName }
+
+// Field returns the name of the attribute and relationship.
+func (e *FieldNameConflictError) Field() string { return e.FieldName }
+
+// UnknownRelTypeError is returned if the target type of a relationship does not
+// exist.
+type UnknownRelTypeError struct {
+	TypeName  string
+	FieldName string
+	ToType    string
+}
+
+// Error returns the error message.
+func (e *UnknownRelTypeError) Error() string {
+	return fmt.Sprintf("jsonapi: field ToType of relationship %q of type %q does not exist",
+		e.FieldName, e.TypeName)
+}
+
+// Type returns the name of the type.
+func (e *UnknownRelTypeError) Type() string { return e.TypeName }
+
+// Field returns the name of the relationship.
+func (e *UnknownRelTypeError) Field() string { return e.FieldName }
+
+// InvalidRelFromTypeError is returned if the FromType field of a relationship
+// with an inverse is not the name of its type.
+type InvalidRelFromTypeError struct {
+	TypeName  string
+	FieldName string
+	FromType  string
+}
+
+// Error returns the error message.
+func (e *InvalidRelFromTypeError) Error() string {
+	return fmt.Sprintf(
+		"jsonapi: field FromType of relationship %q must be its type's name (%q, not %q)",
+		e.FieldName, e.TypeName, e.FromType,
+	)
+}
+
+// Type returns the name of the type.
+func (e *InvalidRelFromTypeError) Type() string { return e.TypeName }
+
+// Field returns the name of the relationship.
+func (e *InvalidRelFromTypeError) Field() string { return e.FieldName }
+
+// MissingInverseRelError is returned if the inverse of a relationship does not
+// exist or does not point back to the relationship.
+type MissingInverseRelError struct {
+	TypeName  string
+	FieldName string
+	ToType    string
+	ToName    string
+}
+
+// Error returns the error message.
+func (e *MissingInverseRelError) Error() string {
+	return fmt.Sprintf(
+		"jsonapi: relationship %q of type %q and its inverse do not point each other",
+		e.FieldName, e.TypeName,
+	)
+}
+
+// Type returns the name of the type.
+func (e *MissingInverseRelError) Type() string { return e.TypeName }
+
+// Field returns the name of the relationship.
+func (e *MissingInverseRelError) Field() string { return e.FieldName }
+
+// InverseCardinalityError is returned if a relationship and its inverse do not
+// agree on the cardinalities.
+type InverseCardinalityError struct {
+	TypeName  string
+	FieldName string
+}
+
+// Error returns the error message.
+func (e *InverseCardinalityError) Error() string {
+	return fmt.Sprintf(
+		"jsonapi: cardinality of relationship %q of type %q does not match its inverse",
+		e.FieldName, e.TypeName,
+	)
+}
+
+// Type returns the name of the type.
+func (e *InverseCardinalityError) Type() string { return e.TypeName }
+
+// Field returns the name of the relationship.
+func (e *InverseCardinalityError) Field() string { return e.FieldName }
+
+// CheckError holds all the errors found by Schema.Check. It is returned by
+// Schema.Validate.
+type CheckError struct {
+	Errs []SchemaCheckError
+}
+
+// Error returns the messages of all the errors separated by semicolons.
+func (e *CheckError) Error() string {
+	msgs := make([]string, 0, len(e.Errs))
+	for _, err := range e.Errs {
+		msgs = append(msgs, strings.TrimPrefix(err.Error(), "jsonapi: "))
+	}
+
+	return "jsonapi: invalid schema: " + strings.Join(msgs, "; ")
+}
+
+// Unwrap returns the errors.
+func (e *CheckError) Unwrap() []error {
+	errs := make([]error, 0, len(e.Errs))
+	for _, err := range e.Errs {
+		errs = append(errs, err)
+	}
+
+	return errs
+}
+
+// sortedAttrNames returns the keys of attrs in alphabetical order.
+func sortedAttrNames(attrs map[string]Attr) []string {
+	names := make([]string, 0, len(attrs))
+	for name := range attrs {
+		names = append(names, name)
+	}
+
+	sort.Strings(names)
+
+	return names
+}
+
+// sortedRelNames returns the keys of rels in alphabetical order.
+func sortedRelNames(rels map[string]Rel) []string {
+	names := make([]string, 0, len(rels))
+	for name := range rels {
+		names = append(names, name)
+	}
+
+	sort.Strings(names)
+
+	return names
+}
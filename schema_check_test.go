@@ -0,0 +1,98 @@
+package jsonapi_test
+
+import (
+	"errors"
+	"testing"
+
+	. "github.com/mark-hartmann/jsonapi"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSchemaCheckErrors(t *testing.T) {
+	assert := assert.New(t)
+
+	schema := &Schema{}
+	schema.MustAddType(Type{
+		Name: "type1",
+		Attrs: map[string]Attr{
+			"type": {Name: "type", Type: AttrTypeString},
+			"same": {Name: "same", Type: AttrTypeString},
+		},
+		Rels: map[string]Rel{
+			"same":    {FromType: "type1", FromName: "same", ToType: "type2"},
+			"unknown": {FromType: "type1", FromName: "unknown", ToType: "type3"},
+			"to-one": {
+				FromType: "type1", FromName: "to-one", ToOne: true,
+				ToType: "type2", ToName: "to-many",
+			},
+		},
+	})
+	schema.MustAddType(Type{
+		Name: "type2",
+		Rels: map[string]Rel{
+			"to-many": {
+				FromType: "type2", FromName: "to-many",
+				ToType: "type1", ToName: "to-one",
+			},
+			"inverse": {
+				FromType: "type2", FromName: "inverse",
+				ToType: "type1", ToName: "nothing",
+			},
+			"from": {
+				FromType: "type1", FromName: "from",
+				ToType: "type1", ToName: "to-one",
+			},
+		},
+	})
+
+	// AddType sets FromOne from the inverse relationships, but Rels can be
+	// modified directly to make the cardinalities disagree.
+	rel := schema.Types[0].Rels["to-one"]
+	rel.FromOne = true
+	schema.Types[0].Rels["to-one"] = rel
+
+	// AddType refuses duplicates, but Types can be modified directly.
+	err := schema.AddType(Type{Name: "type2"})
+	assert.Equal(&DuplicateTypeError{TypeName: "type2"}, err)
+
+	schema.Types = append(schema.Types, Type{Name: "type2"})
+
+	errs := schema.Check()
+	assert.Equal([]error{
+		&FieldNameConflictError{TypeName: "type1", FieldName: "same"},
+		&InverseCardinalityError{TypeName: "type1", FieldName: "to-one"},
+		&InvalidAttrNameError{TypeName: "type1", FieldName: "type"},
+		&UnknownRelTypeError{TypeName: "type1", FieldName: "unknown", ToType: "type3"},
+		&DuplicateTypeError{TypeName: "type2"},
+		&InvalidRelFromTypeError{TypeName: "type2", FieldName: "from", FromType: "type1"},
+		&MissingInverseRelError{
+			TypeName: "type2", FieldName: "inverse", ToType: "type1", ToName: "nothing",
+		},
+		&InverseCardinalityError{TypeName: "type2", FieldName: "to-many"},
+	}, errs)
+
+	for _, err := range errs {
+		var ce SchemaCheckError
+
+		assert.True(errors.As(err, &ce))
+		assert.NotEmpty(ce.Type())
+	}
+
+	// Validate
+	err = schema.Validate()
+
+	var ce *CheckError
+
+	assert.True(errors.As(err, &ce))
+	assert.Len(ce.Errs, 8)
+	assert.Equal(errs, ce.Unwrap())
+	assert.Contains(err.Error(), "jsonapi: invalid schema: type \"type1\" can not have "+
+		"an attribute and relationship with the same name \"same\"; cardinality of")
+
+	// A valid schema
+	schema = &Schema{}
+	schema.MustAddType(Type{Name: "type1"})
+	assert.NoError(schema.Validate())
+	assert.Empty(schema.Check())
+}
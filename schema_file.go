@@ -0,0 +1,312 @@
+package jsonapi
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"sync/atomic"
+)
+
+// A SchemaDefinition is the declarative form of a Schema, meant to be stored
+// in a file outside of the Go code. ReadSchema and WriteSchema read and write
+// it as JSON, and it can be decoded from other formats like YAML with the
+// appropriate package before Schema is called.
+//
+// Only what can be declared is kept: the names of the attribute types (see
+// RegisterAttrType), the relationships, the default fields, the field groups
+// and the documentation. The functions of the types and attributes, like
+// NewFunc, Unmarshaler or Transformer, and the NameMapper of the schema are
+// not part of it.
+type SchemaDefinition struct {
+	Types []TypeDefinition `json:"types" yaml:"types"`
+}
+
+// A TypeDefinition is the declarative form of a Type.
+type TypeDefinition struct {
+	Name          string           `json:"name" yaml:"name"`
+	Attrs         []AttrDefinition `json:"attrs,omitempty" yaml:"attrs,omitempty"`
+	Rels          []RelDefinition  `json:"rels,omitempty" yaml:"rels,omitempty"`
+	DefaultFields []string         `json:"defaultFields,omitempty" yaml:"defaultFields,omitempty"`
+	Description   string           `json:"description,omitempty" yaml:"description,omitempty"`
+	Example       string           `json:"example,omitempty" yaml:"example,omitempty"`
+	Deprecated    bool             `json:"deprecated,omitempty" yaml:"deprecated,omitempty"`
+
+	// FieldGroups maps the names of the field groups to their fields (see
+	// Schema.SetFieldGroup).
+	FieldGroups map[string][]string `json:"fieldGroups,omitempty" yaml:"fieldGroups,omitempty"`
+}
+
+// An AttrDefinition is the declarative form of an Attr. Type is the name under
+// which the attribute type is registered, like "string" or "time".
+type AttrDefinition struct {
+	Name        string `json:"name" yaml:"name"`
+	Type        string `json:"type" yaml:"type"`
+	Array       bool   `json:"array,omitempty" yaml:"array,omitempty"`
+	Nullable    bool   `json:"nullable,omitempty" yaml:"nullable,omitempty"`
+	Format      string `json:"format,omitempty" yaml:"format,omitempty"`
+	ReadOnly    bool   `json:"readOnly,omitempty" yaml:"readOnly,omitempty"`
+	OmitEmpty   bool   `json:"omitEmpty,omitempty" yaml:"omitEmpty,omitempty"`
+	Description string `json:"description,omitempty" yaml:"description,omitempty"`
+	Example     string `json:"example,omitempty" yaml:"example,omitempty"`
+	Deprecated  bool   `json:"deprecated,omitempty" yaml:"deprecated,omitempty"`
+}
+
+// A RelDefinition is the declarative form of a Rel. The cardinality of the
+// inverse relationship is not declared, it is resolved from the definition of
+// the inverse (see Schema.ResolveRels).
+type RelDefinition struct {
+	Name        string `json:"name" yaml:"name"`
+	ToType      string `json:"toType" yaml:"toType"`
+	ToOne       bool   `json:"toOne,omitempty" yaml:"toOne,omitempty"`
+	ToName      string `json:"toName,omitempty" yaml:"toName,omitempty"`
+	Description string `json:"description,omitempty" yaml:"description,omitempty"`
+	Example     string `json:"example,omitempty" yaml:"example,omitempty"`
+	Deprecated  bool   `json:"deprecated,omitempty" yaml:"deprecated,omitempty"`
+}
+
+// Definition returns the definition of the schema. The types are kept in
+// order and their fields are sorted by name.
+//
+// An error is returned if an attribute type is not registered, like the ones
+// that only have an Unmarshaler.
+func (s *Schema) Definition() (SchemaDefinition, error) {
+	def := SchemaDefinition{Types: make([]TypeDefinition, 0, len(s.Types))}
+
+	for _, typ := range s.Types {
+		td := TypeDefinition{
+			Name:        typ.Name,
+			Description: typ.Description,
+			Example:     typ.Example,
+			Deprecated:  typ.Deprecated,
+		}
+
+		for _, name := range sortedAttrNames(typ.Attrs) {
+			attr := typ.Attrs[name]
+
+			typeName, ok := registry.names[attr.Type]
+			if !ok {
+				return SchemaDefinition{}, fmt.Errorf(
+					"jsonapi: attribute %q of type %q has an unregistered type", name, typ.Name)
+			}
+
+			td.Attrs = append(td.Attrs, AttrDefinition{
+				Name:        attr.Name,
+				Type:        typeName,
+				Array:       attr.Array,
+				Nullable:    attr.Nullable,
+				Format:      attr.Format,
+				ReadOnly:    attr.ReadOnly,
+				OmitEmpty:   attr.OmitEmpty,
+				Description: attr.Description,
+				Example:     attr.Example,
+				Deprecated:  attr.Deprecated,
+			})
+		}
+
+		for _, name := range sortedRelNames(typ.Rels) {
+			rel := typ.Rels[name]
+
+			td.Rels = append(td.Rels, RelDefinition{
+				Name:        rel.FromName,
+				ToType:      rel.ToType,
+				ToOne:       rel.ToOne,
+				ToName:      rel.ToName,
+				Description: rel.Description,
+				Example:     rel.Example,
+				Deprecated:  rel.Deprecated,
+			})
+		}
+
+		td.DefaultFields, _ = s.DefaultFields(typ.Name)
+
+		for _, group := range s.FieldGroups(typ.Name) {
+			if td.FieldGroups == nil {
+				td.FieldGroups = map[string][]string{}
+			}
+
+			td.FieldGroups[group], _ = s.FieldGroup(typ.Name, group)
+		}
+
+		def.Types = append(def.Types, td)
+	}
+
+	return def, nil
+}
+
+// Schema builds a new schema from the definition. The inverse relationships
+// are resolved and the schema is checked with Schema.Validate, so a
+// relationship that points to an unknown type or an inconsistent inverse
+// relationship is reported as a *CheckError.
+func (d SchemaDefinition) Schema() (*Schema, error) {
+	schema := &Schema{}
+
+	for _, td := range d.Types {
+		typ, err := td.typ()
+		if err != nil {
+			return nil, err
+		}
+
+		if err := schema.AddType(typ); err != nil {
+			return nil, err
+		}
+	}
+
+	schema.ResolveRels()
+
+	if err := schema.Validate(); err != nil {
+		return nil, err
+	}
+
+	for _, td := range d.Types {
+		if len(td.DefaultFields) > 0 {
+			if err := schema.SetDefaultFields(td.Name, td.DefaultFields...); err != nil {
+				return nil, err
+			}
+		}
+
+		groups := make([]string, 0, len(td.FieldGroups))
+		for name := range td.FieldGroups {
+			groups = append(groups, name)
+		}
+
+		sort.Strings(groups)
+
+		for _, name := range groups {
+			if err := schema.SetFieldGroup(td.Name, name, td.FieldGroups[name]...); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	return schema, nil
+}
+
+// typ returns the type defined by td.
+func (td TypeDefinition) typ() (Type, error) {
+	typ := Type{
+		Name:        td.Name,
+		Description: td.Description,
+		Example:     td.Example,
+		Deprecated:  td.Deprecated,
+	}
+
+	for _, ad := range td.Attrs {
+		attrType, ok := attrTypeByName(ad.Type)
+		if !ok {
+			return Type{}, fmt.Errorf("jsonapi: attribute %q of type %q has unknown type %q",
+				ad.Name, td.Name, ad.Type)
+		}
+
+		err := typ.AddAttr(Attr{
+			Name:        ad.Name,
+			Type:        attrType,
+			Array:       ad.Array,
+			Nullable:    ad.Nullable,
+			Format:      ad.Format,
+			ReadOnly:    ad.ReadOnly,
+			OmitEmpty:   ad.OmitEmpty,
+			Description: ad.Description,
+			Example:     ad.Example,
+			Deprecated:  ad.Deprecated,
+		})
+		if err != nil {
+			return Type{}, err
+		}
+	}
+
+	for _, rd := range td.Rels {
+		err := typ.AddRel(Rel{
+			FromType:    td.Name,
+			FromName:    rd.Name,
+			ToType:      rd.ToType,
+			ToOne:       rd.ToOne,
+			ToName:      rd.ToName,
+			Description: rd.Description,
+			Example:     rd.Example,
+			Deprecated:  rd.Deprecated,
+		})
+		if err != nil {
+			return Type{}, err
+		}
+	}
+
+	return typ, nil
+}
+
+// ReadSchema reads a SchemaDefinition encoded in JSON from r and returns the
+// schema it defines (see SchemaDefinition.Schema).
+func ReadSchema(r io.Reader) (*Schema, error) {
+	var def SchemaDefinition
+
+	dec := json.NewDecoder(r)
+	dec.DisallowUnknownFields()
+
+	if err := dec.Decode(&def); err != nil {
+		return nil, fmt.Errorf("jsonapi: failed to read schema definition: %w", err)
+	}
+
+	return def.Schema()
+}
+
+// WriteSchema writes the definition of schema to w in JSON (see
+// Schema.Definition).
+func WriteSchema(w io.Writer, schema *Schema) error {
+	def, err := schema.Definition()
+	if err != nil {
+		return err
+	}
+
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+
+	return enc.Encode(def)
+}
+
+// A SchemaFile holds the schema defined by a file and reloads it on demand,
+// so that the schema of a running server can be changed without rebuilding
+// it.
+//
+// Schema can be called concurrently with Reload. A schema that was returned
+// before a reload is never modified.
+type SchemaFile struct {
+	path   string
+	schema atomic.Value
+}
+
+// OpenSchemaFile reads the schema defined in the JSON file at path (see
+// ReadSchema) and returns a *SchemaFile holding it.
+func OpenSchemaFile(path string) (*SchemaFile, error) {
+	f := &SchemaFile{path: path}
+	if err := f.Reload(); err != nil {
+		return nil, err
+	}
+
+	return f, nil
+}
+
+// Schema returns the last schema that was successfully loaded.
+func (f *SchemaFile) Schema() *Schema {
+	return f.schema.Load().(*Schema)
+}
+
+// Reload reads the file again and replaces the schema. The current schema is
+// kept if the file cannot be read or if it defines an invalid schema.
+func (f *SchemaFile) Reload() error {
+	file, err := os.Open(f.path)
+	if err != nil {
+		return fmt.Errorf("jsonapi: failed to open schema file: %w", err)
+	}
+
+	defer func() { _ = file.Close() }()
+
+	schema, err := ReadSchema(file)
+	if err != nil {
+		return err
+	}
+
+	f.schema.Store(schema)
+
+	return nil
+}
@@ -0,0 +1,214 @@
+package jsonapi_test
+
+import (
+	"bytes"
+	"errors"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	. "github.com/mark-hartmann/jsonapi"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const schemaDefinition = `{
+  "types": [
+    {
+      "name": "articles",
+      "attrs": [
+        {
+          "name": "tags",
+          "type": "string",
+          "array": true
+        },
+        {
+          "name": "title",
+          "type": "string",
+          "description": "The title."
+        },
+        {
+          "name": "updated",
+          "type": "time",
+          "nullable": true,
+          "readOnly": true
+        }
+      ],
+      "rels": [
+        {
+          "name": "author",
+          "toType": "people",
+          "toOne": true,
+          "toName": "articles"
+        }
+      ],
+      "defaultFields": [
+        "title"
+      ],
+      "fieldGroups": {
+        "summary": [
+          "author",
+          "title"
+        ]
+      }
+    },
+    {
+      "name": "people",
+      "attrs": [
+        {
+          "name": "name",
+          "type": "string"
+        }
+      ],
+      "rels": [
+        {
+          "name": "articles",
+          "toType": "articles",
+          "toName": "author"
+        }
+      ],
+      "deprecated": true
+    }
+  ]
+}
+`
+
+func TestReadWriteSchema(t *testing.T) {
+	assert := assert.New(t)
+
+	schema, err := ReadSchema(strings.NewReader(schemaDefinition))
+	assert.NoError(err)
+
+	articles := schema.GetType("articles")
+	assert.Equal(Attr{Name: "tags", Type: AttrTypeString, Array: true}, articles.Attrs["tags"])
+	assert.Equal("The title.", articles.Attrs["title"].Description)
+	assert.Equal(Attr{
+		Name:     "updated",
+		Type:     AttrTypeTime,
+		Nullable: true,
+		ReadOnly: true,
+	}, articles.Attrs["updated"])
+	assert.Equal(Rel{
+		FromType: "articles",
+		FromName: "author",
+		ToOne:    true,
+		ToType:   "people",
+		ToName:   "articles",
+	}, articles.Rels["author"])
+	assert.True(schema.GetType("people").Deprecated)
+
+	// The inverse relationships are resolved.
+	assert.True(schema.GetType("people").Rels["articles"].FromOne)
+
+	fields, ok := schema.DefaultFields("articles")
+	assert.True(ok)
+	assert.Equal([]string{"title"}, fields)
+
+	fields, ok = schema.FieldGroup("articles", "summary")
+	assert.True(ok)
+	assert.Equal([]string{"author", "title"}, fields)
+
+	// Round trip
+	buf := &bytes.Buffer{}
+	assert.NoError(WriteSchema(buf, schema))
+	assert.Equal(schemaDefinition, buf.String())
+
+	// Unregistered attribute type
+	typ := Type{Name: "things"}
+	typ.MustAddAttr(Attr{
+		Name:        "custom",
+		Type:        1000,
+		Unmarshaler: ReflectTypeUnmarshaler{},
+	})
+
+	_, err = (&Schema{Types: []Type{typ}}).Definition()
+	assert.EqualError(err, `jsonapi: attribute "custom" of type "things" has an unregistered type`)
+}
+
+func TestReadSchemaInvalid(t *testing.T) {
+	assert := assert.New(t)
+
+	tests := []struct {
+		name string
+		def  string
+		err  string
+	}{
+		{
+			name: "invalid json",
+			def:  `{"types":`,
+			err:  "jsonapi: failed to read schema definition: unexpected EOF",
+		}, {
+			name: "unknown member",
+			def:  `{"types":[{"name":"articles","fields":[]}]}`,
+			err: "jsonapi: failed to read schema definition: " +
+				`json: unknown field "fields"`,
+		}, {
+			name: "unknown attribute type",
+			def:  `{"types":[{"name":"articles","attrs":[{"name":"title","type":"text"}]}]}`,
+			err:  `jsonapi: attribute "title" of type "articles" has unknown type "text"`,
+		}, {
+			name: "duplicate type",
+			def:  `{"types":[{"name":"articles"},{"name":"articles"}]}`,
+			err:  `jsonapi: type name "articles" is already used`,
+		}, {
+			name: "unknown field in group",
+			def: `{"types":[{"name":"articles","attrs":[{"name":"title","type":"string"}],` +
+				`"fieldGroups":{"summary":["body"]}}]}`,
+			err: `jsonapi: field "body" does not exist in resource type "articles"`,
+		},
+	}
+
+	for _, test := range tests {
+		_, err := ReadSchema(strings.NewReader(test.def))
+		assert.EqualError(err, test.err, test.name)
+	}
+
+	// The schema is checked.
+	_, err := ReadSchema(strings.NewReader(
+		`{"types":[{"name":"articles","rels":[{"name":"author","toType":"people"}]}]}`,
+	))
+
+	var ce *CheckError
+
+	assert.True(errors.As(err, &ce))
+}
+
+func TestSchemaFile(t *testing.T) {
+	assert := assert.New(t)
+
+	path := filepath.Join(t.TempDir(), "schema.json")
+
+	_, err := OpenSchemaFile(path)
+	assert.Error(err)
+
+	assert.NoError(ioutil.WriteFile(path, []byte(schemaDefinition), 0o600))
+
+	f, err := OpenSchemaFile(path)
+	assert.NoError(err)
+
+	schema := f.Schema()
+	assert.True(schema.HasType("articles"))
+	assert.False(schema.HasType("comments"))
+
+	// Reload
+	def, err := schema.Definition()
+	assert.NoError(err)
+
+	def.Types = append(def.Types, TypeDefinition{Name: "comments"})
+
+	buf := &bytes.Buffer{}
+	s2, err := def.Schema()
+	assert.NoError(err)
+	assert.NoError(WriteSchema(buf, s2))
+	assert.NoError(ioutil.WriteFile(path, buf.Bytes(), 0o600))
+
+	assert.NoError(f.Reload())
+	assert.True(f.Schema().HasType("comments"))
+	assert.False(schema.HasType("comments"))
+
+	// An invalid file does not replace the schema.
+	assert.NoError(ioutil.WriteFile(path, []byte(`{"types":[{"name":""}]}`), 0o600))
+	assert.Error(f.Reload())
+	assert.True(f.Schema().HasType("comments"))
+}
@@ -1,6 +1,7 @@
 package jsonapi_test
 
 import (
+	"bytes"
 	"testing"
 
 	. "github.com/mark-hartmann/jsonapi"
@@ -95,6 +96,31 @@ func TestSchemaTypes(t *testing.T) {
 	assert.Error(err)
 }
 
+func TestSchemaAddTwoWayRelSelf(t *testing.T) {
+	assert := assert.New(t)
+
+	schema := newPeopleSchema()
+	typ := schema.GetType("people")
+
+	assert.Equal([]string{"friends", "manager", "reports"}, typ.Fields())
+	assert.Equal(Rel{
+		FromType: "people",
+		FromName: "manager",
+		ToOne:    true,
+		ToType:   "people",
+		ToName:   "reports",
+	}, typ.Rels["manager"])
+
+	reports := typ.Rels["reports"]
+	assert.Equal(typ.Rels["manager"], reports.Invert())
+	assert.Equal(Rel{
+		FromType: "people",
+		FromName: "friends",
+		ToType:   "people",
+		ToName:   "friends",
+	}, typ.Rels["friends"])
+}
+
 func TestSchemaAddTwoWayRel(t *testing.T) {
 	assert := assert.New(t)
 
@@ -133,6 +159,19 @@ func TestSchemaAddTwoWayRel(t *testing.T) {
 	})
 	assert.EqualError(err, `jsonapi: illegal relationship name "type"`)
 
+	// Add two-way relationship (inverted by normalization)
+	err = schema.AddTwoWayRel(Rel{
+		FromType: "type2",
+		FromName: "children3",
+		ToOne:    false,
+		ToType:   "type1",
+		ToName:   "parent3",
+		FromOne:  true,
+	})
+	assert.NoError(err)
+	assert.Contains(schema.GetType("type1").Rels, "parent3")
+	assert.Contains(schema.GetType("type2").Rels, "children3")
+
 	// Add two-way relationship (missing type)
 	schema = &Schema{}
 	_ = schema.AddType(Type{Name: "type1"})
@@ -260,6 +299,152 @@ func TestSchemaCheck(t *testing.T) {
 	)
 }
 
+func TestSchemaCheckCardinality(t *testing.T) {
+	assert := assert.New(t)
+
+	schema := &Schema{}
+	schema.MustAddType(Type{Name: "type1"})
+	schema.MustAddType(Type{Name: "type2"})
+	schema.MustAddRel("type1", Rel{
+		FromType: "type1",
+		FromName: "rel1",
+		ToOne:    true,
+		ToType:   "type2",
+		ToName:   "rel2",
+		FromOne:  true,
+	})
+	schema.MustAddRel("type2", Rel{
+		FromType: "type2",
+		FromName: "rel2",
+		ToOne:    false,
+		ToType:   "type1",
+		ToName:   "rel1",
+		FromOne:  true,
+	})
+
+	errs := schema.Check()
+	assert.Len(errs, 2)
+
+	errsStr := []string{}
+	for _, err := range errs {
+		errsStr = append(errsStr, err.Error())
+	}
+
+	assert.Contains(
+		errsStr,
+		"jsonapi: cardinality of relationship \"rel1\" of type \"type1\" does not match its inverse",
+	)
+	assert.Contains(
+		errsStr,
+		"jsonapi: cardinality of relationship \"rel2\" of type \"type2\" does not match its inverse",
+	)
+
+	// The inverse relationship points to another type
+	schema = &Schema{}
+	schema.MustAddType(Type{Name: "type1"})
+	schema.MustAddType(Type{Name: "type2"})
+	schema.MustAddType(Type{Name: "type3"})
+	schema.MustAddRel("type1", Rel{
+		FromType: "type1",
+		FromName: "rel1",
+		ToType:   "type2",
+		ToName:   "rel2",
+	})
+	schema.MustAddRel("type2", Rel{
+		FromType: "type2",
+		FromName: "rel2",
+		ToType:   "type3",
+		ToName:   "rel1",
+	})
+	schema.MustAddRel("type3", Rel{
+		FromType: "type3",
+		FromName: "rel1",
+		ToType:   "type2",
+		ToName:   "rel2",
+	})
+
+	errs = schema.Check()
+	assert.Len(errs, 1)
+	assert.EqualError(
+		errs[0],
+		"jsonapi: relationship \"rel1\" of type \"type1\" and its inverse do not point each other",
+	)
+}
+
+func TestSchemaResolveRels(t *testing.T) {
+	assert := assert.New(t)
+
+	// AddType resolves the cardinalities of the inverses.
+	schema := &Schema{}
+	schema.MustAddType(MustBuildType(mockType1{}))
+	schema.MustAddType(MustBuildType(mockType2{}))
+	schema.MustAddType(MustBuildType(mockType3{}))
+	assert.Empty(schema.Check())
+
+	// The types added before their inverses are modified.
+	assert.True(schema.GetType("mocktypes1").Rels["to-many-from-one"].FromOne)
+
+	// AddRel also modifies the inverse when it adds a to-one relationship.
+	schema = &Schema{}
+	schema.MustAddType(Type{Name: "type1"})
+	schema.MustAddType(Type{Name: "type2"})
+	schema.MustAddRel("type1", Rel{
+		FromType: "type1",
+		FromName: "rel1",
+		ToType:   "type2",
+		ToName:   "rel2",
+	})
+	schema.MustAddRel("type2", Rel{
+		FromType: "type2",
+		FromName: "rel2",
+		ToType:   "type1",
+		ToName:   "rel1",
+		ToOne:    true,
+	})
+	assert.True(schema.GetType("type1").Rels["rel1"].FromOne)
+	assert.Empty(schema.Check())
+
+	// Types built from structs do not know the cardinality of the inverses.
+	schema = &Schema{Types: []Type{
+		MustBuildType(mockType1{}),
+		MustBuildType(mockType2{}),
+		MustBuildType(mockType3{}),
+	}}
+	assert.NotEmpty(schema.Check())
+
+	schema.ResolveRels()
+	assert.Empty(schema.Check())
+
+	rels := schema.GetType("mocktypes1").Rels
+	assert.False(rels["to-one"].FromOne)
+	assert.True(rels["to-one-from-one"].FromOne)
+	assert.False(rels["to-one-from-many"].FromOne)
+	assert.False(rels["to-many"].FromOne)
+	assert.True(rels["to-many-from-one"].FromOne)
+	assert.False(rels["to-many-from-many"].FromOne)
+
+	rels = schema.GetType("mocktypes2").Rels
+	assert.True(rels["to-one-from-one"].FromOne)
+	assert.False(rels["to-one-from-many"].FromOne)
+	assert.True(rels["to-many-from-one"].FromOne)
+	assert.False(rels["to-many-from-many"].FromOne)
+
+	// Relationships without a matching inverse are left untouched.
+	schema = &Schema{}
+	schema.MustAddType(Type{Name: "type1"})
+	schema.MustAddType(Type{Name: "type2"})
+	schema.MustAddRel("type1", Rel{
+		FromType: "type1",
+		FromName: "rel1",
+		ToType:   "type2",
+		ToName:   "rel2",
+		FromOne:  true,
+	})
+
+	schema.ResolveRels()
+	assert.True(schema.GetType("type1").Rels["rel1"].FromOne)
+}
+
 func TestSchemaRels(t *testing.T) {
 	assert := assert.New(t)
 
@@ -308,3 +493,285 @@ func TestSchemaRels(t *testing.T) {
 	assert.Equal(messages.Rels["author"], rels[0])
 	assert.Equal(users.Rels["favorites"], rels[1])
 }
+
+func TestSchemaMustAdd(t *testing.T) {
+	assert := assert.New(t)
+
+	schema := &Schema{}
+
+	assert.NotPanics(func() {
+		schema.MustAddType(Type{Name: "type1"})
+		schema.MustAddType(Type{Name: "type2"})
+		schema.MustAddAttr("type1", Attr{Name: "attr1", Type: AttrTypeString})
+		schema.MustAddRel("type1", Rel{FromName: "rel1", ToType: "type1"})
+		schema.MustAddTwoWayRel(Rel{
+			FromType: "type1",
+			FromName: "rel2",
+			ToType:   "type2",
+			ToName:   "rel3",
+		})
+	})
+
+	assert.Contains(schema.GetType("type1").Attrs, "attr1")
+	assert.Contains(schema.GetType("type1").Rels, "rel1")
+	assert.Contains(schema.GetType("type2").Rels, "rel3")
+
+	assert.PanicsWithError(`jsonapi: type name "type1" is already used`, func() {
+		schema.MustAddType(Type{Name: "type1"})
+	})
+	assert.Panics(func() {
+		schema.MustAddAttr("type3", Attr{Name: "attr1", Type: AttrTypeString})
+	})
+	assert.Panics(func() {
+		schema.MustAddRel("type1", Rel{FromName: "rel1", ToType: "type1"})
+	})
+	assert.Panics(func() {
+		schema.MustAddTwoWayRel(Rel{FromType: "type1", FromName: "rel4", ToType: "type3"})
+	})
+}
+
+func TestSchemaDefaultFields(t *testing.T) {
+	assert := assert.New(t)
+
+	schema := &Schema{}
+	schema.MustAddType(Type{Name: "type1"})
+	schema.MustAddAttr("type1", Attr{Name: "attr1", Type: AttrTypeString})
+	schema.MustAddAttr("type1", Attr{Name: "attr2", Type: AttrTypeString})
+	schema.MustAddRel("type1", Rel{FromName: "rel1", ToType: "type1"})
+
+	_, ok := schema.DefaultFields("type1")
+	assert.False(ok)
+
+	assert.NoError(schema.SetDefaultFields("type1", "rel1", "attr1", "attr1"))
+
+	fields, ok := schema.DefaultFields("type1")
+	assert.True(ok)
+	assert.Equal([]string{"attr1", "rel1"}, fields)
+
+	// Unknown type or field
+	assert.EqualError(
+		schema.SetDefaultFields("type2", "attr1"),
+		`jsonapi: resource type "type2" does not exist`,
+	)
+	assert.EqualError(
+		schema.SetDefaultFields("type1", "attr3"),
+		`jsonapi: field "attr3" does not exist in resource type "type1"`,
+	)
+
+	// Removed fields are removed from the default fieldset.
+	schema.RemoveAttr("type1", "attr1")
+
+	fields, _ = schema.DefaultFields("type1")
+	assert.Equal([]string{"rel1"}, fields)
+
+	// An empty fieldset is kept.
+	schema.RemoveRel("type1", "rel1")
+
+	fields, ok = schema.DefaultFields("type1")
+	assert.True(ok)
+	assert.Empty(fields)
+
+	// Removal
+	assert.NoError(schema.SetDefaultFields("type1", "attr2"))
+	assert.NoError(schema.SetDefaultFields("type1"))
+
+	_, ok = schema.DefaultFields("type1")
+	assert.False(ok)
+
+	assert.NoError(schema.SetDefaultFields("type1", "attr2"))
+	schema.RemoveType("type1")
+
+	_, ok = schema.DefaultFields("type1")
+	assert.False(ok)
+}
+
+func TestSchemaSubset(t *testing.T) {
+	assert := assert.New(t)
+
+	schema := &Schema{}
+	schema.MustAddType(Type{Name: "articles"})
+	schema.MustAddType(Type{Name: "people"})
+	schema.MustAddType(Type{Name: "comments"})
+	schema.MustAddAttr("articles", Attr{Name: "title", Type: AttrTypeString})
+	schema.MustAddAttr("articles", Attr{Name: "body", Type: AttrTypeString})
+	schema.MustAddAttr("people", Attr{Name: "name", Type: AttrTypeString})
+	schema.MustAddRel("articles", Rel{FromName: "author", ToType: "people", ToOne: true})
+	schema.MustAddTwoWayRel(Rel{
+		FromType: "articles",
+		FromName: "comments",
+		ToType:   "comments",
+		ToName:   "article",
+		FromOne:  true,
+	})
+	assert.NoError(schema.SetDefaultFields("articles", "title", "comments"))
+
+	_, err := schema.Subset("articles", "unknown")
+	assert.EqualError(err, `jsonapi: resource type "unknown" does not exist`)
+
+	view, err := schema.Subset("articles", "people")
+	assert.NoError(err)
+	assert.Empty(view.Check())
+
+	assert.True(view.HasType("articles"))
+	assert.True(view.HasType("people"))
+	assert.False(view.HasType("comments"))
+	assert.Contains(view.GetType("articles").Rels, "author")
+	assert.NotContains(view.GetType("articles").Rels, "comments")
+
+	fields, _ := view.DefaultFields("articles")
+	assert.Equal([]string{"title"}, fields)
+
+	// The view can be tailored without affecting the schema.
+	view.RemoveAttr("articles", "body")
+	assert.NotContains(view.GetType("articles").Attrs, "body")
+	assert.Contains(schema.GetType("articles").Attrs, "body")
+	assert.Contains(schema.GetType("articles").Rels, "comments")
+
+	// URLs
+	_, err = NewURLFromRaw(view, "/comments")
+	assert.Error(err)
+
+	_, err = NewURLFromRaw(view, "/articles?fields[articles]=body")
+	assert.Error(err)
+
+	url, err := NewURLFromRaw(view, "/articles/a1?include=author")
+	assert.NoError(err)
+
+	// Marshaling
+	art := &SoftResource{}
+	art.SetType(ptrType(schema.GetType("articles").Copy()))
+	art.SetID("a1")
+	art.Set("title", "Title")
+	art.Set("body", "Body")
+	art.Set("author", "p1")
+	art.Set("comments", []string{"c1"})
+
+	url.Params.Fields = map[string][]string{"articles": {"body", "title", "author", "comments"}}
+
+	buf := &bytes.Buffer{}
+	err = MarshalOptions{Schema: view}.MarshalDocument(buf, &Document{Data: art}, url)
+	assert.NoError(err)
+	assert.JSONEq(`{
+		"data": {
+			"attributes": {"title": "Title"},
+			"id": "a1",
+			"links": {"self": "/articles/a1"},
+			"relationships": {
+				"author": {
+					"links": {
+						"related": "/articles/a1/author",
+						"self": "/articles/a1/relationships/author"
+					}
+				}
+			},
+			"type": "articles"
+		},
+		"jsonapi": {"version": "1.0"},
+		"links": {
+			"self": "/articles/a1?fields%5Barticles%5D=author%2Cbody%2Ccomments%2Ctitle&include=author"
+		}
+	}`, buf.String())
+}
+
+func ptrType(typ Type) *Type {
+	return &typ
+}
+
+func TestSchemaLinkResources(t *testing.T) {
+	assert := assert.New(t)
+
+	schema := NewSchemaBuilder().
+		Type(Type{Name: "articles"}).
+		Type(Type{Name: "users"}).
+		Type(Type{Name: "tags"}).
+		TwoWayRel(Rel{
+			FromType: "articles",
+			FromName: "author",
+			ToOne:    true,
+			ToType:   "users",
+			ToName:   "articles",
+		}).
+		TwoWayRel(Rel{
+			FromType: "articles",
+			FromName: "tags",
+			ToType:   "tags",
+			ToName:   "articles",
+		}).
+		Rel("users", Rel{FromType: "users", FromName: "favorite", ToOne: true, ToType: "tags"}).
+		MustBuild()
+
+	newRes := func(typ, id string) Resource {
+		t := schema.GetType(typ)
+		res := t.New()
+		res.Set("id", id)
+
+		return res
+	}
+
+	art1, art2 := newRes("articles", "a1"), newRes("articles", "a2")
+	user1 := newRes("users", "u1")
+	tag1, tag2 := newRes("tags", "t1"), newRes("tags", "t2")
+
+	// To-one with to-many inverse
+	assert.NoError(schema.LinkResources(art1, "author", user1))
+	assert.NoError(schema.LinkResources(art2, "author", user1))
+	assert.NoError(schema.LinkResources(art2, "author", user1))
+	assert.Equal("u1", art1.Get("author"))
+	assert.Equal([]string{"a1", "a2"}, user1.Get("articles"))
+
+	// From the other side
+	assert.NoError(schema.UnlinkResources(user1, "articles", art1))
+	assert.Equal("", art1.Get("author"))
+	assert.Equal([]string{"a2"}, user1.Get("articles"))
+
+	// Many-to-many
+	assert.NoError(schema.LinkResources(art1, "tags", tag1))
+	assert.NoError(schema.LinkResources(tag2, "articles", art1))
+	assert.Equal([]string{"t1", "t2"}, art1.Get("tags"))
+	assert.Equal([]string{"a1"}, tag1.Get("articles"))
+	assert.Equal([]string{"a1"}, tag2.Get("articles"))
+
+	assert.NoError(schema.UnlinkResources(art1, "tags", tag1))
+	assert.Equal([]string{"t2"}, art1.Get("tags"))
+	assert.Equal([]string{}, tag1.Get("articles"))
+
+	// Unlinking resources that are not linked does nothing.
+	assert.NoError(schema.UnlinkResources(art2, "tags", tag1))
+	assert.Equal([]string{}, art2.Get("tags"))
+
+	// One-way relationship
+	assert.NoError(schema.LinkResources(user1, "favorite", tag1))
+	assert.Equal("t1", user1.Get("favorite"))
+
+	// Errors
+	assert.EqualError(schema.LinkResources(art1, "author", tag1),
+		`jsonapi: relationship "author" of type "articles" cannot point to type "tags"`)
+	assert.Error(schema.LinkResources(art1, "unknown", user1))
+	assert.Error(schema.UnlinkResources(newRes("other", "o1"), "author", user1))
+}
+
+func TestSchemaTypeNames(t *testing.T) {
+	assert := assert.New(t)
+
+	schema := &Schema{}
+
+	for _, name := range []string{"a", "type-1", "type_1", "Type1"} {
+		assert.NoError(schema.AddType(Type{Name: name}), name)
+	}
+
+	for _, name := range []string{"-type", "type-", "a b", "type!", "ty.pe"} {
+		err := schema.AddType(Type{Name: name})
+		assert.Equal(&InvalidTypeNameError{TypeName: name}, err, name)
+	}
+
+	assert.EqualError(schema.AddType(Type{Name: "a b"}), `jsonapi: name of type "a b" is invalid`)
+
+	// Types added directly are reported by Check.
+	schema.Types = append(schema.Types, Type{Name: "a b"})
+
+	errs := schema.Check()
+	assert.Len(errs, 1)
+	assert.Equal(&InvalidTypeNameError{TypeName: "a b"}, errs[0])
+	assert.Equal("a b", errs[0].(SchemaCheckError).Type())
+	assert.Equal("", errs[0].(SchemaCheckError).Field())
+}
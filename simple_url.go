@@ -13,7 +13,8 @@ type SimpleURL struct {
 	Route     string   // /users/:id/articles
 
 	// Fields contains all resource fields (attributes and relationships), grouped by
-	// their resource type.
+	// their resource type. An empty but present fieldset (fields[type]=) is stored
+	// as an empty slice.
 	Fields       map[string][]string
 	Filter       map[string][]string
 	SortingRules []string
@@ -21,6 +22,23 @@ type SimpleURL struct {
 	Include      []string
 	// Params contains all off-spec query parameters.
 	Params map[string][]string
+
+	// RawPath and RawQuery are the path and the query exactly as they were
+	// found in the url, without normalization or decoding.
+	RawPath  string
+	RawQuery string
+}
+
+// A QueryParam is a query parameter as it appears in a raw query.
+type QueryParam struct {
+	// Name and Value are the decoded name and value. If the parameter cannot be
+	// decoded, they hold the raw name and value.
+	Name  string
+	Value string
+
+	// Raw is the parameter exactly as it was found in the query, like
+	// "fields%5Busers%5D=name".
+	Raw string
 }
 
 // NewSimpleURL takes and parses a *url.URL and returns a SimpleURL.
@@ -31,6 +49,9 @@ func NewSimpleURL(u *url.URL) (SimpleURL, error) {
 		return sURL, errors.New("jsonapi: pointer to url.URL is nil")
 	}
 
+	sURL.RawPath = u.EscapedPath()
+	sURL.RawQuery = u.RawQuery
+
 	fragments := parseFragments(u.Path)
 	if len(fragments) != 0 {
 		sURL.Fragments = fragments
@@ -48,24 +69,26 @@ func NewSimpleURL(u *url.URL) (SimpleURL, error) {
 
 	values := u.Query()
 	for name := range values {
-		switch {
-		case strings.HasPrefix(name, "fields[") && strings.HasSuffix(name, "]") &&
-			len(name) > 8:
-			resType := name[7 : len(name)-1]
+		family, member := specParam(name)
+
+		switch family {
+		case "fields":
+			if _, ok := suFields[member]; !ok {
+				suFields[member] = []string{}
+			}
+
 			for _, fields := range values[name] {
-				suFields[resType] = append(suFields[resType], parseCommaList(fields)...)
+				suFields[member] = append(suFields[member], parseCommaList(fields)...)
 			}
-		case strings.HasPrefix(name, "page[") && strings.HasSuffix(name, "]") &&
-			len(name) > 6:
-			nme := name[5 : len(name)-1]
-			suPage[nme] = values.Get(name)
-		case name == "filter" || strings.HasPrefix(name, "filter["):
+		case "page":
+			suPage[member] = values.Get(name)
+		case "filter":
 			suFilter[name] = append(suFilter[name], values[name]...)
-		case name == "sort":
+		case "sort":
 			for _, rules := range values[name] {
 				suSortingRules = append(suSortingRules, parseCommaList(rules)...)
 			}
-		case name == "include":
+		case "include":
 			for _, include := range values[name] {
 				suInclude = append(suInclude, parseCommaList(include)...)
 			}
@@ -107,6 +130,89 @@ func (s *SimpleURL) Path() string {
 	return strings.Join(s.Fragments, "/")
 }
 
+// RequestURI returns the original path and query of the url, like
+// url.URL.RequestURI. It can be used where the exact url matters, like when
+// computing signatures or cache keys.
+func (s *SimpleURL) RequestURI() string {
+	uri := s.RawPath
+	if uri == "" {
+		uri = "/"
+	}
+
+	if s.RawQuery != "" {
+		uri += "?" + s.RawQuery
+	}
+
+	return uri
+}
+
+// QueryParams returns all the query parameters found in RawQuery in their
+// original order. Duplicates are kept.
+func (s *SimpleURL) QueryParams() []QueryParam {
+	return parseQueryParams(s.RawQuery, func(string) bool { return true })
+}
+
+// OffSpecParams returns the query parameters that are not defined by the
+// JSON:API specification, like QueryParams does.
+//
+// Unlike the Params field, the order of the parameters and their original
+// encoding are preserved.
+func (s *SimpleURL) OffSpecParams() []QueryParam {
+	return parseQueryParams(s.RawQuery, func(name string) bool {
+		family, _ := specParam(name)
+		return family == ""
+	})
+}
+
+// parseQueryParams parses the query parameters of rawQuery whose decoded name
+// is accepted by keep.
+func parseQueryParams(rawQuery string, keep func(name string) bool) []QueryParam {
+	params := []QueryParam{}
+
+	for _, raw := range strings.Split(rawQuery, "&") {
+		if raw == "" {
+			continue
+		}
+
+		rawName, rawValue, _ := cutString(raw, "=")
+
+		name, err := url.QueryUnescape(rawName)
+		if err != nil {
+			name = rawName
+		}
+
+		value, err := url.QueryUnescape(rawValue)
+		if err != nil {
+			value = rawValue
+		}
+
+		if keep(name) {
+			params = append(params, QueryParam{Name: name, Value: value, Raw: raw})
+		}
+	}
+
+	return params
+}
+
+// specParam returns the family of the query parameter called name if it is
+// defined by the JSON:API specification, like "fields" for "fields[users]",
+// along with the member between the brackets for fields and page. family is
+// empty for the other parameters.
+func specParam(name string) (family, member string) {
+	switch {
+	case strings.HasPrefix(name, "fields[") && strings.HasSuffix(name, "]") && len(name) > 8:
+		return "fields", name[7 : len(name)-1]
+	case strings.HasPrefix(name, "page[") && strings.HasSuffix(name, "]") && len(name) > 6:
+		return "page", name[5 : len(name)-1]
+	case name == "filter" || strings.HasPrefix(name, "filter["):
+		return "filter", ""
+	case name == "sort" || name == "include":
+		return name, ""
+	}
+
+	return "", ""
+}
+
 func parseCommaList(path string) []string {
 	items := strings.Split(path, ",")
 	items2 := make([]string, 0, len(items))
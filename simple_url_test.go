@@ -66,6 +66,15 @@ func TestSimpleURL(t *testing.T) {
 					},
 				},
 			},
+		}, {
+			name: "empty sparse fieldset",
+			url:  `?fields[type1]=&fields[type2]=,`,
+			expectedURL: SimpleURL{
+				Fields: map[string][]string{
+					"type1": {},
+					"type2": {},
+				},
+			},
 		}, {
 			name: "fields, sort, pagination, include",
 			url: `https://api.example.com/type
@@ -236,6 +245,9 @@ func TestSimpleURL(t *testing.T) {
 				err = jaErr
 			}
 
+			// The raw path and query are checked in TestSimpleURLRaw.
+			su.RawPath, su.RawQuery = "", ""
+
 			assert.Equal(t, test.expectedURL, su)
 			assert.Equal(t, test.expectedError, err)
 		})
@@ -249,3 +261,50 @@ func TestSimpleURLPath(t *testing.T) {
 	su = &SimpleURL{Fragments: []string{"a", "b", "c"}}
 	assert.Equal(t, "a/b/c", su.Path())
 }
+
+func TestSimpleURLRaw(t *testing.T) {
+	assert := assert.New(t)
+
+	u, err := url.Parse(
+		"/type/a%2Fb?b=2&fields%5Btype%5D=attr1&a=1&b=1&filter=x&a=%zz&flag&sort=-attr1",
+	)
+	assert.NoError(err)
+
+	su, err := NewSimpleURL(u)
+	assert.NoError(err)
+
+	assert.Equal("/type/a%2Fb", su.RawPath)
+	assert.Equal(
+		"/type/a%2Fb?b=2&fields%5Btype%5D=attr1&a=1&b=1&filter=x&a=%zz&flag&sort=-attr1",
+		su.RequestURI(),
+	)
+
+	assert.Equal([]QueryParam{
+		{Name: "b", Value: "2", Raw: "b=2"},
+		{Name: "fields[type]", Value: "attr1", Raw: "fields%5Btype%5D=attr1"},
+		{Name: "a", Value: "1", Raw: "a=1"},
+		{Name: "b", Value: "1", Raw: "b=1"},
+		{Name: "filter", Value: "x", Raw: "filter=x"},
+		{Name: "a", Value: "%zz", Raw: "a=%zz"},
+		{Name: "flag", Value: "", Raw: "flag"},
+		{Name: "sort", Value: "-attr1", Raw: "sort=-attr1"},
+	}, su.QueryParams())
+
+	assert.Equal([]QueryParam{
+		{Name: "b", Value: "2", Raw: "b=2"},
+		{Name: "a", Value: "1", Raw: "a=1"},
+		{Name: "b", Value: "1", Raw: "b=1"},
+		{Name: "a", Value: "%zz", Raw: "a=%zz"},
+		{Name: "flag", Value: "", Raw: "flag"},
+	}, su.OffSpecParams())
+
+	// All the values of a filter are kept.
+	u, _ = url.Parse("/type?filter[a]=1&filter[b]=3&filter[a]=2")
+	su, _ = NewSimpleURL(u)
+	assert.Equal(map[string][]string{"filter[a]": {"1", "2"}, "filter[b]": {"3"}}, su.Filter)
+
+	// Without a path nor a query
+	su = SimpleURL{}
+	assert.Equal("/", su.RequestURI())
+	assert.Equal([]QueryParam{}, su.QueryParams())
+}
@@ -10,15 +10,37 @@ type payloadSkeleton struct {
 }
 
 type resourceSkeleton struct {
-	ID            string                          `json:"id"`
-	Type          string                          `json:"type"`
-	Attributes    map[string]json.RawMessage      `json:"attributes"`
-	Relationships map[string]relationshipSkeleton `json:"relationships"`
-	Meta          Meta                            `json:"meta"`
+	ID            string                     `json:"id"`
+	Type          string                     `json:"type"`
+	Attributes    map[string]json.RawMessage `json:"attributes"`
+	Relationships map[string]RawRelationship `json:"relationships"`
+	Links         json.RawMessage            `json:"links"`
+	Meta          Meta                       `json:"meta"`
 }
 
-type relationshipSkeleton struct {
+// A RawRelationship is a relationship object as it is found in a payload.
+//
+// Data is nil if the object has no data member and holds null if the member is
+// null. The links are kept as they are because they can be strings or objects.
+type RawRelationship struct {
 	Data  json.RawMessage            `json:"data"`
 	Links map[string]json.RawMessage `json:"links"`
-	Meta  map[string]json.RawMessage `json:"meta"`
+	Meta  Meta                       `json:"meta"`
+}
+
+// A RawRelsHolder can hold the relationship objects of the payload it was
+// unmarshaled from.
+//
+// The unmarshaling functions pass all the relationship objects found in a
+// payload to resources implementing this interface, including the ones that
+// were skipped because of UnmarshalOptions.UnknownFields. It lets frameworks
+// handle linkage that this package does not support, like local IDs, without
+// parsing the payload again.
+//
+// The relationship objects are keyed by their member names (see
+// Schema.NameMapper). RawRels returns nil if the resource was not unmarshaled
+// from a payload with relationships.
+type RawRelsHolder interface {
+	RawRels() map[string]RawRelationship
+	SetRawRels(rels map[string]RawRelationship)
 }
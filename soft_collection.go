@@ -1,40 +1,70 @@
 package jsonapi
 
+import (
+	"sort"
+	"sync"
+)
+
 // SoftCollection is a collection of SoftResources where the type can be changed
 // for all elements at once by modifying the Type field.
+//
+// The methods of a SoftCollection are safe for concurrent use. Setting the Type
+// field directly is not, SetType should be used instead once the collection is
+// shared. The resources themselves are not protected.
 type SoftCollection struct {
 	Type *Type
 
-	col []*SoftResource
+	col      []*SoftResource
+	rules    []SortRule
+	collator Collator
+	mu       sync.RWMutex
 }
 
 // SetType sets the collection's type.
 func (s *SoftCollection) SetType(typ *Type) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
 	s.Type = typ
 }
 
 // GetType returns the collection's type.
 func (s *SoftCollection) GetType() Type {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
 	return *s.Type
 }
 
 // AddAttr adds an attribute to all of the resources in the collection.
 func (s *SoftCollection) AddAttr(attr Attr) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
 	return s.Type.AddAttr(attr)
 }
 
 // AddRel adds a relationship to all of the resources in the collection.
 func (s *SoftCollection) AddRel(rel Rel) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
 	return s.Type.AddRel(rel)
 }
 
 // Len returns the length of the collection.
 func (s *SoftCollection) Len() int {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
 	return len(s.col)
 }
 
 // At returns the element at index i.
 func (s *SoftCollection) At(i int) Resource {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
 	if i >= 0 && i < len(s.col) {
 		return s.col[i]
 	}
@@ -42,10 +72,144 @@ func (s *SoftCollection) At(i int) Resource {
 	return nil
 }
 
+// Range calls fn for each resource of the collection in order until fn returns
+// false.
+//
+// The resources are those of the collection at the time Range is called. The
+// collection can be modified during the iteration, including by fn, without
+// affecting it.
+func (s *SoftCollection) Range(fn func(i int, r Resource) bool) {
+	s.mu.RLock()
+	col := make([]*SoftResource, len(s.col))
+	copy(col, s.col)
+	s.mu.RUnlock()
+
+	for i, r := range col {
+		if !fn(i, r) {
+			return
+		}
+	}
+}
+
+// Sort sorts the resources of the collection with the less function. The sort
+// is stable.
+//
+// The resources are sorted in a copy of the collection, without holding the
+// lock, so less can use the collection. The sorted copy then replaces the
+// resources, discarding any change made to the collection during the sort.
+func (s *SoftCollection) Sort(less func(a, b Resource) bool) {
+	s.mu.RLock()
+	col := make([]*SoftResource, len(s.col))
+	copy(col, s.col)
+	s.mu.RUnlock()
+
+	sort.SliceStable(col, func(i, j int) bool {
+		return less(col[i], col[j])
+	})
+
+	s.mu.Lock()
+	s.col = col
+	s.mu.Unlock()
+}
+
+// SetSortRules sets the rules Less compares the resources with, like the ones
+// of Params.SortRules. The collection itself is not sorted (see SortStable).
+//
+// The rules with a relationship path are ignored, since the related resources
+// are not part of the collection.
+func (s *SoftCollection) SetSortRules(rules []SortRule) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.rules = make([]SortRule, 0, len(rules))
+
+	for _, rule := range rules {
+		if len(rule.Path) == 0 {
+			s.rules = append(s.rules, rule)
+		}
+	}
+}
+
+// SortRules returns the rules set with SetSortRules.
+func (s *SoftCollection) SortRules() []SortRule {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	rules := make([]SortRule, len(s.rules))
+	copy(rules, s.rules)
+
+	return rules
+}
+
+// SetCollator sets the collator Less compares the strings with, like one
+// selected with Collations.Select. The strings are compared byte-wise if c is
+// nil.
+func (s *SoftCollection) SetCollator(c Collator) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.collator = c
+}
+
+// Less reports whether the resource at index i comes before the resource at
+// index j according to the sort rules. The rules are applied in order, the
+// next one being used only when the values of the previous ones are equal.
+//
+// The values are compared according to their attribute types: numbers by
+// value, times chronologically, strings with the collator (see SetCollator) or
+// byte-wise and false before true. Null values come first.
+//
+// Together with Len and Swap, Less makes SoftCollection a sort.Interface.
+func (s *SoftCollection) Less(i, j int) bool {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return s.compare(s.col[i], s.col[j]) < 0
+}
+
+// Swap swaps the resources at index i and j.
+func (s *SoftCollection) Swap(i, j int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.col[i], s.col[j] = s.col[j], s.col[i]
+}
+
+// SortStable sorts the collection according to the sort rules, like
+// sort.Stable(s) would, so the resources whose values are equal keep their
+// order.
+func (s *SoftCollection) SortStable() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	sort.SliceStable(s.col, func(i, j int) bool {
+		return s.compare(s.col[i], s.col[j]) < 0
+	})
+}
+
+// compare compares a and b according to the sort rules.
+func (s *SoftCollection) compare(a, b *SoftResource) int {
+	for _, rule := range s.rules {
+		c := compareValues(a.Get(rule.Name), b.Get(rule.Name), s.collator)
+		if rule.Desc {
+			c = -c
+		}
+
+		if c != 0 {
+			return c
+		}
+	}
+
+	return 0
+}
+
 // Resource returns the element with an ID equal to id.
 //
 // It builds and returns a SoftResource with only the specified fields.
 func (s *SoftCollection) Resource(id string, fields []string) Resource {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
 	for i := range s.col {
 		if s.col[i].GetID() == id {
 			return s.col[i]
@@ -61,6 +225,10 @@ func (s *SoftCollection) Add(r Resource) {
 	// then it is added to the collection.
 	sr := &SoftResource{}
 	sr.id = r.Get("id").(string)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
 	sr.Type = s.Type
 
 	for _, attr := range r.Attrs() {
@@ -85,6 +253,9 @@ func (s *SoftCollection) Add(r Resource) {
 //
 // Nothing happens if no resource has such an ID.
 func (s *SoftCollection) Remove(id string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
 	for i := range s.col {
 		if s.col[i].GetID() == id {
 			s.col = append(s.col[:i], s.col[i+1:]...)
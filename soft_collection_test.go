@@ -1,7 +1,11 @@
 package jsonapi_test
 
 import (
+	"sort"
+	"strconv"
+	"sync"
 	"testing"
+	"time"
 
 	. "github.com/mark-hartmann/jsonapi"
 
@@ -169,3 +173,175 @@ func TestSoftCollectionMiscellaneous(t *testing.T) {
 	sc := &SoftCollection{}
 	assert.Nil(sc.At(99), "nonexistent element")
 }
+
+func TestSoftCollectionRangeSort(t *testing.T) {
+	assert := assert.New(t)
+
+	typ := &Type{Name: "thistype"}
+	typ.MustAddAttr(Attr{Name: "num", Type: AttrTypeInt})
+
+	sc := &SoftCollection{}
+	sc.SetType(typ)
+
+	for _, id := range []string{"c", "a", "b", "a2"} {
+		sr := &SoftResource{Type: typ}
+		sr.SetID(id)
+		sr.Set("num", len(id))
+		sc.Add(sr)
+	}
+
+	// The less function can use the collection.
+	sc.Sort(func(a, b Resource) bool {
+		_ = sc.Len()
+
+		return a.Get("num").(int) < b.Get("num").(int)
+	})
+
+	ids := []string{}
+
+	// The collection is modified during the iteration.
+	sc.Range(func(i int, r Resource) bool {
+		sc.Remove(r.Get("id").(string))
+
+		ids = append(ids, r.Get("id").(string))
+
+		return i < 2
+	})
+
+	// Stable sort
+	assert.Equal([]string{"c", "a", "b"}, ids)
+	assert.Equal(1, sc.Len())
+	assert.Equal("a2", sc.At(0).Get("id"))
+}
+
+func TestSoftCollectionSortRules(t *testing.T) {
+	assert := assert.New(t)
+
+	typ := &Type{Name: "people"}
+	typ.MustAddAttr(Attr{Name: "name", Type: AttrTypeString})
+	typ.MustAddAttr(Attr{Name: "age", Type: AttrTypeUint8, Nullable: true})
+	typ.MustAddAttr(Attr{Name: "born", Type: AttrTypeTime})
+	typ.MustAddAttr(Attr{Name: "score", Type: AttrTypeFloat64})
+	typ.MustAddRel(Rel{FromName: "manager", ToType: "people", ToOne: true})
+
+	schema := &Schema{}
+	schema.MustAddType(*typ)
+
+	sc := &SoftCollection{}
+	sc.SetType(typ)
+
+	age := func(n uint8) *uint8 { return &n }
+	day := func(d int) time.Time { return time.Date(2000, 1, d, 0, 0, 0, 0, time.UTC) }
+
+	for _, p := range []struct {
+		id    string
+		name  string
+		age   *uint8
+		born  time.Time
+		score float64
+	}{
+		{"p1", "bob", age(30), day(3), 1.5},
+		{"p2", "ann", nil, day(1), 2},
+		{"p3", "bob", age(20), day(2), 1.5},
+		{"p4", "ann", age(20), day(4), -1},
+		{"p5", "carl", age(30), day(5), 1.5},
+	} {
+		sr := &SoftResource{Type: typ}
+		sr.SetID(p.id)
+		sr.Set("name", p.name)
+		sr.Set("age", p.age)
+		sr.Set("born", p.born)
+		sr.Set("score", p.score)
+		sc.Add(sr)
+	}
+
+	ids := func() []string {
+		ids := []string{}
+		sc.Range(func(_ int, r Resource) bool {
+			ids = append(ids, r.Get("id").(string))
+			return true
+		})
+
+		return ids
+	}
+
+	sortBy := func(rules ...string) []string {
+		srs := make([]SortRule, 0, len(rules))
+
+		for _, rule := range rules {
+			sr, err := ParseSortRule(schema, *typ, rule)
+			assert.NoError(err)
+
+			srs = append(srs, sr)
+		}
+
+		sc.SetSortRules(srs)
+		sc.SortStable()
+
+		return ids()
+	}
+
+	assert.Equal([]string{"p2", "p4", "p1", "p3", "p5"}, sortBy("name"))
+	assert.Equal([]string{"p2", "p4", "p3", "p1", "p5"}, sortBy("name", "age"))
+	assert.Equal([]string{"p5", "p1", "p4", "p3", "p2"}, sortBy("-age", "-born"))
+	assert.Equal([]string{"p4", "p3", "p1", "p5", "p2"}, sortBy("score", "born"))
+	assert.Equal([]string{"p2", "p3", "p1", "p4", "p5"}, sortBy("born"))
+
+	// Stable
+	assert.Equal([]string{"p2", "p3", "p4", "p1", "p5"}, sortBy("age"))
+
+	// Rules with a relationship path are ignored.
+	sc.SetSortRules([]SortRule{{Path: []Rel{typ.Rels["manager"]}, Name: "name"}, {Name: "name"}})
+	assert.Equal([]SortRule{{Name: "name"}}, sc.SortRules())
+
+	// sort.Interface
+	sc.SetSortRules([]SortRule{{Name: "born", Desc: true}})
+	sort.Sort(sc)
+	assert.Equal([]string{"p5", "p4", "p1", "p3", "p2"}, ids())
+	assert.True(sc.Less(0, 1))
+	assert.False(sc.Less(1, 0))
+}
+
+func TestSoftCollectionConcurrency(t *testing.T) {
+	typ := &Type{Name: "thistype"}
+	typ.MustAddAttr(Attr{Name: "attr", Type: AttrTypeString})
+
+	sc := &SoftCollection{}
+	sc.SetType(typ)
+
+	var wg sync.WaitGroup
+
+	wg.Add(2)
+
+	go func() {
+		defer wg.Done()
+
+		for i := 0; i < 100; i++ {
+			sr := &SoftResource{Type: typ}
+			sr.SetID(strconv.Itoa(i))
+			sc.Add(sr)
+
+			if i%10 == 0 {
+				sc.Remove(strconv.Itoa(i / 2))
+			}
+		}
+	}()
+
+	go func() {
+		defer wg.Done()
+
+		for i := 0; i < 100; i++ {
+			_ = sc.Len()
+			_ = sc.At(i / 2)
+			_ = sc.Resource(strconv.Itoa(i), nil)
+
+			sc.Range(func(_ int, r Resource) bool {
+				return r != nil
+			})
+		}
+	}()
+
+	wg.Wait()
+
+	assert.Equal(t, 90, sc.Len())
+}
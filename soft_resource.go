@@ -1,8 +1,10 @@
 package jsonapi
 
 import (
+	"encoding/json"
+	"errors"
+	"fmt"
 	"reflect"
-	"time"
 )
 
 // SoftResource represents a resource whose type is defined by an internal field
@@ -11,13 +13,25 @@ import (
 // Changing the type automatically changes the resource's attributes and
 // relationships. When a field is added, its value is the zero value of the
 // field's type.
+//
+// Unknown fields and values that cannot be assigned to a field are ignored by
+// Set, unless Strict is true, in which case Set panics. SetE returns an error
+// instead.
 type SoftResource struct {
 	Type *Type
 
-	id    string
-	data  map[string]interface{}
-	meta  Meta
-	links map[string]Link
+	// Strict makes Set panic with the error SetE would return.
+	Strict bool
+
+	id      string
+	data    map[string]interface{}
+	meta    Meta
+	links   map[string]Link
+	linkage map[string]Identifiers
+	relMeta map[string]Meta
+	counts  map[string]int
+	rawRels map[string]RawRelationship
+	skipped []*UnknownFieldError
 }
 
 // Attrs returns the resource's attributes.
@@ -89,7 +103,8 @@ func (sr *SoftResource) New() Resource {
 	typ := sr.Type.Copy()
 
 	return &SoftResource{
-		Type: &typ,
+		Type:   &typ,
+		Strict: sr.Strict,
 	}
 }
 
@@ -128,6 +143,16 @@ func (sr *SoftResource) Get(key string) interface{} {
 	return nil
 }
 
+// GetE returns the value associated to the field named after key like Get does,
+// or an *UnknownFieldError if the type of the resource has no such field.
+func (sr *SoftResource) GetE(key string) (interface{}, error) {
+	if err := sr.checkField(key); err != nil {
+		return nil, err
+	}
+
+	return sr.Get(key), nil
+}
+
 // SetID sets the resource's ID.
 func (sr *SoftResource) SetID(id string) {
 	sr.check()
@@ -141,42 +166,147 @@ func (sr *SoftResource) SetType(typ *Type) {
 }
 
 // Set sets the value associated to the field named key to v.
+//
+// Values of a different type than the one of the attribute are ignored, except
+// for attributes of type AttrTypeJSON, where any value that can be marshaled to
+// JSON is accepted and stored as a json.RawMessage. If Strict is true, Set
+// panics with the error SetE would return instead.
+//
+// The value of a relationship can also be given as an Identifier or a RelData
+// (to-one), or as an Identifiers or a RelDataMany (to-many). In that case, the
+// IDs are set and the identifiers are kept as the linkage of the relationship
+// (see LinkageHolder). The meta values of a RelData or a RelDataMany become the
+// meta values of the relationship (see RelMetaHolder).
 func (sr *SoftResource) Set(key string, v interface{}) {
+	if !sr.Strict {
+		sr.set(key, v)
+		return
+	}
+
+	if err := sr.SetE(key, v); err != nil {
+		panic(err)
+	}
+}
+
+// SetE sets the value associated to the field named key to v like Set does. It
+// returns an *UnknownFieldError if the type of the resource has no such field,
+// and an *InvalidFieldValueError if v cannot be assigned to the field, in which
+// case the current value is kept.
+func (sr *SoftResource) SetE(key string, v interface{}) error {
+	if err := sr.checkField(key); err != nil {
+		return err
+	}
+
+	if !sr.set(key, v) {
+		return NewInvalidFieldValueError(*sr.Type, key, v)
+	}
+
+	return nil
+}
+
+// set sets the value associated to the field named key to v and returns false
+// if v cannot be assigned to the field.
+func (sr *SoftResource) set(key string, v interface{}) bool {
 	sr.check()
 
 	if key == "id" {
-		id, _ := v.(string)
+		id, ok := v.(string)
+		if !ok && !isNil(v) {
+			return false
+		}
+
 		sr.id = id
 
-		return
+		return true
 	}
 
 	if attr, ok := sr.Type.Attrs[key]; ok {
-		zv, _ := GetZeroValue(attr.Type, attr.Array, attr.Nullable)
-		if isNil(v) {
+		zv := attrZeroValue(attr)
+
+		switch {
+		case isNil(v):
 			sr.data[key] = zv
-		} else if reflect.TypeOf(v) == reflect.TypeOf(zv) {
+		case reflect.TypeOf(v) == reflect.TypeOf(zv):
 			sr.data[key] = v
+		case attr.Type == AttrTypeJSON:
+			jv, err := jsonAttrValue(attr, v)
+			if err != nil {
+				return false
+			}
+
+			sr.data[key] = jv
+		default:
+			return false
 		}
-	} else if rel, ok := sr.Type.Rels[key]; ok {
-		if _, ok := v.(string); ok && rel.ToOne {
-			sr.data[key] = v
-		} else if _, ok := v.([]string); ok && !rel.ToOne {
-			sr.data[key] = v
+
+		return true
+	}
+
+	rel, ok := sr.Type.Rels[key]
+	if !ok {
+		return false
+	}
+
+	if val, idens, ok := linkageValue(rel, v); ok {
+		sr.data[key] = val
+		sr.SetLinkage(key, idens)
+
+		switch v := v.(type) {
+		case RelData:
+			sr.SetRelMeta(key, v.Meta)
+		case RelDataMany:
+			sr.SetRelMeta(key, v.Meta)
 		}
+	} else if _, ok := v.(string); ok && rel.ToOne {
+		sr.data[key] = v
+	} else if _, ok := v.([]string); ok && !rel.ToOne {
+		sr.data[key] = v
+	} else {
+		return false
 	}
+
+	return true
+}
+
+// checkField returns an *UnknownFieldError if key is not the name of a field
+// of the type of the resource.
+func (sr *SoftResource) checkField(key string) error {
+	sr.check()
+
+	if key == "id" {
+		return nil
+	}
+
+	if _, ok := sr.Type.Attrs[key]; ok {
+		return nil
+	}
+
+	if _, ok := sr.Type.Rels[key]; ok {
+		return nil
+	}
+
+	return &UnknownFieldError{Type: sr.Type.Name, Field: key}
 }
 
-// Copy returns a new SoftResource object with the same type and values.
+// Copy returns a new SoftResource object with the same type and values, and
+// the same meta values, links, linkages and counts of relationships. It is a
+// deep copy: modifying the copy, or the values it holds, does not affect sr
+// (see Cloner).
 func (sr *SoftResource) Copy() Resource {
 	sr.check()
 
 	typ := sr.Type.Copy()
 
 	return &SoftResource{
-		Type: &typ,
-		id:   sr.id,
-		data: copyData(sr.data),
+		Type:    &typ,
+		Strict:  sr.Strict,
+		id:      sr.id,
+		data:    copyValue(sr.data).(map[string]interface{}),
+		meta:    copyValue(sr.meta).(Meta),
+		links:   copyValue(sr.links).(map[string]Link),
+		linkage: copyValue(sr.linkage).(map[string]Identifiers),
+		relMeta: copyValue(sr.relMeta).(map[string]Meta),
+		counts:  copyValue(sr.counts).(map[string]int),
 	}
 }
 
@@ -200,6 +330,145 @@ func (sr *SoftResource) SetLinks(links map[string]Link) {
 	sr.links = links
 }
 
+// Linkage returns the identifiers of the relationship named rel.
+func (sr *SoftResource) Linkage(rel string) Identifiers {
+	return sr.linkage[rel]
+}
+
+// SetLinkage sets the identifiers of the relationship named rel.
+func (sr *SoftResource) SetLinkage(rel string, idens Identifiers) {
+	if sr.linkage == nil {
+		sr.linkage = map[string]Identifiers{}
+	}
+
+	sr.linkage[rel] = idens
+}
+
+// RelMeta returns the meta values of the relationship named rel.
+func (sr *SoftResource) RelMeta(rel string) Meta {
+	return sr.relMeta[rel]
+}
+
+// SetRelMeta sets the meta values of the relationship named rel.
+func (sr *SoftResource) SetRelMeta(rel string, meta Meta) {
+	if sr.relMeta == nil {
+		sr.relMeta = map[string]Meta{}
+	}
+
+	sr.relMeta[rel] = meta
+}
+
+// RelCount returns the number of resources in the relationship named rel and
+// whether it is known.
+func (sr *SoftResource) RelCount(rel string) (int, bool) {
+	n, ok := sr.counts[rel]
+	return n, ok
+}
+
+// SetRelCount sets the number of resources in the relationship named rel. A
+// negative n makes it unknown.
+func (sr *SoftResource) SetRelCount(rel string, n int) {
+	if n < 0 {
+		delete(sr.counts, rel)
+		return
+	}
+
+	if sr.counts == nil {
+		sr.counts = map[string]int{}
+	}
+
+	sr.counts[rel] = n
+}
+
+// RawRels returns the relationship objects of the payload the resource was
+// unmarshaled from.
+func (sr *SoftResource) RawRels() map[string]RawRelationship {
+	return sr.rawRels
+}
+
+// SetRawRels sets the relationship objects of the payload the resource was
+// unmarshaled from.
+func (sr *SoftResource) SetRawRels(rels map[string]RawRelationship) {
+	sr.rawRels = rels
+}
+
+// SkippedFields returns the unknown fields that were skipped when the resource
+// was unmarshaled (see UnmarshalOptions.UnknownFields).
+func (sr *SoftResource) SkippedFields() []*UnknownFieldError {
+	return sr.skipped
+}
+
+// SetSkippedFields sets the unknown fields that were skipped when the resource
+// was unmarshaled.
+func (sr *SoftResource) SetSkippedFields(fields []*UnknownFieldError) {
+	sr.skipped = fields
+}
+
+// MarshalJSON returns the resource object of the resource, with all its fields
+// and the linkage of its relationships, like MarshalResource does. It lets a
+// SoftResource be part of any value given to json.Marshal.
+func (sr *SoftResource) MarshalJSON() ([]byte, error) {
+	sr.check()
+
+	relData := map[string][]string{sr.Type.Name: sortedRelNames(sr.Type.Rels)}
+
+	data := MarshalResource(sr, "", sr.Type.Fields(), relData)
+	if data == nil {
+		return nil, fmt.Errorf("jsonapi: resource %q of type %q cannot be marshaled",
+			sr.id, sr.Type.Name)
+	}
+
+	return data, nil
+}
+
+var errSoftResourceType = errors.New("jsonapi: type of SoftResource must be set to unmarshal it")
+
+// UnmarshalJSON populates the resource from a resource object, like
+// UnmarshalResource does. Its Type must be set beforehand and must match the
+// type of the resource object. Only the types the relationships point to are
+// accepted in the linkages.
+//
+// The values, the meta values and the linkages of the resource are replaced.
+func (sr *SoftResource) UnmarshalJSON(data []byte) error {
+	if sr.Type == nil || sr.Type.Name == "" {
+		return errSoftResourceType
+	}
+
+	var ske struct {
+		Type string `json:"type"`
+	}
+
+	if err := json.Unmarshal(data, &ske); err != nil {
+		return payloadErr(err)
+	}
+
+	if ske.Type != sr.Type.Name {
+		return &UnknownTypeError{Type: ske.Type}
+	}
+
+	typ := sr.Type.Copy()
+	typ.NewFunc = nil
+
+	schema := &Schema{Types: []Type{typ}}
+
+	for _, rel := range typ.Rels {
+		if !schema.HasType(rel.ToType) {
+			schema.Types = append(schema.Types, Type{Name: rel.ToType})
+		}
+	}
+
+	res, err := UnmarshalResource(data, schema)
+	if err != nil {
+		return err
+	}
+
+	usr := res.(*SoftResource)
+	usr.Type = sr.Type
+	*sr = *usr
+
+	return nil
+}
+
 func (sr *SoftResource) fields() []string {
 	fields := make([]string, 0, len(sr.Type.Attrs)+len(sr.Type.Rels))
 	for i := range sr.Type.Attrs {
@@ -233,8 +502,8 @@ func (sr *SoftResource) check() {
 	for i := range sr.Type.Attrs {
 		attr := sr.Type.Attrs[i]
 		if _, ok := sr.data[attr.Name]; !ok {
-			sr.data[attr.Name], _ = GetZeroValue(attr.Type, attr.Array ||
-				attr.Type == AttrTypeBytes, attr.Nullable)
+			attr.Array = attr.Array || attr.Type == AttrTypeBytes
+			sr.data[attr.Name] = attrZeroValue(attr)
 		}
 	}
 
@@ -267,186 +536,3 @@ func (sr *SoftResource) check() {
 		}
 	}
 }
-
-func copyData(d map[string]interface{}) map[string]interface{} {
-	// todo: handle AttrTypeOther
-	d2 := map[string]interface{}{}
-
-	for k, v := range d {
-		switch v2 := v.(type) {
-		// String array
-		case []string:
-			nv := make([]string, len(v2))
-			_ = copy(nv, v2)
-			d2[k] = v2
-		case *[]string:
-			if v2 == nil {
-				d2[k] = (*[]string)(nil)
-			} else {
-				nv := make([]string, len(*v2))
-				_ = copy(nv, *v2)
-				d2[k] = v2
-			}
-		// Int array
-		case []int:
-			nv := make([]int, len(v2))
-			_ = copy(nv, v2)
-			d2[k] = v2
-		case *[]int:
-			if v2 == nil {
-				d2[k] = (*[]int)(nil)
-			} else {
-				nv := make([]int, len(*v2))
-				_ = copy(nv, *v2)
-				d2[k] = v2
-			}
-		// Int8 array
-		case []int8:
-			nv := make([]int8, len(v2))
-			_ = copy(nv, v2)
-			d2[k] = v2
-		case *[]int8:
-			if v2 == nil {
-				d2[k] = (*[]int8)(nil)
-			} else {
-				nv := make([]int8, len(*v2))
-				_ = copy(nv, *v2)
-				d2[k] = v2
-			}
-		// Int16 array
-		case []int16:
-			nv := make([]int16, len(v2))
-			_ = copy(nv, v2)
-			d2[k] = v2
-		case *[]int16:
-			if v2 == nil {
-				d2[k] = (*[]int16)(nil)
-			} else {
-				nv := make([]int16, len(*v2))
-				_ = copy(nv, *v2)
-				d2[k] = v2
-			}
-		// Int32 array
-		case []int32:
-			nv := make([]int32, len(v2))
-			_ = copy(nv, v2)
-			d2[k] = v2
-		case *[]int32:
-			if v2 == nil {
-				d2[k] = (*[]int32)(nil)
-			} else {
-				nv := make([]int32, len(*v2))
-				_ = copy(nv, *v2)
-				d2[k] = v2
-			}
-		// Int64 array
-		case []int64:
-			nv := make([]int64, len(v2))
-			_ = copy(nv, v2)
-			d2[k] = v2
-		case *[]int64:
-			if v2 == nil {
-				d2[k] = (*[]int64)(nil)
-			} else {
-				nv := make([]int64, len(*v2))
-				_ = copy(nv, *v2)
-				d2[k] = v2
-			}
-		// Uint array
-		case []uint:
-			nv := make([]uint, len(v2))
-			_ = copy(nv, v2)
-			d2[k] = v2
-		case *[]uint:
-			if v2 == nil {
-				d2[k] = (*[]uint)(nil)
-			} else {
-				nv := make([]uint, len(*v2))
-				_ = copy(nv, *v2)
-				d2[k] = v2
-			}
-		// Uint8 array
-		case []uint8:
-			nv := make([]uint8, len(v2))
-			_ = copy(nv, v2)
-			d2[k] = v2
-		case *[]uint8:
-			if v2 == nil {
-				d2[k] = (*[]uint8)(nil)
-			} else {
-				nv := make([]uint8, len(*v2))
-				_ = copy(nv, *v2)
-				d2[k] = v2
-			}
-		// Uint16 array
-		case []uint16:
-			nv := make([]uint16, len(v2))
-			_ = copy(nv, v2)
-			d2[k] = v2
-		case *[]uint16:
-			if v2 == nil {
-				d2[k] = (*[]uint16)(nil)
-			} else {
-				nv := make([]uint16, len(*v2))
-				_ = copy(nv, *v2)
-				d2[k] = v2
-			}
-		// Uint32 array
-		case []uint32:
-			nv := make([]uint32, len(v2))
-			_ = copy(nv, v2)
-			d2[k] = v2
-		case *[]uint32:
-			if v2 == nil {
-				d2[k] = (*[]uint32)(nil)
-			} else {
-				nv := make([]uint32, len(*v2))
-				_ = copy(nv, *v2)
-				d2[k] = v2
-			}
-		// Uint64 array
-		case []uint64:
-			nv := make([]uint64, len(v2))
-			_ = copy(nv, v2)
-			d2[k] = v2
-		case *[]uint64:
-			if v2 == nil {
-				d2[k] = (*[]uint64)(nil)
-			} else {
-				nv := make([]uint64, len(*v2))
-				_ = copy(nv, *v2)
-				d2[k] = v2
-			}
-		// Bool array
-		case []bool:
-			nv := make([]bool, len(v2))
-			_ = copy(nv, v2)
-			d2[k] = v2
-		case *[]bool:
-			if v2 == nil {
-				d2[k] = (*[]bool)(nil)
-			} else {
-				nv := make([]bool, len(*v2))
-				_ = copy(nv, *v2)
-				d2[k] = v2
-			}
-		// Time array
-		case []time.Time:
-			nv := make([]time.Time, len(v2))
-			_ = copy(nv, v2)
-			d2[k] = v2
-		case *[]time.Time:
-			if v2 == nil {
-				d2[k] = (*[]time.Time)(nil)
-			} else {
-				nv := make([]time.Time, len(*v2))
-				_ = copy(nv, *v2)
-				d2[k] = v2
-			}
-		default:
-			d2[k] = v2
-		}
-	}
-
-	return d2
-}
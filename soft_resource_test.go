@@ -1,6 +1,7 @@
 package jsonapi_test
 
 import (
+	"encoding/json"
 	"testing"
 	"time"
 
@@ -11,6 +12,10 @@ import (
 
 var _ Resource = (*SoftResource)(nil)
 var _ Copier = (*SoftResource)(nil)
+var _ GetterE = (*SoftResource)(nil)
+var _ SetterE = (*SoftResource)(nil)
+var _ json.Marshaler = (*SoftResource)(nil)
+var _ json.Unmarshaler = (*SoftResource)(nil)
 
 func TestSoftResource(t *testing.T) {
 	sr := &SoftResource{}
@@ -475,3 +480,181 @@ func TestSoftResourceGetSetID(t *testing.T) {
 	assert.Equal(t, "def456", sr.GetID())
 	assert.Equal(t, "def456", sr.Get("id"))
 }
+
+func TestSoftResourceLinkage(t *testing.T) {
+	assert := assert.New(t)
+
+	sr := &SoftResource{}
+	assert.Nil(sr.Linkage("rel1"))
+
+	idens := Identifiers{{ID: "id1", Type: "type1", Meta: Meta{"key": "val"}}}
+	sr.SetLinkage("rel1", idens)
+	assert.Equal(idens, sr.Linkage("rel1"))
+	assert.Nil(sr.Linkage("rel2"))
+}
+
+func TestSoftResourceSetIdentifiers(t *testing.T) {
+	assert := assert.New(t)
+
+	typ := &Type{Name: "type"}
+	typ.MustAddRel(Rel{FromName: "to-one", ToType: "type", ToOne: true})
+	typ.MustAddRel(Rel{FromName: "to-many", ToType: "type"})
+
+	sr := &SoftResource{Type: typ}
+
+	iden := Identifier{ID: "id1", Type: "type", Meta: Meta{"key": "val"}}
+	idens := Identifiers{{ID: "id2", Type: "type"}, {ID: "id3", Type: "type"}}
+
+	sr.Set("to-one", iden)
+	sr.Set("to-many", idens)
+	assert.Equal("id1", sr.Get("to-one"))
+	assert.Equal(Identifiers{iden}, sr.Linkage("to-one"))
+	assert.Equal([]string{"id2", "id3"}, sr.Get("to-many"))
+	assert.Equal(idens, sr.Linkage("to-many"))
+
+	sr.Set("to-one", RelData{Res: Identifier{ID: "id4", Type: "type"}})
+	sr.Set("to-many", RelDataMany{Res: Identifiers{{ID: "id5", Type: "type"}}})
+	assert.Equal("id4", sr.Get("to-one"))
+	assert.Equal([]string{"id5"}, sr.Get("to-many"))
+
+	// Wrong cardinality
+	sr.Set("to-one", idens)
+	sr.Set("to-many", iden)
+	assert.Equal("id4", sr.Get("to-one"))
+	assert.Equal([]string{"id5"}, sr.Get("to-many"))
+}
+
+func TestSoftResourceJSON(t *testing.T) {
+	assert := assert.New(t)
+
+	typ := Type{Name: "articles"}
+	typ.MustAddAttr(Attr{Name: "title", Type: AttrTypeString})
+	typ.MustAddRel(Rel{FromName: "author", ToType: "people", ToOne: true})
+	typ.MustAddRel(Rel{FromName: "tags", ToType: "tags"})
+
+	sr := &SoftResource{Type: &typ}
+	sr.SetID("a1")
+	sr.Set("title", "Hello")
+	sr.Set("author", "p1")
+	sr.Set("tags", []string{"t1", "t2"})
+	sr.SetMeta(Meta{"views": float64(3)})
+
+	// Embedded in a custom payload
+	payload, err := json.Marshal(map[string]interface{}{"resource": sr})
+	assert.NoError(err)
+	assert.JSONEq(`{"resource": {
+		"attributes": {"title": "Hello"},
+		"id": "a1",
+		"links": {"self": "/articles/a1"},
+		"meta": {"views": 3},
+		"relationships": {
+			"author": {
+				"data": {"id": "p1", "type": "people"},
+				"links": {
+					"related": "/articles/a1/author",
+					"self": "/articles/a1/relationships/author"
+				}
+			},
+			"tags": {
+				"data": [{"id": "t1", "type": "tags"}, {"id": "t2", "type": "tags"}],
+				"links": {
+					"related": "/articles/a1/tags",
+					"self": "/articles/a1/relationships/tags"
+				}
+			}
+		},
+		"type": "articles"
+	}}`, string(payload))
+
+	// Round trip
+	var pl struct {
+		Resource *SoftResource `json:"resource"`
+	}
+
+	pl.Resource = &SoftResource{Type: &typ}
+	assert.NoError(json.Unmarshal(payload, &pl))
+	assert.Same(&typ, pl.Resource.Type)
+	assert.Equal("a1", pl.Resource.GetID())
+	assert.Equal("Hello", pl.Resource.Get("title"))
+	assert.Equal("p1", pl.Resource.Get("author"))
+	assert.Equal([]string{"t1", "t2"}, pl.Resource.Get("tags"))
+	assert.Equal(Meta{"views": float64(3)}, pl.Resource.Meta())
+	assert.True(Equal(sr, pl.Resource))
+
+	// Errors
+	err = json.Unmarshal(payload, &struct{ Resource *SoftResource }{})
+	assert.EqualError(err, "jsonapi: type of SoftResource must be set to unmarshal it")
+
+	err = (&SoftResource{Type: &typ}).UnmarshalJSON([]byte(`{"id":"p1","type":"people"}`))
+	assert.EqualError(err, `jsonapi: resource type "people" does not exist`)
+
+	err = (&SoftResource{Type: &typ}).UnmarshalJSON([]byte(`{"id":"a1","type":"articles",` +
+		`"relationships":{"author":{"data":{"id":"u1","type":"users"}}}}`))
+	assert.Error(err)
+}
+
+func TestSoftResourceGetSetE(t *testing.T) {
+	assert := assert.New(t)
+
+	typ := &Type{Name: "articles"}
+	typ.MustAddAttr(Attr{Name: "title", Type: AttrTypeString})
+	typ.MustAddRel(Rel{FromName: "author", ToType: "people", ToOne: true})
+
+	sr := &SoftResource{Type: typ}
+
+	assert.NoError(sr.SetE("id", "a1"))
+	assert.NoError(sr.SetE("title", "Hello"))
+	assert.NoError(sr.SetE("author", "p1"))
+
+	v, err := sr.GetE("title")
+	assert.NoError(err)
+	assert.Equal("Hello", v)
+
+	v, err = sr.GetE("author")
+	assert.NoError(err)
+	assert.Equal("p1", v)
+
+	// Unknown field
+	err = sr.SetE("body", "World")
+	assert.EqualError(err, `jsonapi: field "body" does not exist in resource type "articles"`)
+	assert.IsType(&UnknownFieldError{}, err)
+
+	_, err = sr.GetE("body")
+	assert.EqualError(err, `jsonapi: field "body" does not exist in resource type "articles"`)
+
+	// Invalid values
+	err = sr.SetE("title", 3)
+	assert.EqualError(err, `jsonapi: invalid value "3" for field "title": `+
+		`got value of type int, not string`)
+	assert.IsType(&InvalidFieldValueError{}, err)
+	assert.Equal("Hello", sr.Get("title"))
+
+	err = sr.SetE("author", []string{"p2"})
+	assert.EqualError(err, `jsonapi: invalid value "[p2]" for field "author": `+
+		`got value of type []string, not string`)
+	assert.Equal("p1", sr.Get("author"))
+
+	err = sr.SetE("id", 3)
+	assert.EqualError(err, `jsonapi: invalid value "3" for field "id": `+
+		`got value of type int, not string`)
+	assert.Equal("a1", sr.GetID())
+
+	assert.NoError(sr.SetE("title", nil))
+	assert.Equal("", sr.Get("title"))
+
+	// Strict
+	sr.Set("title", 3)
+	assert.Equal("", sr.Get("title"))
+
+	sr.Strict = true
+
+	assert.PanicsWithError(`jsonapi: invalid value "3" for field "title": `+
+		`got value of type int, not string`, func() { sr.Set("title", 3) })
+	assert.PanicsWithError(
+		`jsonapi: field "body" does not exist in resource type "articles"`,
+		func() { sr.Set("body", "World") },
+	)
+	assert.NotPanics(func() { sr.Set("title", "Hello") })
+	assert.True(sr.Copy().(*SoftResource).Strict)
+	assert.True(sr.New().(*SoftResource).Strict)
+}
@@ -0,0 +1,213 @@
+package jsonapi
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// fieldTag is the parsed api tag of a struct field.
+//
+// The tag starts with the kind of the field followed by positional arguments and
+// options. An option is either a flag or a key=value pair.
+type fieldTag struct {
+	// kind is "attr", "rel", "-" or the name of the type for the ID field.
+	kind string
+	args []string
+
+	// name overrides the member name taken from the json tag.
+	name string
+
+	// nullable overrides the nullability deduced from the Go type.
+	nullable *bool
+
+	// format is the format of the attribute (see Attr.Format).
+	format string
+
+	// readOnly marks the attribute as read-only (see Attr.ReadOnly).
+	readOnly bool
+
+	// omitEmpty omits the attribute when it is empty (see Attr.OmitEmpty).
+	omitEmpty bool
+
+	// deprecated marks the field as deprecated (see Attr.Deprecated).
+	deprecated bool
+
+	// doc and example are the values of the api-doc and api-example tags
+	// (see Attr.Description and Attr.Example).
+	doc, example string
+}
+
+// parseFieldTag parses the api tag of the struct field.
+func parseFieldTag(sf reflect.StructField) (fieldTag, error) {
+	parts := strings.Split(sf.Tag.Get("api"), ",")
+	tag := fieldTag{
+		kind:    parts[0],
+		doc:     sf.Tag.Get("api-doc"),
+		example: sf.Tag.Get("api-example"),
+	}
+
+	for _, part := range parts[1:] {
+		key, val, isPair := cutString(part, "=")
+
+		switch {
+		case key == "nullable":
+			b := true
+
+			if isPair {
+				var err error
+				if b, err = strconv.ParseBool(val); err != nil {
+					return tag, fmt.Errorf("jsonapi: invalid nullable option %q of field %q",
+						val, sf.Name)
+				}
+			}
+
+			tag.nullable = &b
+		case key == "name" && isPair:
+			if !memberRegexp.MatchString(val) {
+				return tag, fmt.Errorf("jsonapi: invalid name option %q of field %q",
+					val, sf.Name)
+			}
+
+			tag.name = val
+		case key == "format" && isPair:
+			tag.format = val
+		case key == "readonly" && !isPair:
+			tag.readOnly = true
+		case key == "omitempty" && !isPair:
+			tag.omitEmpty = true
+		case key == "deprecated" && !isPair:
+			tag.deprecated = true
+		case isPair:
+			return tag, fmt.Errorf("jsonapi: unknown option %q of field %q", key, sf.Name)
+		default:
+			tag.args = append(tag.args, part)
+		}
+	}
+
+	return tag, nil
+}
+
+// parseTypeTag parses the tags of the ID field of a struct and returns a type
+// that only has its name and its documentation. The api tag holds the name of
+// the type, optionally followed by the deprecated option.
+func parseTypeTag(sf reflect.StructField) (Type, error) {
+	name, opts, _ := cutString(sf.Tag.Get("api"), ",")
+	typ := Type{
+		Name:        name,
+		Description: sf.Tag.Get("api-doc"),
+		Example:     sf.Tag.Get("api-example"),
+	}
+
+	if opts == "" {
+		return typ, nil
+	}
+
+	for _, opt := range strings.Split(opts, ",") {
+		if opt != "deprecated" {
+			return typ, fmt.Errorf("jsonapi: unknown option %q of field %q", opt, sf.Name)
+		}
+
+		typ.Deprecated = true
+	}
+
+	return typ, nil
+}
+
+// cutString slices s around the first instance of sep. It is a replacement for
+// strings.Cut which is not available in all supported versions of Go.
+func cutString(s, sep string) (string, string, bool) {
+	if i := strings.Index(s, sep); i >= 0 {
+		return s[:i], s[i+len(sep):], true
+	}
+
+	return s, "", false
+}
+
+// structField is a struct field that has an api tag.
+type structField struct {
+	reflect.StructField
+
+	// name is the member name of the field, which is the name option of the
+	// api tag if set, otherwise the name found in the json tag.
+	name string
+	tag  fieldTag
+}
+
+// apiFields returns the fields of the struct type t that have an api tag,
+// including the ones promoted from embedded structs. The Index of each returned
+// field is the full index sequence to be used with reflect.Value.FieldByIndex.
+//
+// An embedded struct without an api tag has its fields promoted. Like in Go, a
+// field hides the fields of the same name found deeper in embedded structs. An
+// error is returned if two fields of the same name are found at the same depth.
+// Fields tagged with api:"-" are ignored.
+func apiFields(t reflect.Type) ([]structField, error) {
+	type embedded struct {
+		typ   reflect.Type
+		index []int
+	}
+
+	var (
+		fields []structField
+		level  = []embedded{{typ: t}}
+		depths = map[string]int{}
+	)
+
+	for depth := 0; len(level) > 0; depth++ {
+		var next []embedded
+
+		for _, e := range level {
+			for i := 0; i < e.typ.NumField(); i++ {
+				sf := e.typ.Field(i)
+				apiTag := sf.Tag.Get("api")
+
+				index := make([]int, len(e.index)+1)
+				copy(index, e.index)
+				index[len(e.index)] = i
+
+				if sf.Anonymous && apiTag == "" && sf.Type.Kind() == reflect.Struct {
+					next = append(next, embedded{typ: sf.Type, index: index})
+					continue
+				}
+
+				if apiTag == "" || apiTag == "-" {
+					continue
+				}
+
+				tag, err := parseFieldTag(sf)
+				if err != nil {
+					return nil, err
+				}
+
+				name := tag.name
+				if name == "" {
+					name, _, _ = cutString(sf.Tag.Get("json"), ",")
+				}
+
+				if d, ok := depths[name]; ok {
+					if d < depth {
+						// Hidden by a field closer to the root.
+						continue
+					}
+
+					return nil, fmt.Errorf("jsonapi: field %q of struct %q is defined more than once",
+						name, t.Name())
+				}
+
+				depths[name] = depth
+				sf.Index = index
+				fields = append(fields, structField{
+					StructField: sf,
+					name:        name,
+					tag:         tag,
+				})
+			}
+		}
+
+		level = next
+	}
+
+	return fields, nil
+}
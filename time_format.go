@@ -0,0 +1,313 @@
+package jsonapi
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Formats of the attributes of type AttrTypeTime. The default format (an empty
+// Attr.Format) is an RFC 3339 datetime string.
+const (
+	// TimeFormatDate is a date string without time like "2006-01-02". Dates are
+	// parsed in UTC.
+	TimeFormatDate = "date"
+
+	// TimeFormatUnix is the number of seconds elapsed since January 1, 1970 UTC.
+	TimeFormatUnix = "unix"
+
+	// TimeFormatUnixMilli is the number of milliseconds elapsed since January 1,
+	// 1970 UTC.
+	TimeFormatUnixMilli = "unix-milli"
+)
+
+// Formats of the attributes of type AttrTypeDuration. The default format (an
+// empty Attr.Format) is a Go duration string like "1h30m0s".
+//
+// Both formats are accepted when unmarshaling, whatever the format of the
+// attribute is.
+const (
+	// DurationFormatISO8601 is an ISO 8601 duration like "PT1H30M". Years and
+	// months are not supported because their length varies.
+	DurationFormatISO8601 = "iso8601"
+)
+
+const dateLayout = "2006-01-02"
+
+// checkAttrFormat returns an error if the format of attr is not supported by
+// its type.
+//
+// The formats of the attribute types registered by the users are not checked.
+func checkAttrFormat(attr Attr) error {
+	if attr.Format == "" || attr.Type > 0 {
+		return nil
+	}
+
+	switch {
+	case attr.Type == AttrTypeTime && (attr.Format == TimeFormatDate ||
+		attr.Format == TimeFormatUnix || attr.Format == TimeFormatUnixMilli):
+		return nil
+	case attr.Type == AttrTypeDuration && attr.Format == DurationFormatISO8601:
+		return nil
+	}
+
+	return fmt.Errorf("jsonapi: format %q of attribute %q is not supported", attr.Format, attr.Name)
+}
+
+// parseTime parses the JSON value data as a time in the given format.
+func parseTime(data []byte, format string) (time.Time, error) {
+	var t time.Time
+
+	switch format {
+	case TimeFormatDate:
+		var s string
+		if err := json.Unmarshal(data, &s); err != nil {
+			return t, err
+		}
+
+		return time.ParseInLocation(dateLayout, s, time.UTC)
+	case TimeFormatUnix, TimeFormatUnixMilli:
+		n, err := strconv.ParseInt(string(data), 10, 64)
+		if err != nil {
+			return t, fmt.Errorf("%s is not an integer", data)
+		}
+
+		if format == TimeFormatUnix {
+			return time.Unix(n, 0).UTC(), nil
+		}
+
+		return time.Unix(n/1e3, (n%1e3)*1e6).UTC(), nil
+	}
+
+	err := json.Unmarshal(data, &t)
+
+	return t, err
+}
+
+// formatTime returns the JSON value of t in the given format.
+func formatTime(t time.Time, format string) []byte {
+	switch format {
+	case TimeFormatDate:
+		return []byte(`"` + t.Format(dateLayout) + `"`)
+	case TimeFormatUnix:
+		return strconv.AppendInt(nil, t.Unix(), 10)
+	case TimeFormatUnixMilli:
+		return strconv.AppendInt(nil, t.Unix()*1e3+int64(t.Nanosecond())/1e6, 10)
+	}
+
+	b, _ := t.MarshalJSON()
+
+	return b
+}
+
+// timesToUTC returns v, a time.Time, a []time.Time or a pointer to one of them,
+// with all the times converted to UTC. Other values are returned as is.
+func timesToUTC(v interface{}) interface{} {
+	switch v := v.(type) {
+	case time.Time:
+		return v.UTC()
+	case *time.Time:
+		if v == nil {
+			return v
+		}
+
+		t := v.UTC()
+
+		return &t
+	case []time.Time:
+		ts := make([]time.Time, len(v))
+		for i := range v {
+			ts[i] = v[i].UTC()
+		}
+
+		return ts
+	case *[]time.Time:
+		if v == nil {
+			return v
+		}
+
+		ts := timesToUTC(*v).([]time.Time)
+
+		return &ts
+	}
+
+	return v
+}
+
+// parseDuration parses the JSON string data as a Go or ISO 8601 duration.
+func parseDuration(data []byte) (time.Duration, error) {
+	var s string
+	if err := json.Unmarshal(data, &s); err != nil {
+		return 0, err
+	}
+
+	if strings.HasPrefix(s, "P") || strings.HasPrefix(s, "-P") {
+		return parseISO8601Duration(s)
+	}
+
+	return time.ParseDuration(s)
+}
+
+// formatDuration returns the JSON value of d in the given format.
+func formatDuration(d time.Duration, format string) []byte {
+	if format == DurationFormatISO8601 {
+		return []byte(`"` + formatISO8601Duration(d) + `"`)
+	}
+
+	return []byte(`"` + d.String() + `"`)
+}
+
+// parseISO8601Duration parses s as an ISO 8601 duration. Weeks and days are
+// supported, but not years and months.
+func parseISO8601Duration(s string) (time.Duration, error) {
+	invalid := fmt.Errorf("invalid ISO 8601 duration %q", s)
+
+	neg := strings.HasPrefix(s, "-")
+	if neg {
+		s = s[1:]
+	}
+
+	if len(s) < 2 || s[0] != 'P' {
+		return 0, invalid
+	}
+
+	var (
+		total  uint64
+		inTime bool
+		found  bool
+	)
+
+	for rest := s[1:]; rest != ""; {
+		if rest[0] == 'T' {
+			if inTime || len(rest) == 1 {
+				return 0, invalid
+			}
+
+			inTime = true
+			rest = rest[1:]
+
+			continue
+		}
+
+		i := strings.IndexFunc(rest, func(r rune) bool {
+			return (r < '0' || r > '9') && r != '.' && r != ','
+		})
+		if i <= 0 {
+			return 0, invalid
+		}
+
+		var unit time.Duration
+
+		switch c := rest[i]; {
+		case c == 'W' && !inTime:
+			unit = 7 * 24 * time.Hour
+		case c == 'D' && !inTime:
+			unit = 24 * time.Hour
+		case c == 'H' && inTime:
+			unit = time.Hour
+		case c == 'M' && inTime:
+			unit = time.Minute
+		case c == 'S' && inTime:
+			unit = time.Second
+		case c == 'Y' || c == 'M':
+			return 0, errors.New("years and months are not supported in ISO 8601 durations")
+		default:
+			return 0, invalid
+		}
+
+		v, err := durationValue(rest[:i], unit)
+		if err != nil || total+v < total || total+v > math.MaxInt64 {
+			return 0, invalid
+		}
+
+		total += v
+		found = true
+		rest = rest[i+1:]
+	}
+
+	if !found {
+		return 0, invalid
+	}
+
+	if neg {
+		return -time.Duration(total), nil
+	}
+
+	return time.Duration(total), nil
+}
+
+// durationValue returns the number of nanoseconds of num units. num is a
+// decimal number with a dot or a comma as separator.
+func durationValue(num string, unit time.Duration) (uint64, error) {
+	intPart, fracPart, _ := cutString(strings.Replace(num, ",", ".", 1), ".")
+
+	n, err := strconv.ParseUint(intPart, 10, 64)
+	if err != nil || strings.ContainsAny(fracPart, ".,") {
+		return 0, fmt.Errorf("invalid number %q", num)
+	}
+
+	if n > math.MaxInt64/uint64(unit) {
+		return 0, fmt.Errorf("number %q is too large", num)
+	}
+
+	v := n * uint64(unit)
+
+	// Digits beyond the nanosecond are ignored.
+	if len(fracPart) > 9 {
+		fracPart = fracPart[:9]
+	}
+
+	if fracPart != "" {
+		f, err := strconv.ParseUint(fracPart, 10, 64)
+		if err != nil {
+			return 0, fmt.Errorf("invalid number %q", num)
+		}
+
+		v += uint64(float64(f) / math.Pow10(len(fracPart)) * float64(unit))
+	}
+
+	return v, nil
+}
+
+// formatISO8601Duration formats d as an ISO 8601 duration using hours,
+// minutes and seconds.
+func formatISO8601Duration(d time.Duration) string {
+	if d == 0 {
+		return "PT0S"
+	}
+
+	var sb strings.Builder
+
+	u := uint64(d)
+	if d < 0 {
+		sb.WriteByte('-')
+
+		u = -u
+	}
+
+	sb.WriteString("PT")
+
+	if h := u / uint64(time.Hour); h > 0 {
+		sb.WriteString(strconv.FormatUint(h, 10) + "H")
+	}
+
+	if m := u / uint64(time.Minute) % 60; m > 0 {
+		sb.WriteString(strconv.FormatUint(m, 10) + "M")
+	}
+
+	if ns := u % uint64(time.Minute); ns > 0 {
+		sb.WriteString(strconv.FormatUint(ns/uint64(time.Second), 10))
+
+		if frac := ns % uint64(time.Second); frac > 0 {
+			sb.WriteString(strings.TrimRight(fmt.Sprintf(".%09d", frac), "0"))
+		}
+
+		sb.WriteByte('S')
+	}
+
+	return sb.String()
+}
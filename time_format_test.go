@@ -0,0 +1,222 @@
+package jsonapi_test
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	. "github.com/mark-hartmann/jsonapi"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestUnmarshalToTypeTimeFormats(t *testing.T) {
+	assert := assert.New(t)
+
+	date := time.Date(2021, 3, 14, 0, 0, 0, 0, time.UTC)
+	tm := time.Date(2021, 3, 14, 15, 9, 26, 535000000, time.UTC)
+
+	tests := []struct {
+		data     string
+		attr     Attr
+		expected interface{}
+	}{
+		{
+			data:     `"2021-03-14"`,
+			attr:     Attr{Type: AttrTypeTime, Format: TimeFormatDate},
+			expected: date,
+		}, {
+			data:     `["2021-03-14"]`,
+			attr:     Attr{Type: AttrTypeTime, Format: TimeFormatDate, Array: true},
+			expected: []time.Time{date},
+		}, {
+			data:     `1615734566`,
+			attr:     Attr{Type: AttrTypeTime, Format: TimeFormatUnix},
+			expected: tm.Truncate(time.Second),
+		}, {
+			data:     `1615734566535`,
+			attr:     Attr{Type: AttrTypeTime, Format: TimeFormatUnixMilli, Nullable: true},
+			expected: &tm,
+		}, {
+			data:     `"1h30m"`,
+			attr:     Attr{Type: AttrTypeDuration},
+			expected: 90 * time.Minute,
+		}, {
+			data:     `"-P1DT2H3M4.5S"`,
+			attr:     Attr{Type: AttrTypeDuration},
+			expected: -(26*time.Hour + 3*time.Minute + 4500*time.Millisecond),
+		}, {
+			data:     `["P2W", "PT0,25S", "0s"]`,
+			attr:     Attr{Type: AttrTypeDuration, Format: DurationFormatISO8601, Array: true},
+			expected: []time.Duration{14 * 24 * time.Hour, 250 * time.Millisecond, 0},
+		},
+	}
+
+	for _, test := range tests {
+		v, err := UnmarshalToType([]byte(test.data), test.attr)
+		assert.NoError(err, test.data)
+		assert.Equal(test.expected, v, test.data)
+	}
+
+	for _, test := range []struct {
+		data string
+		attr Attr
+	}{
+		{data: `"2021-03-14T15:09:26Z"`, attr: Attr{Type: AttrTypeTime, Format: TimeFormatDate}},
+		{data: `"2021-03-14"`, attr: Attr{Type: AttrTypeTime}},
+		{data: `1.5`, attr: Attr{Type: AttrTypeTime, Format: TimeFormatUnixMilli}},
+		{data: `90`, attr: Attr{Type: AttrTypeDuration}},
+		{data: `"P1Y"`, attr: Attr{Type: AttrTypeDuration}},
+		{data: `"P1H"`, attr: Attr{Type: AttrTypeDuration}},
+		{data: `"PT"`, attr: Attr{Type: AttrTypeDuration}},
+		{data: `"P"`, attr: Attr{Type: AttrTypeDuration}},
+		{data: `"PT1.2.3S"`, attr: Attr{Type: AttrTypeDuration}},
+		{data: `"P999999999999W"`, attr: Attr{Type: AttrTypeDuration}},
+	} {
+		_, err := UnmarshalToType([]byte(test.data), test.attr)
+		assert.Error(err, test.data)
+	}
+}
+
+func TestMarshalTimeFormats(t *testing.T) {
+	assert := assert.New(t)
+
+	typ := Type{Name: "events"}
+	typ.MustAddAttr(Attr{Name: "day", Type: AttrTypeTime, Format: TimeFormatDate})
+	typ.MustAddAttr(Attr{Name: "at", Type: AttrTypeTime, Format: TimeFormatUnixMilli})
+	typ.MustAddAttr(Attr{Name: "timeout", Type: AttrTypeDuration})
+	typ.MustAddAttr(Attr{
+		Name:     "delays",
+		Type:     AttrTypeDuration,
+		Format:   DurationFormatISO8601,
+		Array:    true,
+		Nullable: true,
+	})
+	typ.MustAddAttr(Attr{
+		Name:     "retry",
+		Type:     AttrTypeDuration,
+		Nullable: true,
+	})
+
+	assert.EqualError(
+		typ.AddAttr(Attr{Name: "bad", Type: AttrTypeString, Format: TimeFormatDate}),
+		`jsonapi: format "date" of attribute "bad" is not supported`,
+	)
+	assert.Error(typ.AddAttr(Attr{Name: "bad", Type: AttrTypeTime, Format: "iso8601"}))
+
+	tm := time.Date(2021, 3, 14, 15, 9, 26, 535000000, time.UTC)
+
+	res := typ.New()
+	res.Set("id", "e1")
+	res.Set("day", tm)
+	res.Set("at", tm)
+	res.Set("timeout", 90*time.Second)
+	res.Set("delays", &[]time.Duration{-time.Hour - time.Nanosecond, 0})
+
+	buf := &bytes.Buffer{}
+	assert.NoError(MarshalDocument(buf, &Document{Data: res}, nil))
+	assert.Contains(buf.String(), `"attributes":{"at":1615734566535,"day":"2021-03-14",`+
+		`"delays":["-PT1H0.000000001S","PT0S"],"retry":null,"timeout":"1m30s"}`)
+
+	// The payload can be unmarshaled again.
+	schema := &Schema{}
+	schema.MustAddType(typ)
+
+	doc, err := UnmarshalDocument(buf, schema)
+	assert.NoError(err)
+
+	res2 := doc.Data.(Resource)
+	assert.Equal(tm.Truncate(24*time.Hour), res2.Get("day"))
+	assert.Equal(tm, res2.Get("at"))
+	assert.Equal(90*time.Second, res2.Get("timeout"))
+	assert.Equal(&[]time.Duration{-time.Hour - time.Nanosecond, 0}, res2.Get("delays"))
+}
+
+func TestTimeFormatTag(t *testing.T) {
+	assert := assert.New(t)
+
+	type event struct {
+		ID      string        `json:"id" api:"events"`
+		Day     time.Time     `json:"day" api:"attr,format=date"`
+		Timeout time.Duration `json:"timeout" api:"attr,format=iso8601"`
+	}
+
+	typ, err := BuildType(event{})
+	assert.NoError(err)
+	assert.Equal(Attr{Name: "day", Type: AttrTypeTime, Format: TimeFormatDate}, typ.Attrs["day"])
+	assert.Equal(
+		Attr{Name: "timeout", Type: AttrTypeDuration, Format: DurationFormatISO8601},
+		typ.Attrs["timeout"],
+	)
+
+	type badEvent struct {
+		ID  string    `json:"id" api:"events"`
+		Day time.Time `json:"day" api:"attr,format=week"`
+	}
+
+	_, err = BuildType(badEvent{})
+	assert.Error(err)
+	assert.Panics(func() { Wrap(&badEvent{}) })
+}
+
+func TestTimeZones(t *testing.T) {
+	assert := assert.New(t)
+
+	typ := Type{Name: "events"}
+	typ.MustAddAttr(Attr{Name: "start", Type: AttrTypeTime})
+	typ.MustAddAttr(Attr{Name: "end", Type: AttrTypeTime, Nullable: true})
+	typ.MustAddAttr(Attr{Name: "days", Type: AttrTypeTime, Format: TimeFormatDate, Array: true})
+
+	schema := &Schema{}
+	schema.MustAddType(typ)
+
+	zone := time.FixedZone("UTC+2", 2*3600)
+	tm := time.Date(2021, 3, 14, 1, 30, 0, 0, zone)
+
+	res := typ.New()
+	res.Set("id", "e1")
+	res.Set("start", tm)
+	res.Set("end", &time.Time{})
+	res.Set("days", []time.Time{tm})
+
+	marshal := func(opts MarshalOptions) string {
+		buf := &bytes.Buffer{}
+		assert.NoError(opts.MarshalDocument(buf, &Document{Data: res}, nil))
+
+		return buf.String()
+	}
+
+	// The offsets are kept by default and a zero nullable time is null.
+	assert.Contains(marshal(MarshalOptions{}), `"attributes":{`+
+		`"days":["2021-03-14"],"end":null,"start":"2021-03-14T01:30:00+02:00"}`)
+
+	// UTC
+	assert.Contains(marshal(MarshalOptions{UTCTimes: true}), `"attributes":{`+
+		`"days":["2021-03-13"],"end":null,"start":"2021-03-13T23:30:00Z"}`)
+
+	// A non-zero nullable time is written.
+	res.Set("end", &tm)
+	assert.Contains(marshal(MarshalOptions{}), `"end":"2021-03-14T01:30:00+02:00"`)
+
+	// Unmarshaling
+	payload := []byte(`{
+		"id": "e1",
+		"type": "events",
+		"attributes": {
+			"start": "2021-03-14T01:30:00+02:00",
+			"end": "2021-03-14T01:30:00+02:00"
+		}
+	}`)
+
+	res2, err := UnmarshalResource(payload, schema)
+	assert.NoError(err)
+
+	_, offset := res2.Get("start").(time.Time).Zone()
+	assert.Equal(2*3600, offset)
+
+	res2, err = UnmarshalOptions{UTCTimes: true}.UnmarshalResource(payload, schema)
+	assert.NoError(err)
+	assert.Equal(tm.UTC(), res2.Get("start"))
+	assert.Equal(time.UTC, res2.Get("end").(*time.Time).Location())
+	assert.True(tm.Equal(*res2.Get("end").(*time.Time)))
+}
@@ -35,7 +35,7 @@ const (
 	AttrTypeBool
 
 	// AttrTypeTime corresponds to the go-type time.Time and output the time as an
-	// RFC 3339 datetime string.
+	// RFC 3339 datetime string, unless another format is set (see Attr.Format).
 	AttrTypeTime
 
 	// AttrTypeBytes is a special attribute type which represents a byte/uint8 array as a
@@ -43,27 +43,28 @@ const (
 	// displayed as number array, AttrTypeUint8 must be used. AttrTypeBytes is always
 	// processed as an array, even if Attr.Array is false.
 	AttrTypeBytes
-)
 
-var memberRegexp = regexp.MustCompile(`^[a-zA-Z0-9](?:[-\w]*[a-zA-Z0-9])?$`)
+	// AttrTypeDecimal corresponds to the go-type Decimal, an arbitrary-precision decimal
+	// number. It is output as a JSON string by default to avoid any loss of precision, see
+	// MarshalOptions.DecimalAsNumber. Both strings and numbers are accepted as input.
+	AttrTypeDecimal
 
-// uint8Array is used to marshal *[]uint8 or []byte as literal array instead of
-// a base64 encoded string value.
-type uint8Array struct {
-	Data     *[]uint8
-	Nullable bool
-}
+	// AttrTypeUUID corresponds to the go-type UUID and is represented as a string in its
+	// canonical form. Struct fields of any other 16 bytes array type (like the UUID types of
+	// the common UUID packages) or strings with the api tag type "uuid" are supported too.
+	AttrTypeUUID
 
-func (u uint8Array) MarshalJSON() ([]byte, error) {
-	var result string
-	if u.Data == nil {
-		result = "null"
-	} else {
-		result = strings.Join(strings.Fields(fmt.Sprintf("%d", *u.Data)), ",")
-	}
+	// AttrTypeJSON corresponds to the go-type json.RawMessage and holds any JSON value, which
+	// is written as it is in the generated json responses. Struct fields of other types (like
+	// map[string]interface{}) are supported too and set by unmarshaling the raw value.
+	AttrTypeJSON
 
-	return []byte(result), nil
-}
+	// AttrTypeDuration corresponds to the go-type time.Duration and is represented as a Go
+	// duration string like "1h30m0s" or as an ISO 8601 duration (see Attr.Format).
+	AttrTypeDuration
+)
+
+var memberRegexp = regexp.MustCompile(`^[a-zA-Z0-9](?:[-\w]*[a-zA-Z0-9])?$`)
 
 // A Type stores all the necessary information about a type as represented in
 // the JSON:API specification.
@@ -81,6 +82,13 @@ type Type struct {
 	Attrs   map[string]Attr
 	Rels    map[string]Rel
 	NewFunc func() Resource
+
+	// Description, Example and Deprecated document the type. They are not
+	// used by the marshaling and the unmarshaling, only by tools that
+	// generate documentation from a schema.
+	Description string
+	Example     string
+	Deprecated  bool
 }
 
 // AddAttr adds an attributes to the type.
@@ -102,10 +110,14 @@ func (t *Type) AddAttr(attr Attr) error {
 		return fmt.Errorf("jsonapi: cannot add attribute with type AttrTypeInvalid")
 	}
 
-	if !attrTypeRegistered(attr.Type) {
+	if !attrTypeRegistered(attr.Type) && attr.Unmarshaler == nil {
 		return fmt.Errorf("jsonapi: attribute type %q is unknown", attr.Type)
 	}
 
+	if err := checkAttrFormat(attr); err != nil {
+		return err
+	}
+
 	// Make sure the name isn't already used
 	for i := range t.Attrs {
 		if t.Attrs[i].Name == attr.Name {
@@ -122,6 +134,13 @@ func (t *Type) AddAttr(attr Attr) error {
 	return nil
 }
 
+// MustAddAttr calls AddAttr and panics if an error is returned.
+func (t *Type) MustAddAttr(attr Attr) {
+	if err := t.AddAttr(attr); err != nil {
+		panic(err)
+	}
+}
+
 // RemoveAttr removes an attribute from the type if it exists.
 func (t *Type) RemoveAttr(attr string) {
 	for i := range t.Attrs {
@@ -164,6 +183,13 @@ func (t *Type) AddRel(rel Rel) error {
 	return nil
 }
 
+// MustAddRel calls AddRel and panics if an error is returned.
+func (t *Type) MustAddRel(rel Rel) {
+	if err := t.AddRel(rel); err != nil {
+		panic(err)
+	}
+}
+
 // RemoveRel removes a relationship from the type if it exists.
 func (t *Type) RemoveRel(rel string) {
 	for i := range t.Rels {
@@ -207,16 +233,54 @@ func (t *Type) New() Resource {
 func (t Type) Equal(typ Type) bool {
 	t.NewFunc = nil
 	typ.NewFunc = nil
+	t.Attrs = attrsWithoutFuncs(t.Attrs)
+	typ.Attrs = attrsWithoutFuncs(typ.Attrs)
 
 	return reflect.DeepEqual(t, typ)
 }
 
+// attrsWithoutFuncs returns a copy of attrs where the transformers are removed,
+// since they usually hold functions, which cannot be compared. attrs is
+// returned as it is if none of its attributes has one.
+func attrsWithoutFuncs(attrs map[string]Attr) map[string]Attr {
+	found := false
+
+	for _, attr := range attrs {
+		if attr.Transformer != nil {
+			found = true
+			break
+		}
+	}
+
+	if !found {
+		return attrs
+	}
+
+	cattrs := make(map[string]Attr, len(attrs))
+
+	for name, attr := range attrs {
+		attr.Transformer = nil
+		cattrs[name] = attr
+	}
+
+	return cattrs
+}
+
 // Copy deeply copies the receiver and returns the result.
+//
+// Functions cannot be copied, so NewFunc and the hooks of the attributes (like
+// Marshaler or Transformer) are shared with the copy, along with the values
+// they capture. A NewFunc that returns resources pointing to a *Type, like a
+// SoftResource, still points them to the original type, which is why such a
+// NewFunc should give each resource its own copy of the type.
 func (t Type) Copy() Type {
 	ctyp := Type{
-		Name:  t.Name,
-		Attrs: map[string]Attr{},
-		Rels:  map[string]Rel{},
+		Name:        t.Name,
+		Attrs:       map[string]Attr{},
+		Rels:        map[string]Rel{},
+		Description: t.Description,
+		Example:     t.Example,
+		Deprecated:  t.Deprecated,
 	}
 
 	for name, attr := range t.Attrs {
@@ -233,11 +297,54 @@ func (t Type) Copy() Type {
 }
 
 // Attr represents a resource attribute.
+//
+// Format selects an alternative representation for the attribute types that
+// support it, like TimeFormatDate for AttrTypeTime. It is used both when
+// marshaling and unmarshaling. An empty Format means the default one.
+//
+// ReadOnly marks an attribute that cannot be modified by the clients. It is
+// honored by ApplyPartial.
+//
+// OmitEmpty omits the attribute from the attributes object of a marshaled
+// resource when its value is empty, even if the sparse fieldset includes it.
+// A value is empty if it is nil, an empty string, slice or map, or the zero
+// value of its type (false, 0, a zero time.Time, a zero Decimal, etc). The
+// value of a nullable attribute is only empty if it is nil, since a pointer to
+// a zero value is an explicit value. The attributes object itself is omitted
+// if no attribute is left.
+//
+// Unmarshaler and Marshaler customize the handling of the values of a single
+// attribute. If set, Unmarshaler provides the zero values and unmarshals the
+// payloads instead of the functions of the registered attribute type, which
+// then does not have to be registered. It is used by UnmarshalToType,
+// SoftResource and Wrapper. Marshaler is used by MarshalResource and the other
+// marshaling functions to encode the values.
+//
+// Transformer changes the values of a single attribute without a custom
+// attribute type, like for field-level encryption or normalization. Its
+// TransformIn method is called on the unmarshaled values of the payloads
+// before they are set, and TransformOut on the values of the resources before
+// they are marshaled. The values they return must have the Go type of the
+// attribute.
 type Attr struct {
-	Name     string
-	Type     int
-	Nullable bool
-	Array    bool
+	Name      string
+	Type      int
+	Nullable  bool
+	Array     bool
+	Format    string
+	ReadOnly  bool
+	OmitEmpty bool
+
+	Unmarshaler TypeUnmarshaler
+	Marshaler   TypeMarshaler
+
+	Transformer Transformer
+
+	// Description, Example and Deprecated document the attribute (see
+	// Type.Description).
+	Description string
+	Example     string
+	Deprecated  bool
 }
 
 // Rel represents a resource relationship.
@@ -248,6 +355,13 @@ type Rel struct {
 	ToType   string
 	ToName   string
 	FromOne  bool
+
+	// Description, Example and Deprecated document the relationship (see
+	// Type.Description). They are not carried over to the inverse
+	// relationship.
+	Description string
+	Example     string
+	Deprecated  bool
 }
 
 // Invert returns the inverse relationship of r.
@@ -265,12 +379,17 @@ func (r *Rel) Invert() Rel {
 // Normalize inverts the relationship if necessary in order to have it in the
 // right direction and returns the result.
 //
+// The direction is given by the type names and, for a relationship between two
+// resources of the same type, by the relationship names. A relationship that
+// is its own inverse, like friends, is returned as it is.
+//
 // This is the form stored in Schema.Rels.
 func (r *Rel) Normalize() Rel {
-	from := r.FromType + r.FromName
-	to := r.ToType + r.ToName
+	if r.ToName == "" || r.FromType < r.ToType {
+		return *r
+	}
 
-	if from < to || r.ToName == "" {
+	if r.FromType == r.ToType && r.FromName <= r.ToName {
 		return *r
 	}
 
@@ -303,8 +422,26 @@ type SortRule struct {
 	Desc bool
 }
 
+// String returns the rule as it is written in the sort query parameter, like
+// "-author.name".
+func (r SortRule) String() string {
+	names := make([]string, 0, len(r.Path)+1)
+	for _, rel := range r.Path {
+		names = append(names, rel.FromName)
+	}
+
+	rule := strings.Join(append(names, r.Name), ".")
+	if r.Desc {
+		rule = "-" + rule
+	}
+
+	return rule
+}
+
 // ParseSortRule parses a string to a SortRule using the Schema. If the sort rule contains a
 // relationship path, it is checked for correctness and simplified if possible.
+//
+// The names of the rule are member names (see Schema.NameMapper).
 func ParseSortRule(schema *Schema, typ Type, rule string) (SortRule, error) {
 	sr := SortRule{}
 
@@ -321,7 +458,7 @@ func ParseSortRule(schema *Schema, typ Type, rule string) (SortRule, error) {
 	path := make([]Rel, 0, len(parts)-1)
 
 	for i := 0; i < len(parts)-1; i++ {
-		rel, ok := typ.Rels[parts[i]]
+		rel, ok := typ.Rels[schema.fieldNameOrMember(typ)(parts[i])]
 		if !ok {
 			return sr, &UnknownFieldError{
 				Type:    typ.Name,
@@ -346,11 +483,13 @@ func ParseSortRule(schema *Schema, typ Type, rule string) (SortRule, error) {
 		typ = schema.GetType(rel.ToType)
 	}
 
-	sr.Name = parts[len(parts)-1]
+	last := parts[len(parts)-1]
+
+	sr.Name = schema.fieldNameOrMember(typ)(last)
 	if _, ok := typ.Attrs[sr.Name]; !ok && sr.Name != "id" {
 		return sr, &UnknownFieldError{
 			Type:    typ.Name,
-			Field:   sr.Name,
+			Field:   last,
 			relPath: relPath(rule),
 		}
 	}
@@ -417,6 +556,15 @@ func GetAttrType(t string) (typ int, array bool, nullable bool) {
 		return AttrTypeBool, array, nullable
 	case "time.Time":
 		return AttrTypeTime, array, nullable
+	case "time.Duration":
+		return AttrTypeDuration, array, nullable
+	case "jsonapi.Decimal":
+		return AttrTypeDecimal, array, nullable
+	case "jsonapi.UUID", "uuid.UUID":
+		return AttrTypeUUID, array, nullable
+	// json.RawMessage is an alias of jsontext.Value when the json/v2 experiment is enabled.
+	case "json.RawMessage", "jsontext.Value", "map[string]interface {}":
+		return AttrTypeJSON, array, nullable
 	default:
 		return AttrTypeInvalid, array, nullable
 	}
@@ -428,7 +576,7 @@ func isNil(v interface{}) bool {
 	}
 
 	switch reflect.TypeOf(v).Kind() {
-	case reflect.Ptr, reflect.Map, reflect.Array, reflect.Chan, reflect.Slice:
+	case reflect.Ptr, reflect.Map, reflect.Chan, reflect.Slice:
 		return reflect.ValueOf(v).IsNil()
 	}
 
@@ -161,6 +161,26 @@ func TestType_AddRel(t *testing.T) {
 	assert.Error(t, err)
 }
 
+func TestTypeMustAdd(t *testing.T) {
+	assert := assert.New(t)
+
+	typ := &Type{Name: "type1"}
+
+	assert.NotPanics(func() {
+		typ.MustAddAttr(Attr{Name: "attr1", Type: AttrTypeString})
+		typ.MustAddRel(Rel{FromName: "rel1", ToType: "type1"})
+	})
+	assert.Contains(typ.Attrs, "attr1")
+	assert.Contains(typ.Rels, "rel1")
+
+	assert.PanicsWithError(`jsonapi: attribute name "attr1" is already used`, func() {
+		typ.MustAddAttr(Attr{Name: "attr1", Type: AttrTypeString})
+	})
+	assert.PanicsWithError(`jsonapi: relationship name "rel1" is already used`, func() {
+		typ.MustAddRel(Rel{FromName: "rel1", ToType: "type1"})
+	})
+}
+
 // TODO Add tests with attributes and relationships.
 func TestTypeEqual(t *testing.T) {
 	assert := assert.New(t)
@@ -262,6 +282,39 @@ func TestRelNormalize(t *testing.T) {
 	assert.Equal(false, norm.FromOne)
 }
 
+func TestRelNormalizeSelf(t *testing.T) {
+	assert := assert.New(t)
+
+	manager := Rel{
+		FromType: "people",
+		FromName: "manager",
+		ToOne:    true,
+		ToType:   "people",
+		ToName:   "reports",
+	}
+	reports := manager.Invert()
+
+	assert.Equal(manager, manager.Normalize())
+	assert.Equal(manager, reports.Normalize())
+	assert.Equal("people_manager_people_reports", reports.String())
+
+	// A relationship that is its own inverse stays the same.
+	friends := Rel{
+		FromType:    "people",
+		FromName:    "friends",
+		ToType:      "people",
+		ToName:      "friends",
+		Description: "The friends of the person.",
+	}
+
+	assert.Equal(friends, friends.Normalize())
+	assert.Equal("people_friends_people_friends", friends.String())
+
+	// The names are not concatenated before being compared.
+	rel := Rel{FromType: "ab", FromName: "c", ToType: "a", ToName: "bd"}
+	assert.Equal("a", rel.Normalize().FromType)
+}
+
 func TestRelString(t *testing.T) {
 	assert := assert.New(t)
 
@@ -0,0 +1,201 @@
+package jsonapi
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// UnmarshalOptions configures how payloads are unmarshaled.
+//
+// The zero value unmarshals payloads like the UnmarshalDocument,
+// UnmarshalResource, UnmarshalPartialResource and UnmarshalCollection
+// functions do.
+type UnmarshalOptions struct {
+	// CollectFieldErrors makes the unmarshaling go through all the fields of
+	// the resources instead of stopping at the first invalid one. All the
+	// errors found in the fields are returned as FieldErrors.
+	CollectFieldErrors bool
+
+	// UnknownFields defines what happens to the attributes and relationships
+	// of a payload that do not exist in the type of the resource. They are
+	// rejected by default.
+	UnknownFields UnknownFieldPolicy
+
+	// Numbers defines which values are accepted for the numeric attributes.
+	// Only plain JSON numbers of the right form are accepted by default.
+	Numbers NumberCoercion
+
+	// UTCTimes converts the values of the attributes of type AttrTypeTime to
+	// UTC. By default, they keep the offsets found in the payload.
+	UTCTimes bool
+
+	// CheckMetaNames makes the unmarshaling fail if a meta object of the
+	// payload, or an object inside it, has a member whose name does not meet
+	// the member name requirements. The names of the attributes and the
+	// relationships are always checked.
+	CheckMetaNames bool
+
+	// CollectIncludedErrors makes UnmarshalDocument go through all the
+	// included resources instead of stopping at the first invalid one. Each
+	// invalid resource is reported as an *IncludedError in a FieldErrors, or
+	// as one *IncludedError per field error if CollectFieldErrors is also
+	// set.
+	CollectIncludedErrors bool
+
+	// Policy defines which violations of the specification are rejected by
+	// UnmarshalDocument.
+	Policy UnmarshalPolicy
+
+	// KeepLinks passes the links of the resources found in the payload to the
+	// resources that implement LinkHolder, and makes the unmarshaling fail if
+	// they are invalid. Links are set by servers, so they are ignored by
+	// default: a client is not expected to send them in a request.
+	KeepLinks bool
+
+	// ctx is checked between the resources of the payload by
+	// UnmarshalDocumentContext.
+	ctx context.Context
+}
+
+// UnknownFieldPolicy defines how unknown attributes and relationships are
+// handled when a resource is unmarshaled.
+type UnknownFieldPolicy int
+
+// Unknown field policies
+const (
+	// UnknownFieldsReject makes the unmarshaling fail with an
+	// *UnknownFieldError.
+	UnknownFieldsReject UnknownFieldPolicy = iota
+	// UnknownFieldsReport skips the unknown fields and reports them to the
+	// resources that implement SkippedFieldsHolder, and in the SkippedFields
+	// of the documents returned by UnmarshalDocument.
+	UnknownFieldsReport
+	// UnknownFieldsIgnore silently skips the unknown fields.
+	UnknownFieldsIgnore
+)
+
+// SkippedFieldsHolder can be implemented by a resource to know which fields of
+// a payload were skipped because they are unknown to its type. It is used when
+// UnmarshalOptions.UnknownFields is UnknownFieldsReport, which allows a client
+// to keep working with a server that has more fields than it knows about.
+//
+// SetSkippedFields is only called if at least one field was skipped. The
+// attributes come first, then the relationships, each sorted by name.
+type SkippedFieldsHolder interface {
+	SkippedFields() []*UnknownFieldError
+	SetSkippedFields(fields []*UnknownFieldError)
+}
+
+// FieldErrors is returned when UnmarshalOptions.CollectFieldErrors is set and
+// one or more fields of a resource or a collection are invalid. It also holds
+// the errors of the included resources when
+// UnmarshalOptions.CollectIncludedErrors is set.
+//
+// Each error has its own source pointer, so ErrorMapper.MapAll can turn them
+// into a list of error objects.
+type FieldErrors []error
+
+// Error returns the messages of all the errors separated by semicolons.
+func (e FieldErrors) Error() string {
+	msgs := make([]string, 0, len(e))
+	for _, err := range e {
+		msgs = append(msgs, err.Error())
+	}
+
+	return strings.Join(msgs, "; ")
+}
+
+// An IncludedError is an error found in the included resource at Index of a
+// compound document.
+//
+// Its source pointer is the one of the error relative to the document, like
+// /included/3/attributes/title.
+type IncludedError struct {
+	Index int
+	err   error
+}
+
+func (e *IncludedError) Error() string {
+	return fmt.Sprintf("jsonapi: failed to unmarshal included resource at %d: %s",
+		e.Index, e.err)
+}
+
+func (e *IncludedError) Unwrap() error {
+	return e.err
+}
+
+// Source returns the JSON pointer to the part of the document that caused the
+// error.
+func (e *IncludedError) Source() (string, bool) {
+	src := fmt.Sprintf("/included/%d", e.Index)
+	if s, isPtr, ok := errSrc(e.err); ok && isPtr {
+		src += s
+	}
+
+	return src, true
+}
+
+// setSkippedFields reports the skipped fields to res if it implements
+// SkippedFieldsHolder and the options require it. skipped is sorted in place.
+func (o UnmarshalOptions) setSkippedFields(res Resource, skipped []*UnknownFieldError) {
+	if o.UnknownFields != UnknownFieldsReport || len(skipped) == 0 {
+		return
+	}
+
+	sort.Slice(skipped, func(i, j int) bool {
+		if skipped[i].IsAttr() != skipped[j].IsAttr() {
+			return skipped[i].IsAttr()
+		}
+
+		return skipped[i].Field < skipped[j].Field
+	})
+
+	if sfh, ok := res.(SkippedFieldsHolder); ok {
+		sfh.SetSkippedFields(skipped)
+	}
+}
+
+// skippedErrors returns the skipped fields of the resource object found at
+// src as errors whose source pointers are relative to the document, or nil if
+// the options do not require them to be reported.
+func (o UnmarshalOptions) skippedErrors(src string, skipped []*UnknownFieldError) FieldErrors {
+	if o.UnknownFields != UnknownFieldsReport || len(skipped) == 0 {
+		return nil
+	}
+
+	errs := make(FieldErrors, len(skipped))
+
+	for i, ufe := range skipped {
+		member := "/attributes"
+		if !ufe.IsAttr() {
+			member = "/relationships"
+		}
+
+		errs[i] = &srcError{ptr: true, src: src + member, error: ufe}
+	}
+
+	return errs
+}
+
+// sort sorts the errors by source pointer.
+func (e FieldErrors) sort() {
+	sort.SliceStable(e, func(i, j int) bool {
+		src1, _, _ := errSrc(e[i])
+		src2, _, _ := errSrc(e[j])
+
+		return src1 < src2
+	})
+}
+
+// withPrefix returns a copy of e where src is added in front of the source
+// pointer of each error.
+func (e FieldErrors) withPrefix(src string) FieldErrors {
+	errs := make(FieldErrors, len(e))
+	for i := range e {
+		errs[i] = &srcError{ptr: true, src: src, error: e[i]}
+	}
+
+	return errs
+}
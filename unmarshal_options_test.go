@@ -0,0 +1,305 @@
+package jsonapi_test
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	. "github.com/mark-hartmann/jsonapi"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestUnmarshalOptionsCollectFieldErrors(t *testing.T) {
+	assert := assert.New(t)
+
+	schema := newMockSchema()
+	opts := UnmarshalOptions{CollectFieldErrors: true}
+
+	payload := `{
+		"id": "id1",
+		"type": "mocktypes1",
+		"attributes": {
+			"str": 1,
+			"int": "a",
+			"bool": true,
+			"unknown": 1
+		},
+		"relationships": {
+			"to-one": {"data": 123},
+			"unknown": {"data": null}
+		}
+	}`
+
+	// Without the option, the first error is returned.
+	_, err := UnmarshalResource([]byte(payload), schema)
+	assert.Error(err)
+
+	var fieldErrs FieldErrors
+
+	assert.False(errors.As(err, &fieldErrs))
+
+	// Resource
+	_, err = opts.UnmarshalResource([]byte(payload), schema)
+	assert.True(errors.As(err, &fieldErrs))
+	assert.Len(fieldErrs, 5)
+
+	var unknownField *UnknownFieldError
+
+	assert.True(errors.As(fieldErrs[0], &unknownField))
+
+	list := (&ErrorMapper{}).MapAll(err)
+	assert.Len(list.Errors(), 5)
+
+	ptrs := make([]interface{}, 0, len(list.Errors()))
+	for _, e := range list.Errors() {
+		ptrs = append(ptrs, e.Source["pointer"])
+	}
+
+	assert.Equal([]interface{}{
+		"/attributes",
+		"/attributes/int",
+		"/attributes/str",
+		"/relationships",
+		"/relationships/to-one",
+	}, ptrs)
+
+	_, err = opts.UnmarshalPartialResource([]byte(payload), schema)
+	assert.True(errors.As(err, &fieldErrs))
+	assert.Len(fieldErrs, 5)
+	assert.Contains(err.Error(), `field "unknown" does not exist`)
+
+	// Valid resource
+	res, err := opts.UnmarshalResource([]byte(`{
+		"id": "id1",
+		"type": "mocktypes1",
+		"attributes": {"str": "a", "int": 1}
+	}`), schema)
+	assert.NoError(err)
+	assert.Equal("a", res.Get("str"))
+
+	// Document
+	doc := `{
+		"data": [
+			{"id": "id1", "type": "mocktypes1", "attributes": {"int": "a"}},
+			{"id": "id2", "type": "mocktypes1", "attributes": {"str": "b"}},
+			{"id": "id3", "type": "mocktypes1", "attributes": {"str": 1, "int": true}}
+		],
+		"included": [
+			{"id": "id4", "type": "mocktypes2", "attributes": {"boolptr": 1}}
+		]
+	}`
+
+	_, err = opts.UnmarshalDocument(strings.NewReader(doc), schema)
+	assert.True(errors.As(err, &fieldErrs))
+
+	list = (&ErrorMapper{}).MapAll(err)
+	ptrs = ptrs[:0]
+
+	for _, e := range list.Errors() {
+		ptrs = append(ptrs, e.Source["pointer"])
+	}
+
+	assert.Equal([]interface{}{
+		"/data/0/attributes/int",
+		"/data/2/attributes/int",
+		"/data/2/attributes/str",
+		"/included/0/attributes/boolptr",
+	}, ptrs)
+
+	// Map only converts the first error.
+	e := (&ErrorMapper{}).Map(err)
+	assert.Equal("/data/0/attributes/int", e.Source["pointer"])
+
+	// Other errors are still returned right away.
+	_, err = opts.UnmarshalDocument(strings.NewReader(`{"data": 1}`), schema)
+	assert.False(errors.As(err, &fieldErrs))
+}
+
+func TestUnmarshalOptionsUnknownFields(t *testing.T) {
+	assert := assert.New(t)
+
+	schema := newMockSchema()
+
+	payload := []byte(`{
+		"id": "id1",
+		"type": "mocktypes1",
+		"attributes": {
+			"str": "abc",
+			"unknown2": 1,
+			"unknown1": 2
+		},
+		"relationships": {
+			"to-one": {"data": {"type": "mocktypes2", "id": "id2"}},
+			"unknown3": {"data": null}
+		}
+	}`)
+
+	// Strict mode
+	_, err := UnmarshalResource(payload, schema)
+
+	var unknownField *UnknownFieldError
+
+	assert.True(errors.As(err, &unknownField))
+
+	// Report
+	opts := UnmarshalOptions{UnknownFields: UnknownFieldsReport}
+
+	res, err := opts.UnmarshalPartialResource(payload, schema)
+	assert.NoError(err)
+	assert.Equal("abc", res.Get("str"))
+	assert.Equal("id2", res.Get("to-one"))
+
+	skipped := res.SkippedFields()
+	fields := make([]string, 0, len(skipped))
+
+	for _, f := range skipped {
+		assert.Equal("mocktypes1", f.Type)
+		fields = append(fields, f.Field)
+	}
+
+	assert.Equal([]string{"unknown1", "unknown2", "unknown3"}, fields)
+	assert.True(skipped[0].IsAttr())
+	assert.False(skipped[2].IsAttr())
+
+	// Wrappers
+	wres, err := opts.UnmarshalResource(payload, schema)
+	assert.NoError(err)
+	assert.Equal(skipped, wres.(*Wrapper).SkippedFields())
+
+	// Resources that do not implement SkippedFieldsHolder
+	mr, err := WrapMap(&Type{Name: "mocktypes1"}, map[string]interface{}{"id": ""})
+	assert.NoError(err)
+
+	mschema := &Schema{}
+	mschema.MustAddType(Type{Name: "mocktypes1", NewFunc: func() Resource { return mr }})
+
+	_, err = opts.UnmarshalResource([]byte(`{"id":"id1","type":"mocktypes1",`+
+		`"attributes":{"unknown":1}}`), mschema)
+	assert.NoError(err)
+
+	// Documents
+	doc, err := opts.UnmarshalDocument(strings.NewReader(`{
+		"data": [`+string(payload)+`],
+		"included": [
+			{"id": "id2", "type": "mocktypes2", "attributes": {"unknown4": 1}}
+		]
+	}`), schema)
+	assert.NoError(err)
+
+	srcs := make([]string, 0, len(doc.SkippedFields))
+
+	for _, err := range doc.SkippedFields {
+		srcs = append(srcs, (&ErrorMapper{}).Map(err).Source["pointer"].(string))
+	}
+
+	assert.Equal([]string{
+		"/data/0/attributes",
+		"/data/0/attributes",
+		"/data/0/relationships",
+		"/included/0/attributes",
+	}, srcs)
+	assert.True(errors.As(doc.SkippedFields[3], &unknownField))
+	assert.Equal("unknown4", unknownField.Field)
+
+	doc, err = opts.UnmarshalDocument(strings.NewReader(`{"data":`+string(payload)+`}`),
+		schema)
+	assert.NoError(err)
+	assert.Len(doc.SkippedFields, 3)
+
+	// Ignore
+	opts.UnknownFields = UnknownFieldsIgnore
+
+	res, err = opts.UnmarshalPartialResource(payload, schema)
+	assert.NoError(err)
+	assert.Nil(res.SkippedFields())
+	assert.Equal("abc", res.Get("str"))
+
+	doc, err = opts.UnmarshalDocument(strings.NewReader(`{"data":`+string(payload)+`}`),
+		schema)
+	assert.NoError(err)
+	assert.Nil(doc.SkippedFields)
+
+	// Other errors are still reported.
+	opts.CollectFieldErrors = true
+
+	_, err = opts.UnmarshalResource([]byte(`{
+		"id": "id1",
+		"type": "mocktypes1",
+		"attributes": {"str": 1, "unknown": 1}
+	}`), schema)
+
+	var fieldErrs FieldErrors
+
+	assert.True(errors.As(err, &fieldErrs))
+	assert.Len(fieldErrs, 1)
+}
+
+func TestUnmarshalOptionsCollectIncludedErrors(t *testing.T) {
+	assert := assert.New(t)
+
+	schema := newMockSchema()
+	payload := `{
+		"data": {"id": "id1", "type": "mocktypes1"},
+		"included": [
+			{"id": "id2", "type": "mocktypes2", "attributes": {"strptr": 1, "intptr": "a"}},
+			{"id": "id3", "type": "mocktypes2"},
+			{"id": "id4", "type": "mocktypes2", "relationships": {"to-one-from-one": {"data": 1}}},
+			{"id": "id3", "type": "mocktypes2"}
+		]
+	}`
+
+	// Without the option, the first error is returned.
+	_, err := UnmarshalDocument(strings.NewReader(payload), schema)
+
+	var fieldErrs FieldErrors
+
+	assert.False(errors.As(err, &fieldErrs))
+
+	// All the included resources are checked.
+	opts := UnmarshalOptions{CollectIncludedErrors: true}
+	_, err = opts.UnmarshalDocument(strings.NewReader(payload), schema)
+
+	if assert.True(errors.As(err, &fieldErrs)) && assert.Len(fieldErrs, 3) {
+		indexes := make([]int, 0, len(fieldErrs))
+
+		for _, err := range fieldErrs {
+			var ie *IncludedError
+			if assert.True(errors.As(err, &ie)) {
+				indexes = append(indexes, ie.Index)
+			}
+		}
+
+		assert.Equal([]int{0, 2, 3}, indexes)
+		assert.True(errors.Is(fieldErrs[2], ErrInvalidPayload))
+		assert.Contains(fieldErrs[2].Error(), "failed to unmarshal included resource at 3")
+	}
+
+	list := (&ErrorMapper{}).MapAll(err)
+
+	ptrs := make([]string, 0, len(list.Errors()))
+	for _, e := range list.Errors() {
+		ptrs = append(ptrs, e.Src.Pointer)
+	}
+
+	assert.Len(ptrs, 3)
+	assert.Contains(ptrs[0], "/included/0/attributes/")
+	assert.Equal([]string{"/included/2/relationships/to-one-from-one", "/included/3"}, ptrs[1:])
+
+	// One error per field with CollectFieldErrors
+	opts.CollectFieldErrors = true
+	_, err = opts.UnmarshalDocument(strings.NewReader(payload), schema)
+	list = (&ErrorMapper{}).MapAll(err)
+
+	ptrs = ptrs[:0]
+	for _, e := range list.Errors() {
+		ptrs = append(ptrs, e.Src.Pointer)
+	}
+
+	assert.Equal([]string{
+		"/included/0/attributes/intptr",
+		"/included/0/attributes/strptr",
+		"/included/2/relationships/to-one-from-one",
+		"/included/3",
+	}, ptrs)
+}
@@ -0,0 +1,125 @@
+package jsonapi
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// A ViolationPolicy defines what happens when a payload violates a rule of the
+// specification (see UnmarshalPolicy).
+type ViolationPolicy int
+
+// Violation policies
+const (
+	// ViolationDefault applies the default policy of the rule.
+	ViolationDefault ViolationPolicy = iota
+	// ViolationReject makes the unmarshaling fail.
+	ViolationReject
+	// ViolationWarn tolerates the violation and passes it to
+	// UnmarshalPolicy.Warn.
+	ViolationWarn
+	// ViolationIgnore silently tolerates the violation.
+	ViolationIgnore
+)
+
+// UnmarshalPolicy defines which violations of the specification are rejected
+// by UnmarshalDocument and which ones are tolerated.
+//
+// Servers usually want to reject anything that does not conform, while proxies
+// and tolerant clients need to accept payloads from servers that do not fully
+// conform. The zero value keeps the default policy of each rule.
+type UnmarshalPolicy struct {
+	// IncludedWithoutData applies to a document with an included member but
+	// no data member. It is rejected by default. If it is tolerated, the
+	// included resources are unmarshaled anyway.
+	IncludedWithoutData ViolationPolicy
+
+	// EmptyErrorsWithData applies to a document with a data member and an
+	// errors member that is an empty array. It is rejected by default. If it
+	// is tolerated, the errors member is dropped.
+	EmptyErrorsWithData ViolationPolicy
+
+	// UnknownMembers applies to the top-level members that are not defined
+	// by the specification. They are ignored by default. The members of
+	// extensions (whose names contain a colon) and the @-members are always
+	// accepted.
+	UnknownMembers ViolationPolicy
+
+	// Warn is called with each tolerated violation whose policy is
+	// ViolationWarn. The errors are marked with ErrInvalidPayload.
+	Warn func(err error)
+}
+
+// check returns err if the violation must be rejected according to p, or
+// reports it and returns nil if it is tolerated. def is the default policy of
+// the rule.
+func (p UnmarshalPolicy) check(v, def ViolationPolicy, err error) error {
+	if v == ViolationDefault {
+		v = def
+	}
+
+	switch v {
+	case ViolationReject:
+		return err
+	case ViolationWarn:
+		if p.Warn != nil {
+			p.Warn(err)
+		}
+	}
+
+	return nil
+}
+
+// topLevelMembers are the top-level members defined by the specification.
+var topLevelMembers = map[string]bool{
+	"data":     true,
+	"errors":   true,
+	"meta":     true,
+	"jsonapi":  true,
+	"links":    true,
+	"included": true,
+}
+
+// ptrEscaper escapes a member name to be used in a JSON pointer.
+var ptrEscaper = strings.NewReplacer("~", "~0", "/", "~1")
+
+// checkMembers checks the top-level members of the document found in data
+// according to the UnknownMembers policy.
+func (p UnmarshalPolicy) checkMembers(data []byte) error {
+	if p.UnknownMembers == ViolationDefault || p.UnknownMembers == ViolationIgnore {
+		return nil
+	}
+
+	var members map[string]json.RawMessage
+	if err := json.Unmarshal(data, &members); err != nil {
+		return payloadErr(err)
+	}
+
+	names := make([]string, 0, len(members))
+	for name := range members {
+		names = append(names, name)
+	}
+
+	sort.Strings(names)
+
+	for _, name := range names {
+		if topLevelMembers[name] || strings.Contains(name, ":") ||
+			strings.HasPrefix(name, "@") {
+			continue
+		}
+
+		err := &srcError{
+			ptr:   true,
+			src:   "/" + ptrEscaper.Replace(name),
+			error: payloadErr(fmt.Errorf("jsonapi: unknown top-level member %q", name)),
+		}
+
+		if err := p.check(p.UnknownMembers, ViolationIgnore, err); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,76 @@
+package jsonapi_test
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	. "github.com/mark-hartmann/jsonapi"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestUnmarshalPolicy(t *testing.T) {
+	assert := assert.New(t)
+
+	schema := newMockSchema()
+
+	var warnings []string
+
+	warn := func(err error) {
+		assert.True(errors.Is(err, ErrInvalidPayload))
+		warnings = append(warnings, err.Error())
+	}
+
+	unmarshal := func(policy UnmarshalPolicy, payload string) (*Document, error) {
+		return UnmarshalOptions{Policy: policy}.UnmarshalDocument(strings.NewReader(payload), schema)
+	}
+
+	// Included without data
+	payload := `{"meta": {"a": 1}, "included": [{"id": "id1", "type": "mocktypes1"}]}`
+
+	_, err := unmarshal(UnmarshalPolicy{}, payload)
+	assert.EqualError(err, "jsonapi: invalid inclusions without primary data")
+
+	doc, err := unmarshal(UnmarshalPolicy{IncludedWithoutData: ViolationWarn, Warn: warn}, payload)
+	assert.NoError(err)
+	assert.True(doc.OmitData)
+	assert.Len(doc.Included, 1)
+	assert.Equal([]string{"jsonapi: invalid inclusions without primary data"}, warnings)
+
+	// Empty errors with data
+	warnings = nil
+	payload = `{"data": null, "errors": []}`
+
+	_, err = unmarshal(UnmarshalPolicy{}, payload)
+	assert.EqualError(err, `jsonapi: "data" and "errors" must not coexist`)
+
+	doc, err = unmarshal(UnmarshalPolicy{EmptyErrorsWithData: ViolationIgnore, Warn: warn}, payload)
+	assert.NoError(err)
+	assert.Nil(doc.Data)
+	assert.Empty(doc.Errors)
+	assert.Empty(warnings)
+
+	// Errors that are not empty are always rejected.
+	_, err = unmarshal(UnmarshalPolicy{EmptyErrorsWithData: ViolationIgnore},
+		`{"data": null, "errors": [{"status": "500"}]}`)
+	assert.EqualError(err, `jsonapi: "data" and "errors" must not coexist`)
+
+	// Unknown top-level members
+	payload = `{"data": null, "foo": 1, "a/b": 2, "ext:member": 3, "@context": 4}`
+
+	_, err = unmarshal(UnmarshalPolicy{}, payload)
+	assert.NoError(err)
+
+	_, err = unmarshal(UnmarshalPolicy{UnknownMembers: ViolationReject}, payload)
+	assert.EqualError(err, `jsonapi: unknown top-level member "a/b"`)
+	assert.True(errors.Is(err, ErrInvalidPayload))
+	assert.Equal("/a~1b", (&ErrorMapper{}).Map(err).Src.Pointer)
+
+	_, err = unmarshal(UnmarshalPolicy{UnknownMembers: ViolationWarn, Warn: warn}, payload)
+	assert.NoError(err)
+	assert.Equal([]string{
+		`jsonapi: unknown top-level member "a/b"`,
+		`jsonapi: unknown top-level member "foo"`,
+	}, warnings)
+}
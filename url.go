@@ -10,6 +10,11 @@ import (
 // NewURL builds a URL from a SimpleURL and a schema for validating and
 // supplementing the object with extra information.
 func NewURL(schema *Schema, su SimpleURL) (*URL, error) {
+	return URLOptions{}.NewURL(schema, su)
+}
+
+// NewURL builds a URL like the NewURL function does, according to the options.
+func (o URLOptions) NewURL(schema *Schema, su SimpleURL) (*URL, error) {
 	url := &URL{}
 
 	// Route
@@ -48,7 +53,7 @@ func NewURL(schema *Schema, su SimpleURL) (*URL, error) {
 
 	if len(url.Fragments) >= 3 {
 		relName := url.Fragments[len(url.Fragments)-1]
-		if url.Rel, ok = typ.Rels[relName]; !ok {
+		if url.Rel, ok = typ.Rels[schema.fieldNameOrMember(typ)(relName)]; !ok {
 			// No Parameter/Pointer because it's part of the url path.
 			return nil, &UnknownFieldError{
 				Type:   typ.Name,
@@ -87,16 +92,24 @@ func NewURL(schema *Schema, su SimpleURL) (*URL, error) {
 	}
 
 	var err error
-	if url.Params, err = NewParams(schema, su, url.ResType); err != nil {
+	if url.Params, err = o.NewParams(schema, su, url.ResType); err != nil {
 		return nil, fmt.Errorf("jsonapi: failed to create jsonapi.Params: %w", err)
 	}
 
+	url.names = schema.NameMapper
+
 	return url, nil
 }
 
 // NewURLFromRaw parses rawurl to make a *url.URL before making and returning a
 // *URL.
 func NewURLFromRaw(schema *Schema, rawurl string) (*URL, error) {
+	return URLOptions{}.NewURLFromRaw(schema, rawurl)
+}
+
+// NewURLFromRaw parses rawurl like the NewURLFromRaw function does, according
+// to the options.
+func (o URLOptions) NewURLFromRaw(schema *Schema, rawurl string) (*URL, error) {
 	u, err := url.Parse(rawurl)
 	if err != nil {
 		return nil, fmt.Errorf("jsonapi: failed to parse url.URL: %w", err)
@@ -105,7 +118,7 @@ func NewURLFromRaw(schema *Schema, rawurl string) (*URL, error) {
 	// u is a valid errJSONPtr, error can be ignored.
 	su, _ := NewSimpleURL(u)
 
-	return NewURL(schema, su)
+	return o.NewURL(schema, su)
 }
 
 // A URL stores all the information from a URL formatted for a JSON:API request.
@@ -127,139 +140,150 @@ type URL struct {
 
 	// Params
 	Params *Params
+
+	// names is the NameMapper of the schema the URL was made with.
+	names NameMapper
 }
 
 // String returns a string representation of the URL where special characters
-// are escaped.
+// are escaped, in the fragments of the path as well as in the query.
 //
 // The URL is normalized, so it always returns exactly the same string given the
 // same URL.
 func (u *URL) String() string {
-	// Path
-	path := "/"
-	for _, p := range u.Fragments {
-		path += p + "/"
+	fragments := make([]string, len(u.Fragments))
+	for i, frag := range u.Fragments {
+		fragments[i] = url.PathEscape(frag)
 	}
 
-	path = path[:len(path)-1]
+	path := "/" + strings.Join(fragments, "/")
 
-	// Params
-	urlParams := []string{}
+	query := encodeQuery(u.Params, u.IsCol, u.names)
+	if query == "" {
+		return path
+	}
+
+	return path + "?" + query
+}
+
+// UnescapedString returns the same thing as String, but special characters are
+// not escaped.
+func (u *URL) UnescapedString() string {
+	// The string is escaped by String, so unescaping it cannot fail.
+	str, _ := url.PathUnescape(u.String())
+
+	return str
+}
+
+// encodeQuery returns the escaped query string made from params.
+//
+// The parameters are always written in the same order: fields, include,
+// filter, page and sort. The page parameters are only written if isCol is true.
+// The names of the fields are written as the member names returned by names.
+func encodeQuery(params *Params, isCol bool, names NameMapper) string {
+	if params == nil {
+		return ""
+	}
+
+	member := memberNames(names)
+
+	var sb strings.Builder
+
+	add := func(key, val string) {
+		if sb.Len() > 0 {
+			sb.WriteByte('&')
+		}
+
+		sb.WriteString(url.QueryEscape(key))
+		sb.WriteByte('=')
+		sb.WriteString(url.QueryEscape(val))
+	}
 
 	// Fields
-	fields := make([]string, 0, len(u.Params.Fields))
-	for key := range u.Params.Fields {
-		fields = append(fields, key)
+	types := make([]string, 0, len(params.Fields))
+	for typ := range params.Fields {
+		types = append(types, typ)
 	}
 
-	sort.Strings(fields)
+	sort.Strings(types)
 
-	for _, typ := range fields {
-		if len(u.Params.Fields[typ]) == 0 {
+	for _, typ := range types {
+		// An empty fieldset is only written if it was explicitly given,
+		// like NewParams does for fields[type]=.
+		if params.Fields[typ] == nil {
 			continue
 		}
 
-		sort.Strings(u.Params.Fields[typ])
-
-		param := "fields%5B" + typ + "%5D="
-		for _, f := range u.Params.Fields[typ] {
-			param += f + "%2C"
+		fields := make([]string, len(params.Fields[typ]))
+		for i, name := range params.Fields[typ] {
+			fields[i] = member(name)
 		}
 
-		param = param[:len(param)-3]
+		sort.Strings(fields)
 
-		urlParams = append(urlParams, param)
+		add("fields["+typ+"]", strings.Join(fields, ","))
 	}
 
 	// Inclusions
-	if len(u.Params.Include) > 0 {
-		param := "include="
-		inclusions := make([]string, 0, len(u.Params.Include))
-
-		for _, rels := range u.Params.Include {
-			var r string
-			for _, rel := range rels {
-				r += rel.FromName + "."
-			}
+	if len(params.Include) > 0 {
+		inclusions := make([]string, 0, len(params.Include))
 
-			inclusions = append(inclusions, r[:len(r)-1])
+		for _, rels := range params.Include {
+			inclusions = append(inclusions, joinMemberNames(rels, "", member))
 		}
 
 		sort.Strings(inclusions)
-		param += strings.Join(inclusions, "%2C")
-		urlParams = append(urlParams, param)
+		add("include", strings.Join(inclusions, ","))
 	}
 
 	// Filter
-	if u.Params.Filter != nil {
-		var filterParams []string
-
-		for name, filters := range u.Params.Filter {
-			for _, f := range filters {
-				// name (e.g. filter[xyz]) may contain characters that need to be url
-				// encoded as well.
-				filterParams = append(filterParams, url.QueryEscape(name)+"="+url.QueryEscape(f))
-			}
+	if len(params.Filter) > 0 {
+		filter := make(url.Values, len(params.Filter))
+
+		for name, vals := range params.Filter {
+			filter[name] = make([]string, len(vals))
+			copy(filter[name], vals)
+			sort.Strings(filter[name])
 		}
 
-		sort.Strings(filterParams)
-		urlParams = append(urlParams, filterParams...)
+		if sb.Len() > 0 {
+			sb.WriteByte('&')
+		}
+
+		sb.WriteString(filter.Encode())
 	}
 
 	// Pagination
-	if u.IsCol {
-		var pageParams []string
-		for k, v := range u.Params.Page {
-			pageParams = append(pageParams, "page%5B"+url.QueryEscape(k)+"%5D="+fmt.Sprint(v))
+	if isCol {
+		keys := make([]string, 0, len(params.Page))
+		for k := range params.Page {
+			keys = append(keys, k)
 		}
 
-		// Maps have no reliable order. One could also use sort.Slice(pageParams) with a function
-		// that explicitly checks the actual names, e.g.:
-		// params[i][:strings.Index(params[i], "=")] < params[i][:strings.Index(params[j], "=")]
-		sort.Strings(pageParams)
-		urlParams = append(urlParams, pageParams...)
+		sort.Strings(keys)
+
+		for _, k := range keys {
+			add("page["+k+"]", params.Page[k])
+		}
 	}
 
 	// Sorting
-	if len(u.Params.SortRules) > 0 {
-		param := "sort="
-
-		for _, sr := range u.Params.SortRules {
-			rule := sr.Name
-
-			if len(sr.Path) > 0 {
-				for _, rel := range sr.Path {
-					rule = rel.FromName + "." + rule
-				}
-			}
+	if len(params.SortRules) > 0 {
+		rules := make([]string, 0, len(params.SortRules))
 
+		for _, sr := range params.SortRules {
+			rule := joinMemberNames(sr.Path, sr.Name, member)
 			if sr.Desc {
 				rule = "-" + rule
 			}
 
-			param += rule + "%2C"
+			rules = append(rules, rule)
 		}
 
-		param = param[:len(param)-3]
-		urlParams = append(urlParams, param)
+		add("sort", strings.Join(rules, ","))
 	}
 
-	params := "?"
-	for _, param := range urlParams {
-		params += param + "&"
-	}
-
-	params = params[:len(params)-1]
-
-	return path + params
-}
-
-// UnescapedString returns the same thing as String, but special characters are
-// not escaped.
-func (u *URL) UnescapedString() string {
-	str, _ := url.PathUnescape(u.String())
-	// TODO Can an error occur?
-	return str
+	return sb.String()
 }
 
 // A BelongsToFilter represents a parent resource, used to filter out resources
@@ -273,3 +297,19 @@ type BelongsToFilter struct {
 	Name   string
 	ToName string
 }
+
+// joinMemberNames returns the member names of the relationships of path,
+// followed by the one of the field named name if it is not empty, separated by
+// dots like in an include or a sort parameter.
+func joinMemberNames(path []Rel, name string, member func(string) string) string {
+	names := make([]string, 0, len(path)+1)
+	for _, rel := range path {
+		names = append(names, member(rel.FromName))
+	}
+
+	if name != "" {
+		names = append(names, member(name))
+	}
+
+	return strings.Join(names, ".")
+}
@@ -0,0 +1,47 @@
+//go:build go1.18
+// +build go1.18
+
+package jsonapi_test
+
+import (
+	"testing"
+
+	. "github.com/mark-hartmann/jsonapi"
+)
+
+func FuzzURLString(f *testing.F) {
+	schema := newMockSchema()
+
+	for _, seed := range []string{
+		"/mocktypes1",
+		"/mocktypes1/abc/to-one",
+		"/mocktypes1?fields[mocktypes1]=bool,int8&page[number]=2&page[size]=10&sort=-str",
+		"/mocktypes1?include=to-many-from-one.to-one-from-many&filter[a%26b]=c%3Dd",
+		`/mocktypes1?filter={"f":"str","o":"=","v":"a b+c"}&sort=to-one.boolptr`,
+	} {
+		f.Add(seed)
+	}
+
+	f.Fuzz(func(t *testing.T, raw string) {
+		u, err := NewURLFromRaw(schema, raw)
+		if err != nil {
+			return
+		}
+
+		str := u.String()
+
+		u2, err := NewURLFromRaw(schema, str)
+		if err != nil {
+			t.Fatalf("String() of %q returned %q which cannot be parsed: %s", raw, str, err)
+		}
+
+		if str2 := u2.String(); str2 != str {
+			t.Fatalf("String() of %q is not stable: %q != %q", raw, str, str2)
+		}
+
+		if u.UnescapedString() != u2.UnescapedString() {
+			t.Fatalf("UnescapedString() of %q is not stable: %q != %q",
+				raw, u.UnescapedString(), u2.UnescapedString())
+		}
+	})
+}
@@ -0,0 +1,68 @@
+package jsonapi
+
+// URLOptions configures how URLs and their query parameters are parsed.
+//
+// The zero value parses URLs like the NewURL, NewURLFromRaw and NewParams
+// functions do.
+type URLOptions struct {
+	// AuthorizePath is called with each relationship path of the include
+	// parameter, once the duplicates and the paths that are part of longer
+	// ones are removed, and with the relationship path of each rule of the
+	// sort parameter that has one.
+	//
+	// It lets an application reject traversals that are too expensive or
+	// that the client is not allowed to make, like "user.payments", before
+	// any data is accessed. The returned error is wrapped in an
+	// IllegalParameterError. If it is an Error, ErrorMapper returns it as
+	// it is.
+	AuthorizePath func(path []Rel) error
+
+	// StrictFields rejects the fields[...] parameters of the types that are
+	// neither the type of the endpoint nor the type of a resource included
+	// through the include parameter, since their fields would never be
+	// marshaled. An IllegalParameterError is returned for such a parameter.
+	StrictFields bool
+
+	// StrictParams rejects the query parameters that are not defined by the
+	// specification, like fields[...] or sort, and are not in AllowedParams.
+	// The specification requires a server to answer with 400 Bad Request to
+	// a parameter it does not know how to process. An
+	// *UnknownParameterError is returned for the first of them, by name,
+	// which ErrorMapper maps to NewErrUnknownParameter. By default, they are
+	// kept in Params.Params.
+	StrictParams bool
+
+	// AllowedParams holds the names of the implementation-specific query
+	// parameters accepted when StrictParams is set, like "x-collation".
+	AllowedParams []string
+}
+
+// checkParams returns an *UnknownParameterError for the first parameter of
+// params, by name, that is not allowed if StrictParams is set.
+func (o URLOptions) checkParams(params map[string][]string) error {
+	if !o.StrictParams {
+		return nil
+	}
+
+	for _, name := range sortedKeys(params) {
+		if !containsString(o.AllowedParams, name) {
+			return &UnknownParameterError{Param: name}
+		}
+	}
+
+	return nil
+}
+
+// authorizePath calls AuthorizePath with path, if it is set, and wraps the
+// returned error in an IllegalParameterError for param.
+func (o URLOptions) authorizePath(param string, path []Rel) error {
+	if o.AuthorizePath == nil {
+		return nil
+	}
+
+	if err := o.AuthorizePath(path); err != nil {
+		return &IllegalParameterError{Param: param, err: err}
+	}
+
+	return nil
+}
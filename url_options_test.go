@@ -0,0 +1,142 @@
+package jsonapi_test
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	. "github.com/mark-hartmann/jsonapi"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestURLOptionsAuthorizePath(t *testing.T) {
+	assert := assert.New(t)
+
+	schema := newMockSchema()
+	errDenied := errors.New("too expensive")
+
+	var paths []string
+
+	opts := URLOptions{
+		AuthorizePath: func(path []Rel) error {
+			names := make([]string, 0, len(path))
+			for _, rel := range path {
+				names = append(names, rel.FromName)
+			}
+
+			paths = append(paths, strings.Join(names, "."))
+
+			if len(path) > 1 {
+				return errDenied
+			}
+
+			return nil
+		},
+	}
+
+	// Normalized include paths and sort paths
+	url, err := opts.NewURLFromRaw(schema,
+		"/mocktypes1?include=to-one,to-many,to-many&sort=-to-one.strptr,str")
+	assert.NoError(err)
+	assert.NotNil(url)
+	assert.Equal([]string{"to-many", "to-one", "to-one"}, paths)
+
+	// Rejected include path
+	paths = nil
+	_, err = opts.NewURLFromRaw(schema, "/mocktypes1?include=to-one.to-one-from-one")
+
+	var illegal *IllegalParameterError
+	if assert.True(errors.As(err, &illegal)) {
+		assert.Equal("include", illegal.Param)
+	}
+
+	assert.True(errors.Is(err, errDenied))
+	assert.Equal([]string{"to-one.to-one-from-one"}, paths)
+
+	e := (&ErrorMapper{}).Map(err)
+	assert.Equal("400", e.Status)
+	assert.Equal("include", e.Src.Parameter)
+	assert.Equal(`Illegal query parameter "include": too expensive.`, e.Detail)
+
+	// An Error is returned as it is.
+	opts.AuthorizePath = func([]Rel) error { return NewErrForbidden() }
+	_, err = opts.NewURLFromRaw(schema, "/mocktypes1?include=to-one")
+	assert.Equal("403", (&ErrorMapper{}).Map(err).Status)
+
+	// Without the option
+	_, err = NewURLFromRaw(schema, "/mocktypes1?include=to-one.to-one-from-one")
+	assert.NoError(err)
+}
+
+func TestURLOptionsStrictFields(t *testing.T) {
+	assert := assert.New(t)
+
+	schema := newMockSchema()
+	opts := URLOptions{StrictFields: true}
+
+	// The type of the endpoint and the included types
+	_, err := opts.NewURLFromRaw(schema,
+		"/mocktypes1?fields[mocktypes1]=str&include=to-one&fields[mocktypes2]=strptr")
+	assert.NoError(err)
+
+	// A type that is reachable but not included
+	_, err = opts.NewURLFromRaw(schema, "/mocktypes1?fields[mocktypes2]=strptr")
+	assert.EqualError(err, `jsonapi: failed to create jsonapi.Params: jsonapi: illegal query `+
+		`parameter "fields": type "mocktypes2" is neither the type of the endpoint nor included`)
+
+	e := (&ErrorMapper{}).Map(err)
+	assert.Equal("400", e.Status)
+	assert.Equal("fields", e.Src.Parameter)
+
+	// Unknown types are still reported as such.
+	_, err = opts.NewURLFromRaw(schema, "/mocktypes1?fields[unknown]=a")
+
+	var unknown *UnknownTypeError
+	assert.True(errors.As(err, &unknown))
+
+	// Without the option
+	_, err = NewURLFromRaw(schema, "/mocktypes1?fields[mocktypes2]=strptr")
+	assert.NoError(err)
+}
+
+func TestURLOptionsStrictParams(t *testing.T) {
+	assert := assert.New(t)
+
+	schema := newMockSchema()
+	raw := "/mocktypes1?fields[mocktypes1]=str&sort=str&page[size]=2&filter[str]=a" +
+		"&x-collation=fr&camelCase=1&debug=1"
+
+	// Off-spec parameters are kept by default.
+	url, err := NewURLFromRaw(schema, raw)
+	assert.NoError(err)
+	assert.Equal(map[string][]string{
+		"camelCase":   {"1"},
+		"debug":       {"1"},
+		"x-collation": {"fr"},
+	}, url.Params.Params)
+
+	opts := URLOptions{StrictParams: true, AllowedParams: []string{"x-collation"}}
+
+	_, err = opts.NewURLFromRaw(schema, raw)
+	assert.EqualError(err, `jsonapi: failed to create jsonapi.Params: `+
+		`jsonapi: unknown query parameter "camelCase"`)
+
+	var unknown *UnknownParameterError
+	if assert.True(errors.As(err, &unknown)) {
+		assert.Equal("camelCase", unknown.Param)
+	}
+
+	e := (&ErrorMapper{}).Map(err)
+	assert.Equal(NewErrUnknownParameter("camelCase"), e)
+	assert.Equal("400", e.Status)
+	assert.Equal("camelCase", e.Src.Parameter)
+	assert.Equal(`The query parameter "camelCase" is not supported.`, e.Detail)
+
+	// Allowed parameters
+	opts.AllowedParams = append(opts.AllowedParams, "camelCase", "debug")
+
+	url, err = opts.NewURLFromRaw(schema, raw)
+	assert.NoError(err)
+	assert.Len(url.Params.Params, 3)
+}
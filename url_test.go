@@ -397,3 +397,30 @@ func TestURLString(t *testing.T) {
 		})
 	}
 }
+
+func TestURLStringParamsChanges(t *testing.T) {
+	assert := assert.New(t)
+
+	u, err := NewURLFromRaw(newMockSchema(), "/mocktypes1?page[size]=10&sort=-to-one.strptr")
+	assert.NoError(err)
+	assert.Equal("/mocktypes1?page%5Bsize%5D=10&sort=-to-one.strptr", u.String())
+
+	// Changes made to the params in place are reflected.
+	u.Params.Page["size"] = "20"
+	u.Params.SortRules[0].Path = append(u.Params.SortRules[0].Path,
+		Rel{FromName: "to-many-from-one"})
+	assert.Equal("/mocktypes1?page%5Bsize%5D=20&sort=-to-one.to-many-from-one.strptr",
+		u.String())
+
+	u.IsCol = false
+	assert.Equal("/mocktypes1?sort=-to-one.to-many-from-one.strptr", u.String())
+
+	// The fields are not sorted in place.
+	u.Params.SortRules = nil
+	u.Params.Fields = map[string][]string{"mocktypes1": {"str", "bool"}}
+	assert.Equal("/mocktypes1?fields%5Bmocktypes1%5D=bool%2Cstr", u.String())
+	assert.Equal([]string{"str", "bool"}, u.Params.Fields["mocktypes1"])
+
+	u.Params = nil
+	assert.Equal("/mocktypes1", u.String())
+}
@@ -0,0 +1,140 @@
+package jsonapi
+
+import (
+	"encoding/hex"
+	"fmt"
+	"reflect"
+)
+
+// UUID is a universally unique identifier as defined by RFC 4122. It is the
+// go-type of the attributes of type AttrTypeUUID.
+//
+// A UUID is represented by its canonical string form, for example
+// "f47ac10b-58cc-4372-a567-0e02b2c3d479". The zero value is the nil UUID
+// "00000000-0000-0000-0000-000000000000".
+type UUID [16]byte
+
+// ParseUUID parses s as a UUID in its canonical form. Upper and lower case
+// hexadecimal digits are accepted.
+//
+// Apart from the nil UUID, only UUIDs of the RFC 4122 variant are valid.
+func ParseUUID(s string) (UUID, error) {
+	var u UUID
+
+	if len(s) != 36 || s[8] != '-' || s[13] != '-' || s[18] != '-' || s[23] != '-' {
+		return u, fmt.Errorf("jsonapi: invalid UUID %q", s)
+	}
+
+	src := []byte(s[0:8] + s[9:13] + s[14:18] + s[19:23] + s[24:])
+	if _, err := hex.Decode(u[:], src); err != nil {
+		return UUID{}, fmt.Errorf("jsonapi: invalid UUID %q", s)
+	}
+
+	if u != (UUID{}) && u[8]&0xc0 != 0x80 {
+		return UUID{}, fmt.Errorf("jsonapi: UUID %q is not of the RFC 4122 variant", s)
+	}
+
+	return u, nil
+}
+
+// MustParseUUID calls ParseUUID and panics if an error is returned.
+func MustParseUUID(s string) UUID {
+	u, err := ParseUUID(s)
+	if err != nil {
+		panic(err)
+	}
+
+	return u
+}
+
+// String returns the canonical form of u with lower case letters.
+func (u UUID) String() string {
+	buf := make([]byte, 36)
+
+	hex.Encode(buf[0:8], u[0:4])
+	buf[8] = '-'
+	hex.Encode(buf[9:13], u[4:6])
+	buf[13] = '-'
+	hex.Encode(buf[14:18], u[6:8])
+	buf[18] = '-'
+	hex.Encode(buf[19:23], u[8:10])
+	buf[23] = '-'
+	hex.Encode(buf[24:], u[10:])
+
+	return string(buf)
+}
+
+// MarshalText implements encoding.TextMarshaler, so a UUID is marshaled as a
+// JSON string.
+func (u UUID) MarshalText() ([]byte, error) {
+	return []byte(u.String()), nil
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler. The text is validated
+// with ParseUUID.
+func (u *UUID) UnmarshalText(text []byte) error {
+	uuid, err := ParseUUID(string(text))
+	if err != nil {
+		return err
+	}
+
+	*u = uuid
+
+	return nil
+}
+
+// isUUIDType returns true if typ is a 16 bytes array like UUID and the UUID
+// types of the common third-party packages.
+func isUUIDType(typ reflect.Type) bool {
+	return typ.Kind() == reflect.Array && typ.Len() == 16 && typ.Elem().Kind() == reflect.Uint8
+}
+
+// convertUUID converts val, a UUID, *UUID, []UUID or *[]UUID, to typ if typ
+// has the same shape with another UUID type or a string as base type.
+//
+// This allows struct fields of third-party UUID types or strings tagged as
+// UUID attributes to be set with the values returned by UnmarshalToType.
+func convertUUID(val reflect.Value, typ reflect.Type) (reflect.Value, bool) {
+	switch {
+	case val.Type() == reflect.TypeOf(UUID{}):
+		switch {
+		case isUUIDType(typ):
+			return val.Convert(typ), true
+		case typ.Kind() == reflect.String:
+			return reflect.ValueOf(val.Interface().(UUID).String()).Convert(typ), true
+		}
+	case val.Kind() == reflect.Ptr && typ.Kind() == reflect.Ptr:
+		if val.IsNil() {
+			if _, ok := convertUUID(reflect.Zero(val.Type().Elem()), typ.Elem()); ok {
+				return reflect.Zero(typ), true
+			}
+
+			return val, false
+		}
+
+		elem, ok := convertUUID(val.Elem(), typ.Elem())
+		if !ok {
+			return val, false
+		}
+
+		ptr := reflect.New(typ.Elem())
+		ptr.Elem().Set(elem)
+
+		return ptr, true
+	case val.Kind() == reflect.Slice && typ.Kind() == reflect.Slice:
+		if _, ok := convertUUID(reflect.Zero(val.Type().Elem()), typ.Elem()); !ok {
+			return val, false
+		}
+
+		s := reflect.MakeSlice(typ, val.Len(), val.Len())
+
+		for i := 0; i < val.Len(); i++ {
+			elem, _ := convertUUID(val.Index(i), typ.Elem())
+			s.Index(i).Set(elem)
+		}
+
+		return s, true
+	}
+
+	return val, false
+}
@@ -0,0 +1,132 @@
+package jsonapi_test
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+
+	. "github.com/mark-hartmann/jsonapi"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseUUID(t *testing.T) {
+	assert := assert.New(t)
+
+	u, err := ParseUUID("F47AC10B-58CC-4372-A567-0E02B2C3D479")
+	assert.NoError(err)
+	assert.Equal("f47ac10b-58cc-4372-a567-0e02b2c3d479", u.String())
+
+	u, err = ParseUUID("00000000-0000-0000-0000-000000000000")
+	assert.NoError(err)
+	assert.Equal(UUID{}, u)
+	assert.Equal("00000000-0000-0000-0000-000000000000", UUID{}.String())
+
+	for _, s := range []string{
+		"",
+		"f47ac10b58cc4372a5670e02b2c3d479",
+		"{f47ac10b-58cc-4372-a567-0e02b2c3d479}",
+		"f47ac10b-58cc-4372-a567-0e02b2c3d47",
+		"f47ac10b-58cc-4372-a567_0e02b2c3d479",
+		"g47ac10b-58cc-4372-a567-0e02b2c3d479",
+		// Not the RFC 4122 variant
+		"f47ac10b-58cc-4372-c567-0e02b2c3d479",
+	} {
+		_, err = ParseUUID(s)
+		assert.Error(err, s)
+	}
+
+	assert.Panics(func() { MustParseUUID("abc") })
+
+	typ, arr, null := GetAttrType("*[]uuid.UUID")
+	assert.Equal(AttrTypeUUID, typ)
+	assert.True(arr)
+	assert.True(null)
+}
+
+func TestUUIDAttribute(t *testing.T) {
+	assert := assert.New(t)
+
+	typ := Type{Name: "devices"}
+	typ.MustAddAttr(Attr{Name: "serial", Type: AttrTypeUUID})
+	typ.MustAddAttr(Attr{Name: "parent", Type: AttrTypeUUID, Nullable: true})
+	typ.MustAddAttr(Attr{Name: "siblings", Type: AttrTypeUUID, Array: true})
+
+	schema := &Schema{}
+	schema.MustAddType(typ)
+
+	res, err := UnmarshalResource([]byte(`{
+		"id": "d1",
+		"type": "devices",
+		"attributes": {
+			"serial": "f47ac10b-58cc-4372-a567-0e02b2c3d479",
+			"parent": null,
+			"siblings": ["6ba7b810-9dad-11d1-80b4-00c04fd430c8"]
+		}
+	}`), schema)
+	assert.NoError(err)
+	assert.Equal(MustParseUUID("f47ac10b-58cc-4372-a567-0e02b2c3d479"), res.Get("serial"))
+	assert.Equal((*UUID)(nil), res.Get("parent"))
+	assert.Equal([]UUID{MustParseUUID("6ba7b810-9dad-11d1-80b4-00c04fd430c8")}, res.Get("siblings"))
+
+	_, err = UnmarshalResource([]byte(`{
+		"id": "d1",
+		"type": "devices",
+		"attributes": {"serial": "not-a-uuid"}
+	}`), schema)
+
+	var ifve *InvalidFieldValueError
+
+	assert.True(errors.As(err, &ifve))
+	assert.Equal("serial", ifve.Field)
+	assert.Equal(`"not-a-uuid"`, ifve.Value)
+
+	buf := &bytes.Buffer{}
+	assert.NoError(MarshalDocument(buf, &Document{Data: res}, nil))
+	assert.Contains(buf.String(), `"attributes":{"parent":null,`+
+		`"serial":"f47ac10b-58cc-4372-a567-0e02b2c3d479",`+
+		`"siblings":["6ba7b810-9dad-11d1-80b4-00c04fd430c8"]}`)
+}
+
+// otherUUID stands for the UUID type of a third-party package.
+type otherUUID [16]byte
+
+func (u otherUUID) MarshalText() ([]byte, error) {
+	return UUID(u).MarshalText()
+}
+
+func TestUUIDWrapper(t *testing.T) {
+	assert := assert.New(t)
+
+	type device struct {
+		ID       string       `json:"id" api:"devices"`
+		Serial   otherUUID    `json:"serial" api:"attr,uuid"`
+		Parent   *otherUUID   `json:"parent" api:"attr,uuid"`
+		Siblings []otherUUID  `json:"siblings" api:"attr,uuid"`
+		Label    string       `json:"label" api:"attr,uuid"`
+		Others   *[]otherUUID `json:"others" api:"attr,uuid"`
+	}
+
+	dev := &device{}
+	wrap := Wrap(dev)
+
+	for _, name := range []string{"serial", "parent", "siblings", "label", "others"} {
+		assert.Equal(AttrTypeUUID, wrap.Attr(name).Type, name)
+	}
+
+	u := MustParseUUID("f47ac10b-58cc-4372-a567-0e02b2c3d479")
+
+	wrap.Set("serial", u)
+	wrap.Set("parent", &u)
+	wrap.Set("siblings", []UUID{u})
+	wrap.Set("label", u)
+	wrap.Set("others", (*[]UUID)(nil))
+
+	assert.Equal(otherUUID(u), dev.Serial)
+	assert.Equal(otherUUID(u), *dev.Parent)
+	assert.Equal([]otherUUID{otherUUID(u)}, dev.Siblings)
+	assert.Equal("f47ac10b-58cc-4372-a567-0e02b2c3d479", dev.Label)
+	assert.Nil(dev.Others)
+
+	assert.Panics(func() { wrap.Set("serial", 1) })
+}
@@ -0,0 +1,80 @@
+package jsonapi
+
+import "fmt"
+
+// MetaVersion is the meta key under which the version of a resource is
+// written and read (see Versioned).
+//
+// The version is used for optimistic locking: a client sends back the version
+// it has seen when it updates a resource, and the update is refused if the
+// resource has changed since (see CheckVersion).
+const MetaVersion = "version"
+
+// A Versioned is a resource that has a version, like a revision number or a
+// hash of its content.
+//
+// The version of a Versioned resource is added to its meta values when it is
+// marshaled, unless it is empty, and set from its meta values when it is
+// unmarshaled.
+type Versioned interface {
+	Version() string
+	SetVersion(v string)
+}
+
+// ResourceVersion returns the version of res and whether it has one. The
+// Version method is used if res is a Versioned. Otherwise, the version is the
+// MetaVersion meta value of res if it is a MetaHolder.
+func ResourceVersion(res Resource) (string, bool) {
+	if v, ok := res.(Versioned); ok {
+		version := v.Version()
+
+		return version, version != ""
+	}
+
+	if mh, ok := res.(MetaHolder); ok {
+		version, ok := mh.Meta().LookupString(MetaVersion)
+
+		return version, ok && version != ""
+	}
+
+	return "", false
+}
+
+// CheckVersion checks that the version provided by patch, a resource sent by
+// a client to update current, is the version of current. Nothing is checked
+// if patch has no version.
+//
+// A 409 Conflict error (see NewErrConflict) is returned if the versions are
+// different.
+func CheckVersion(current, patch Resource) error {
+	provided, ok := ResourceVersion(patch)
+	if !ok {
+		return nil
+	}
+
+	if version, _ := ResourceVersion(current); provided != version {
+		e := NewErrConflict()
+		e.Detail = fmt.Sprintf(
+			"The version %q of the resource is not its current version %q.",
+			provided, version,
+		)
+		e.setSource(ErrorSource{Pointer: "/data/meta/" + MetaVersion})
+
+		return e
+	}
+
+	return nil
+}
+
+// setVersion sets the version of res to the MetaVersion value of meta if res
+// is a Versioned and the value is a string.
+func setVersion(res Resource, meta Meta) {
+	v, ok := res.(Versioned)
+	if !ok {
+		return
+	}
+
+	if version, ok := meta.LookupString(MetaVersion); ok {
+		v.SetVersion(version)
+	}
+}
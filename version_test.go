@@ -0,0 +1,125 @@
+package jsonapi_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+
+	. "github.com/mark-hartmann/jsonapi"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// versionedResource is a SoftResource with a version.
+type versionedResource struct {
+	*SoftResource
+	version string
+}
+
+func (r *versionedResource) Version() string     { return r.version }
+func (r *versionedResource) SetVersion(v string) { r.version = v }
+
+func TestVersionedMarshaling(t *testing.T) {
+	assert := assert.New(t)
+
+	typ := Type{Name: "articles"}
+	typ.MustAddAttr(Attr{Name: "title", Type: AttrTypeString})
+	typ.NewFunc = func() Resource {
+		return &versionedResource{SoftResource: &SoftResource{Type: &typ}}
+	}
+
+	schema := &Schema{}
+	schema.MustAddType(typ)
+
+	res := &versionedResource{SoftResource: &SoftResource{Type: &typ}, version: "3"}
+	res.SetID("a1")
+	res.SetMeta(Meta{"key": "value"})
+
+	buf := &bytes.Buffer{}
+	err := MarshalDocument(buf, &Document{Data: res}, nil)
+	assert.NoError(err)
+
+	var payload struct {
+		Data struct {
+			Meta map[string]interface{} `json:"meta"`
+		} `json:"data"`
+	}
+
+	assert.NoError(json.Unmarshal(buf.Bytes(), &payload))
+	assert.Equal(map[string]interface{}{"key": "value", "version": "3"}, payload.Data.Meta)
+	assert.Equal(Meta{"key": "value"}, res.Meta())
+
+	// An empty version is not written.
+	res.version = ""
+	payload.Data.Meta = nil
+	buf.Reset()
+	err = MarshalDocument(buf, &Document{Data: res}, nil)
+	assert.NoError(err)
+	assert.NoError(json.Unmarshal(buf.Bytes(), &payload))
+	assert.Equal(map[string]interface{}{"key": "value"}, payload.Data.Meta)
+
+	// Unmarshaling
+	body := []byte(`{"type":"articles","id":"a1","meta":{"version":"4"}}`)
+
+	ures, err := UnmarshalOptions{}.UnmarshalResource(body, schema)
+	assert.NoError(err)
+	assert.Equal("4", ures.(*versionedResource).version)
+
+	version, ok := ResourceVersion(ures)
+	assert.True(ok)
+	assert.Equal("4", version)
+
+	partial, err := UnmarshalPartialResource(body, schema)
+	assert.NoError(err)
+
+	version, ok = ResourceVersion(partial)
+	assert.True(ok)
+	assert.Equal("4", version)
+}
+
+func TestCheckVersion(t *testing.T) {
+	assert := assert.New(t)
+
+	typ := Type{Name: "articles"}
+	typ.MustAddAttr(Attr{Name: "title", Type: AttrTypeString})
+
+	schema := &Schema{}
+	schema.MustAddType(typ)
+
+	current := &versionedResource{SoftResource: &SoftResource{Type: &typ}, version: "3"}
+	current.SetID("a1")
+
+	// No version
+	patch, err := UnmarshalPartialResource([]byte(`{"type":"articles","id":"a1"}`), schema)
+	assert.NoError(err)
+	assert.NoError(CheckVersion(current, patch))
+
+	_, ok := ResourceVersion(patch)
+	assert.False(ok)
+
+	// Same version
+	patch, err = UnmarshalPartialResource(
+		[]byte(`{"type":"articles","id":"a1","meta":{"version":"3"}}`), schema,
+	)
+	assert.NoError(err)
+	assert.NoError(CheckVersion(current, patch))
+
+	// Different version
+	patch, err = UnmarshalPartialResource(
+		[]byte(`{"type":"articles","id":"a1","meta":{"version":"2"}}`), schema,
+	)
+	assert.NoError(err)
+
+	err = CheckVersion(current, patch)
+	assert.Error(err)
+
+	e, ok := err.(Error)
+	assert.True(ok)
+	assert.Equal("409", e.Status)
+	assert.Equal(`The version "2" of the resource is not its current version "3".`, e.Detail)
+	assert.Equal(ErrorSource{Pointer: "/data/meta/version"}, e.Src)
+
+	// The current resource has no version.
+	current.version = ""
+	assert.Error(CheckVersion(current, patch))
+}
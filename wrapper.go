@@ -4,8 +4,21 @@ import (
 	"encoding/json"
 	"fmt"
 	"reflect"
+	"sync"
 )
 
+// wrapperCache maps the reflect.Type of the structs given to Wrap to their
+// *wrapperInfo, so the reflection work is only done once per struct type.
+var wrapperCache sync.Map
+
+// wrapperInfo holds what Wrap computes from the definition of a struct type.
+type wrapperInfo struct {
+	typ Type
+
+	// index maps the member names to the index sequences of the struct fields.
+	index map[string][]int
+}
+
 // Wrapper wraps a reflect.Value that represents a struct.
 //
 // The Wrap function can be used to wrap a struct and make a Wrapper object.
@@ -13,9 +26,12 @@ import (
 // It implements the Resource interface, so the value can be handled as if it
 // were a Resource.
 type Wrapper struct {
-	val  reflect.Value // Actual value (with content)
-	typ  Type
-	meta Meta
+	val     reflect.Value // Actual value (with content)
+	typ     Type
+	index   map[string][]int
+	meta    Meta
+	linkage map[string]Identifiers
+	skipped []*UnknownFieldError
 }
 
 // Wrap wraps v (a struct or a pointer to a struct) and returns a Wrapper that
@@ -42,68 +58,94 @@ func Wrap(v interface{}) *Wrapper {
 		val = val.Elem()
 	}
 
+	info := getWrapperInfo(val.Type())
+
+	w := &Wrapper{
+		val:   val,
+		typ:   info.typ,
+		index: info.index,
+	}
+
+	// Meta
+	if m, ok := v.(MetaHolder); ok {
+		if len(m.Meta()) > 0 {
+			w.SetMeta(m.Meta())
+		}
+	}
+
+	return w
+}
+
+// getWrapperInfo returns the type information of the struct type t. It is
+// computed and validated on the first call for t and cached for the next ones.
+//
+// It panics if t is not a valid resource struct.
+func getWrapperInfo(t reflect.Type) *wrapperInfo {
+	if info, ok := wrapperCache.Load(t); ok {
+		return info.(*wrapperInfo)
+	}
+
+	val := reflect.New(t).Elem()
+
 	err := Check(val.Interface())
 	if err != nil {
 		panic("invalid struct: " + err.Error())
 	}
 
-	typ, attrs, rels := getTypeInfo(val)
-	for _, attr := range attrs {
+	typ := getTypeInfo(val)
+	for _, attr := range typ.Attrs {
 		if attr.Type == AttrTypeInvalid {
 			panic(fmt.Sprintf("jsonapi: unable to resolve attribute type for \"%s.%s\"",
-				typ, attr.Name))
+				typ.Name, attr.Name))
+		}
+
+		if err := checkAttrFormat(attr); err != nil {
+			panic(err.Error())
 		}
 	}
 
-	w := &Wrapper{
-		val: val,
-		typ: Type{
-			Name:  typ,
-			Attrs: attrs,
-			Rels:  rels,
-		},
+	// Check has made sure that the fields are valid.
+	fields, _ := apiFields(t)
+
+	info := &wrapperInfo{
+		typ:   typ,
+		index: make(map[string][]int, len(fields)),
 	}
 
-	// Meta
-	if m, ok := v.(MetaHolder); ok {
-		if len(m.Meta()) > 0 {
-			w.SetMeta(m.Meta())
-		}
+	for _, sf := range fields {
+		info.index[sf.name] = sf.Index
 	}
 
-	return w
+	// Another goroutine may have stored the same information in the meantime.
+	cached, _ := wrapperCache.LoadOrStore(t, info)
+
+	return cached.(*wrapperInfo)
 }
 
 // Attrs returns the attributes of the Wrapper.
+//
+// The map is shared by all the wrappers of the same struct type and must not be
+// modified.
 func (w *Wrapper) Attrs() map[string]Attr {
 	return w.typ.Attrs
 }
 
 // Rels returns the relationships of the Wrapper.
+//
+// The map is shared by all the wrappers of the same struct type and must not be
+// modified.
 func (w *Wrapper) Rels() map[string]Rel {
 	return w.typ.Rels
 }
 
 // Attr returns the attribute that corresponds to the given key.
 func (w *Wrapper) Attr(key string) Attr {
-	for _, attr := range w.typ.Attrs {
-		if attr.Name == key {
-			return attr
-		}
-	}
-
-	return Attr{}
+	return w.typ.Attrs[key]
 }
 
 // Rel returns the relationship that corresponds to the given key.
 func (w *Wrapper) Rel(key string) Rel {
-	for _, rel := range w.typ.Rels {
-		if rel.FromName == key {
-			return rel
-		}
-	}
-
-	return Rel{}
+	return w.typ.Rels[key]
 }
 
 // New returns a copy of the resource under the wrapper.
@@ -113,29 +155,76 @@ func (w *Wrapper) New() Resource {
 }
 
 // GetType returns the wrapped resource's type.
+//
+// The returned type is a copy that can be modified, for example by adding it
+// to a schema, without affecting the other wrappers of the same struct type.
 func (w *Wrapper) GetType() Type {
-	return w.typ
+	return w.typ.Copy()
 }
 
 // Get returns the value associated to the attribute named after key.
+//
+// It panics if the field does not exist. See GetE.
 func (w *Wrapper) Get(key string) interface{} {
+	v, err := w.getField(key)
+	if err != nil {
+		panic(err)
+	}
+
+	return v
+}
+
+// GetE returns the value associated to the attribute named after key, or an
+// *UnknownFieldError if the field does not exist.
+func (w *Wrapper) GetE(key string) (interface{}, error) {
 	return w.getField(key)
 }
 
 // Set sets the value associated to the attribute named after key.
+//
+// Like with SoftResource.Set, the value of a relationship can also be given as
+// identifiers to keep them as the linkage of the relationship.
+//
+// It panics if the field does not exist or if the value cannot be assigned to
+// it. See SetE.
 func (w *Wrapper) Set(key string, val interface{}) {
-	w.setField(key, val)
+	if err := w.SetE(key, val); err != nil {
+		panic(err)
+	}
+}
+
+// SetE sets the value associated to the attribute named after key like Set
+// does. An *UnknownFieldError is returned if the field does not exist and an
+// *InvalidFieldValueError if the value cannot be assigned to it.
+func (w *Wrapper) SetE(key string, val interface{}) error {
+	if rel, ok := w.typ.Rels[key]; ok {
+		if ids, idens, ok := linkageValue(rel, val); ok {
+			if err := w.setField(key, ids); err != nil {
+				return err
+			}
+
+			w.SetLinkage(key, idens)
+
+			return nil
+		}
+	}
+
+	return w.setField(key, val)
 }
 
 // Copy makes a copy of the wrapped resource and returns it.
 //
-// The returned value's concrete type is also a Wrapper.
+// The returned value's concrete type is also a Wrapper. The ID, the values of
+// the fields, the meta values and the linkages are deep copies (see Cloner),
+// so modifying them does not affect w. The other fields of the struct are left
+// to their zero values.
 func (w *Wrapper) Copy() Resource {
 	nw := Wrap(reflect.New(w.val.Type()).Interface())
+	nw.Set("id", w.Get("id"))
 
 	// Attributes
 	for _, attr := range w.Attrs() {
-		nw.Set(attr.Name, w.Get(attr.Name))
+		nw.Set(attr.Name, copyValue(w.Get(attr.Name)))
 	}
 
 	// Relationships
@@ -143,10 +232,13 @@ func (w *Wrapper) Copy() Resource {
 		if rel.ToOne {
 			nw.Set(rel.FromName, w.Get(rel.FromName).(string))
 		} else {
-			nw.Set(rel.FromName, w.Get(rel.FromName).([]string))
+			nw.Set(rel.FromName, copyValue(w.Get(rel.FromName)).([]string))
 		}
 	}
 
+	nw.meta = copyValue(w.meta).(Meta)
+	nw.linkage = copyValue(w.linkage).(map[string]Identifiers)
+
 	return nw
 }
 
@@ -160,64 +252,124 @@ func (w *Wrapper) SetMeta(m Meta) {
 	w.meta = m
 }
 
-// Private methods
+// Linkage returns the identifiers of the relationship named rel.
+func (w *Wrapper) Linkage(rel string) Identifiers {
+	return w.linkage[rel]
+}
 
-func (w *Wrapper) getField(key string) interface{} {
-	if key == "" {
-		panic("key is empty")
+// SetLinkage sets the identifiers of the relationship named rel.
+func (w *Wrapper) SetLinkage(rel string, idens Identifiers) {
+	if w.linkage == nil {
+		w.linkage = map[string]Identifiers{}
 	}
 
-	for i := 0; i < w.val.NumField(); i++ {
-		field := w.val.Field(i)
-		sf := w.val.Type().Field(i)
+	w.linkage[rel] = idens
+}
 
-		if key == sf.Tag.Get("json") && sf.Tag.Get("api") != "" {
-			// If a key does not exist in the attribute map, it's a relationship and does not have
-			// a "zero value".
-			if attr, ok := w.typ.Attrs[key]; ok && isNil(field.Interface()) {
-				zv, _ := GetZeroValue(attr.Type, attr.Array, attr.Nullable)
-				return zv
-			}
+// SkippedFields returns the unknown fields that were skipped when the resource
+// was unmarshaled (see UnmarshalOptions.UnknownFields).
+func (w *Wrapper) SkippedFields() []*UnknownFieldError {
+	return w.skipped
+}
 
-			return field.Interface()
-		}
+// SetSkippedFields sets the unknown fields that were skipped when the resource
+// was unmarshaled.
+func (w *Wrapper) SetSkippedFields(fields []*UnknownFieldError) {
+	w.skipped = fields
+}
+
+// Private methods
+
+func (w *Wrapper) getField(key string) (interface{}, error) {
+	index, ok := w.index[key]
+	if !ok || key == "" {
+		return nil, &UnknownFieldError{Type: w.typ.Name, Field: key}
 	}
 
-	panic(fmt.Sprintf("attribute %q does not exist", key))
+	field := w.val.FieldByIndex(index)
+
+	// If a key does not exist in the attribute map, it's a relationship and does not have
+	// a "zero value".
+	if attr, ok := w.typ.Attrs[key]; ok && isNil(field.Interface()) {
+		return attrZeroValue(attr), nil
+	}
+
+	return field.Interface(), nil
 }
 
-func (w *Wrapper) setField(key string, v interface{}) {
-	if key == "" {
-		panic("key is empty")
+func (w *Wrapper) setField(key string, v interface{}) error {
+	index, ok := w.index[key]
+	if !ok || key == "" {
+		return &UnknownFieldError{Type: w.typ.Name, Field: key}
 	}
 
-	for i := 0; i < w.val.NumField(); i++ {
-		field := w.val.Field(i)
-		sf := w.val.Type().Field(i)
+	field := w.val.FieldByIndex(index)
 
-		if key == sf.Tag.Get("json") {
-			if v == nil {
-				field.Set(reflect.New(field.Type()).Elem())
-				return
-			}
+	if v == nil {
+		field.Set(reflect.New(field.Type()).Elem())
+		return nil
+	}
 
-			val := reflect.ValueOf(v)
-			if val.Type() == field.Type() {
-				field.Set(val)
-				return
-			}
+	val := reflect.ValueOf(v)
+	if val.Type() == field.Type() {
+		field.Set(val)
+		return nil
+	}
+
+	if cv, ok := convertUUID(val, field.Type()); ok {
+		field.Set(cv)
+		return nil
+	}
+
+	attr, isAttr := w.typ.Attrs[key]
 
-			panic(fmt.Sprintf(
-				"got value of type %q, not %q",
-				field.Type(), val.Type(),
-			))
+	if cv, ok := convertNullable(val, field.Type()); ok && isAttr {
+		field.Set(cv)
+		return nil
+	}
+
+	err := fmt.Errorf("got value of type %q, not %q", val.Type(), field.Type())
+	if isAttr && attr.Type == AttrTypeJSON {
+		if err = setJSONField(field, v); err == nil {
+			return nil
 		}
 	}
 
-	panic(fmt.Sprintf("attribute %q does not exist", key))
+	return &InvalidFieldValueError{
+		Type:      w.typ.Name,
+		Field:     key,
+		FieldType: field.Type().String(),
+		Value:     fmt.Sprint(v),
+		asRel:     !isAttr,
+		err:       err,
+	}
+}
+
+// convertNullable converts val, a *T, to a T or val, a T, to a *T if typ is
+// the other one. A nil pointer becomes the zero value of T.
+//
+// This allows the fields whose nullability is overridden by the nullable option
+// of the api tag to be set with the values returned by UnmarshalToType.
+func convertNullable(val reflect.Value, typ reflect.Type) (reflect.Value, bool) {
+	switch {
+	case val.Kind() == reflect.Ptr && val.Type().Elem() == typ:
+		if val.IsNil() {
+			return reflect.Zero(typ), true
+		}
+
+		return val.Elem(), true
+	case typ.Kind() == reflect.Ptr && typ.Elem() == val.Type():
+		ptr := reflect.New(val.Type())
+		ptr.Elem().Set(val)
+
+		return ptr, true
+	}
+
+	return val, false
 }
 
-// ReflectTypeUnmarshaler is a reflection based type unmarshaler.
+// ReflectTypeUnmarshaler is a reflection based TypeUnmarshaler. It can be used
+// as Attr.Unmarshaler for attributes of any type the json package can handle.
 type ReflectTypeUnmarshaler struct {
 	// Type is the "base" type (not nullable and not an array) of the attribute. For example, for
 	// a Struct property of type "*[]string", reflect.TypeOf("") would be correct.
@@ -237,6 +389,8 @@ func (u ReflectTypeUnmarshaler) typ(array, nullable bool) reflect.Type {
 	return u.Type
 }
 
+// GetZeroValue returns the zero value of the type for the given combination of
+// array and nullable. The attribute type is ignored.
 func (u ReflectTypeUnmarshaler) GetZeroValue(_ int, array, nullable bool) interface{} {
 	var typ reflect.Type
 
@@ -264,6 +418,8 @@ func (u ReflectTypeUnmarshaler) GetZeroValue(_ int, array, nullable bool) interf
 	return reflect.Zero(typ).Interface()
 }
 
+// UnmarshalToType unmarshals data into a value of the type according to the
+// Array and Nullable fields of attr.
 func (u ReflectTypeUnmarshaler) UnmarshalToType(data []byte, attr Attr) (interface{}, error) {
 	if data == nil || (!attr.Nullable && string(data) == "null") {
 		return nil, fmt.Errorf("type is not nullable")
@@ -13,6 +13,8 @@ import (
 
 var _ Resource = (*Wrapper)(nil)
 var _ Copier = (*Wrapper)(nil)
+var _ GetterE = (*Wrapper)(nil)
+var _ SetterE = (*Wrapper)(nil)
 
 func TestWrap(t *testing.T) {
 	assert := assert.New(t)
@@ -401,6 +403,176 @@ func TestWrapperSet(t *testing.T) {
 	})
 }
 
+func TestWrapperEmbeddedStruct(t *testing.T) {
+	assert := assert.New(t)
+
+	now := time.Date(2021, 1, 2, 3, 4, 5, 0, time.UTC)
+
+	v := &embeddingType{}
+	res := Wrap(v)
+
+	res.Set("id", "id1")
+	res.Set("title", "A title")
+	res.Set("created-at", now.Add(time.Hour))
+	res.Set("updated-at", now)
+	res.Set("author", "user1")
+
+	assert.Equal("id1", v.ID)
+	assert.Equal("A title", v.Title)
+	assert.Equal(now.Add(time.Hour), v.CreatedAt)
+	assert.Equal(now, v.UpdatedAt)
+	assert.Equal("user1", v.Author)
+
+	assert.Equal("id1", res.Get("id"))
+	assert.Equal(now.Add(time.Hour), res.Get("created-at"))
+	assert.Equal(now, res.Get("updated-at"))
+	assert.Equal("user1", res.Get("author"))
+}
+
+func TestWrapperTypeCache(t *testing.T) {
+	assert := assert.New(t)
+
+	mt1 := &mocktype{Str: "a"}
+	mt2 := &mocktype{Str: "b"}
+
+	w1, w2 := Wrap(mt1), Wrap(mt2)
+
+	// Both wrappers share the same type information.
+	assert.Equal(w1.GetType(), w2.GetType())
+	assert.Equal(reflect.ValueOf(w1.Attrs()).Pointer(), reflect.ValueOf(w2.Attrs()).Pointer())
+	assert.True(MustBuildType(mocktype{}).Equal(w1.GetType()))
+
+	// The values are still distinct.
+	w1.Set("str", "c")
+	assert.Equal("c", mt1.Str)
+	assert.Equal("b", w2.Get("str"))
+
+	// Embedded structs are cached like any other struct.
+	res1, res2 := Wrap(&embeddingType{}), Wrap(&embeddingType{})
+	res1.Set("author", "user1")
+	res2.Set("author", "user2")
+	assert.Equal("user1", res1.Get("author"))
+	assert.Equal("user2", res2.Get("author"))
+
+	// Invalid structs still panic on every call.
+	for i := 0; i < 2; i++ {
+		assert.Panics(func() {
+			_ = Wrap(&struct{ ID string }{})
+		})
+	}
+}
+
+func TestWrapperGetTypeIsolation(t *testing.T) {
+	assert := assert.New(t)
+
+	typ := Wrap(&mocktype{}).GetType()
+	typ.Attrs["str"] = Attr{Name: "str", Type: AttrTypeInt}
+	delete(typ.Rels, "to-1")
+
+	// Through a schema.
+	schema := &Schema{}
+	schema.MustAddType(Wrap(&mocktype{}).GetType())
+	schema.Types[0].MustAddAttr(Attr{Name: "extra", Type: AttrTypeString})
+
+	w := Wrap(&mocktype{Str: "a"})
+	assert.Equal(AttrTypeString, w.Attr("str").Type)
+	assert.Contains(w.Rels(), "to-1")
+	assert.NotContains(w.Attrs(), "extra")
+	assert.True(MustBuildType(mocktype{}).Equal(w.GetType()))
+
+	payload := MarshalResource(w, "", []string{"str"}, nil)
+	assert.Contains(string(payload), `"str":"a"`)
+}
+
+func BenchmarkWrap(b *testing.B) {
+	mt := &mocktype{ID: "id1", Str: "str"}
+
+	b.ReportAllocs()
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		w := Wrap(mt)
+		w.Set("int", i)
+		_ = w.Get("str")
+	}
+}
+
+func TestWrapperTagOptions(t *testing.T) {
+	assert := assert.New(t)
+
+	v := &tagOptionsType{}
+	res := Wrap(v)
+
+	res.Set("display-name", "name")
+	res.Set("full-text", "text")
+	res.Set("owner", "user1")
+
+	assert.Equal("name", v.Name)
+	assert.Equal("text", v.Text)
+	assert.Equal("user1", v.Owner)
+	assert.Equal("name", res.Get("display-name"))
+
+	assert.Panics(func() {
+		_ = res.Get("name")
+	})
+
+	assert.Panics(func() {
+		res.Set("other", "value")
+	})
+}
+
+func TestWrapperNullableOption(t *testing.T) {
+	assert := assert.New(t)
+
+	type nullableOptionType struct {
+		ID   string  `json:"id" api:"nullables"`
+		Name string  `json:"name" api:"attr,nullable"`
+		Nick *string `json:"nick" api:"attr,nullable=false"`
+	}
+
+	typ := MustBuildType(nullableOptionType{})
+	assert.True(typ.Attrs["name"].Nullable)
+	assert.False(typ.Attrs["nick"].Nullable)
+
+	typ.NewFunc = func() Resource {
+		return Wrap(&nullableOptionType{})
+	}
+
+	schema := &Schema{}
+	schema.MustAddType(typ)
+
+	res, err := UnmarshalResource([]byte(`{
+		"id": "n1",
+		"type": "nullables",
+		"attributes": {"name": "Ann", "nick": "A"}
+	}`), schema)
+	assert.NoError(err)
+	assert.Equal("Ann", res.Get("name"))
+	assert.Equal("A", *res.Get("nick").(*string))
+
+	// A null value sets the zero value of a field that is not a pointer.
+	res, err = UnmarshalResource([]byte(`{
+		"id": "n1",
+		"type": "nullables",
+		"attributes": {"name": null}
+	}`), schema)
+	assert.NoError(err)
+	assert.Equal("", res.Get("name"))
+
+	// The fields can also be set with the values of the other kind.
+	v := &nullableOptionType{}
+	w := Wrap(v)
+	name := "Bob"
+
+	assert.NoError(w.SetE("name", &name))
+	assert.NoError(w.SetE("nick", "B"))
+	assert.Equal("Bob", v.Name)
+	assert.Equal("B", *v.Nick)
+
+	assert.NoError(w.SetE("name", (*string)(nil)))
+	assert.Equal("", v.Name)
+}
+
 func TestWrapperGetAndSetErrors(t *testing.T) {
 	assert := assert.New(t)
 
@@ -787,3 +959,78 @@ func TestReflectTypeUnmarshaler_UnmarshalToType(t *testing.T) {
 		assert.Error(t, err)
 	})
 }
+
+func TestWrapperLinkage(t *testing.T) {
+	assert := assert.New(t)
+
+	wrap := Wrap(&mockType1{})
+	assert.Nil(wrap.Linkage("to-one"))
+
+	idens := Identifiers{{ID: "id1", Type: "mocktypes2", Meta: Meta{"key": "val"}}}
+	wrap.SetLinkage("to-one", idens)
+	assert.Equal(idens, wrap.Linkage("to-one"))
+	assert.Nil(wrap.Linkage("to-many"))
+}
+
+func TestWrapperSetIdentifiers(t *testing.T) {
+	assert := assert.New(t)
+
+	mt := &mockType1{}
+	wrap := Wrap(mt)
+
+	iden := Identifier{ID: "id1", Type: "mocktypes2", Meta: Meta{"key": "val"}}
+	idens := Identifiers{{ID: "id2", Type: "mocktypes2"}}
+
+	wrap.Set("to-one", iden)
+	wrap.Set("to-many", RelDataMany{Res: idens})
+	assert.Equal("id1", mt.ToOne)
+	assert.Equal([]string{"id2"}, mt.ToMany)
+	assert.Equal(Identifiers{iden}, wrap.Linkage("to-one"))
+	assert.Equal(idens, wrap.Linkage("to-many"))
+
+	// Wrong cardinality
+	assert.Panics(func() {
+		wrap.Set("to-many", iden)
+	})
+}
+
+func TestWrapperGetSetE(t *testing.T) {
+	assert := assert.New(t)
+
+	wrap := Wrap(&mockType1{ID: "mt1"})
+
+	assert.NoError(wrap.SetE("str", "abc"))
+	assert.NoError(wrap.SetE("to-one", "mt2"))
+
+	v, err := wrap.GetE("str")
+	assert.NoError(err)
+	assert.Equal("abc", v)
+
+	// Unknown field
+	err = wrap.SetE("unknown", "abc")
+	assert.EqualError(err, `jsonapi: field "unknown" does not exist in resource type "mocktypes1"`)
+
+	_, err = wrap.GetE("")
+	assert.IsType(&UnknownFieldError{}, err)
+
+	assert.PanicsWithError(
+		`jsonapi: field "unknown" does not exist in resource type "mocktypes1"`,
+		func() { _ = wrap.Get("unknown") },
+	)
+
+	// Invalid value
+	err = wrap.SetE("int", "abc")
+	assert.EqualError(err,
+		`jsonapi: invalid value "abc" for field "int": got value of type "string", not "int"`)
+
+	ive := &InvalidFieldValueError{}
+	assert.ErrorAs(err, &ive)
+	assert.True(ive.IsAttr())
+	assert.Equal("int", ive.FieldType)
+
+	err = wrap.SetE("to-many", "mt2")
+	assert.ErrorAs(err, &ive)
+	assert.False(ive.IsAttr())
+
+	assert.Panics(func() { wrap.Set("int", "abc") })
+}
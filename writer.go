@@ -0,0 +1,733 @@
+package jsonapi
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"math"
+	"reflect"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+	"unicode/utf8"
+)
+
+// jsonWriter writes JSON directly into a buffer. It is used to marshal resources
+// without building intermediate maps that would have to be encoded again.
+//
+// The first error that occurs is stored and all subsequent writes are ignored.
+// Object members are written in alphabetical order to produce the same output
+// as the json package does for maps.
+type jsonWriter struct {
+	buf  *bytes.Buffer
+	enc  *json.Encoder
+	err  error
+	opts MarshalOptions
+}
+
+func newJSONWriter(buf *bytes.Buffer) *jsonWriter {
+	return &jsonWriter{
+		buf: buf,
+		enc: json.NewEncoder(buf),
+	}
+}
+
+// writerPool holds jsonWriters whose buffers and encoders can be reused.
+var writerPool = sync.Pool{
+	New: func() interface{} {
+		return newJSONWriter(&bytes.Buffer{})
+	},
+}
+
+// maxPooledBuffer is the capacity above which a buffer is not put back in the
+// pool, so that a single large payload does not keep its memory alive.
+const maxPooledBuffer = 1 << 20
+
+// writer returns a jsonWriter with an empty buffer. It comes from the pool
+// unless the options disable it and must be given back with release.
+func (o MarshalOptions) writer() *jsonWriter {
+	var w *jsonWriter
+
+	if o.DisablePooling {
+		w = newJSONWriter(&bytes.Buffer{})
+	} else {
+		w = writerPool.Get().(*jsonWriter)
+		w.buf.Reset()
+		w.err = nil
+	}
+
+	w.opts = o
+
+	return w
+}
+
+// bytes returns the content of the buffer of w. It is copied if w comes from
+// the pool, because the buffer is reused once w is released.
+func (o MarshalOptions) bytes(w *jsonWriter) []byte {
+	if o.DisablePooling {
+		return w.buf.Bytes()
+	}
+
+	b := make([]byte, w.buf.Len())
+	copy(b, w.buf.Bytes())
+
+	return b
+}
+
+// release puts w back in the pool if it comes from it.
+func (o MarshalOptions) release(w *jsonWriter) {
+	if o.DisablePooling || w.buf.Cap() > maxPooledBuffer {
+		return
+	}
+
+	// The options can hold a schema and hooks that should not be kept alive.
+	w.opts = MarshalOptions{}
+	writerPool.Put(w)
+}
+
+// value writes the JSON encoding of v.
+func (w *jsonWriter) value(v interface{}) {
+	if w.err != nil {
+		return
+	}
+
+	if err := w.enc.Encode(v); err != nil {
+		w.err = err
+		return
+	}
+
+	// Encode always terminates the value with a newline.
+	w.buf.Truncate(w.buf.Len() - 1)
+}
+
+// string writes s as a JSON string.
+func (w *jsonWriter) string(s string) {
+	if w.err != nil {
+		return
+	}
+
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		if c < 0x20 || c >= utf8.RuneSelf || c == '"' || c == '\\' ||
+			c == '<' || c == '>' || c == '&' {
+			// Let the json package deal with escaping.
+			w.value(s)
+			return
+		}
+	}
+
+	w.buf.WriteByte('"')
+	w.buf.WriteString(s)
+	w.buf.WriteByte('"')
+}
+
+// key writes the name of an object member. A comma is written before it if
+// it is not the first member.
+func (w *jsonWriter) key(name string, first bool) {
+	if !first {
+		w.buf.WriteByte(',')
+	}
+
+	w.string(name)
+	w.buf.WriteByte(':')
+}
+
+// uint8Array writes a *[]uint8 or []byte as a literal array of numbers instead
+// of a base64 encoded string.
+func (w *jsonWriter) uint8Array(a *[]uint8) {
+	if a == nil {
+		w.buf.WriteString("null")
+		return
+	}
+
+	var num [3]byte
+
+	w.buf.WriteByte('[')
+
+	for i, b := range *a {
+		if i > 0 {
+			w.buf.WriteByte(',')
+		}
+
+		w.buf.Write(strconv.AppendUint(num[:0], uint64(b), 10))
+	}
+
+	w.buf.WriteByte(']')
+}
+
+// attrValue writes v, the value of attr.
+func (w *jsonWriter) attrValue(attr Attr, v interface{}) {
+	switch {
+	case attr.Marshaler != nil:
+		if w.err != nil {
+			return
+		}
+
+		b, err := attr.Marshaler.MarshalAttr(v, attr)
+		if err == nil {
+			err = json.Compact(w.buf, b)
+		}
+
+		if err != nil {
+			w.err = err
+		}
+	case attr.Type == AttrTypeDecimal && w.opts.DecimalAsNumber:
+		w.each(v, func(elem interface{}) {
+			w.buf.WriteString(elem.(Decimal).String())
+		})
+	case attr.Type == AttrTypeTime:
+		// A nullable time set to the zero time has no meaningful value.
+		if t, ok := v.(*time.Time); ok && t != nil && t.IsZero() {
+			w.buf.WriteString("null")
+			return
+		}
+
+		w.each(v, func(elem interface{}) {
+			t, ok := elem.(time.Time)
+			if !ok {
+				w.value(elem)
+				return
+			}
+
+			if w.opts.UTCTimes {
+				t = t.UTC()
+			}
+
+			w.buf.Write(formatTime(t, attr.Format))
+		})
+	case attr.Type == AttrTypeDuration:
+		w.each(v, func(elem interface{}) {
+			w.buf.Write(formatDuration(elem.(time.Duration), attr.Format))
+		})
+	case attr.Type == AttrTypeFloat32 || attr.Type == AttrTypeFloat64:
+		w.each(v, func(elem interface{}) {
+			var err error
+
+			switch f := elem.(type) {
+			case float32:
+				err = appendFloat(w.buf, float64(f), 32)
+			case float64:
+				err = appendFloat(w.buf, f, 64)
+			default:
+				w.value(elem)
+			}
+
+			if err != nil {
+				w.err = err
+			}
+		})
+	default:
+		w.value(v)
+	}
+}
+
+// appendFloat writes the canonical representation of f, a float of the given
+// bit size. It is the shortest one that parses back into the same value, with
+// an exponent only if the absolute value is less than 1e-6 or at least 1e21.
+// The output does not depend on the json package, so payloads stay the same
+// across Go versions.
+func appendFloat(buf *bytes.Buffer, f float64, bits int) error {
+	if math.IsInf(f, 0) || math.IsNaN(f) {
+		return fmt.Errorf("jsonapi: unsupported float value %v", f)
+	}
+
+	format := byte('f')
+
+	if abs := math.Abs(f); abs != 0 {
+		// The limits are compared with the precision of f, so that a float32
+		// set to 1e-6 is not written with an exponent.
+		if bits == 32 && (float32(abs) < 1e-6 || float32(abs) >= 1e21) ||
+			bits == 64 && (abs < 1e-6 || abs >= 1e21) {
+			format = 'e'
+		}
+	}
+
+	b := strconv.AppendFloat(make([]byte, 0, 24), f, format, -1, bits)
+
+	// The exponent is written without a leading zero (1e-7 and not 1e-07).
+	if n := len(b); format == 'e' && n >= 4 && b[n-4] == 'e' && b[n-3] == '-' &&
+		b[n-2] == '0' {
+		b[n-2] = b[n-1]
+		b = b[:n-1]
+	}
+
+	buf.Write(b)
+
+	return nil
+}
+
+// each writes v, a value, a slice or a pointer to any of them, by calling write
+// with each element that is not a slice or a pointer.
+func (w *jsonWriter) each(v interface{}, write func(elem interface{})) {
+	if w.err != nil {
+		return
+	}
+
+	rv := reflect.ValueOf(v)
+
+	switch rv.Kind() {
+	case reflect.Invalid:
+		w.buf.WriteString("null")
+	case reflect.Ptr:
+		if rv.IsNil() {
+			w.buf.WriteString("null")
+			return
+		}
+
+		w.each(rv.Elem().Interface(), write)
+	case reflect.Slice:
+		w.buf.WriteByte('[')
+
+		for i := 0; i < rv.Len(); i++ {
+			if i > 0 {
+				w.buf.WriteByte(',')
+			}
+
+			w.each(rv.Index(i).Interface(), write)
+		}
+
+		w.buf.WriteByte(']')
+	default:
+		write(v)
+	}
+}
+
+// isEmptyAttrValue reports whether v is an empty attribute value according to
+// Attr.OmitEmpty.
+func isEmptyAttrValue(v interface{}) bool {
+	if isNil(v) {
+		return true
+	}
+
+	switch v := v.(type) {
+	case Decimal:
+		return v.Sign() == 0
+	case interface{ IsZero() bool }:
+		return v.IsZero()
+	}
+
+	rv := reflect.ValueOf(v)
+
+	switch rv.Kind() {
+	case reflect.String, reflect.Slice, reflect.Map:
+		return rv.Len() == 0
+	case reflect.Ptr:
+		return false
+	}
+
+	return rv.IsZero()
+}
+
+// get returns the value of the field named key of r. If r is a GetterE that
+// returns an error, the error is kept in w.err and nil is returned.
+func (w *jsonWriter) get(r Resource, key string) interface{} {
+	v, err := getValue(r, key)
+	if err != nil {
+		if w.err == nil {
+			w.err = fmt.Errorf("jsonapi: failed to get field %q of type %q: %w",
+				key, r.GetType().Name, err)
+		}
+
+		return nil
+	}
+
+	return v
+}
+
+// resource writes the resource object of r.
+func (w *jsonWriter) resource(r Resource, prepath string, fields []string,
+	relData map[string][]string) {
+	typ := r.GetType()
+	id, _ := w.get(r, "id").(string)
+	self := w.opts.resourceLink(prepath, typ.Name, id)
+	fields = w.opts.schemaFields(typ.Name, fields)
+	fields = w.opts.FieldMask.Apply(typ.Name, fields)
+
+	w.buf.WriteByte('{')
+
+	// Attributes
+	attrs := r.Attrs()
+	names := make([]string, 0, len(fields))
+
+	for _, attr := range attrs {
+		if !containsString(fields, attr.Name) {
+			continue
+		}
+
+		if attr.OmitEmpty && isEmptyAttrValue(w.get(r, attr.Name)) {
+			continue
+		}
+
+		names = append(names, attr.Name)
+	}
+
+	if len(names) > 0 {
+		w.opts.Schema.sortByMemberName(names)
+
+		w.key("attributes", true)
+		w.buf.WriteByte('{')
+
+		for i, name := range names {
+			attr := attrs[name]
+			w.key(w.opts.Schema.memberName(attr.Name), i == 0)
+
+			v, err := getValue(r, attr.Name)
+			if err != nil {
+				w.buf.WriteString("null")
+
+				if w.err == nil {
+					w.err = fmt.Errorf("jsonapi: failed to get attribute %q of type %q: %w",
+						attr.Name, typ.Name, err)
+				}
+
+				continue
+			}
+
+			if attr.Transformer != nil {
+				if v, err = attr.Transformer.TransformOut(v, attr); err != nil {
+					w.buf.WriteString("null")
+
+					if w.err == nil {
+						w.err = fmt.Errorf("jsonapi: failed to transform attribute %q of type %q: %w",
+							attr.Name, typ.Name, err)
+					}
+
+					continue
+				}
+			}
+
+			// AttrTypeUint8(Array=true) is handled like any other array.
+			if attr.Type == AttrTypeUint8 && attr.Array {
+				if attr.Nullable {
+					w.uint8Array(v.(*[]uint8))
+				} else {
+					a := v.([]uint8)
+					w.uint8Array(&a)
+				}
+
+				continue
+			}
+
+			w.attrValue(attr, v)
+		}
+
+		w.buf.WriteByte('}')
+	}
+
+	var written []string
+	if w.opts.Stats != nil {
+		written = append(written, names...)
+	}
+
+	// ID
+	w.key("id", len(names) == 0)
+	w.string(id)
+
+	// Links
+	w.key("links", false)
+
+	w.links(w.opts.resourceLinks(r), self, "")
+
+	// Meta
+	if meta := w.opts.resourceMeta(r); len(meta) > 0 {
+		w.key("meta", false)
+		w.value(meta)
+	}
+
+	// Relationships
+	rels := r.Rels()
+	names = names[:0]
+
+	for _, rel := range rels {
+		if containsString(fields, rel.FromName) {
+			names = append(names, rel.FromName)
+		}
+	}
+
+	if len(names) > 0 {
+		w.opts.Schema.sortByMemberName(names)
+
+		relsStart := w.buf.Len()
+		first := true
+
+		w.key("relationships", false)
+		w.buf.WriteByte('{')
+
+		// The empty relationship objects are removed, and so is the
+		// relationships object if all of them are empty.
+		for _, name := range names {
+			rel := rels[name]
+			start := w.buf.Len()
+
+			w.key(w.opts.Schema.memberName(rel.FromName), first)
+
+			if w.relationship(r, rel, self, containsString(relData[typ.Name], rel.FromName)) {
+				first = false
+
+				if w.opts.Stats != nil {
+					written = append(written, rel.FromName)
+				}
+			} else {
+				w.buf.Truncate(start)
+			}
+		}
+
+		w.buf.WriteByte('}')
+
+		if first {
+			w.buf.Truncate(relsStart)
+		}
+	}
+
+	// Type
+	w.key("type", false)
+	w.string(typ.Name)
+
+	w.buf.WriteByte('}')
+
+	if w.opts.Stats != nil && w.err == nil {
+		w.opts.Stats.add(typ.Name, written)
+	}
+}
+
+// relationship writes the relationship object of rel. The linkage is only
+// written if withData is true.
+//
+// false is returned if the object is empty, which happens when it has no
+// linkage, no links and no meta. It is not a valid relationship object.
+func (w *jsonWriter) relationship(r Resource, rel Rel, self string, withData bool) bool {
+	var (
+		links map[string]Link
+		meta  Meta
+
+		// count is the size of the relationship if it is known (see
+		// RelCountHolder) or if its linkage is omitted because of
+		// MarshalOptions.MaxLinkage.
+		count    int
+		hasCount bool
+	)
+
+	w.buf.WriteByte('{')
+
+	if withData {
+		val := w.get(r, rel.FromName)
+
+		if rel.ToOne {
+			switch v := val.(type) {
+			case RelData:
+				links, meta = v.Links, v.Meta
+
+				w.key("data", true)
+				w.identifier(v.Res.ID, rel.ToType, v.Res.Meta)
+			case string:
+				w.key("data", true)
+
+				if v != "" {
+					w.identifier(v, rel.ToType, linkageMetas(r, rel.FromName)[v])
+				} else {
+					w.buf.WriteString("null")
+				}
+			default:
+				withData = false
+			}
+		} else if n := len(relIDs(val)); w.opts.MaxLinkage > 0 && n > w.opts.MaxLinkage {
+			withData = false
+
+			if v, ok := val.(RelDataMany); ok {
+				links, meta = v.Links, v.Meta
+			}
+
+			count, hasCount = n, true
+		} else {
+			w.key("data", true)
+			w.buf.WriteByte('[')
+
+			switch v := val.(type) {
+			case RelDataMany:
+				links, meta = v.Links, v.Meta
+
+				idens := v.Res
+				if !w.opts.KeepLinkageOrder && !sort.SliceIsSorted(idens, func(i, j int) bool {
+					return idens[i].ID < idens[j].ID
+				}) {
+					idens = make(Identifiers, len(v.Res))
+					copy(idens, v.Res)
+					sort.SliceStable(idens, func(i, j int) bool {
+						return idens[i].ID < idens[j].ID
+					})
+				}
+
+				for i := range idens {
+					if i > 0 {
+						w.buf.WriteByte(',')
+					}
+
+					w.identifier(idens[i].ID, rel.ToType, idens[i].Meta)
+				}
+			case []string:
+				ids := v
+				if !w.opts.KeepLinkageOrder && !sort.StringsAreSorted(ids) {
+					ids = make([]string, len(v))
+					copy(ids, v)
+					sort.Strings(ids)
+				}
+
+				metas := linkageMetas(r, rel.FromName)
+
+				for i := range ids {
+					if i > 0 {
+						w.buf.WriteByte(',')
+					}
+
+					w.identifier(ids[i], rel.ToType, metas[ids[i]])
+				}
+			}
+
+			w.buf.WriteByte(']')
+		}
+	}
+
+	// written reports whether a member was written.
+	written := withData
+
+	// The relationship links cannot be overridden.
+	relSelf, related := w.opts.relLinks(r.GetType().Name, rel, self)
+	if relSelf != "" || related != "" || len(links) > 0 {
+		w.key("links", !written)
+		w.links(links, relSelf, related)
+
+		written = true
+	}
+
+	if rmh, ok := r.(RelMetaHolder); ok && len(meta) == 0 {
+		meta = rmh.RelMeta(rel.FromName)
+	}
+
+	if rch, ok := r.(RelCountHolder); ok && !rel.ToOne {
+		if n, ok := rch.RelCount(rel.FromName); ok {
+			count, hasCount = n, true
+		}
+	}
+
+	if hasCount {
+		m := make(Meta, len(meta)+1)
+		for k, v := range meta {
+			m[k] = v
+		}
+
+		m["count"] = count
+		meta = m
+	}
+
+	if len(meta) > 0 {
+		w.key("meta", !written)
+		w.value(meta)
+	}
+
+	w.buf.WriteByte('}')
+
+	return written || len(meta) > 0
+}
+
+// identifier writes a resource identifier object.
+func (w *jsonWriter) identifier(id, typ string, meta Meta) {
+	w.buf.WriteString(`{"id":`)
+	w.string(id)
+
+	if len(meta) > 0 {
+		w.key("meta", false)
+		w.value(meta)
+	}
+
+	w.key("type", false)
+	w.string(typ)
+	w.buf.WriteByte('}')
+}
+
+// links writes a links object made of the self link and the related link if
+// they are not empty, and the given links. The self and related links take
+// precedence over the links of the same name.
+func (w *jsonWriter) links(links map[string]Link, self, related string) {
+	w.buf.WriteByte('{')
+
+	if len(links) == 0 {
+		if related != "" {
+			w.key("related", true)
+			w.string(related)
+		}
+
+		if self != "" {
+			w.key("self", related == "")
+			w.string(self)
+		}
+
+		w.buf.WriteByte('}')
+
+		return
+	}
+
+	names := make([]string, 0, len(links)+2)
+
+	if self != "" {
+		names = append(names, "self")
+	}
+
+	if related != "" {
+		names = append(names, "related")
+	}
+
+	for name := range links {
+		if (name != "self" || self == "") && (name != "related" || related == "") {
+			names = append(names, name)
+		}
+	}
+
+	sort.Strings(names)
+
+	for i, name := range names {
+		w.key(name, i == 0)
+
+		switch {
+		case name == "self" && self != "":
+			w.string(self)
+		case name == "related" && related != "":
+			w.string(related)
+		default:
+			w.value(links[name])
+		}
+	}
+
+	w.buf.WriteByte('}')
+}
+
+// linkageMetas returns the meta of the identifiers of the relationship named
+// rel, indexed by ID, if r is a LinkageHolder. The first identifier wins if an
+// ID appears more than once.
+func linkageMetas(r Resource, rel string) map[string]Meta {
+	lh, ok := r.(LinkageHolder)
+	if !ok {
+		return nil
+	}
+
+	idens := lh.Linkage(rel)
+	metas := make(map[string]Meta, len(idens))
+
+	for _, iden := range idens {
+		if _, ok := metas[iden.ID]; !ok {
+			metas[iden.ID] = iden.Meta
+		}
+	}
+
+	return metas
+}
+
+func containsString(s []string, str string) bool {
+	for i := range s {
+		if s[i] == str {
+			return true
+		}
+	}
+
+	return false
+}